@@ -0,0 +1,153 @@
+package querybuild
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// aggregateField 获取聚合的字段引用或已注册表达式及其参数
+func (qb *QueryBuilder[T]) aggregateField(query *gorm.DB, aggr Aggregation) (string, []interface{}, error) {
+	if aggr.Expr != "" {
+		expr, err := qb.expression(aggr.Expr)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("(%s)", expr.SQL), expr.Args, nil
+	}
+
+	field, err := qb.resolveField(query, aggr.Field)
+	if err != nil {
+		return "", nil, err
+	}
+	// 配置了SQL脱敏表达式的字段聚合脱敏后的取值，避免 MAX 等聚合返回原始值
+	return qb.maskedColumn(query, aggr.Field, field), nil, nil
+}
+
+// conditionalAggregateExpr 生成可能带条件的聚合表达式及其参数
+//
+// PostgreSQL 使用 FILTER (WHERE ...) 子句，其他方言使用 CASE WHEN 包装字段，
+// SUM 在条件不满足时计为 0，其余聚合计为 NULL。
+func (qb *QueryBuilder[T]) conditionalAggregateExpr(query *gorm.DB, field string, fieldArgs []interface{}, aggr Aggregation) (string, []interface{}, error) {
+	if aggr.Condition == nil {
+		expr, err := qb.aggregateExpr(field, aggr)
+		return expr, repeatArgs(expr, field, fieldArgs), err
+	}
+
+	condField, err := qb.resolveField(query, aggr.Condition.Field)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := qb.checkOperator(aggr.Condition.Op); err != nil {
+		return "", nil, err
+	}
+	cond, condArgs := buildCondition(condField, *aggr.Condition)
+	if cond == "" {
+		return "", nil, fmt.Errorf("invalid aggregation condition on field: %s", aggr.Condition.Field)
+	}
+
+	if qb.dialect() == dialectPostgres {
+		expr, err := qb.aggregateExpr(field, aggr)
+		if err != nil || expr == "" {
+			return expr, nil, err
+		}
+		args := append(repeatArgs(expr, field, fieldArgs), condArgs...)
+		return fmt.Sprintf("%s FILTER (WHERE %s)", expr, cond), args, nil
+	}
+
+	caseField := fmt.Sprintf("CASE WHEN %s THEN %s END", cond, field)
+	if aggr.Op == SUM {
+		caseField = fmt.Sprintf("CASE WHEN %s THEN %s ELSE 0 END", cond, field)
+	}
+	expr, err := qb.aggregateExpr(caseField, aggr)
+	if err != nil || expr == "" {
+		return expr, nil, err
+	}
+	return expr, repeatArgs(expr, caseField, append(condArgs, fieldArgs...)), nil
+}
+
+// repeatArgs 按字段在表达式中的引用次数重复绑定参数，近似实现可能多次引用同一字段
+func repeatArgs(expr, field string, args []interface{}) []interface{} {
+	if len(args) == 0 {
+		return nil
+	}
+	var repeated []interface{}
+	for i := strings.Count(expr, field); i > 0; i-- {
+		repeated = append(repeated, args...)
+	}
+	return repeated
+}
+
+// aggregateExpr 生成聚合表达式，未知的聚合类型返回空字符串
+//
+// STDDEV、VARIANCE 为样本统计量；PERCENTILE、MEDIAN 仅支持 PostgreSQL（percentile_cont）。
+// SQLite 默认不提供统计函数，VARIANCE 使用 SUM/COUNT 公式近似计算，STDDEV 与百分位数返回错误。
+func (qb *QueryBuilder[T]) aggregateExpr(field string, aggr Aggregation) (string, error) {
+	dialect := qb.dialect()
+	switch aggr.Op {
+	case COUNT:
+		return fmt.Sprintf("COUNT(%s)", field), nil
+	case SUM:
+		return fmt.Sprintf("SUM(%s)", field), nil
+	case AVG:
+		return fmt.Sprintf("AVG(%s)", field), nil
+	case MAX:
+		return fmt.Sprintf("MAX(%s)", field), nil
+	case MIN:
+		return fmt.Sprintf("MIN(%s)", field), nil
+	case STDDEV:
+		if dialect == dialectSQLite {
+			break
+		}
+		return fmt.Sprintf("STDDEV_SAMP(%s)", field), nil
+	case VARIANCE:
+		if dialect == dialectSQLite {
+			return fmt.Sprintf("(SUM(%[1]s * %[1]s) - SUM(%[1]s) * SUM(%[1]s) * 1.0 / COUNT(%[1]s)) / (COUNT(%[1]s) - 1)", field), nil
+		}
+		return fmt.Sprintf("VAR_SAMP(%s)", field), nil
+	case PERCENTILE, MEDIAN:
+		if dialect != dialectPostgres {
+			break
+		}
+		fraction := 0.5
+		if aggr.Op == PERCENTILE {
+			fraction = aggr.Fraction
+		}
+		if fraction < 0 || fraction > 1 {
+			return "", fmt.Errorf("invalid percentile fraction: %v", aggr.Fraction)
+		}
+		return fmt.Sprintf("percentile_cont(%s) WITHIN GROUP (ORDER BY %s)",
+			strconv.FormatFloat(fraction, 'f', -1, 64), field), nil
+	default:
+		return "", nil
+	}
+	return "", fmt.Errorf("aggregation %s is unsupported on dialect %s", aggr.Op, dialect)
+}
+
+// String 返回聚合操作类型的字符串表示
+func (op AggregationOp) String() string {
+	switch op {
+	case COUNT:
+		return "COUNT"
+	case SUM:
+		return "SUM"
+	case AVG:
+		return "AVG"
+	case MAX:
+		return "MAX"
+	case MIN:
+		return "MIN"
+	case STDDEV:
+		return "STDDEV"
+	case VARIANCE:
+		return "VARIANCE"
+	case PERCENTILE:
+		return "PERCENTILE"
+	case MEDIAN:
+		return "MEDIAN"
+	default:
+		return "UNKNOWN_OP"
+	}
+}
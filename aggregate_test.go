@@ -0,0 +1,239 @@
+package querybuild
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// namedDialector 复用 SQLite 连接但报告指定方言名称，用于验证方言相关的SQL生成
+type namedDialector struct {
+	gorm.Dialector
+	name string
+}
+
+func (d namedDialector) Name() string {
+	return d.name
+}
+
+// setupDialectDB 创建报告指定方言名称的 DryRun 数据库连接
+func setupDialectDB(t *testing.T, name string) *gorm.DB {
+	db, err := gorm.Open(namedDialector{Dialector: sqlite.Open(":memory:"), name: name}, &gorm.Config{DryRun: true})
+	assert.NoError(t, err)
+	return db
+}
+
+func TestQueryBuilder_StatisticalAggregations(t *testing.T) {
+	t.Run("Variance approximation on SQLite", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](setupTestDB(t))
+		type Result struct {
+			AgeVar float64 `gorm:"column:age_var"`
+		}
+		var result Result
+		err := builder.FindOne(&FilterRequest{
+			Aggrs: []Aggregation{{Field: "Age", Op: VARIANCE, Alias: "age_var"}},
+		}, &result)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(25), result.AgeVar)
+	})
+
+	t.Run("Unsupported on SQLite", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](setupTestDB(t))
+		for _, op := range []AggregationOp{STDDEV, PERCENTILE, MEDIAN} {
+			var result map[string]interface{}
+			err := builder.FindOne(&FilterRequest{
+				Aggrs: []Aggregation{{Field: "Age", Op: op, Fraction: 0.9}},
+			}, &result)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "unsupported on dialect sqlite")
+		}
+	})
+
+	t.Run("Postgres rendering", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](setupDialectDB(t, "postgres"))
+		var results []map[string]interface{}
+		query := builder.Build(&FilterRequest{
+			Aggrs: []Aggregation{
+				{Field: "Age", Op: PERCENTILE, Fraction: 0.95, Alias: "p95"},
+				{Field: "Age", Op: MEDIAN, Alias: "p50"},
+				{Field: "Age", Op: STDDEV, Alias: "sd"},
+			},
+		}).Find(&results)
+		assert.NoError(t, query.Error)
+		sql := query.Statement.SQL.String()
+		assert.Contains(t, sql, "percentile_cont(0.95) WITHIN GROUP (ORDER BY `test_users`.`age`) as p95")
+		assert.Contains(t, sql, "percentile_cont(0.5) WITHIN GROUP (ORDER BY `test_users`.`age`) as p50")
+		assert.Contains(t, sql, "STDDEV_SAMP(`test_users`.`age`) as sd")
+	})
+
+	t.Run("Invalid fraction", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](setupDialectDB(t, "postgres"))
+		var results []map[string]interface{}
+		err := builder.FindAll(&FilterRequest{
+			Aggrs: []Aggregation{{Field: "Age", Op: PERCENTILE, Fraction: 1.5}},
+		}, &results)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid percentile fraction")
+	})
+}
+
+func TestQueryBuilder_ConditionalAggregations(t *testing.T) {
+	t.Run("Side by side conditional counts", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](setupTestDB(t))
+		type Result struct {
+			ActiveCount   int64   `gorm:"column:active_count"`
+			InactiveCount int64   `gorm:"column:inactive_count"`
+			ActiveAgeSum  float64 `gorm:"column:active_age_sum"`
+			OldAgeVar     float64 `gorm:"column:old_age_var"`
+		}
+		var result Result
+		err := builder.FindOne(&FilterRequest{
+			Aggrs: []Aggregation{
+				{Field: "ID", Op: COUNT, Alias: "active_count", Condition: &Filter{Field: "Status", Op: EQ, Value: "active"}},
+				{Field: "ID", Op: COUNT, Alias: "inactive_count", Condition: &Filter{Field: "Status", Op: EQ, Value: "inactive"}},
+				{Field: "Age", Op: SUM, Alias: "active_age_sum", Condition: &Filter{Field: "Status", Op: EQ, Value: "active"}},
+				{Field: "Age", Op: VARIANCE, Alias: "old_age_var", Condition: &Filter{Field: "Age", Op: GE, Value: "30"}},
+			},
+		}, &result)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), result.ActiveCount)
+		assert.Equal(t, int64(1), result.InactiveCount)
+		assert.Equal(t, float64(60), result.ActiveAgeSum)
+		assert.Equal(t, 12.5, result.OldAgeVar)
+	})
+
+	t.Run("Postgres FILTER clause", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](setupDialectDB(t, "postgres"))
+		var results []map[string]interface{}
+		query := builder.Build(&FilterRequest{
+			Aggrs: []Aggregation{
+				{Field: "ID", Op: COUNT, Alias: "active_count", Condition: &Filter{Field: "Status", Op: EQ, Value: "active"}},
+			},
+		}).Find(&results)
+		assert.NoError(t, query.Error)
+		assert.Contains(t, query.Statement.SQL.String(), "COUNT(`test_users`.`id`) FILTER (WHERE `test_users`.`status` = ?) as active_count")
+		assert.Equal(t, []interface{}{"active"}, query.Statement.Vars)
+	})
+
+	t.Run("Invalid condition field", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](setupTestDB(t))
+		var result map[string]interface{}
+		err := builder.FindOne(&FilterRequest{
+			Aggrs: []Aggregation{
+				{Field: "ID", Op: COUNT, Condition: &Filter{Field: "Password", Op: EQ, Value: "x"}},
+			},
+		}, &result)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid field name")
+	})
+}
+
+func TestQueryBuilder_AddSelects(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+
+	t.Run("Grouping column in select list", func(t *testing.T) {
+		type Result struct {
+			Status string `gorm:"column:status"`
+			Count  int64  `gorm:"column:count"`
+		}
+		var results []Result
+		err := builder.FindAll(&FilterRequest{
+			Groups: []Group{{Field: "Status"}},
+			Aggrs: []Aggregation{
+				{Field: "ID", Op: COUNT, Alias: "count", AddSelects: []string{"Status"}},
+				{Field: "Age", Op: MAX, Alias: "max_age", AddSelects: []string{"Status"}},
+			},
+			Sorts: []Sort{{Field: "Status"}},
+		}, &results)
+		assert.NoError(t, err)
+		assert.Equal(t, []Result{{Status: "active", Count: 2}, {Status: "inactive", Count: 1}}, results)
+	})
+
+	t.Run("Join alias column", func(t *testing.T) {
+		orders := NewQueryBuilder[TestOrder](db)
+		type Result struct {
+			Name  string  `gorm:"column:name"`
+			Total float64 `gorm:"column:total"`
+		}
+		var results []Result
+		err := orders.FindAll(&FilterRequest{
+			Joins:  []Join{{Type: "INNER", Table: "test_users", Alias: "u", Condition: "u.id = test_orders.user_id"}},
+			Groups: []Group{{Field: "u.name"}},
+			Aggrs:  []Aggregation{{Field: "Amount", Op: SUM, Alias: "total", AddSelects: []string{"u.name"}}},
+			Sorts:  []Sort{{Field: "u.name"}},
+		}, &results)
+		assert.NoError(t, err)
+		assert.Equal(t, []Result{{Name: "Jane Smith", Total: 80}, {Name: "John Doe", Total: 150}}, results)
+	})
+
+	t.Run("Invalid select is rejected", func(t *testing.T) {
+		var results []map[string]interface{}
+		err := builder.FindAll(&FilterRequest{
+			Aggrs: []Aggregation{{Field: "ID", Op: COUNT, AddSelects: []string{"1; DROP TABLE test_users"}}},
+		}, &results)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid field name")
+	})
+}
+
+func TestQueryBuilder_AggregateDecoding(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+	req := func() *FilterRequest {
+		return &FilterRequest{
+			Groups: []Group{{Field: "Status"}},
+			Aggrs: []Aggregation{
+				{Field: "CreatedAt", Op: MAX, Alias: "latest"},
+				{Field: "CreatedAt", Op: MIN, Alias: "earliest"},
+			},
+			Sorts: []Sort{{Field: "Status"}},
+		}
+	}
+
+	var users []TestUser
+	assert.NoError(t, db.Order("id").Find(&users).Error)
+
+	t.Run("Struct", func(t *testing.T) {
+		type statusRange struct {
+			Status   string
+			Latest   time.Time
+			Earliest *time.Time
+		}
+		var rows []statusRange
+		assert.NoError(t, builder.FindAll(req(), &rows))
+		if assert.Len(t, rows, 2) {
+			assert.Equal(t, "active", rows[0].Status)
+			assert.True(t, users[0].CreatedAt.Equal(rows[0].Latest))
+			if assert.NotNil(t, rows[0].Earliest) {
+				assert.True(t, users[2].CreatedAt.Equal(*rows[0].Earliest))
+			}
+		}
+
+		var row statusRange
+		assert.NoError(t, builder.FindOne(&FilterRequest{Aggrs: []Aggregation{{Field: "CreatedAt", Op: MAX, Alias: "latest"}}}, &row))
+		assert.True(t, users[0].CreatedAt.Equal(row.Latest))
+	})
+
+	t.Run("Maps", func(t *testing.T) {
+		rows, err := builder.FindMaps(req())
+		assert.NoError(t, err)
+		if assert.Len(t, rows, 2) {
+			latest, ok := rows[1]["latest"].(time.Time)
+			assert.True(t, ok)
+			assert.True(t, users[1].CreatedAt.Equal(latest))
+		}
+	})
+
+	t.Run("Decimal", func(t *testing.T) {
+		column := aggregateColumn{op: SUM, field: builder.schema.LookUpField("Age")}
+		assert.Equal(t, int64(90), decodeAggregate([]byte("90"), column, nil))
+		column.op = AVG
+		assert.Equal(t, 30.5, decodeAggregate("30.5", column, nil))
+		column.field = builder.schema.LookUpField("CreatedAt")
+		assert.Equal(t, "n/a", decodeAggregate("n/a", column, nil))
+	})
+}
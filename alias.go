@@ -0,0 +1,138 @@
+package querybuild
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// joinAliasesKey 连接表别名在查询实例中的存储键
+const joinAliasesKey = "querybuild:join_aliases"
+
+// identifierPattern 合法的SQL标识符
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// isIdentifier 判断是否为合法的SQL标识符
+func isIdentifier(name string) bool {
+	return identifierPattern.MatchString(name)
+}
+
+// joinKey 连接的去重键，由连接类型、表名、别名与连接条件组成，忽略类型的大小写与条件中多余的空白
+func joinKey(join Join) string {
+	if join.Type == "" && join.ScopeName != "" {
+		return "scope\x00" + join.ScopeName + "\x00" + fmt.Sprint(join.Values)
+	}
+	return strings.ToUpper(join.Type) + "\x00" + join.Table + "\x00" + join.Alias + "\x00" +
+		strings.Join(strings.Fields(join.Condition), " ")
+}
+
+// uniqueJoins 去除请求中重复的连接，保持首次出现的顺序
+func uniqueJoins(joins []Join) []Join {
+	if len(joins) < 2 {
+		return joins
+	}
+	seen := make(map[string]bool, len(joins))
+	unique := make([]Join, 0, len(joins))
+	for _, join := range joins {
+		key := joinKey(join)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, join)
+	}
+	return unique
+}
+
+// dedupeStatementJoins 移除查询中SQL与参数相同的重复连接，作用域与请求添加的相同连接只保留一次
+func dedupeStatementJoins(query *gorm.DB) {
+	joins := query.Statement.Joins
+	if len(joins) < 2 {
+		return
+	}
+	unique := joins[:0:0]
+	for _, join := range joins {
+		join.Name = strings.Join(strings.Fields(join.Name), " ")
+		duplicate := false
+		for _, prev := range unique {
+			if reflect.DeepEqual(prev, join) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			unique = append(unique, join)
+		}
+	}
+	if len(unique) < len(joins) {
+		query.Statement.Joins = unique
+	}
+}
+
+// lookupField 解析字段引用，支持模型字段与 alias.column 形式的连接表字段
+func (qb *QueryBuilder[T]) lookupField(query *gorm.DB, fieldName string) (FieldInfo, error) {
+	alias, column, ok := strings.Cut(fieldName, ".")
+	if !ok {
+		return qb.validateField(fieldName)
+	}
+
+	var aliases map[string]string
+	if v, ok := query.InstanceGet(joinAliasesKey); ok {
+		aliases, _ = v.(map[string]string)
+	}
+
+	table, ok := aliases[alias]
+	if !ok {
+		return FieldInfo{}, fmt.Errorf("invalid field name: %s", fieldName)
+	}
+
+	// 已知模型的表按模型字段校验，其他表仅允许合法标识符
+	if fields, ok := qb.tableFields(table); ok {
+		for name, info := range fields {
+			if name == column || info.Name == column {
+				info.TableName = alias
+				return info, nil
+			}
+		}
+		return FieldInfo{}, fmt.Errorf("invalid field name: %s", fieldName)
+	}
+
+	if !isIdentifier(column) {
+		return FieldInfo{}, fmt.Errorf("invalid field name: %s", fieldName)
+	}
+	return FieldInfo{Name: column, TableName: alias}, nil
+}
+
+// tableFields 获取当前模型及其关联模型中指定表的字段映射
+func (qb *QueryBuilder[T]) tableFields(table string) (map[string]FieldInfo, bool) {
+	if qb.tableName() == table {
+		return qb.fields, true
+	}
+	for _, rel := range qb.schema.Relationships.Relations {
+		if rel.FieldSchema.Table == table {
+			return schemaFields(rel.FieldSchema), true
+		}
+	}
+	return nil, false
+}
+
+// resolveField 获取安全的字段引用，支持连接表别名
+func (qb *QueryBuilder[T]) resolveField(query *gorm.DB, fieldName string) (string, error) {
+	info, err := qb.lookupField(query, fieldName)
+	if err != nil {
+		return "", err
+	}
+	return quoteField(info), nil
+}
+
+// resolveSimpleField 获取不带表名的字段引用，支持连接表别名
+func (qb *QueryBuilder[T]) resolveSimpleField(query *gorm.DB, fieldName string) (string, error) {
+	info, err := qb.lookupField(query, fieldName)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("`%s`", info.Name), nil
+}
@@ -0,0 +1,108 @@
+package querybuild
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestQueryBuilder_JoinAlias(t *testing.T) {
+	db := setupTestDB(t)
+
+	t.Run("Filter and sort on aliased join", func(t *testing.T) {
+		builder := NewQueryBuilder[TestOrder](db)
+		var orders []TestOrder
+		req := &FilterRequest{
+			Joins: []Join{
+				{Type: "INNER", Table: "test_users", Alias: "buyer", Condition: "buyer.id = test_orders.user_id"},
+			},
+			Filters: []Filter{{Field: "buyer.name", Op: EQ, Value: "John Doe"}},
+			Sorts:   []Sort{{Field: "buyer.age"}, {Field: "Amount", Desc: true}},
+		}
+		err := builder.FindAll(req, &orders)
+		assert.NoError(t, err)
+		assert.Len(t, orders, 2)
+		assert.Equal(t, float64(100), orders[0].Amount)
+	})
+
+	t.Run("Same table joined twice", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db)
+		count, err := builder.Count(&FilterRequest{
+			Joins: []Join{
+				{Type: "INNER", Table: "test_users", Alias: "older", Condition: "older.age > test_users.age"},
+				{Type: "INNER", Table: "test_users", Alias: "oldest", Condition: "oldest.age > older.age"},
+			},
+			Filters: []Filter{{Field: "oldest.Status", Op: EQ, Value: "active"}},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("Unknown column on known table", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db)
+		var users []TestUser
+		err := builder.FindAll(&FilterRequest{
+			Joins:   []Join{{Type: "LEFT", Table: "test_users", Alias: "other", Condition: "other.id = test_users.id"}},
+			Filters: []Filter{{Field: "other.Password", Op: EQ, Value: "x"}},
+		}, &users)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid field name")
+	})
+
+	t.Run("Undeclared alias", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db)
+		var users []TestUser
+		err := builder.FindAll(&FilterRequest{
+			Filters: []Filter{{Field: "other.name", Op: EQ, Value: "x"}},
+		}, &users)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid field name")
+	})
+
+	t.Run("Invalid alias", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db)
+		var users []TestUser
+		err := builder.FindAll(&FilterRequest{
+			Joins: []Join{{Type: "LEFT", Table: "test_orders", Alias: "o; DROP", Condition: "1 = 1"}},
+		}, &users)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid join alias")
+	})
+
+	t.Run("Duplicate joins", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db)
+		builder.RegisterScope(JoinScope, "orders", func(db *gorm.DB) *gorm.DB {
+			return db.Joins("INNER JOIN test_orders AS o ON o.user_id = test_users.id")
+		})
+		join := Join{Type: "INNER", Table: "test_orders", Alias: "o", Condition: "o.user_id = test_users.id"}
+		req := &FilterRequest{
+			Joins:   []Join{join, {ScopeName: "orders"}, join, {Type: "inner", Table: "test_orders", Alias: "o", Condition: "o.user_id  =  test_users.id"}},
+			Filters: []Filter{{Field: "o.status", Op: EQ, Value: "pending"}},
+		}
+		assert.NoError(t, builder.Validate(req))
+
+		var users []TestUser
+		query := builder.Build(req).Session(&gorm.Session{DryRun: true}).Find(&users)
+		assert.NoError(t, query.Error)
+		assert.Equal(t, 1, strings.Count(query.Statement.SQL.String(), "JOIN"))
+
+		assert.NoError(t, builder.FindAll(req, &users))
+		if assert.Len(t, users, 1) {
+			assert.Equal(t, "Jane Smith", users[0].Name)
+		}
+	})
+
+	t.Run("Conflicting alias", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db)
+		req := &FilterRequest{Joins: []Join{
+			{Type: "INNER", Table: "test_orders", Alias: "o", Condition: "o.user_id = test_users.id"},
+			{Type: "LEFT", Table: "test_orders", Alias: "o", Condition: "o.user_id = test_users.id"},
+		}}
+		var users []TestUser
+		err := builder.FindAll(req, &users)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "duplicate join alias")
+	})
+}
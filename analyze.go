@@ -0,0 +1,244 @@
+package querybuild
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// WarningKind 过滤条件分析发现的问题类型
+type WarningKind string
+
+const (
+	WarningDuplicate     WarningKind = "duplicate"     // 重复的过滤条件
+	WarningContradictory WarningKind = "contradictory" // 互相矛盾的过滤条件，如同一字段 EQ "a" 且 EQ "b"
+	WarningEmptyRange    WarningKind = "empty_range"   // 取值范围为空，如 GT 10 且 LT 5
+)
+
+// FilterWarning 过滤条件分析发现的问题，请求仍可执行，但结果可能不符合预期
+type FilterWarning struct {
+	Kind    WarningKind `json:"kind"`
+	Field   string      `json:"field"`
+	Filters []int       `json:"filters"` // 相关过滤条件在请求中的下标
+	Message string      `json:"message"`
+}
+
+func (w *FilterWarning) Error() string {
+	return w.Message
+}
+
+// Analyze 分析请求中的过滤条件，返回重复的条件、互相矛盾的条件与取值范围为空的条件
+//
+// 分析不访问数据库，适合在执行查询前提示调用方，Validate 通过的请求仍可能存在这些问题。
+// 范围分析仅针对数值与时间字段。
+func (qb *QueryBuilder[T]) Analyze(req *FilterRequest) []*FilterWarning {
+	groups := make(map[string][]int)
+	var fields []string
+	for i, filter := range req.Filters {
+		field := qb.canonicalField(filter.Field)
+		if filter.Fn != "" {
+			field = filter.Fn + "(" + field + ")"
+		}
+		if _, ok := groups[field]; !ok {
+			fields = append(fields, field)
+		}
+		groups[field] = append(groups[field], i)
+	}
+
+	var warnings []*FilterWarning
+	for _, field := range fields {
+		warnings = append(warnings, qb.analyzeField(field, req.Filters, groups[field])...)
+	}
+	return warnings
+}
+
+// analyzeField 分析同一字段上的过滤条件
+func (qb *QueryBuilder[T]) analyzeField(field string, filters []Filter, indexes []int) []*FilterWarning {
+	var warnings []*FilterWarning
+	warn := func(kind WarningKind, related []int, format string, args ...interface{}) {
+		warnings = append(warnings, &FilterWarning{
+			Kind:    kind,
+			Field:   field,
+			Filters: related,
+			Message: fmt.Sprintf("field %s: ", field) + fmt.Sprintf(format, args...),
+		})
+	}
+
+	// 重复的过滤条件
+	for j, b := range indexes {
+		for _, a := range indexes[:j] {
+			if filters[a].Op == filters[b].Op && filters[a].NoCase == filters[b].NoCase && filters[a].Value == filters[b].Value {
+				warn(WarningDuplicate, []int{a, b}, "duplicate filter %s %q", filters[b].Op, filters[b].Value)
+				break
+			}
+		}
+	}
+
+	// 为空与非空或取值条件同时出现
+	var nulls, notNulls, valued []int
+	for _, i := range indexes {
+		switch filters[i].Op {
+		case IS_NULL:
+			nulls = append(nulls, i)
+		case NOT_NULL:
+			notNulls = append(notNulls, i)
+		case GT_OR_NULL, GE_OR_NULL, LT_OR_NULL, LE_OR_NULL, NE, NOT_IN, NOT_LIKE, NOT_REGEXP, NOT_BETWEEN:
+		default:
+			valued = append(valued, i)
+		}
+	}
+	if len(nulls) > 0 && len(notNulls)+len(valued) > 0 {
+		warn(WarningContradictory, append(append(append([]int(nil), nulls...), notNulls...), valued...),
+			"IS_NULL cannot be combined with conditions that require a value")
+	}
+
+	// 等值与 IN 条件的取值没有交集，或全部被 NE 与 NOT_IN 排除
+	var allowed map[string]bool
+	var related []int
+	for _, i := range indexes {
+		filter := filters[i]
+		if filter.NoCase || (filter.Op != EQ && filter.Op != IN) {
+			continue
+		}
+		values := map[string]bool{filter.Value: true}
+		if filter.Op == IN {
+			values = make(map[string]bool)
+			for _, value := range strings.Split(filter.Value, ",") {
+				values[value] = true
+			}
+		}
+		related = append(related, i)
+		if allowed == nil {
+			allowed = values
+			continue
+		}
+		for value := range allowed {
+			if !values[value] {
+				delete(allowed, value)
+			}
+		}
+	}
+	if allowed != nil {
+		for _, i := range indexes {
+			filter := filters[i]
+			if filter.NoCase || (filter.Op != NE && filter.Op != NOT_IN) {
+				continue
+			}
+			excluded := false
+			for _, value := range strings.Split(filter.Value, ",") {
+				if allowed[value] {
+					delete(allowed, value)
+					excluded = true
+				}
+				if filter.Op == NE {
+					break
+				}
+			}
+			if excluded {
+				related = append(related, i)
+			}
+		}
+		if len(allowed) == 0 {
+			warn(WarningContradictory, related, "no value satisfies all equality conditions")
+		}
+	}
+
+	if w := qb.analyzeRange(field, filters, indexes); w != nil {
+		warnings = append(warnings, w)
+	}
+	return warnings
+}
+
+// rangeBound 取值范围的边界
+type rangeBound struct {
+	value     float64
+	inclusive bool
+	set       bool
+}
+
+// analyzeRange 合并数值与时间字段上的比较条件，范围为空时返回警告
+func (qb *QueryBuilder[T]) analyzeRange(field string, filters []Filter, indexes []int) *FilterWarning {
+	f := qb.schema.LookUpField(field)
+	if f == nil {
+		return nil
+	}
+	parse := func(value string) (float64, bool) {
+		switch f.DataType {
+		case schema.Int, schema.Uint, schema.Float:
+			n, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			return n, err == nil
+		case schema.Time:
+			t, ok := parseTime(value)
+			return float64(t.UnixNano()), ok
+		}
+		return 0, false
+	}
+
+	var lower, upper rangeBound
+	var related []int
+	raise := func(value float64, inclusive bool) {
+		if !lower.set || value > lower.value || (value == lower.value && !inclusive) {
+			lower = rangeBound{value: value, inclusive: inclusive, set: true}
+		}
+	}
+	cut := func(value float64, inclusive bool) {
+		if !upper.set || value < upper.value || (value == upper.value && !inclusive) {
+			upper = rangeBound{value: value, inclusive: inclusive, set: true}
+		}
+	}
+
+	for _, i := range indexes {
+		filter := filters[i]
+		var values []float64
+		items := []string{filter.Value}
+		if filter.Op == BETWEEN {
+			items = strings.Split(filter.Value, ",")
+		}
+		for _, item := range items {
+			n, ok := parse(item)
+			if !ok {
+				values = nil
+				break
+			}
+			values = append(values, n)
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		switch {
+		case filter.Op == EQ:
+			raise(values[0], true)
+			cut(values[0], true)
+		case filter.Op == GT:
+			raise(values[0], false)
+		case filter.Op == GE:
+			raise(values[0], true)
+		case filter.Op == LT:
+			cut(values[0], false)
+		case filter.Op == LE:
+			cut(values[0], true)
+		case filter.Op == BETWEEN && len(values) == 2:
+			raise(values[0], true)
+			cut(values[1], true)
+		default:
+			continue
+		}
+		related = append(related, i)
+	}
+
+	if !lower.set || !upper.set {
+		return nil
+	}
+	if lower.value > upper.value || (lower.value == upper.value && !(lower.inclusive && upper.inclusive)) {
+		return &FilterWarning{
+			Kind:    WarningEmptyRange,
+			Field:   field,
+			Filters: related,
+			Message: fmt.Sprintf("field %s: range conditions can never be satisfied", field),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_Analyze(t *testing.T) {
+	builder := NewQueryBuilder[TestUser](setupTestDB(t))
+
+	tests := []struct {
+		name    string
+		filters []Filter
+		kind    WarningKind
+		related []int
+	}{
+		{
+			name:    "Duplicate",
+			filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}, {Field: "status", Op: EQ, Value: "active"}},
+			kind:    WarningDuplicate,
+			related: []int{0, 1},
+		},
+		{
+			name:    "Different equality values",
+			filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}, {Field: "Age", Op: GT, Value: "1"}, {Field: "Status", Op: EQ, Value: "inactive"}},
+			kind:    WarningContradictory,
+			related: []int{0, 2},
+		},
+		{
+			name:    "Excluded by NOT_IN",
+			filters: []Filter{{Field: "Status", Op: IN, Value: "active,inactive"}, {Field: "Status", Op: NOT_IN, Value: "inactive,active"}},
+			kind:    WarningContradictory,
+			related: []int{0, 1},
+		},
+		{
+			name:    "Null and value",
+			filters: []Filter{{Field: "Email", Op: IS_NULL}, {Field: "Email", Op: LIKE, Value: "@"}},
+			kind:    WarningContradictory,
+			related: []int{0, 1},
+		},
+		{
+			name:    "Empty numeric range",
+			filters: []Filter{{Field: "Age", Op: GT, Value: "30"}, {Field: "Age", Op: LE, Value: "30"}},
+			kind:    WarningEmptyRange,
+			related: []int{0, 1},
+		},
+		{
+			name:    "Reversed BETWEEN",
+			filters: []Filter{{Field: "CreatedAt", Op: BETWEEN, Value: "2024-02-01,2024-01-01"}},
+			kind:    WarningEmptyRange,
+			related: []int{0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := builder.Analyze(&FilterRequest{Filters: tt.filters})
+			if assert.Len(t, warnings, 1) {
+				assert.Equal(t, tt.kind, warnings[0].Kind)
+				assert.Equal(t, tt.related, warnings[0].Filters)
+			}
+		})
+	}
+
+	t.Run("No warnings", func(t *testing.T) {
+		assert.Empty(t, builder.Analyze(&FilterRequest{Filters: []Filter{
+			{Field: "Status", Op: IN, Value: "active,inactive"},
+			{Field: "Status", Op: NE, Value: "inactive"},
+			{Field: "Age", Op: GE, Value: "30"},
+			{Field: "Age", Op: LE, Value: "30"},
+			{Field: "Name", Op: GT, Value: "b"},
+			{Field: "Name", Op: LT, Value: "a"},
+		}}))
+	})
+}
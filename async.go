@@ -0,0 +1,211 @@
+package querybuild
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrJobNotFound 查询任务不存在
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobNotFinished 查询任务尚未完成
+	ErrJobNotFinished = errors.New("job not finished")
+)
+
+// JobState 查询任务状态
+type JobState string
+
+const (
+	JobPending JobState = "pending" // 等待执行
+	JobRunning JobState = "running" // 执行中
+	JobDone    JobState = "done"    // 执行成功
+	JobFailed  JobState = "failed"  // 执行失败
+)
+
+// Job 后台执行的查询任务
+type Job struct {
+	ID        string          `json:"id"`
+	State     JobState        `json:"state"`
+	Request   *FilterRequest  `json:"request"`
+	Result    json.RawMessage `json:"result,omitempty"` // JSON 格式的查询结果，任务成功后写入
+	Error     string          `json:"error,omitempty"`  // 任务失败的原因
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// JobStore 查询任务存储
+//
+// 实现需支持并发访问，可使用数据库或 Redis 等外部存储在多个实例之间共享任务。
+type JobStore interface {
+	// Save 保存任务，已存在时覆盖
+	Save(ctx context.Context, job *Job) error
+	// Load 加载任务，不存在时返回 ErrJobNotFound
+	Load(ctx context.Context, id string) (*Job, error)
+}
+
+// MemoryJobStore 进程内的查询任务存储
+type MemoryJobStore struct {
+	jobs map[string]Job
+	mu   sync.RWMutex
+}
+
+// NewMemoryJobStore 创建进程内的查询任务存储
+func NewMemoryJobStore() *MemoryJobStore {
+	return &MemoryJobStore{jobs: make(map[string]Job)}
+}
+
+// Save 保存任务
+func (s *MemoryJobStore) Save(_ context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = *job
+	return nil
+}
+
+// Load 加载任务
+func (s *MemoryJobStore) Load(_ context.Context, id string) (*Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return &job, nil
+}
+
+// AsyncRunner 在后台执行耗时较长的查询，调用方通过任务编号轮询状态与结果
+type AsyncRunner[T any] struct {
+	builder *QueryBuilder[T]
+	store   JobStore
+	slots   chan struct{} // 限制同时执行的任务数
+	timeout time.Duration // 单个任务的执行时间上限
+}
+
+// NewAsyncRunner 创建后台查询执行器，concurrency 为同时执行的任务数上限，timeout 不大于 0 时不限制执行时间
+func NewAsyncRunner[T any](builder *QueryBuilder[T], store JobStore, concurrency int, timeout time.Duration) *AsyncRunner[T] {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	return &AsyncRunner[T]{
+		builder: builder,
+		store:   store,
+		slots:   make(chan struct{}, concurrency),
+		timeout: timeout,
+	}
+}
+
+// Submit 校验请求后提交后台执行，返回任务编号
+//
+// 任务使用 ctx 中的值（如调用方身份）执行，但不随 ctx 取消。请求指定分页时按分页查询，否则查询全部记录。
+func (r *AsyncRunner[T]) Submit(ctx context.Context, req *FilterRequest) (string, error) {
+	if err := r.builder.Validate(req); err != nil {
+		return "", err
+	}
+	id, err := newJobID()
+	if err != nil {
+		return "", err
+	}
+
+	// 任务执行时会写入分页信息，使用请求的副本
+	jobReq := *req
+	if req.Page != nil {
+		page := *req.Page
+		jobReq.Page = &page
+	}
+
+	now := time.Now()
+	job := &Job{ID: id, State: JobPending, Request: &jobReq, CreatedAt: now, UpdatedAt: now}
+	if err := r.store.Save(ctx, job); err != nil {
+		return "", err
+	}
+
+	go r.run(context.WithoutCancel(ctx), job)
+	return id, nil
+}
+
+// Status 获取任务状态，返回的任务不含查询结果
+func (r *AsyncRunner[T]) Status(ctx context.Context, id string) (*Job, error) {
+	job, err := r.store.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	job.Result = nil
+	return job, nil
+}
+
+// Result 获取任务的查询结果，任务未完成时返回 ErrJobNotFinished，任务失败时返回执行错误
+func (r *AsyncRunner[T]) Result(ctx context.Context, id string) (*PageResult[T], error) {
+	job, err := r.store.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch job.State {
+	case JobDone:
+		var result PageResult[T]
+		if err := json.Unmarshal(job.Result, &result); err != nil {
+			return nil, err
+		}
+		return &result, nil
+	case JobFailed:
+		return nil, fmt.Errorf("job %s failed: %s", id, job.Error)
+	}
+	return nil, ErrJobNotFinished
+}
+
+// run 等待执行名额后执行任务并保存结果
+func (r *AsyncRunner[T]) run(ctx context.Context, job *Job) {
+	r.slots <- struct{}{}
+	defer func() { <-r.slots }()
+
+	job.State = JobRunning
+	job.UpdatedAt = time.Now()
+	_ = r.store.Save(ctx, job)
+
+	queryCtx := ctx
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		queryCtx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	result, err := r.execute(queryCtx, job.Request)
+	if err == nil {
+		job.Result, err = json.Marshal(result)
+	}
+	if err != nil {
+		job.State = JobFailed
+		job.Error = err.Error()
+	} else {
+		job.State = JobDone
+	}
+	job.UpdatedAt = time.Now()
+	_ = r.store.Save(ctx, job)
+}
+
+// execute 执行任务中的查询
+func (r *AsyncRunner[T]) execute(ctx context.Context, req *FilterRequest) (*PageResult[T], error) {
+	builder := r.builder.WithContext(ctx)
+	if req.Page != nil {
+		return builder.FindPage(req)
+	}
+	var items []T
+	if err := builder.FindAll(req, &items); err != nil {
+		return nil, err
+	}
+	return &PageResult[T]{Items: items}, nil
+}
+
+// newJobID 生成随机的任务编号
+func newJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,41 @@
+package querybuild
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsyncRunner(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+	runner := NewAsyncRunner(builder, NewMemoryJobStore(), 1, time.Minute)
+	ctx := context.Background()
+
+	req := &FilterRequest{
+		Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+		Page:    &Pagination{Page: 1, PageSize: 1},
+	}
+	id, err := runner.Submit(ctx, req)
+	assert.NoError(t, err)
+	assert.Len(t, id, 32)
+
+	assert.Eventually(t, func() bool {
+		job, err := runner.Status(ctx, id)
+		return err == nil && job.State == JobDone
+	}, time.Second, 5*time.Millisecond)
+
+	result, err := runner.Result(ctx, id)
+	assert.NoError(t, err)
+	assert.Len(t, result.Items, 1)
+	assert.Equal(t, int64(2), result.Pagination.Total)
+	assert.Zero(t, req.Page.Total, "submitted request is not modified")
+
+	_, err = runner.Status(ctx, "missing")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+
+	_, err = runner.Submit(ctx, &FilterRequest{Filters: []Filter{{Field: "Unknown", Op: EQ}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,47 @@
+package querybuild
+
+import (
+	"context"
+	"time"
+)
+
+// AuditEntry 一次查询执行的审计记录
+type AuditEntry struct {
+	Model    string         // 模型表名
+	Request  *FilterRequest // 查询请求
+	SQL      string         // 不含取值的SQL语句
+	SQLHash  string         // SQL语句的 SHA-256 哈希
+	Rows     int64          // 返回或影响的行数
+	Duration time.Duration  // 执行耗时
+	Identity interface{}    // 通过 WithIdentity 记录在上下文中的调用方身份
+	Err      error          // 执行错误
+}
+
+// Auditor 查询审计接口，每次执行由构建器构建的查询后调用
+type Auditor interface {
+	Audit(ctx context.Context, entry AuditEntry)
+}
+
+// AuditorFunc 以函数实现的查询审计
+type AuditorFunc func(ctx context.Context, entry AuditEntry)
+
+// Audit 调用审计函数
+func (f AuditorFunc) Audit(ctx context.Context, entry AuditEntry) {
+	f(ctx, entry)
+}
+
+// identityKey 调用方身份的上下文键
+type identityKey struct{}
+
+// WithIdentity 返回记录调用方身份的上下文，审计记录从中取得调用方身份
+func WithIdentity(ctx context.Context, identity interface{}) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext 获取上下文中记录的调用方身份
+func IdentityFromContext(ctx context.Context) interface{} {
+	if ctx == nil {
+		return nil
+	}
+	return ctx.Value(identityKey{})
+}
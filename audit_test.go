@@ -0,0 +1,76 @@
+package querybuild
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_WithAuditor(t *testing.T) {
+	db := setupTestDB(t)
+
+	var entries []AuditEntry
+	builder := NewQueryBuilder[TestUser](db, WithAuditor(AuditorFunc(func(ctx context.Context, entry AuditEntry) {
+		entries = append(entries, entry)
+	})))
+
+	ctx := WithIdentity(context.Background(), "alice")
+	req := &FilterRequest{Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}}}
+
+	var users []TestUser
+	assert.NoError(t, builder.WithContext(ctx).Build(req).Find(&users).Error)
+	assert.Len(t, entries, 1)
+
+	entry := entries[0]
+	assert.Equal(t, "test_users", entry.Model)
+	assert.Same(t, req, entry.Request)
+	assert.Equal(t, int64(2), entry.Rows)
+	assert.Equal(t, "alice", entry.Identity)
+	assert.Contains(t, entry.SQL, "`test_users`.`status` = ?")
+	assert.Len(t, entry.SQLHash, 64)
+	assert.NoError(t, entry.Err)
+
+	t.Run("Pagination and updates", func(t *testing.T) {
+		entries = nil
+		_, err := builder.FindPage(&FilterRequest{Page: &Pagination{Page: 1, PageSize: 2}})
+		assert.NoError(t, err)
+		assert.Len(t, entries, 2, "count and page queries")
+
+		entries = nil
+		_, err = builder.UpdateExpr(req, []FieldExpr{{Field: "Age", Op: INC, Value: 1}})
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+		assert.Equal(t, int64(2), entries[0].Rows)
+	})
+
+	t.Run("Deletes", func(t *testing.T) {
+		entries = nil
+		affected, err := builder.DeleteAll(&FilterRequest{Filters: []Filter{{Field: "Status", Op: EQ, Value: "inactive"}}})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), affected)
+		if assert.Len(t, entries, 1) {
+			assert.Equal(t, int64(1), entries[0].Rows)
+			assert.Contains(t, entries[0].SQL, "DELETE FROM")
+		}
+	})
+
+	t.Run("Creates", func(t *testing.T) {
+		entries = nil
+		lookup := &FilterRequest{Filters: []Filter{{Field: "Email", Op: EQ, Value: "alice@example.com"}}}
+		_, created, err := builder.FirstOrCreate(lookup, TestUser{Name: "Alice", Age: 28, Status: "active"})
+		assert.NoError(t, err)
+		assert.True(t, created)
+		if assert.Len(t, entries, 2, "lookup and insert") {
+			assert.Same(t, lookup, entries[1].Request)
+			assert.Equal(t, int64(1), entries[1].Rows)
+			assert.Contains(t, entries[1].SQL, "INSERT INTO")
+		}
+	})
+
+	t.Run("Unrelated queries are not audited", func(t *testing.T) {
+		entries = nil
+		assert.NoError(t, db.Find(&users).Error)
+		assert.Empty(t, entries)
+	})
+}
@@ -0,0 +1,84 @@
+package querybuild
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// benchmarkRequest 基准测试使用的典型列表请求
+var benchmarkRequest = &FilterRequest{
+	Filters: []Filter{
+		{Field: "Status", Op: EQ, Value: "active"},
+		{Field: "Age", Op: BETWEEN, Value: "20,40"},
+		{Field: "Name", Op: STARTS_WITH, Value: "J", NoCase: true},
+		{Field: "Tags", Op: IN, Value: "tag1,tag2,tag3"},
+		{Field: "Email", Op: NOT_NULL},
+	},
+	Sorts: []Sort{
+		{Field: "CreatedAt", Desc: true},
+		{Field: "Name", NoCase: true},
+	},
+}
+
+// setupBenchmarkDB 创建基准测试使用的 DryRun 数据库连接
+func setupBenchmarkDB(b *testing.B) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		DryRun: true,
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return db
+}
+
+func BenchmarkBuildCondition(b *testing.B) {
+	field := quoteField(FieldInfo{TableName: "test_users", Name: "name"})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, filter := range benchmarkRequest.Filters {
+			buildCondition(field, filter)
+		}
+	}
+}
+
+func BenchmarkQueryBuilder_ApplyFilters(b *testing.B) {
+	builder := NewQueryBuilder[TestUser](setupBenchmarkDB(b))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		builder.applyFilters(builder.newQuery(), benchmarkRequest.Filters)
+	}
+}
+
+func BenchmarkQueryBuilder_ApplySorts(b *testing.B) {
+	builder := NewQueryBuilder[TestUser](setupBenchmarkDB(b))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		builder.applySorts(builder.newQuery(), benchmarkRequest.Sorts)
+	}
+}
+
+func BenchmarkQueryBuilder_Build(b *testing.B) {
+	builder := NewQueryBuilder[TestUser](setupBenchmarkDB(b))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var users []TestUser
+		builder.Build(benchmarkRequest).Find(&users)
+	}
+}
+
+func BenchmarkQueryBuilder_BuildPlanCache(b *testing.B) {
+	builder := NewQueryBuilder[TestUser](setupBenchmarkDB(b), WithPlanCache(16))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var users []TestUser
+		builder.Build(benchmarkRequest).Find(&users)
+	}
+}
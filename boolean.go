@@ -0,0 +1,53 @@
+package querybuild
+
+import (
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// boolSpellings 布尔字段过滤取值接受的写法，不区分大小写
+var boolSpellings = map[string]bool{
+	"true": true, "t": true, "1": true, "yes": true, "y": true, "on": true,
+	"false": false, "f": false, "0": false, "no": false, "n": false, "off": false,
+}
+
+// parseBool 解析布尔取值的常见写法，如 true、1、yes、off
+func parseBool(value string) (bool, bool) {
+	b, ok := boolSpellings[strings.ToLower(strings.TrimSpace(value))]
+	return b, ok
+}
+
+// isBoolFilter 判断过滤条件是否为模型布尔字段的等值或 IN 条件，这类条件的取值绑定为布尔值
+func (qb *QueryBuilder[T]) isBoolFilter(filter Filter) bool {
+	switch filter.Op {
+	case EQ, NE, IN, NOT_IN:
+	default:
+		return false
+	}
+	field := qb.schema.LookUpField(filter.Field)
+	return field != nil && field.DBName != "" && field.DataType == schema.Bool
+}
+
+// boolCondition 构建布尔字段的过滤条件，取值转换为布尔值后绑定，避免依赖数据库对字符串的隐式转换
+func boolCondition(field string, filter Filter) (string, []interface{}, error) {
+	items := []string{filter.Value}
+	if filter.Op == IN || filter.Op == NOT_IN {
+		items = strings.Split(filter.Value, ",")
+	}
+
+	values := make([]bool, 0, len(items))
+	for _, item := range items {
+		b, ok := parseBool(item)
+		if !ok {
+			return "", nil, &InvalidValueError{Field: filter.Field, Value: item, Reason: "not a boolean"}
+		}
+		values = append(values, b)
+	}
+
+	filter.NoCase = false
+	if filter.Op == IN || filter.Op == NOT_IN {
+		return conditionSQL(field, filter), []interface{}{values}, nil
+	}
+	return conditionSQL(field, filter), []interface{}{values[0]}, nil
+}
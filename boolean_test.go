@@ -0,0 +1,52 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// boolUser 带布尔字段的测试模型
+type boolUser struct {
+	ID     uint   `gorm:"primarykey"`
+	Name   string `gorm:"column:name"`
+	Active bool   `gorm:"column:active"`
+}
+
+func TestQueryBuilder_BoolCoercion(t *testing.T) {
+	db := setupTestDB(t)
+	assert.NoError(t, db.AutoMigrate(&boolUser{}))
+	assert.NoError(t, db.Create([]boolUser{{Name: "a", Active: true}, {Name: "b", Active: false}, {Name: "c", Active: true}}).Error)
+
+	builder := NewQueryBuilder[boolUser](db, WithPlanCache(10))
+
+	tests := []struct {
+		name  string
+		op    Operator
+		value string
+		count int
+	}{
+		{name: "true", op: EQ, value: "true", count: 2},
+		{name: "1", op: EQ, value: "1", count: 2},
+		{name: "YES", op: EQ, value: "YES", count: 2},
+		{name: "off", op: EQ, value: "off", count: 1},
+		{name: "not no", op: NE, value: "no", count: 2},
+		{name: "IN", op: IN, value: "y,f", count: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var users []boolUser
+			assert.NoError(t, builder.FindAll(&FilterRequest{Filters: []Filter{{Field: "Active", Op: tt.op, Value: tt.value}}}, &users))
+			assert.Len(t, users, tt.count)
+		})
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		req := &FilterRequest{Filters: []Filter{{Field: "Active", Op: EQ, Value: "maybe"}}}
+		var users []boolUser
+		var valueErr *InvalidValueError
+		assert.ErrorAs(t, builder.FindAll(req, &users), &valueErr)
+		assert.Equal(t, "not a boolean", valueErr.Reason)
+		assert.ErrorAs(t, builder.Validate(req), &valueErr)
+	})
+}
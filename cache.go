@@ -0,0 +1,210 @@
+package querybuild
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache 查询结果缓存
+//
+// 实现需支持并发访问。缓存读写失败时查询直接访问数据库，不会返回错误。
+type Cache interface {
+	// Get 获取缓存值，不存在或已过期时返回 false
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set 设置缓存值及其有效期与标签
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags []string) error
+	// Invalidate 删除带有任一标签的缓存值
+	Invalidate(ctx context.Context, tags ...string) error
+}
+
+// MemoryCache 进程内的查询结果缓存
+type MemoryCache struct {
+	entries map[string]memoryCacheEntry
+	tags    map[string]map[string]struct{}
+	mu      sync.Mutex
+}
+
+// memoryCacheEntry 进程内缓存条目
+type memoryCacheEntry struct {
+	value   []byte
+	expires time.Time
+	tags    []string
+}
+
+// NewMemoryCache 创建进程内的查询结果缓存
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		entries: make(map[string]memoryCacheEntry),
+		tags:    make(map[string]map[string]struct{}),
+	}
+}
+
+// Get 获取缓存值
+func (c *MemoryCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		c.remove(key)
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+// Set 设置缓存值，ttl 不大于 0 时不过期
+func (c *MemoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration, tags []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.remove(key)
+	entry := memoryCacheEntry{value: value, tags: tags}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+	c.entries[key] = entry
+	for _, tag := range tags {
+		keys, ok := c.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			c.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	return nil
+}
+
+// Invalidate 删除带有任一标签的缓存值
+func (c *MemoryCache) Invalidate(_ context.Context, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range c.tags[tag] {
+			c.remove(key)
+		}
+	}
+	return nil
+}
+
+// remove 删除缓存条目及其标签索引
+func (c *MemoryCache) remove(key string) {
+	entry, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	delete(c.entries, key)
+	for _, tag := range entry.tags {
+		delete(c.tags[tag], key)
+		if len(c.tags[tag]) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+}
+
+// cacheKey 根据模型表名、查询类型与请求的规范形式生成缓存键，并区分适用的缺省规则、脱敏状态与作用域授权
+func (qb *QueryBuilder[T]) cacheKey(kind string, req *FilterRequest) (string, error) {
+	hash, err := qb.Hash(req)
+	if err != nil {
+		return "", err
+	}
+	if qb.temporal != nil {
+		kind += ":" + qb.temporal.key
+	}
+	// 时间取值按请求或构建器的业务时区解析，时区不同的结果不能共享
+	if loc, err := qb.requestLocation(req); err == nil && loc != nil {
+		kind += ":tz=" + loc.String()
+	}
+	if key := qb.defaultsKey(); key != "" {
+		kind += ":" + key
+	}
+	if key := qb.maskKey(qb.db.Statement.Context); key != "" {
+		kind += ":" + key
+	}
+	if denied := qb.registry.deniedScopes(qb.db.Statement.Context); len(denied) > 0 {
+		kind += ":denied=" + strings.Join(denied, ",")
+	}
+	return "querybuild:" + qb.tableName() + ":" + kind + ":" + hash, nil
+}
+
+// cacheTags 返回缓存结果附加的标签
+func (qb *QueryBuilder[T]) cacheTags() []string {
+	return append([]string{qb.tableName()}, qb.opts.cacheTags...)
+}
+
+// loadCached 从缓存读取结果，未命中时执行 load 并写入缓存
+func loadCached[R any](ctx context.Context, cache Cache, key string, ttl time.Duration, tags []string, load func() (R, error)) (R, error) {
+	if data, ok, err := cache.Get(ctx, key); err == nil && ok {
+		var result R
+		if err := json.Unmarshal(data, &result); err == nil {
+			return result, nil
+		}
+	}
+
+	result, err := load()
+	if err != nil {
+		return result, err
+	}
+	if data, err := json.Marshal(result); err == nil {
+		_ = cache.Set(ctx, key, data, ttl, tags)
+	}
+	return result, nil
+}
+
+// cachedPage 从缓存读取分页查询结果，并将分页信息写回请求，按缺省规则补全了每页数量时不写回
+func (qb *QueryBuilder[T]) cachedPage(req *FilterRequest) (*PageResult[T], error) {
+	key, err := qb.cacheKey("page", req)
+	if err != nil {
+		return qb.findPage(req)
+	}
+
+	result, err := loadCached(qb.db.Statement.Context, qb.opts.cache, key, qb.opts.cacheTTL, qb.cacheTags(),
+		func() (*PageResult[T], error) { return qb.findPage(req) })
+	if err != nil {
+		return nil, err
+	}
+	if result.Pagination != nil && result.Pagination != req.Page && !pageDefaulted(result.Defaults) {
+		*req.Page = *result.Pagination
+		result.Pagination = req.Page
+	}
+	return result, nil
+}
+
+// cachedCount 从计数缓存读取总记录数，未命中时执行 load 并写入缓存
+//
+// 缓存键忽略排序与分页，相同过滤条件的请求共享总记录数。
+func (qb *QueryBuilder[T]) cachedCount(req *FilterRequest, load func() (int64, error)) (int64, error) {
+	countReq := *req
+	countReq.Sorts = nil
+	countReq.Page = nil
+	key, err := qb.cacheKey("count", &countReq)
+	if err != nil {
+		return load()
+	}
+	return loadCached(qb.db.Statement.Context, qb.opts.countCache, key, qb.opts.countTTL, qb.cacheTags(), load)
+}
+
+// InvalidateCache 使当前模型的缓存结果与计数缓存失效，应在写入数据后调用
+//
+// 未指定 tags 时失效模型表名标签下的全部缓存结果。
+func (qb *QueryBuilder[T]) InvalidateCache(tags ...string) error {
+	if len(tags) == 0 {
+		tags = []string{qb.tableName()}
+	}
+	ctx := qb.db.Statement.Context
+	if qb.opts.cache != nil {
+		if err := qb.opts.cache.Invalidate(ctx, tags...); err != nil {
+			return err
+		}
+	}
+	if qb.opts.countCache != nil && qb.opts.countCache != qb.opts.cache {
+		return qb.opts.countCache.Invalidate(ctx, tags...)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package querybuild
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache()
+
+	assert.NoError(t, cache.Set(ctx, "a", []byte("1"), 0, []string{"users"}))
+	assert.NoError(t, cache.Set(ctx, "b", []byte("2"), time.Millisecond, []string{"orders"}))
+
+	value, ok, err := cache.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+
+	time.Sleep(5 * time.Millisecond)
+	_, ok, _ = cache.Get(ctx, "b")
+	assert.False(t, ok, "expired entry")
+
+	assert.NoError(t, cache.Invalidate(ctx, "users"))
+	_, ok, _ = cache.Get(ctx, "a")
+	assert.False(t, ok, "invalidated entry")
+}
+
+func TestQueryBuilder_FindPageCache(t *testing.T) {
+	db := setupTestDB(t)
+	cache := NewMemoryCache()
+	builder := NewQueryBuilder[TestUser](db, WithCache(cache, time.Minute))
+
+	newReq := func() *FilterRequest {
+		return &FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+			Page:    &Pagination{Page: 1, PageSize: 10},
+		}
+	}
+
+	result, err := builder.FindPage(newReq())
+	assert.NoError(t, err)
+	assert.Len(t, result.Items, 2)
+
+	// 绕过构建器修改数据，缓存结果保持不变
+	assert.NoError(t, db.Model(&TestUser{}).Where("name = ?", "Jane Smith").Update("status", "active").Error)
+
+	req := newReq()
+	result, err = builder.FindPage(req)
+	assert.NoError(t, err)
+	assert.Len(t, result.Items, 2)
+	assert.Equal(t, int64(2), req.Page.Total)
+	assert.Same(t, req.Page, result.Pagination)
+
+	// 失效后重新查询数据库
+	assert.NoError(t, builder.InvalidateCache())
+	result, err = builder.FindPage(newReq())
+	assert.NoError(t, err)
+	assert.Len(t, result.Items, 3)
+
+	// 通过构建器更新后自动失效
+	_, err = builder.UpdateExpr(&FilterRequest{
+		Filters: []Filter{{Field: "Name", Op: EQ, Value: "Bob Johnson"}},
+	}, []FieldExpr{{Field: "Status", Op: SET, Value: "inactive"}})
+	assert.NoError(t, err)
+	result, err = builder.FindPage(newReq())
+	assert.NoError(t, err)
+	assert.Len(t, result.Items, 2)
+}
+
+func TestQueryBuilder_CountCache(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db, WithCountCache(NewMemoryCache(), time.Minute))
+
+	filters := []Filter{{Field: "Status", Op: EQ, Value: "active"}}
+	count, err := builder.Count(&FilterRequest{Filters: filters})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	assert.NoError(t, db.Model(&TestUser{}).Where("name = ?", "Jane Smith").Update("status", "active").Error)
+
+	// 排序与分页不同的请求共享缓存的总记录数，数据本身实时查询
+	result, err := builder.FindPage(&FilterRequest{
+		Filters: filters,
+		Sorts:   []Sort{{Field: "Age", Desc: true}},
+		Page:    &Pagination{Page: 1, PageSize: 10},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, result.Items, 3)
+	assert.Equal(t, int64(2), result.Pagination.Total)
+	assert.Equal(t, 1, result.Pagination.TotalPages)
+
+	assert.NoError(t, builder.InvalidateCache())
+	count, err = builder.Count(&FilterRequest{Filters: filters})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
+
+func TestQueryBuilder_CacheLocation(t *testing.T) {
+	db := setupTestDB(t)
+	// 2024-01-01 20:00 UTC 在上海时区为 2024-01-02 04:00
+	assert.NoError(t, db.Create(&TestUser{Name: "Eve", Status: "active", CreatedAt: time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)}).Error)
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	assert.NoError(t, err)
+
+	cache := NewMemoryCache()
+	utcBuilder := NewQueryBuilder[TestUser](db, WithCache(cache, time.Minute), WithLocation(time.UTC))
+	localBuilder := NewQueryBuilder[TestUser](db, WithCache(cache, time.Minute), WithLocation(shanghai))
+	newReq := func() *FilterRequest {
+		return &FilterRequest{
+			Filters: []Filter{{Field: "CreatedAt", Op: EQ, Value: "2024-01-01"}},
+			Page:    &Pagination{Page: 1, PageSize: 10},
+		}
+	}
+
+	result, err := utcBuilder.FindPage(newReq())
+	assert.NoError(t, err)
+	assert.Len(t, result.Items, 1)
+
+	// 业务时区不同的构建器不读取其他时区缓存的结果
+	result, err = localBuilder.FindPage(newReq())
+	assert.NoError(t, err)
+	assert.Empty(t, result.Items)
+}
@@ -0,0 +1,51 @@
+package querybuild
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// postgresCollationPattern PostgreSQL 排序规则名称的格式，如 zh-CN-x-icu
+var postgresCollationPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.@-]*$`)
+
+// WithCollation 注册排序可使用的排序规则，排序条件通过 Collation 引用 name
+//
+// collation 为数据库中的排序规则名称，如 PostgreSQL 的 zh-CN-x-icu、MySQL 的 utf8mb4_zh_0900_as_cs，
+// 未注册的名称在构建与校验时返回错误，请求无法直接指定任意排序规则。
+func WithCollation(name, collation string) Option {
+	return func(o *options) {
+		if o.collations == nil {
+			o.collations = make(map[string]string)
+		}
+		o.collations[name] = collation
+	}
+}
+
+// collate 以排序规则包装排序字段，PostgreSQL 的排序规则名称使用双引号
+func (qb *QueryBuilder[T]) collate(field, name string) (string, error) {
+	collation, ok := qb.opts.collations[name]
+	if !ok {
+		return "", fmt.Errorf("unknown collation: %s", name)
+	}
+	if qb.dialect() == dialectPostgres {
+		if !postgresCollationPattern.MatchString(collation) {
+			return "", fmt.Errorf("invalid collation: %s", collation)
+		}
+		return field + ` COLLATE "` + collation + `"`, nil
+	}
+	if !isIdentifier(collation) {
+		return "", fmt.Errorf("invalid collation: %s", collation)
+	}
+	return field + " COLLATE " + collation, nil
+}
+
+// sortField 构建普通排序的字段表达式，处理忽略大小写与排序规则
+func (qb *QueryBuilder[T]) sortField(field string, sort Sort) (string, error) {
+	if sort.NoCase {
+		field = "LOWER(" + field + ")"
+	}
+	if sort.Collation != "" {
+		return qb.collate(field, sort.Collation)
+	}
+	return field, nil
+}
@@ -0,0 +1,54 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_Collation(t *testing.T) {
+	build := func(dialect string, opts ...Option) (string, error) {
+		builder := NewQueryBuilder[TestUser](setupDialectDB(t, dialect), opts...)
+		var users []TestUser
+		query := builder.Build(&FilterRequest{Sorts: []Sort{{Field: "Name", Collation: "zh"}}}).Find(&users)
+		return query.Statement.SQL.String(), query.Error
+	}
+
+	t.Run("Postgres", func(t *testing.T) {
+		sql, err := build("postgres", WithCollation("zh", "zh-CN-x-icu"))
+		assert.NoError(t, err)
+		assert.Contains(t, sql, "ORDER BY `test_users`.`name` COLLATE \"zh-CN-x-icu\" ASC")
+	})
+
+	t.Run("MySQL", func(t *testing.T) {
+		sql, err := build("mysql", WithCollation("zh", "utf8mb4_zh_0900_as_cs"))
+		assert.NoError(t, err)
+		assert.Contains(t, sql, "ORDER BY `test_users`.`name` COLLATE utf8mb4_zh_0900_as_cs ASC")
+
+		_, err = build("mysql", WithCollation("zh", "zh-CN"))
+		assert.Error(t, err)
+	})
+
+	t.Run("Unregistered", func(t *testing.T) {
+		_, err := build("mysql")
+		assert.Error(t, err)
+
+		builder := NewQueryBuilder[TestUser](setupTestDB(t))
+		assert.Error(t, builder.Validate(&FilterRequest{Sorts: []Sort{{Field: "Name", Collation: "zh"}}}))
+	})
+
+	t.Run("SQLite", func(t *testing.T) {
+		db := setupTestDB(t)
+		assert.NoError(t, db.Create(&TestUser{Name: "alice", Status: "active"}).Error)
+		builder := NewQueryBuilder[TestUser](db, WithCollation("ci", "NOCASE"), WithPlanCache(4))
+
+		// 第二次查询使用缓存的查询计划
+		for i := 0; i < 2; i++ {
+			var users []TestUser
+			req := &FilterRequest{Sorts: []Sort{{Field: "Name", Collation: "ci"}}}
+			assert.NoError(t, builder.Validate(req))
+			assert.NoError(t, builder.FindAll(req, &users))
+			assert.Equal(t, "alice", users[0].Name)
+		}
+	})
+}
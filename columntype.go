@@ -0,0 +1,73 @@
+package querybuild
+
+import (
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// arrayTypePattern 可用于类型转换的数组列类型，如 text[]、varchar(64)[]
+var arrayTypePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_ ]*(\(\d+\))?\[\]$`)
+
+// isCIText 判断列是否为不区分大小写的 PostgreSQL citext 类型
+func (f FieldInfo) isCIText() bool {
+	return strings.EqualFold(string(f.DataType), "citext")
+}
+
+// isArray 判断列是否为 PostgreSQL 数组类型
+func (f FieldInfo) isArray() bool {
+	return arrayTypePattern.MatchString(string(f.DataType))
+}
+
+// enumValues 返回 enum('a','b') 形式的列类型声明的枚举取值，其他类型返回 nil
+func (f FieldInfo) enumValues() []string {
+	dataType := strings.TrimSpace(string(f.DataType))
+	if len(dataType) < 6 || !strings.EqualFold(dataType[:5], "enum(") || !strings.HasSuffix(dataType, ")") {
+		return nil
+	}
+	var values []string
+	for _, item := range strings.Split(dataType[5:len(dataType)-1], ",") {
+		values = append(values, strings.Trim(strings.TrimSpace(item), `'"`))
+	}
+	return values
+}
+
+// columnInfo 返回过滤条件字段的列信息，无效字段返回空信息
+func (qb *QueryBuilder[T]) columnInfo(query *gorm.DB, name string) FieldInfo {
+	info, err := qb.lookupField(query, name)
+	if err != nil {
+		return FieldInfo{}
+	}
+	return info
+}
+
+// isArrayFilter 判断过滤条件是否为数组列上的比较或数组操作符，这类条件的取值绑定为数组
+func (qb *QueryBuilder[T]) isArrayFilter(query *gorm.DB, filter Filter) bool {
+	switch filter.Op {
+	case EQ, NE, OVERLAP, ARRAY_CONTAINS, ARRAY_CONTAINED:
+	default:
+		return false
+	}
+	return filter.Fn == "" && qb.columnInfo(query, filter.Field).isArray()
+}
+
+// arrayCondition 构建数组列的过滤条件，逗号分隔的取值（可带花括号）逐个绑定为按列类型转换的 ARRAY[...]
+func arrayCondition(field string, info FieldInfo, filter Filter) (string, []interface{}) {
+	value := strings.TrimSpace(filter.Value)
+	if strings.HasPrefix(value, "{") && strings.HasSuffix(value, "}") {
+		value = value[1 : len(value)-1]
+	}
+
+	var items []interface{}
+	if value != "" {
+		for _, item := range strings.Split(value, ",") {
+			items = append(items, strings.TrimSpace(item))
+		}
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(items)), ",")
+	array := "CAST(ARRAY[" + placeholders + "] AS " + string(info.DataType) + ")"
+
+	filter.NoCase = false
+	return strings.TrimSuffix(conditionSQL(field, filter), "?") + array, items
+}
@@ -0,0 +1,76 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type TestListing struct {
+	ID     uint     `gorm:"primarykey"`
+	Email  string   `gorm:"column:email;type:citext"`
+	Tags   []string `gorm:"column:tags;type:text[]"`
+	Status string   `gorm:"column:status;type:enum('draft','published')"`
+}
+
+func TestQueryBuilder_ColumnTypes(t *testing.T) {
+	builder := NewQueryBuilder[TestListing](setupDialectDB(t, "postgres"))
+	render := func(filter Filter) (string, []interface{}) {
+		var listings []TestListing
+		query := builder.Build(&FilterRequest{Filters: []Filter{filter}}).Find(&listings)
+		assert.NoError(t, query.Error)
+		return query.Statement.SQL.String(), query.Statement.Vars
+	}
+
+	t.Run("Citext skips LOWER", func(t *testing.T) {
+		sql, vars := render(Filter{Field: "Email", Op: EQ, Value: "John@Example.com", NoCase: true})
+		assert.Contains(t, sql, "`test_listings`.`email` = ?")
+		assert.NotContains(t, sql, "LOWER")
+		assert.Equal(t, []interface{}{"John@Example.com"}, vars)
+	})
+
+	t.Run("Array binding", func(t *testing.T) {
+		sql, vars := render(Filter{Field: "Tags", Op: OVERLAP, Value: "{go,sql}"})
+		assert.Contains(t, sql, "`test_listings`.`tags` && CAST(ARRAY[?,?] AS text[])")
+		assert.Equal(t, []interface{}{"go", "sql"}, vars)
+
+		sql, _ = render(Filter{Field: "Tags", Op: EQ, Value: "go"})
+		assert.Contains(t, sql, "`test_listings`.`tags` = CAST(ARRAY[?] AS text[])")
+	})
+
+	t.Run("Enum values", func(t *testing.T) {
+		_, vars := render(Filter{Field: "Status", Op: IN, Value: "draft,published"})
+		assert.Len(t, vars, 2)
+
+		err := builder.Build(&FilterRequest{Filters: []Filter{{Field: "Status", Op: EQ, Value: "archived"}}}).Error
+		var invalid *InvalidValueError
+		if assert.ErrorAs(t, err, &invalid) {
+			assert.Equal(t, "Status", invalid.Field)
+		}
+	})
+
+	t.Run("Metadata", func(t *testing.T) {
+		fields := make(map[string]FieldMetadata)
+		for _, field := range builder.Metadata().Fields {
+			fields[field.Name] = field
+		}
+		assert.Equal(t, StringField, fields["Email"].Type)
+		assert.Contains(t, fields["Email"].Operators, LIKE)
+		assert.Equal(t, ArrayField, fields["Tags"].Type)
+		assert.Equal(t, arrayOperators, fields["Tags"].Operators)
+		assert.Equal(t, StringField, fields["Status"].Type)
+		assert.Equal(t, []string{"draft", "published"}, fields["Status"].Enum)
+	})
+
+	t.Run("Plan cache", func(t *testing.T) {
+		cached := NewQueryBuilder[TestListing](setupDialectDB(t, "postgres"), WithPlanCache(16))
+		req := &FilterRequest{Filters: []Filter{{Field: "Tags", Op: ARRAY_CONTAINS, Value: "go"}}}
+		for i := 0; i < 2; i++ {
+			var listings []TestListing
+			query := cached.WithDB(cached.db.Session(&gorm.Session{DryRun: true})).Build(req).Find(&listings)
+			assert.NoError(t, query.Error)
+			assert.Contains(t, query.Statement.SQL.String(), "@> CAST(ARRAY[?] AS text[])")
+		}
+	})
+}
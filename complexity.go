@@ -0,0 +1,126 @@
+package querybuild
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ComplexityWeights 查询复杂度各项的权重
+type ComplexityWeights struct {
+	Filter          int // 每个过滤条件
+	LeadingWildcard int // 以通配符开头的模糊匹配，无法使用索引
+	Regexp          int // 正则匹配
+	Join            int // 每个连接
+	Exists          int // 每个关联存在性或数量过滤子查询
+	SubQuery        int // 子查询
+	Sort            int // 每个排序
+	UnindexedSort   int // 无法使用索引的排序
+	Group           int // 每个分组
+	Aggregation     int // 每个聚合
+}
+
+// DefaultComplexityWeights 默认的查询复杂度权重
+var DefaultComplexityWeights = ComplexityWeights{
+	Filter:          1,
+	LeadingWildcard: 5,
+	Regexp:          8,
+	Join:            5,
+	Exists:          5,
+	SubQuery:        5,
+	Sort:            1,
+	UnindexedSort:   5,
+	Group:           2,
+	Aggregation:     2,
+}
+
+// Complexity 估算请求的查询复杂度，用于限制外部请求的查询成本
+func (qb *QueryBuilder[T]) Complexity(req *FilterRequest) int {
+	w := qb.complexityWeights()
+	score := qb.filtersComplexity(req.Filters, w) + qb.filtersComplexity(groupFilters(req.FilterGroups), w) +
+		len(req.TupleFilters)*w.Filter
+	for _, exists := range req.Exists {
+		score += w.Exists + qb.filtersComplexity(exists.Filter.Filters, w)
+	}
+	for _, count := range req.CountFilters {
+		score += w.Exists + qb.filtersComplexity(count.Filter.Filters, w)
+	}
+	score += len(req.Joins) * w.Join
+	if req.SubQuery != nil {
+		score += w.SubQuery + qb.filtersComplexity(req.SubQuery.Filter.Filters, w)
+	}
+
+	indexed := qb.indexedColumns()
+	for _, sort := range req.Sorts {
+		score += w.Sort
+		switch {
+		case sort.ScopeName != "":
+		case sort.CountOf != "":
+			score += w.Exists + w.UnindexedSort
+		case len(sort.Values) > 0 || sort.NoCase || sort.Collation != "":
+			score += w.UnindexedSort
+		default:
+			if field := qb.schema.LookUpField(sort.Field); field != nil && !indexed[field.DBName] {
+				score += w.UnindexedSort
+			}
+		}
+	}
+
+	score += len(req.Groups)*w.Group + len(req.Aggrs)*w.Aggregation
+	return score
+}
+
+// filtersComplexity 估算过滤条件的复杂度
+func (qb *QueryBuilder[T]) filtersComplexity(filters []Filter, w ComplexityWeights) int {
+	score := 0
+	for _, filter := range filters {
+		score += w.Filter
+		switch filter.Op {
+		case LIKE, NOT_LIKE:
+			if strings.HasPrefix(filter.Value, "%") || strings.HasPrefix(filter.Value, "_") {
+				score += w.LeadingWildcard
+			}
+		case ENDS_WITH, CONTAINS:
+			score += w.LeadingWildcard
+		case REGEXP, NOT_REGEXP:
+			score += w.Regexp
+		}
+	}
+	return score
+}
+
+// complexityWeights 返回配置的复杂度权重，未配置时使用默认权重
+func (qb *QueryBuilder[T]) complexityWeights() ComplexityWeights {
+	if qb.opts.complexityWeights != nil {
+		return *qb.opts.complexityWeights
+	}
+	return DefaultComplexityWeights
+}
+
+// indexedColumns 返回可用于排序的索引列，包括主键、唯一字段与各索引的首列
+func (qb *QueryBuilder[T]) indexedColumns() map[string]bool {
+	columns := make(map[string]bool)
+	for _, field := range qb.schema.Fields {
+		if field.DBName != "" && (field.PrimaryKey || field.Unique) {
+			columns[field.DBName] = true
+		}
+	}
+	for _, index := range qb.schema.ParseIndexes() {
+		if len(index.Fields) > 0 && index.Fields[0].Field != nil {
+			columns[index.Fields[0].DBName] = true
+		}
+	}
+	return columns
+}
+
+// checkComplexity 校验请求的查询复杂度，超出上限时向查询添加错误
+func (qb *QueryBuilder[T]) checkComplexity(query *gorm.DB, req *FilterRequest) bool {
+	if qb.opts.maxComplexity <= 0 {
+		return true
+	}
+	if score := qb.Complexity(req); score > qb.opts.maxComplexity {
+		query.AddError(&QueryTooComplexError{Score: score, Max: qb.opts.maxComplexity})
+		return false
+	}
+	return true
+}
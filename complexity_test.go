@@ -0,0 +1,92 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_Complexity(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestOrder](db)
+
+	tests := []struct {
+		name     string
+		req      *FilterRequest
+		expected int
+	}{
+		{
+			name:     "Empty request",
+			req:      &FilterRequest{},
+			expected: 0,
+		},
+		{
+			name: "Simple filters",
+			req: &FilterRequest{Filters: []Filter{
+				{Field: "Status", Op: EQ, Value: "paid"},
+				{Field: "Status", Op: LIKE, Value: "pa%"},
+			}},
+			expected: 2,
+		},
+		{
+			name: "Leading wildcard and regexp",
+			req: &FilterRequest{Filters: []Filter{
+				{Field: "Status", Op: LIKE, Value: "%id"},
+				{Field: "Status", Op: CONTAINS, Value: "ai"},
+				{Field: "Status", Op: REGEXP, Value: "^p"},
+			}},
+			expected: 3 + 5 + 5 + 8,
+		},
+		{
+			name:     "Indexed and unindexed sorts",
+			req:      &FilterRequest{Sorts: []Sort{{Field: "ID"}, {Field: "Amount"}}},
+			expected: 2 + 5,
+		},
+		{
+			name: "Joins and aggregations",
+			req: &FilterRequest{
+				Joins:  []Join{{Type: "LEFT", Table: "test_users", Condition: "test_users.id = test_orders.user_id"}},
+				Groups: []Group{{Field: "Status"}},
+				Aggrs:  []Aggregation{{Field: "Amount", Op: SUM}},
+			},
+			expected: 5 + 2 + 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, builder.Complexity(tt.req))
+		})
+	}
+}
+
+func TestQueryBuilder_MaxComplexity(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db, WithMaxComplexity(10))
+
+	var users []TestUser
+	err := builder.Build(&FilterRequest{
+		Filters: []Filter{{Field: "Name", Op: EQ, Value: "John Doe"}},
+	}).Find(&users).Error
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+
+	err = builder.Build(&FilterRequest{
+		Filters: []Filter{
+			{Field: "Name", Op: CONTAINS, Value: "o"},
+			{Field: "Email", Op: ENDS_WITH, Value: ".com"},
+		},
+	}).Find(&users).Error
+	var complexErr *QueryTooComplexError
+	assert.ErrorAs(t, err, &complexErr)
+	assert.Equal(t, 12, complexErr.Score)
+	assert.Equal(t, 10, complexErr.Max)
+
+	custom := NewQueryBuilder[TestUser](db, WithMaxComplexity(10, ComplexityWeights{Filter: 1}))
+	assert.NoError(t, custom.Build(&FilterRequest{
+		Filters: []Filter{
+			{Field: "Name", Op: CONTAINS, Value: "o"},
+			{Field: "Email", Op: ENDS_WITH, Value: ".com"},
+		},
+	}).Find(&users).Error)
+}
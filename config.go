@@ -0,0 +1,201 @@
+package querybuild
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config 声明式的构建器配置，可从 YAML 或 JSON 文件加载
+//
+//	sort_fields: [Name, CreatedAt]
+//	enums:
+//	  Status: [active, disabled]
+//	enum_labels:
+//	  Status: {已激活: active, 已停用: disabled}
+//	scope_aliases:
+//	  filter:
+//	    recent: created_last_week
+//	named_queries:
+//	  - name: older_users
+//	    params:
+//	      - {name: min_age, required: true}
+//	    request:
+//	      filters:
+//	        - {field: Age, op: GT, value: "{{min_age}}"}
+type Config struct {
+	SortFields   []string                     `json:"sort_fields"`   // 允许排序的字段
+	Enums        map[string][]string          `json:"enums"`         // 字段允许的枚举取值
+	EnumLabels   map[string]map[string]string `json:"enum_labels"`   // 字段的枚举标签到枚举取值的映射
+	ScopeAliases map[string]map[string]string `json:"scope_aliases"` // 按作用域类型声明的别名到已注册作用域的映射
+	NamedQueries []NamedQueryConfig           `json:"named_queries"` // 命名查询
+}
+
+// NamedQueryConfig 配置文件中的命名查询
+type NamedQueryConfig struct {
+	Name    string        `json:"name"`
+	Params  []ParamSpec   `json:"params"`
+	Request FilterRequest `json:"request"`
+}
+
+// scopeTypeNames 配置文件中的作用域类型名称
+var scopeTypeNames = map[string]ScopeType{
+	"filter": FilterScope,
+	"sort":   SortScope,
+	"group":  GroupScope,
+	"select": SelectScope,
+	"join":   JoinScope,
+}
+
+// ParseConfig 解析 YAML 或 JSON 格式的配置，操作符可使用名称，如 op: GT
+func ParseConfig(data []byte) (*Config, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	// 经由 JSON 转换，复用请求结构的 JSON 字段名
+	jsonData, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	var config Config
+	if err := json.Unmarshal(jsonData, &config); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return &config, nil
+}
+
+// LoadConfigFile 读取并应用配置文件
+func (qb *QueryBuilder[T]) LoadConfigFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	config, err := ParseConfig(data)
+	if err != nil {
+		return err
+	}
+	return qb.ApplyConfig(config)
+}
+
+// ApplyConfig 按模型结构校验配置后应用到构建器
+//
+// 排序字段与枚举字段需为模型字段，枚举标签需对应声明的枚举取值，作用域别名需指向已注册的作用域且不能与已有作用域重名，命名查询需通过请求校验，
+// 校验失败时返回全部问题且不修改构建器。
+func (qb *QueryBuilder[T]) ApplyConfig(config *Config) error {
+	var errs []error
+
+	for _, field := range config.SortFields {
+		if _, err := qb.validateField(field); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, field := range sortedKeys(config.Enums) {
+		if _, err := qb.validateField(field); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, field := range sortedKeys(config.EnumLabels) {
+		if _, err := qb.validateField(field); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if values, ok := config.Enums[field]; ok {
+			for _, label := range sortedKeys(config.EnumLabels[field]) {
+				if value := config.EnumLabels[field][label]; !containsString(values, value) {
+					errs = append(errs, fmt.Errorf("enum label %s of field %s maps to undeclared value: %s", label, field, value))
+				}
+			}
+		}
+	}
+
+	type alias struct {
+		scopeType ScopeType
+		name      string
+		scope     ParamScopeFunc
+	}
+	var aliases []alias
+	for _, typeName := range sortedKeys(config.ScopeAliases) {
+		scopeType, ok := scopeTypeNames[typeName]
+		if !ok {
+			errs = append(errs, fmt.Errorf("invalid scope type: %s", typeName))
+			continue
+		}
+		for _, name := range sortedKeys(config.ScopeAliases[typeName]) {
+			target := config.ScopeAliases[typeName][name]
+			scope, ok := qb.registry.GetParam(scopeType, target)
+			if !ok {
+				errs = append(errs, fmt.Errorf("invalid %s scope for alias %s: %s", typeName, name, target))
+				continue
+			}
+			aliases = append(aliases, alias{scopeType: scopeType, name: name, scope: scope})
+		}
+	}
+
+	// 命名查询可引用同一配置中的别名与排序字段，在应用了配置的副本上校验
+	staging := qb.Clone()
+	staging.applyConfigOptions(config)
+	for _, a := range aliases {
+		if err := staging.registry.RegisterParam(a.scopeType, a.name, a.scope); err != nil {
+			errs = append(errs, fmt.Errorf("scope alias %s: %w", a.name, err))
+		}
+	}
+	for _, named := range config.NamedQueries {
+		if err := staging.checkNamedQuery(named); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+
+	qb.applyConfigOptions(config)
+	for _, a := range aliases {
+		if err := qb.registry.RegisterParam(a.scopeType, a.name, a.scope); err != nil {
+			return err
+		}
+	}
+	for _, named := range config.NamedQueries {
+		if err := qb.RegisterNamedQuery(named.Name, &named.Request, named.Params...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyConfigOptions 应用配置中的排序字段与枚举声明
+func (qb *QueryBuilder[T]) applyConfigOptions(config *Config) {
+	if len(config.SortFields) > 0 {
+		AllowSortFields(config.SortFields...)(&qb.opts)
+	}
+	for field, values := range config.Enums {
+		WithEnum(field, values...)(&qb.opts)
+	}
+	for field, labels := range config.EnumLabels {
+		WithEnumLabels(field, labels)(&qb.opts)
+	}
+}
+
+// checkNamedQuery 校验配置中的命名查询，含参数占位符的过滤条件仅校验字段
+func (qb *QueryBuilder[T]) checkNamedQuery(named NamedQueryConfig) error {
+	if err := checkNamedParams(named.Name, &named.Request, named.Params); err != nil {
+		return err
+	}
+
+	req := named.Request
+	req.Filters = make([]Filter, 0, len(named.Request.Filters))
+	for _, filter := range named.Request.Filters {
+		if namedParamPattern.MatchString(filter.Value) {
+			filter = Filter{Field: filter.Field, Op: NOT_NULL}
+		}
+		req.Filters = append(req.Filters, filter)
+	}
+	if err := qb.Validate(&req); err != nil {
+		return fmt.Errorf("named query %s: %w", named.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package querybuild
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestQueryBuilder_LoadConfigFile(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+	builder.RegisterScope(FilterScope, "active", func(db *gorm.DB) *gorm.DB {
+		return db.Where("status = ?", "active")
+	})
+
+	path := filepath.Join(t.TempDir(), "users.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+sort_fields: [Name, Age]
+scope_aliases:
+  filter:
+    enabled: active
+named_queries:
+  - name: older_users
+    params:
+      - {name: min_age, required: true}
+    request:
+      filters:
+        - {field: Age, op: GT, value: "{{min_age}}"}
+      custom_filter: {scope: enabled}
+      sorts:
+        - {field: Age, desc: true}
+`), 0o644))
+	assert.NoError(t, builder.LoadConfigFile(path))
+
+	req, err := builder.NamedQuery("older_users", map[string]string{"min_age": "20"})
+	assert.NoError(t, err)
+	var users []TestUser
+	assert.NoError(t, builder.FindAll(req, &users))
+	assert.Len(t, users, 2)
+	assert.Equal(t, "Bob Johnson", users[0].Name)
+
+	assert.IsType(t, &SortNotAllowedError{}, builder.Validate(&FilterRequest{Sorts: []Sort{{Field: "Email"}}}).(*ValidationError).Errors[0])
+}
+
+func TestQueryBuilder_ApplyConfigErrors(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+
+	config, err := ParseConfig([]byte(`{
+		"sort_fields": ["Missing"],
+		"scope_aliases": {"filter": {"enabled": "unregistered"}},
+		"named_queries": [{"name": "bad", "request": {"filters": [{"field": "Unknown", "op": "EQ", "value": "x"}]}}]
+	}`))
+	assert.NoError(t, err)
+
+	err = builder.ApplyConfig(config)
+	var verr *ValidationError
+	assert.ErrorAs(t, err, &verr)
+	assert.Len(t, verr.Errors, 3)
+	assert.Empty(t, builder.NamedQueries(), "nothing is applied on failure")
+
+	_, err = ParseConfig([]byte(`named_queries: [{name: x, request: {filters: [{field: Age, op: NOPE}]}}]`))
+	assert.Error(t, err)
+}
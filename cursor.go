@@ -0,0 +1,252 @@
+package querybuild
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+var (
+	// ErrInvalidCursor 游标格式错误或签名校验失败
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrCursorMismatch 游标记录的排序与当前请求的排序不一致
+	ErrCursorMismatch = errors.New("cursor does not match request sorts")
+)
+
+// CursorResult 游标分页查询结果
+type CursorResult[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"` // 下一页游标，没有下一页时为空
+	HasNext    bool   `json:"has_next"`
+}
+
+// cursorSort 游标中记录的排序字段
+type cursorSort struct {
+	Field string `json:"f"`
+	Desc  bool   `json:"d,omitempty"`
+}
+
+// cursorPayload 游标内容，包含排序字段与最后一条记录的排序值
+type cursorPayload struct {
+	Sorts  []cursorSort      `json:"s"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// FindCursor 按游标分页查询记录
+//
+// 游标分页按请求排序加主键排序的键值定位下一页，cursor 为空时查询第一页。
+// 游标使用 HMAC-SHA256 签名，被篡改或与当前请求排序不一致的游标会被拒绝。
+// 排序仅支持模型字段的普通升降序排序，排序字段值为 NULL 的记录可能被跳过。
+func (qb *QueryBuilder[T]) FindCursor(req *FilterRequest, cursor string, size int) (*CursorResult[T], error) {
+	if len(qb.opts.cursorSecret) == 0 {
+		return nil, fmt.Errorf("cursor secret is not configured")
+	}
+	if len(req.Groups) > 0 || len(req.Aggrs) > 0 || req.Distinct || req.Sample != nil {
+		return nil, fmt.Errorf("cursor pagination does not support groups, aggregations, distinct or sampling")
+	}
+	req, err := qb.beforeFind(req)
+	if err != nil {
+		return nil, err
+	}
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+	if qb.opts.maxPageSize > 0 && size > qb.opts.maxPageSize {
+		size = qb.opts.maxPageSize
+	}
+
+	sorts, fields, err := qb.cursorSorts(req)
+	if err != nil {
+		return nil, err
+	}
+
+	cursorReq := *req
+	cursorReq.Sorts = make([]Sort, 0, len(sorts))
+	for _, sort := range sorts {
+		cursorReq.Sorts = append(cursorReq.Sorts, Sort{Field: sort.Field, Desc: sort.Desc})
+	}
+	cursorReq.Page = nil
+	query := qb.Build(&cursorReq)
+
+	if cursor != "" {
+		values, err := qb.decodeCursor(cursor, sorts, fields)
+		if err != nil {
+			return nil, err
+		}
+		cond, args := keysetCondition(qb.tableName(), fields, sorts, values)
+		query = query.Where(cond, args...)
+	}
+
+	var items []T
+	if err := query.Limit(size + 1).Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	result := &CursorResult[T]{Items: items}
+	if len(items) > size {
+		result.Items = items[:size]
+		result.HasNext = true
+		result.NextCursor, err = qb.encodeCursor(sorts, fields, items[size-1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	if result.Items, err = qb.afterFind(result.Items); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// cursorSorts 确定游标分页使用的排序，并在末尾追加未排序的主键
+func (qb *QueryBuilder[T]) cursorSorts(req *FilterRequest) ([]cursorSort, []*schema.Field, error) {
+	if len(qb.keys) == 0 {
+		return nil, nil, fmt.Errorf("cursor pagination requires a primary key or key fields")
+	}
+
+	reqSorts := req.Sorts
+	if len(reqSorts) == 0 {
+		reqSorts = qb.opts.defaultSorts
+	}
+
+	sorts := make([]cursorSort, 0, len(reqSorts)+len(qb.keys))
+	fields := make([]*schema.Field, 0, cap(sorts))
+	seen := make(map[string]bool, cap(sorts))
+	for _, sort := range reqSorts {
+		if sort.ScopeName != "" || sort.CountOf != "" || len(sort.Values) > 0 || sort.NoCase || sort.Collation != "" {
+			return nil, nil, fmt.Errorf("cursor pagination only supports plain field sorts: %s", sort.Field)
+		}
+		field := qb.schema.LookUpField(sort.Field)
+		if field == nil || field.DBName == "" {
+			return nil, nil, fmt.Errorf("invalid field name: %s", sort.Field)
+		}
+		if err := qb.checkSortAllowed(sort.Field); err != nil {
+			return nil, nil, err
+		}
+		if seen[field.DBName] {
+			continue
+		}
+		seen[field.DBName] = true
+		sorts = append(sorts, cursorSort{Field: field.Name, Desc: sort.Desc})
+		fields = append(fields, field)
+	}
+	for _, pk := range qb.keys {
+		if !seen[pk.DBName] {
+			sorts = append(sorts, cursorSort{Field: pk.Name})
+			fields = append(fields, pk)
+		}
+	}
+	return sorts, fields, nil
+}
+
+// keysetCondition 生成位于游标之后的键集条件
+//
+// 对排序 (a, b) 生成 (a > ?) OR (a = ? AND b > ?)，降序字段使用 <。
+func keysetCondition(table string, fields []*schema.Field, sorts []cursorSort, values []interface{}) (string, []interface{}) {
+	ors := make([]string, 0, len(sorts))
+	var args []interface{}
+	for i, sort := range sorts {
+		ands := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, fmt.Sprintf("%s = ?", quoteField(FieldInfo{TableName: table, Name: fields[j].DBName})))
+			args = append(args, values[j])
+		}
+		op := ">"
+		if sort.Desc {
+			op = "<"
+		}
+		ands = append(ands, fmt.Sprintf("%s %s ?", quoteField(FieldInfo{TableName: table, Name: fields[i].DBName}), op))
+		args = append(args, values[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", args
+}
+
+// encodeCursor 将记录的排序值编码为签名游标
+func (qb *QueryBuilder[T]) encodeCursor(sorts []cursorSort, fields []*schema.Field, item T) (string, error) {
+	values, err := qb.keyValues(fields, item)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(cursorPayload{Sorts: sorts, Values: values})
+	if err != nil {
+		return "", err
+	}
+	body := base64.RawURLEncoding.EncodeToString(data)
+	return body + "." + base64.RawURLEncoding.EncodeToString(qb.signCursor(body)), nil
+}
+
+// decodeCursor 校验游标签名与排序，并按字段类型解码排序值
+func (qb *QueryBuilder[T]) decodeCursor(cursor string, sorts []cursorSort, fields []*schema.Field) ([]interface{}, error) {
+	body, sig, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, qb.signCursor(body)) {
+		return nil, ErrInvalidCursor
+	}
+	data, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if !reflect.DeepEqual(payload.Sorts, sorts) || len(payload.Values) != len(fields) {
+		return nil, ErrCursorMismatch
+	}
+
+	values, err := parseKeyValues(payload.Values, fields)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return values, nil
+}
+
+// keyValues 取出记录中键集字段的 JSON 取值
+func (qb *QueryBuilder[T]) keyValues(fields []*schema.Field, item T) ([]json.RawMessage, error) {
+	rv := reflect.ValueOf(&item).Elem()
+	values := make([]json.RawMessage, 0, len(fields))
+	for _, field := range fields {
+		value, _ := field.ValueOf(qb.db.Statement.Context, rv)
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, data)
+	}
+	return values, nil
+}
+
+// parseKeyValues 按字段类型解码键集字段的 JSON 取值
+func parseKeyValues(raw []json.RawMessage, fields []*schema.Field) ([]interface{}, error) {
+	values := make([]interface{}, 0, len(fields))
+	for i, field := range fields {
+		value := reflect.New(field.FieldType)
+		if !bytes.Equal(raw[i], []byte("null")) {
+			if err := json.Unmarshal(raw[i], value.Interface()); err != nil {
+				return nil, err
+			}
+		}
+		values = append(values, value.Elem().Interface())
+	}
+	return values, nil
+}
+
+// signCursor 计算游标内容的签名
+func (qb *QueryBuilder[T]) signCursor(body string) []byte {
+	mac := hmac.New(sha256.New, qb.opts.cursorSecret)
+	mac.Write([]byte(body))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,78 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_FindCursor(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db, WithCursorSecret([]byte("secret")))
+
+	t.Run("Walk pages", func(t *testing.T) {
+		req := &FilterRequest{Sorts: []Sort{{Field: "Status"}}}
+
+		first, err := builder.FindCursor(req, "", 2)
+		assert.NoError(t, err)
+		assert.Len(t, first.Items, 2)
+		assert.True(t, first.HasNext)
+		assert.NotEmpty(t, first.NextCursor)
+		assert.Equal(t, "John Doe", first.Items[0].Name)
+		assert.Equal(t, "Bob Johnson", first.Items[1].Name)
+
+		second, err := builder.FindCursor(req, first.NextCursor, 2)
+		assert.NoError(t, err)
+		assert.Len(t, second.Items, 1)
+		assert.Equal(t, "Jane Smith", second.Items[0].Name)
+		assert.False(t, second.HasNext)
+		assert.Empty(t, second.NextCursor)
+	})
+
+	t.Run("Descending time sort", func(t *testing.T) {
+		req := &FilterRequest{Sorts: []Sort{{Field: "CreatedAt", Desc: true}}}
+
+		first, err := builder.FindCursor(req, "", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "John Doe", first.Items[0].Name)
+
+		second, err := builder.FindCursor(req, first.NextCursor, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "Jane Smith", second.Items[0].Name)
+	})
+
+	t.Run("Tampered cursor", func(t *testing.T) {
+		req := &FilterRequest{Sorts: []Sort{{Field: "Age"}}}
+		first, err := builder.FindCursor(req, "", 1)
+		assert.NoError(t, err)
+
+		tampered := "x" + first.NextCursor[1:]
+		_, err = builder.FindCursor(req, tampered, 1)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+
+		other := NewQueryBuilder[TestUser](db, WithCursorSecret([]byte("other")))
+		_, err = other.FindCursor(req, first.NextCursor, 1)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+
+		_, err = builder.FindCursor(req, "garbage", 1)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("Mismatched sorts", func(t *testing.T) {
+		first, err := builder.FindCursor(&FilterRequest{Sorts: []Sort{{Field: "Age"}}}, "", 1)
+		assert.NoError(t, err)
+
+		_, err = builder.FindCursor(&FilterRequest{Sorts: []Sort{{Field: "Age", Desc: true}}}, first.NextCursor, 1)
+		assert.ErrorIs(t, err, ErrCursorMismatch)
+	})
+
+	t.Run("Unsupported sorts", func(t *testing.T) {
+		_, err := builder.FindCursor(&FilterRequest{Sorts: []Sort{{Field: "Status", Values: []string{"active"}}}}, "", 1)
+		assert.Error(t, err)
+	})
+
+	t.Run("Secret required", func(t *testing.T) {
+		_, err := NewQueryBuilder[TestUser](db).FindCursor(&FilterRequest{}, "", 1)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,169 @@
+package querybuild
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+// redactedValue 调试日志中隐藏取值时使用的占位符
+const redactedValue = "***"
+
+// debugClauses 调试日志中单独列出的子句
+var debugClauses = []string{"WHERE", "GROUP BY", "ORDER BY", "LIMIT"}
+
+// DebugEntry 调试模式下记录的查询信息
+type DebugEntry struct {
+	Request string            // 查询请求的 JSON
+	Clauses map[string]string // 解析后的各子句，如 WHERE、ORDER BY
+	SQL     string            // 最终的SQL语句，取值以占位符表示
+	Vars    []interface{}     // SQL语句的绑定参数
+	Err     error             // 构建错误
+}
+
+// DebugLogger 调试日志的输出函数
+type DebugLogger func(ctx context.Context, entry DebugEntry)
+
+// Debug 返回开启调试模式的构建器副本，构建查询时一并记录请求、解析后的子句与最终SQL
+func (qb *QueryBuilder[T]) Debug() *QueryBuilder[T] {
+	clone := *qb
+	clone.opts.debug = true
+	return &clone
+}
+
+// logDebug 记录构建完成的查询，SQL 通过 DryRun 生成，不会执行查询
+func (qb *QueryBuilder[T]) logDebug(query *gorm.DB, req *FilterRequest) {
+	entry := DebugEntry{Request: qb.debugRequest(req), Err: query.Error}
+	if query.Error == nil {
+		var dest []T
+		stmt := query.Session(&gorm.Session{DryRun: true}).Find(&dest).Statement
+		entry.SQL = stmt.SQL.String()
+		entry.Vars = stmt.Vars
+		entry.Clauses = make(map[string]string, len(debugClauses))
+		for _, name := range debugClauses {
+			if c, ok := stmt.Clauses[name]; ok {
+				entry.Clauses[name] = clauseSQL(stmt, c)
+			}
+		}
+		if qb.opts.debugRedact {
+			entry.Vars = redactVars(entry.Vars)
+		}
+	}
+
+	ctx := query.Statement.Context
+	if qb.opts.debugLogger != nil {
+		qb.opts.debugLogger(ctx, entry)
+		return
+	}
+	qb.db.Logger.LogMode(logger.Info).Info(ctx, "%s", entry.String())
+}
+
+// String 返回调试信息的文本表示
+func (e DebugEntry) String() string {
+	var b strings.Builder
+	b.WriteString("querybuild request: ")
+	b.WriteString(e.Request)
+	if e.Err != nil {
+		b.WriteString("\n  error: ")
+		b.WriteString(e.Err.Error())
+	}
+	names := make([]string, 0, len(e.Clauses))
+	for name := range e.Clauses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString("\n  ")
+		b.WriteString(e.Clauses[name])
+	}
+	if e.SQL != "" {
+		fmt.Fprintf(&b, "\n  sql: %s\n  vars: %v", e.SQL, e.Vars)
+	}
+	return b.String()
+}
+
+// clauseSQL 单独生成子句的SQL
+func clauseSQL(stmt *gorm.Statement, c clause.Clause) string {
+	sub := &gorm.Statement{
+		DB:      stmt.DB,
+		Table:   stmt.Table,
+		Schema:  stmt.Schema,
+		Clauses: map[string]clause.Clause{},
+	}
+	c.Build(sub)
+	return sub.SQL.String()
+}
+
+// debugRequest 返回调试日志中的请求 JSON，开启隐藏取值时替换过滤取值
+func (qb *QueryBuilder[T]) debugRequest(req *FilterRequest) string {
+	if qb.opts.debugRedact {
+		req = redactRequest(req)
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
+
+// redactRequest 返回过滤取值被替换的请求副本
+func redactRequest(req *FilterRequest) *FilterRequest {
+	redacted := *req
+	redacted.Filters = redactFilters(req.Filters)
+	redacted.Exists = make([]ExistsFilter, len(req.Exists))
+	for i, exists := range req.Exists {
+		exists.Filter = *redactRequest(&exists.Filter)
+		redacted.Exists[i] = exists
+	}
+	redacted.CountFilters = make([]CountFilter, len(req.CountFilters))
+	for i, count := range req.CountFilters {
+		count.Filter = *redactRequest(&count.Filter)
+		redacted.CountFilters[i] = count
+	}
+	if req.CustomFilter != nil {
+		custom := *req.CustomFilter
+		custom.Values = redactVars(custom.Values)
+		redacted.CustomFilter = &custom
+	}
+	if req.CustomFilters != nil {
+		redacted.CustomFilters = make([]CustomFilter, len(req.CustomFilters))
+		for i, custom := range req.CustomFilters {
+			custom.Values = redactVars(custom.Values)
+			redacted.CustomFilters[i] = custom
+		}
+	}
+	return &redacted
+}
+
+// redactFilters 返回取值被替换的过滤条件副本
+func redactFilters(filters []Filter) []Filter {
+	if filters == nil {
+		return nil
+	}
+	redacted := make([]Filter, len(filters))
+	for i, filter := range filters {
+		if filter.Value != "" {
+			filter.Value = redactedValue
+		}
+		redacted[i] = filter
+	}
+	return redacted
+}
+
+// redactVars 返回取值被替换的参数列表
+func redactVars(vars []interface{}) []interface{} {
+	if vars == nil {
+		return nil
+	}
+	redacted := make([]interface{}, len(vars))
+	for i := range vars {
+		redacted[i] = redactedValue
+	}
+	return redacted
+}
@@ -0,0 +1,66 @@
+package querybuild
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_Debug(t *testing.T) {
+	db := setupTestDB(t)
+
+	var entries []DebugEntry
+	logger := WithDebugLogger(func(ctx context.Context, entry DebugEntry) {
+		entries = append(entries, entry)
+	})
+	req := &FilterRequest{
+		Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+		Sorts:   []Sort{{Field: "Age", Desc: true}},
+	}
+
+	t.Run("Annotated SQL", func(t *testing.T) {
+		entries = nil
+		builder := NewQueryBuilder[TestUser](db, logger).Debug()
+
+		var users []TestUser
+		assert.NoError(t, builder.Build(req).Find(&users).Error)
+		assert.Len(t, users, 2)
+		assert.Len(t, entries, 1)
+
+		entry := entries[0]
+		assert.Contains(t, entry.Request, `"value":"active"`)
+		assert.Equal(t, "WHERE `test_users`.`status` = ?", entry.Clauses["WHERE"])
+		assert.Equal(t, "ORDER BY `test_users`.`age` DESC", entry.Clauses["ORDER BY"])
+		assert.Contains(t, entry.SQL, "SELECT * FROM `test_users` WHERE")
+		assert.Equal(t, []interface{}{"active"}, entry.Vars)
+		assert.Contains(t, entry.String(), "ORDER BY `test_users`.`age` DESC")
+	})
+
+	t.Run("Redaction", func(t *testing.T) {
+		entries = nil
+		builder := NewQueryBuilder[TestUser](db, logger, WithDebugRedaction()).Debug()
+		builder.Build(req)
+
+		assert.Len(t, entries, 1)
+		assert.NotContains(t, entries[0].Request, "active")
+		assert.Equal(t, []interface{}{"***"}, entries[0].Vars)
+		assert.Equal(t, "active", req.Filters[0].Value, "request is not modified")
+	})
+
+	t.Run("Build error", func(t *testing.T) {
+		entries = nil
+		builder := NewQueryBuilder[TestUser](db, logger).Debug()
+		builder.Build(&FilterRequest{Filters: []Filter{{Field: "Unknown", Op: EQ, Value: "x"}}})
+
+		assert.Len(t, entries, 1)
+		assert.Error(t, entries[0].Err)
+		assert.Empty(t, entries[0].SQL)
+	})
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		entries = nil
+		NewQueryBuilder[TestUser](db, logger).Build(req)
+		assert.Empty(t, entries)
+	})
+}
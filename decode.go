@@ -0,0 +1,193 @@
+package querybuild
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// resultTimeLayouts 驱动以文本返回的时间聚合结果的格式，如 SQLite 的 MAX(created_at)
+var resultTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999Z07:00",
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// aggregateColumn 需按来源字段类型解码的聚合结果列
+type aggregateColumn struct {
+	op    AggregationOp
+	field *schema.Field
+}
+
+// decodedAggregates 返回结果列名到聚合来源字段的映射
+//
+// 仅包含以模型字段为来源、结果类型取决于字段类型的聚合：时间、数值字段的 MIN 与 MAX，数值字段的 SUM 与 AVG。
+func (qb *QueryBuilder[T]) decodedAggregates(aggrs []Aggregation) map[string]aggregateColumn {
+	var columns map[string]aggregateColumn
+	for _, aggr := range aggrs {
+		if aggr.Expr != "" || aggr.NoCase || (aggr.Window != nil && aggr.Window.Op == PERIOD_CHANGE_RATIO) {
+			continue
+		}
+		field := qb.schema.LookUpField(aggr.Field)
+		if field == nil || field.DBName == "" {
+			continue
+		}
+		switch field.DataType {
+		case schema.Int, schema.Uint, schema.Float:
+			if aggr.Op != MIN && aggr.Op != MAX && aggr.Op != SUM && aggr.Op != AVG {
+				continue
+			}
+		case schema.Time:
+			if (aggr.Op != MIN && aggr.Op != MAX) || (aggr.Window != nil && aggr.Window.Op != PREVIOUS_PERIOD) {
+				continue
+			}
+		default:
+			continue
+		}
+
+		alias := aggr.Alias
+		if alias == "" {
+			alias = field.DBName
+		}
+		if columns == nil {
+			columns = make(map[string]aggregateColumn)
+		}
+		columns[alias] = aggregateColumn{op: aggr.Op, field: field}
+	}
+	return columns
+}
+
+// decodeAggregates 将结果中以文本返回的聚合取值按来源字段类型转换
+func decodeAggregates(rows []map[string]interface{}, columns map[string]aggregateColumn, loc *time.Location) {
+	for _, row := range rows {
+		for name, column := range columns {
+			if value, ok := row[name]; ok {
+				row[name] = decodeAggregate(value, column, loc)
+			}
+		}
+	}
+}
+
+// decodeAggregate 按来源字段类型转换聚合取值，时间字段转换为 time.Time，数值字段的文本取值（如 DECIMAL）转换为数值
+//
+// 不带时区的时间文本按 loc 解析，loc 为 nil 时按 UTC 解析；无法解析的取值保持不变。
+func decodeAggregate(value interface{}, column aggregateColumn, loc *time.Location) interface{} {
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return value
+	}
+
+	if loc == nil {
+		loc = time.UTC
+	}
+	switch {
+	case column.field.DataType == schema.Time:
+		for _, layout := range resultTimeLayouts {
+			if t, err := time.ParseInLocation(layout, text, loc); err == nil {
+				return t
+			}
+		}
+	case column.op == AVG || column.field.DataType == schema.Float:
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f
+		}
+	case column.field.DataType == schema.Uint:
+		if n, err := strconv.ParseUint(text, 10, 64); err == nil {
+			return n
+		}
+	default:
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n
+		}
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f
+		}
+	}
+	return value
+}
+
+// findDecoded 以映射扫描查询结果，解码聚合取值后写入目标，first 为 true 时仅查询第一条记录
+func (qb *QueryBuilder[T]) findDecoded(query *gorm.DB, dest interface{}, columns map[string]aggregateColumn, first bool) error {
+	loc := queryLocation(query)
+	var rows []map[string]interface{}
+	if first {
+		query = query.First(&rows)
+	} else {
+		query = query.Find(&rows)
+	}
+	if query.Error != nil {
+		return query.Error
+	}
+	decodeAggregates(rows, columns, loc)
+	return assignRows(query, dest, rows)
+}
+
+// assignRows 将映射形式的结果写入目标，目标可为映射、结构体或其切片，结构体字段按列名匹配
+func assignRows(query *gorm.DB, dest interface{}, rows []map[string]interface{}) error {
+	switch dest := dest.(type) {
+	case *[]map[string]interface{}:
+		*dest = rows
+		return nil
+	case *map[string]interface{}:
+		if len(rows) > 0 {
+			*dest = rows[0]
+		}
+		return nil
+	}
+
+	stmt := &gorm.Statement{DB: query}
+	if err := stmt.Parse(dest); err != nil {
+		return err
+	}
+	ctx := query.Statement.Context
+	set := func(elem reflect.Value, row map[string]interface{}) error {
+		for column, value := range row {
+			field := stmt.Schema.LookUpField(column)
+			if field == nil || !field.Readable {
+				continue
+			}
+			if err := field.Set(ctx, elem, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(dest))
+	switch value.Kind() {
+	case reflect.Slice:
+		elemType := value.Type().Elem()
+		isPtr := elemType.Kind() == reflect.Ptr
+		if isPtr {
+			elemType = elemType.Elem()
+		}
+		items := reflect.MakeSlice(value.Type(), 0, len(rows))
+		for _, row := range rows {
+			elem := reflect.New(elemType)
+			if err := set(elem.Elem(), row); err != nil {
+				return err
+			}
+			if isPtr {
+				items = reflect.Append(items, elem)
+			} else {
+				items = reflect.Append(items, elem.Elem())
+			}
+		}
+		value.Set(items)
+	case reflect.Struct:
+		if len(rows) > 0 {
+			return set(value, rows[0])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package querybuild
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// applyDedupedConditions 在主键子查询中应用连接、子查询与过滤条件，主查询仅按主键筛选记录
+//
+// 连接一对多关联时同一记录会出现多次，计数与分页会重复统计。主查询不包含连接，
+// 计数即为去重后的记录数，排序与选择字段只能引用模型字段。
+func (qb *QueryBuilder[T]) applyDedupedConditions(query *gorm.DB, req *FilterRequest) *gorm.DB {
+	if len(qb.keys) == 0 {
+		query.AddError(fmt.Errorf("dedupe by primary key requires a primary key or key fields: %s", qb.tableName()))
+		return query
+	}
+
+	table := qb.tableName()
+	columns := make([]string, 0, len(qb.keys))
+	for _, pk := range qb.keys {
+		columns = append(columns, quoteField(FieldInfo{TableName: table, Name: pk.DBName}))
+	}
+	column := strings.Join(columns, ", ")
+	if len(columns) > 1 {
+		column = "(" + column + ")"
+	}
+
+	keys := query.Session(&gorm.Session{})
+	keys = qb.applyJoins(keys, req.Joins)
+	keys = qb.applySubQuery(keys, req.SubQuery)
+	keys = qb.applyConditions(keys, req)
+	if keys.Error != nil {
+		query.AddError(keys.Error)
+		return query
+	}
+	keys = keys.Select(strings.Join(columns, ", "))
+	return query.Clauses(clause.Where{Exprs: []clause.Expression{
+		clause.Expr{SQL: column + " IN (?)", Vars: []interface{}{keys}},
+	}})
+}
@@ -0,0 +1,52 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_DedupeByPK(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+
+	req := func(dedupe bool) *FilterRequest {
+		return &FilterRequest{
+			Joins: []Join{{Type: "INNER", Table: "test_orders", Alias: "o", Condition: "o.user_id = test_users.id"}},
+			Filters: []Filter{
+				{Field: "o.amount", Op: GT, Value: "10"},
+			},
+			Sorts:      []Sort{{Field: "ID"}},
+			Page:       &Pagination{Page: 1, PageSize: 10},
+			DedupeByPK: dedupe,
+		}
+	}
+
+	t.Run("Joins fan out rows", func(t *testing.T) {
+		result, err := builder.FindPage(req(false))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), result.Pagination.Total)
+		assert.Len(t, result.Items, 3)
+	})
+
+	t.Run("Dedupe", func(t *testing.T) {
+		result, err := builder.FindPage(req(true))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), result.Pagination.Total)
+		if assert.Len(t, result.Items, 2) {
+			assert.Equal(t, "John Doe", result.Items[0].Name)
+			assert.Equal(t, "Jane Smith", result.Items[1].Name)
+		}
+
+		count, err := builder.Count(req(true))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("Invalid filter", func(t *testing.T) {
+		r := req(true)
+		r.Filters = append(r.Filters, Filter{Field: "Missing", Op: EQ, Value: "x"})
+		_, err := builder.FindPage(r)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,206 @@
+package querybuild
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrRangeExceeded 请求未限定时间字段的上下界或范围超过最大跨度，但缺省规则要求限定
+var ErrRangeExceeded = errors.New("request must bound the time range within the maximum span")
+
+// DefaultRule 请求缺省规则，在 Build 时为请求补全未指定的排序、每页数量、过滤条件与时间范围
+type DefaultRule struct {
+	Name     string                         `json:"name"`                // 规则名称，记录在应用的缺省值中
+	When     func(ctx context.Context) bool `json:"-"`                   // 规则适用的调用方，如按上下文中的调用方角色判断，为空时适用于所有请求
+	Sorts    []Sort                         `json:"sorts,omitempty"`     // 请求未指定排序时使用的排序
+	PageSize int                            `json:"page_size,omitempty"` // 请求分页未指定每页数量时使用的每页数量
+	Filters  []Filter                       `json:"filters,omitempty"`   // 请求未过滤相应字段时追加的过滤条件
+	Range    *DateRange                     `json:"range,omitempty"`     // 时间字段的最大范围，按 Enforce 补全、要求或收窄
+}
+
+// RangeEnforcement 时间范围规则对请求的处理方式
+type RangeEnforcement int
+
+const (
+	RangeDefault RangeEnforcement = iota // 请求未限定下界时追加 Field >= 当前时间 - Max，不限制请求的跨度
+	RangeRequire                         // 请求需同时限定上下界且跨度不超过 Max，否则返回 ErrRangeExceeded
+	RangeClamp                           // 下界缺失或跨度超过 Max 时将下界收窄为上界 - Max，上界缺失时以当前时间为上界
+)
+
+// DateRange 时间字段的最大范围
+//
+// 请求的范围由该字段的比较、区间与 IN 过滤条件（包括嵌套的非取反过滤条件组）按业务时区解析后合并得到，
+// 仅包含日期的取值表示业务时区中的一整天。范围在跳过空取值并按处理方式过滤无效条目后计算，
+// 字段按模型字段名匹配，被跳过的条件与列名等无效字段的条件不视为限定了范围。
+type DateRange struct {
+	Field   string           `json:"field"`             // 时间字段
+	Max     time.Duration    `json:"max"`               // 范围的最大跨度
+	Enforce RangeEnforcement `json:"enforce,omitempty"` // 处理方式，默认为 RangeDefault
+}
+
+// AppliedDefault 构建时按缺省规则补全的请求内容
+type AppliedDefault struct {
+	Rule     string   `json:"rule"`                // 规则名称
+	Sorts    []Sort   `json:"sorts,omitempty"`     // 补全的排序
+	PageSize int      `json:"page_size,omitempty"` // 补全的每页数量
+	Filters  []Filter `json:"filters,omitempty"`   // 追加的过滤条件，包括时间范围
+}
+
+// WithDefaults 声明请求缺省规则，按声明顺序在 Build 时应用，可多次调用追加规则
+//
+// 规则只补全请求未指定的部分：请求已指定排序、每页数量或已过滤相应字段时不覆盖，前面的规则补全后后面的规则不再补全。
+// 时间范围规则还可以要求请求限定不超过最大跨度的范围，或将过宽的范围收窄，见 RangeEnforcement。
+// 补全在请求副本上进行，原请求不会被修改；FindPage 在返回结果的 Defaults 中提供应用的缺省值，
+// 补全了每页数量时返回结果的 Pagination 为补全后的分页信息。缺省过滤条件可被请求覆盖，需强制的条件应使用作用域。
+func WithDefaults(rules ...DefaultRule) Option {
+	return func(o *options) {
+		o.defaultRules = append(o.defaultRules, rules...)
+	}
+}
+
+// activeDefaults 返回适用于当前调用方的缺省规则
+func (qb *QueryBuilder[T]) activeDefaults() []DefaultRule {
+	if len(qb.opts.defaultRules) == 0 {
+		return nil
+	}
+	ctx := qb.db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var rules []DefaultRule
+	for _, rule := range qb.opts.defaultRules {
+		if rule.When == nil || rule.When(ctx) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// applyDefaults 按缺省规则补全请求，返回补全后的请求副本，应用的缺省值记录在副本的 Defaults 中
+//
+// 原请求不会被修改：补全每页数量时复制分页信息，查询结果信息随之写入副本的分页。
+// 时间范围不符合 RangeRequire 规则或取值无法解析时返回错误。
+func (qb *QueryBuilder[T]) applyDefaults(req *FilterRequest) (*FilterRequest, error) {
+	rules := qb.activeDefaults()
+	if len(rules) == 0 {
+		return req, nil
+	}
+
+	out := *req
+	out.Filters = append([]Filter(nil), req.Filters...)
+	var applied []AppliedDefault
+	for _, rule := range rules {
+		a := AppliedDefault{Rule: rule.Name}
+		if len(out.Sorts) == 0 && len(rule.Sorts) > 0 && !out.Sample.byRows() {
+			out.Sorts = append([]Sort(nil), rule.Sorts...)
+			a.Sorts = out.Sorts
+		}
+		if rule.PageSize > 0 && out.Page != nil && out.Page.PageSize == 0 {
+			page := *out.Page
+			page.PageSize = rule.PageSize
+			out.Page = &page
+			a.PageSize = rule.PageSize
+		}
+		for _, filter := range rule.Filters {
+			if !qb.filtersField(out.Filters, filter.Field) {
+				out.Filters = append(out.Filters, filter)
+				a.Filters = append(a.Filters, filter)
+			}
+		}
+		if rule.Range != nil {
+			filter, err := qb.rangeFilter(&out, *rule.Range)
+			if err != nil {
+				return nil, err
+			}
+			if filter != nil {
+				out.Filters = append(out.Filters, *filter)
+				a.Filters = append(a.Filters, *filter)
+			}
+		}
+		if len(a.Sorts) > 0 || a.PageSize > 0 || len(a.Filters) > 0 {
+			applied = append(applied, a)
+		}
+	}
+	out.Defaults = applied
+	return &out, nil
+}
+
+// pageDefaulted 判断应用的缺省值是否补全了每页数量，此时分页信息不写回原请求
+func pageDefaulted(applied []AppliedDefault) bool {
+	for _, a := range applied {
+		if a.PageSize > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// filtersField 判断过滤条件中是否包含字段的条件
+func (qb *QueryBuilder[T]) filtersField(filters []Filter, field string) bool {
+	name := qb.canonicalField(field)
+	for _, filter := range filters {
+		if qb.canonicalField(filter.Field) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// rangeFilter 按时间范围规则检查请求限定的范围，返回需追加的下界条件，无需追加时返回 nil
+func (qb *QueryBuilder[T]) rangeFilter(req *FilterRequest, rule DateRange) (*Filter, error) {
+	field := qb.schema.LookUpField(rule.Field)
+	if field == nil || field.DBName == "" {
+		return nil, fmt.Errorf("invalid field name: %s", rule.Field)
+	}
+	loc, err := qb.requestLocation(req)
+	if err != nil {
+		return nil, err
+	}
+	r, err := qb.timeRange(field, req, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	var from time.Time
+	switch rule.Enforce {
+	case RangeRequire:
+		if !r.Bounded() || r.To.Sub(*r.From) > rule.Max {
+			return nil, fmt.Errorf("%w: %s (max %s)", ErrRangeExceeded, field.Name, rule.Max)
+		}
+		return nil, nil
+	case RangeClamp:
+		to := time.Now()
+		if r.To != nil {
+			to = *r.To
+		}
+		if r.From != nil && to.Sub(*r.From) <= rule.Max {
+			return nil, nil
+		}
+		from = to.Add(-rule.Max)
+	default:
+		if r.From != nil {
+			return nil, nil
+		}
+		from = time.Now().Add(-rule.Max)
+	}
+	return &Filter{Field: field.Name, Op: GE, Value: from.UTC().Format(time.RFC3339Nano)}, nil
+}
+
+// defaultsKey 返回适用于当前调用方的缺省规则的摘要，用于区分不同调用方的缓存结果，没有适用的规则时返回空
+func (qb *QueryBuilder[T]) defaultsKey() string {
+	rules := qb.activeDefaults()
+	if len(rules) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,185 @@
+package querybuild
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_DefaultRules(t *testing.T) {
+	db := setupTestDB(t)
+	support := func(ctx context.Context) bool { return IdentityFromContext(ctx) == "support" }
+	builder := NewQueryBuilder[TestUser](db,
+		WithLocation(time.UTC),
+		WithCache(NewMemoryCache(), time.Minute),
+		WithDefaults(
+			DefaultRule{Name: "listing", Sorts: []Sort{{Field: "Age", Desc: true}}, PageSize: 2},
+			DefaultRule{Name: "support", When: support, Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}}},
+		),
+	)
+	names := func(users []TestUser) []string {
+		var result []string
+		for _, user := range users {
+			result = append(result, user.Name)
+		}
+		return result
+	}
+
+	t.Run("Sorts and page size", func(t *testing.T) {
+		req := &FilterRequest{Page: &Pagination{Page: 1}}
+		result, err := builder.FindPage(req)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Bob Johnson", "Jane Smith"}, names(result.Items))
+		assert.Equal(t, 2, result.Pagination.PageSize)
+		assert.Equal(t, int64(3), result.Pagination.Total)
+		assert.Equal(t, []AppliedDefault{{Rule: "listing", Sorts: []Sort{{Field: "Age", Desc: true}}, PageSize: 2}}, result.Defaults)
+
+		// 原请求不会被修改，可按其他调用方的规则重复使用
+		assert.Equal(t, &FilterRequest{Page: &Pagination{Page: 1}}, req)
+
+		result, err = builder.FindPage(req)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.Pagination.PageSize)
+		assert.Len(t, result.Defaults, 1, "cached result")
+		assert.Equal(t, &FilterRequest{Page: &Pagination{Page: 1}}, req)
+	})
+
+	t.Run("Build", func(t *testing.T) {
+		req := &FilterRequest{Page: &Pagination{Page: 1}}
+		var users []TestUser
+		assert.NoError(t, builder.Build(req).Find(&users).Error)
+		assert.Len(t, users, 2)
+		assert.Equal(t, &FilterRequest{Page: &Pagination{Page: 1}}, req)
+	})
+
+	t.Run("Caller role filters", func(t *testing.T) {
+		scoped := builder.WithContext(WithIdentity(context.Background(), "support"))
+		req := &FilterRequest{Page: &Pagination{Page: 1, PageSize: 10}}
+		result, err := scoped.FindPage(req)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Bob Johnson", "John Doe"}, names(result.Items))
+		if assert.Len(t, result.Defaults, 2) {
+			assert.Equal(t, []Filter{{Field: "Status", Op: EQ, Value: "active"}}, result.Defaults[1].Filters)
+		}
+		assert.Empty(t, req.Filters)
+		assert.Empty(t, req.Defaults)
+		assert.Equal(t, int64(2), req.Page.Total, "pagination is written back when the page size is not defaulted")
+
+		// 缓存结果按适用的规则区分调用方
+		result, err = builder.FindPage(&FilterRequest{Page: &Pagination{Page: 1, PageSize: 10}})
+		assert.NoError(t, err)
+		assert.Len(t, result.Items, 3)
+
+		var users []TestUser
+		assert.NoError(t, scoped.FindAll(&FilterRequest{Filters: []Filter{{Field: "Status", Op: EQ, Value: "inactive"}}}, &users))
+		assert.Equal(t, []string{"Jane Smith"}, names(users))
+	})
+
+	t.Run("Date range", func(t *testing.T) {
+		ranged := builder.Clone(WithDefaults(DefaultRule{Name: "recent", Range: &DateRange{Field: "CreatedAt", Max: 36 * time.Hour}}))
+		var users []TestUser
+		assert.NoError(t, ranged.FindAll(&FilterRequest{}, &users))
+		assert.Equal(t, []string{"Jane Smith", "John Doe"}, names(users))
+
+		result, err := ranged.FindPage(&FilterRequest{Page: &Pagination{Page: 1, PageSize: 10}})
+		assert.NoError(t, err)
+		if assert.Len(t, result.Defaults, 2) {
+			assert.Equal(t, "recent", result.Defaults[1].Rule)
+			assert.Equal(t, GE, result.Defaults[1].Filters[0].Op)
+		}
+
+		req := &FilterRequest{
+			Filters: []Filter{{Field: "CreatedAt", Op: GE, Value: time.Now().Add(-72 * time.Hour).UTC().Format(time.RFC3339)}},
+			Page:    &Pagination{Page: 1, PageSize: 10},
+		}
+		result, err = ranged.FindPage(req)
+		assert.NoError(t, err)
+		assert.Len(t, result.Items, 3)
+		assert.Len(t, result.Defaults, 1)
+	})
+}
+
+func TestQueryBuilder_DateRangeEnforcement(t *testing.T) {
+	db := setupTestDB(t)
+	external := func(ctx context.Context) bool { return IdentityFromContext(ctx) == "external" }
+	since := func(d time.Duration) string { return time.Now().Add(-d).UTC().Format(time.RFC3339) }
+	ctx := WithIdentity(context.Background(), "external")
+
+	t.Run("Require", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db, WithLocation(time.UTC), WithDefaults(DefaultRule{
+			Name:  "bounded",
+			When:  external,
+			Range: &DateRange{Field: "CreatedAt", Max: 72 * time.Hour, Enforce: RangeRequire},
+		}))
+		var users []TestUser
+		err := builder.WithContext(ctx).FindAll(&FilterRequest{}, &users)
+		assert.ErrorIs(t, err, ErrRangeExceeded)
+
+		err = builder.WithContext(ctx).FindAll(&FilterRequest{Filters: []Filter{{Field: "CreatedAt", Op: GE, Value: since(36 * time.Hour)}}}, &users)
+		assert.ErrorIs(t, err, ErrRangeExceeded)
+
+		wide := &FilterRequest{Filters: []Filter{{Field: "CreatedAt", Op: BETWEEN, Value: since(100*time.Hour) + "," + since(0)}}}
+		assert.ErrorIs(t, builder.WithContext(ctx).FindAll(wide, &users), ErrRangeExceeded)
+
+		req := &FilterRequest{FilterGroups: []FilterGroup{{Filters: []Filter{
+			{Field: "CreatedAt", Op: GE, Value: since(36 * time.Hour)},
+			{Field: "CreatedAt", Op: LE, Value: since(-time.Hour)},
+		}}}}
+		assert.NoError(t, builder.WithContext(ctx).FindAll(req, &users))
+		assert.Len(t, users, 2)
+
+		// 内部调用方不受限制
+		assert.NoError(t, builder.FindAll(&FilterRequest{}, &users))
+		assert.Len(t, users, 3)
+	})
+
+	t.Run("Lenient", func(t *testing.T) {
+		var warnings []error
+		builder := NewQueryBuilder[TestUser](db, WithLocation(time.UTC), WithLenient(func(err error) {
+			warnings = append(warnings, err)
+		}), WithDefaults(DefaultRule{
+			Name:  "bounded",
+			Range: &DateRange{Field: "CreatedAt", Max: 24 * time.Hour, Enforce: RangeRequire},
+		}))
+
+		// 列名不是有效的过滤字段，宽松模式下被跳过，不能满足时间范围要求
+		req := &FilterRequest{Filters: []Filter{{Field: "created_at", Op: BETWEEN, Value: since(12*time.Hour) + "," + since(0)}}}
+		var users []TestUser
+		assert.ErrorIs(t, builder.FindAll(req, &users), ErrRangeExceeded)
+		assert.Len(t, warnings, 1)
+
+		_, err := builder.Count(req)
+		assert.ErrorIs(t, err, ErrRangeExceeded)
+	})
+
+	t.Run("Clamp", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db, WithLocation(time.UTC), WithDefaults(DefaultRule{
+			Name:  "clamped",
+			When:  external,
+			Range: &DateRange{Field: "CreatedAt", Max: 30 * time.Hour, Enforce: RangeClamp},
+		}))
+		req := &FilterRequest{Filters: []Filter{{Field: "CreatedAt", Op: GE, Value: since(100 * time.Hour)}}}
+		result, err := builder.WithContext(ctx).FindPage(req)
+		assert.NoError(t, err)
+		assert.Len(t, result.Items, 2)
+		if assert.Len(t, result.Defaults, 1) {
+			assert.Equal(t, "clamped", result.Defaults[0].Rule)
+		}
+		assert.Len(t, req.Filters, 1)
+
+		var users []TestUser
+		req = &FilterRequest{Filters: []Filter{{Field: "CreatedAt", Op: LT, Value: since(12 * time.Hour)}}}
+		assert.NoError(t, builder.WithContext(ctx).FindAll(req, &users))
+		if assert.Len(t, users, 1) {
+			assert.Equal(t, "Jane Smith", users[0].Name)
+		}
+
+		req = &FilterRequest{Filters: []Filter{{Field: "CreatedAt", Op: GE, Value: since(12 * time.Hour)}}}
+		result, err = builder.WithContext(ctx).FindPage(req)
+		assert.NoError(t, err)
+		assert.Len(t, result.Items, 1)
+		assert.Empty(t, result.Defaults)
+	})
+}
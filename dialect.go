@@ -0,0 +1,64 @@
+package querybuild
+
+// 支持识别的数据库方言名称
+const (
+	dialectMySQL    = "mysql"
+	dialectPostgres = "postgres"
+	dialectSQLite   = "sqlite"
+)
+
+// unsupportedOperators 各方言不支持的过滤操作符，未列出的方言不做限制
+//
+// 数组操作符仅 PostgreSQL 支持；REGEXP 关键字 PostgreSQL 不支持，SQLite 默认未注册 regexp 函数。
+var unsupportedOperators = map[string]map[Operator]bool{
+	dialectMySQL: {
+		OVERLAP: true, ARRAY_CONTAINS: true, ARRAY_CONTAINED: true,
+	},
+	dialectPostgres: {
+		REGEXP: true, NOT_REGEXP: true,
+	},
+	dialectSQLite: {
+		REGEXP: true, NOT_REGEXP: true,
+		OVERLAP: true, ARRAY_CONTAINS: true, ARRAY_CONTAINED: true,
+	},
+}
+
+// WithDialectOperators 声明当前连接额外支持的操作符，如通过连接钩子注册了 regexp 函数的 SQLite
+func WithDialectOperators(ops ...Operator) Option {
+	return func(o *options) {
+		if o.dialectOps == nil {
+			o.dialectOps = make(map[Operator]bool, len(ops))
+		}
+		for _, op := range ops {
+			o.dialectOps[op] = true
+		}
+	}
+}
+
+// dialect 获取当前数据库连接的方言名称
+func (qb *QueryBuilder[T]) dialect() string {
+	if qb.db == nil || qb.db.Dialector == nil {
+		return ""
+	}
+	return qb.db.Dialector.Name()
+}
+
+// nullsLargest 判断方言排序时是否将 NULL 视为大于任何非空取值
+//
+// PostgreSQL 升序时 NULL 排在最后、降序时排在最前，MySQL 与 SQLite 相反。
+func nullsLargest(dialect string) bool {
+	return dialect == dialectPostgres
+}
+
+// supportsOperator 判断当前方言是否支持过滤操作符
+func (qb *QueryBuilder[T]) supportsOperator(op Operator) bool {
+	return qb.opts.dialectOps[op] || !unsupportedOperators[qb.dialect()][op]
+}
+
+// checkOperator 校验当前方言是否支持过滤操作符，避免执行时才由数据库报告语法错误
+func (qb *QueryBuilder[T]) checkOperator(op Operator) error {
+	if !qb.supportsOperator(op) {
+		return &UnsupportedOperatorError{Op: op, Dialect: qb.dialect()}
+	}
+	return nil
+}
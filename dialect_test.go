@@ -0,0 +1,59 @@
+package querybuild
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_DialectOperators(t *testing.T) {
+	t.Run("Regexp on SQLite", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](setupTestDB(t))
+		req := &FilterRequest{Filters: []Filter{{Field: "Name", Op: REGEXP, Value: "^J"}}}
+
+		err := builder.Build(req).Error
+		var unsupported *UnsupportedOperatorError
+		if assert.True(t, errors.As(err, &unsupported)) {
+			assert.Equal(t, REGEXP, unsupported.Op)
+			assert.Equal(t, "sqlite", unsupported.Dialect)
+		}
+		assert.ErrorContains(t, err, "operator REGEXP unsupported on dialect sqlite")
+		assert.ErrorContains(t, builder.Validate(req), "unsupported on dialect")
+
+		var users []TestUser
+		assert.Error(t, builder.FindAll(req, &users))
+	})
+
+	t.Run("Array operators on MySQL", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](setupDialectDB(t, "mysql"))
+		err := builder.Build(&FilterRequest{Filters: []Filter{{Field: "Status", Op: OVERLAP, Value: "{a}"}}}).Error
+		assert.ErrorContains(t, err, "operator OVERLAP unsupported on dialect mysql")
+
+		assert.NoError(t, builder.Build(&FilterRequest{Filters: []Filter{{Field: "Name", Op: REGEXP, Value: "^J"}}}).Error)
+	})
+
+	t.Run("Plan cache", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](setupTestDB(t), WithPlanCache(16))
+		req := &FilterRequest{Filters: []Filter{{Field: "Name", Op: NOT_REGEXP, Value: "^J"}}}
+		assert.ErrorContains(t, builder.Build(req).Error, "unsupported on dialect")
+		assert.ErrorContains(t, builder.Build(req).Error, "unsupported on dialect")
+	})
+
+	t.Run("Declared operators", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](setupDialectDB(t, "sqlite"), WithDialectOperators(REGEXP))
+		assert.NoError(t, builder.Build(&FilterRequest{Filters: []Filter{{Field: "Name", Op: REGEXP, Value: "^J"}}}).Error)
+		assert.Error(t, builder.Build(&FilterRequest{Filters: []Filter{{Field: "Name", Op: NOT_REGEXP, Value: "^J"}}}).Error)
+	})
+
+	t.Run("Metadata", func(t *testing.T) {
+		sqliteMeta := NewQueryBuilder[TestUser](setupTestDB(t)).Metadata()
+		mysqlMeta := NewQueryBuilder[TestUser](setupDialectDB(t, "mysql")).Metadata()
+		for i, field := range sqliteMeta.Fields {
+			if field.Name == "Name" {
+				assert.NotContains(t, field.Operators, REGEXP)
+				assert.Contains(t, mysqlMeta.Fields[i].Operators, REGEXP)
+			}
+		}
+	})
+}
@@ -0,0 +1,372 @@
+package querybuild
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FromElasticsearch 将 Elasticsearch 查询转换为过滤请求，便于将搜索接口迁移到数据库查询
+//
+// 支持 bool 查询的 must / filter / must_not / should 以及 match_all、term、terms、match、
+// match_phrase、prefix、exists、range 叶子查询，顶层可包含 sort、from 与 size。字段名可使用字段名或数据库字段名。
+// match 按包含匹配处理，不做分词；should 仅支持单个子句或同一字段上的 term / terms 子句，
+// 与 must / filter 同时出现且未指定 minimum_should_match 时按 Elasticsearch 的语义忽略。
+// 无法等价转换的查询返回错误。
+func (qb *QueryBuilder[T]) FromElasticsearch(data []byte) (*FilterRequest, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var body map[string]interface{}
+	if err := dec.Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid elasticsearch query: %w", err)
+	}
+
+	req := &FilterRequest{}
+	query := body
+	if q, ok := body["query"]; ok {
+		if query, ok = q.(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("invalid elasticsearch query: query must be an object")
+		}
+		if err := qb.esSorts(req, body["sort"]); err != nil {
+			return nil, err
+		}
+		if err := esPage(req, body["from"], body["size"]); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, clause := range query {
+		filters, err := qb.esClause(name, clause, false)
+		if err != nil {
+			return nil, err
+		}
+		req.Filters = append(req.Filters, filters...)
+	}
+	return req, nil
+}
+
+// esBool 转换 bool 查询
+func (qb *QueryBuilder[T]) esBool(body interface{}) ([]Filter, error) {
+	b, ok := body.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid elasticsearch query: bool must be an object")
+	}
+
+	var filters []Filter
+	for _, key := range []string{"must", "filter", "must_not"} {
+		for _, clause := range esClauses(b[key]) {
+			for name, inner := range clause {
+				converted, err := qb.esClause(name, inner, key == "must_not")
+				if err != nil {
+					return nil, err
+				}
+				filters = append(filters, converted...)
+			}
+		}
+	}
+
+	should := esClauses(b["should"])
+	if len(should) == 0 {
+		return filters, nil
+	}
+	minimum := 0
+	if v, ok := b["minimum_should_match"]; ok {
+		n, err := strconv.Atoi(stringValue(v))
+		if err != nil {
+			return nil, fmt.Errorf("unsupported elasticsearch minimum_should_match: %v", v)
+		}
+		minimum = n
+	} else if len(esClauses(b["must"])) == 0 && len(esClauses(b["filter"])) == 0 {
+		minimum = 1
+	}
+
+	switch {
+	case minimum <= 0:
+		// 仅影响相关度评分，不影响匹配结果
+		return filters, nil
+	case minimum >= len(should) || len(should) == 1:
+		for _, clause := range should {
+			for name, inner := range clause {
+				converted, err := qb.esClause(name, inner, false)
+				if err != nil {
+					return nil, err
+				}
+				filters = append(filters, converted...)
+			}
+		}
+		return filters, nil
+	case minimum == 1:
+		filter, err := qb.esShouldIn(should)
+		if err != nil {
+			return nil, err
+		}
+		return append(filters, filter), nil
+	}
+	return nil, fmt.Errorf("unsupported elasticsearch query: minimum_should_match %d of %d should clauses", minimum, len(should))
+}
+
+// esShouldIn 将同一字段上的 term / terms should 子句合并为 IN 条件
+func (qb *QueryBuilder[T]) esShouldIn(should []map[string]interface{}) (Filter, error) {
+	var field string
+	var values []string
+	for _, clause := range should {
+		for name, inner := range clause {
+			if name != "term" && name != "terms" {
+				return Filter{}, fmt.Errorf("unsupported elasticsearch query: should with %s", name)
+			}
+			filters, err := qb.esClause(name, inner, false)
+			if err != nil {
+				return Filter{}, err
+			}
+			if field != "" && filters[0].Field != field {
+				return Filter{}, fmt.Errorf("unsupported elasticsearch query: should across fields %s and %s", field, filters[0].Field)
+			}
+			field = filters[0].Field
+			values = append(values, filters[0].Value)
+		}
+	}
+	return Filter{Field: field, Op: IN, Value: strings.Join(values, ",")}, nil
+}
+
+// esClause 转换单个查询子句，not 为 true 时转换为否定条件
+func (qb *QueryBuilder[T]) esClause(name string, body interface{}, not bool) ([]Filter, error) {
+	if name == "match_all" {
+		if not {
+			return nil, fmt.Errorf("unsupported elasticsearch query: match_all inside must_not")
+		}
+		return nil, nil
+	}
+	if name == "bool" {
+		if not {
+			return nil, fmt.Errorf("unsupported elasticsearch query: bool inside must_not")
+		}
+		return qb.esBool(body)
+	}
+	if name == "exists" {
+		b, _ := body.(map[string]interface{})
+		field, ok := b["field"].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid elasticsearch query: exists requires a field")
+		}
+		op := NOT_NULL
+		if not {
+			op = IS_NULL
+		}
+		return []Filter{{Field: qb.esField(field), Op: op}}, nil
+	}
+
+	field, value, err := esFieldClause(name, body)
+	if err != nil {
+		return nil, err
+	}
+	field = qb.esField(field)
+
+	switch name {
+	case "term":
+		op := EQ
+		if not {
+			op = NE
+		}
+		return []Filter{{Field: field, Op: op, Value: stringValue(esInner(value, "value"))}}, nil
+	case "terms":
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid elasticsearch query: terms requires an array")
+		}
+		values := make([]string, 0, len(items))
+		for _, item := range items {
+			values = append(values, stringValue(item))
+		}
+		op := IN
+		if not {
+			op = NOT_IN
+		}
+		return []Filter{{Field: field, Op: op, Value: strings.Join(values, ",")}}, nil
+	case "match", "match_phrase":
+		op := CONTAINS
+		if not {
+			op = NOT_LIKE
+		}
+		return []Filter{{Field: field, Op: op, Value: stringValue(esInner(value, "query"))}}, nil
+	case "prefix":
+		if not {
+			return nil, fmt.Errorf("unsupported elasticsearch query: prefix inside must_not")
+		}
+		return []Filter{{Field: field, Op: STARTS_WITH, Value: stringValue(esInner(value, "value"))}}, nil
+	case "range":
+		return esRange(field, value, not)
+	}
+	return nil, fmt.Errorf("unsupported elasticsearch query: %s", name)
+}
+
+// esRangeOps range 查询的比较方式及其否定
+var esRangeOps = map[string][2]Operator{
+	"gt":  {GT, LE},
+	"gte": {GE, LT},
+	"lt":  {LT, GE},
+	"lte": {LE, GT},
+}
+
+// esRange 转换 range 查询，同时包含 gte 与 lte 时使用 BETWEEN 或 NOT_BETWEEN，其他边界组合否定时仅支持单个边界
+func esRange(field string, value interface{}, not bool) ([]Filter, error) {
+	bounds, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid elasticsearch query: range requires an object")
+	}
+
+	keys := make([]string, 0, len(bounds))
+	for key := range bounds {
+		if _, ok := esRangeOps[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("invalid elasticsearch query: range on %s has no bounds", field)
+	}
+	if not {
+		if len(keys) == 2 && keys[0] == "gte" && keys[1] == "lte" {
+			return []Filter{{Field: field, Op: NOT_BETWEEN, Value: stringValue(bounds["gte"]) + "," + stringValue(bounds["lte"])}}, nil
+		}
+		if len(keys) > 1 {
+			return nil, fmt.Errorf("unsupported elasticsearch query: range with multiple bounds inside must_not")
+		}
+		return []Filter{{Field: field, Op: esRangeOps[keys[0]][1], Value: stringValue(bounds[keys[0]])}}, nil
+	}
+	if len(keys) == 2 && keys[0] == "gte" && keys[1] == "lte" {
+		return []Filter{{Field: field, Op: BETWEEN, Value: stringValue(bounds["gte"]) + "," + stringValue(bounds["lte"])}}, nil
+	}
+
+	filters := make([]Filter, 0, len(keys))
+	for _, key := range keys {
+		filters = append(filters, Filter{Field: field, Op: esRangeOps[key][0], Value: stringValue(bounds[key])})
+	}
+	return filters, nil
+}
+
+// esSorts 转换 sort，支持 "field"、{"field": "desc"} 与 {"field": {"order": "desc"}}，忽略 _score
+func (qb *QueryBuilder[T]) esSorts(req *FilterRequest, body interface{}) error {
+	if body == nil {
+		return nil
+	}
+	items, ok := body.([]interface{})
+	if !ok {
+		items = []interface{}{body}
+	}
+	for _, item := range items {
+		switch item := item.(type) {
+		case string:
+			if item != "_score" {
+				req.Sorts = append(req.Sorts, Sort{Field: qb.esField(item)})
+			}
+		case map[string]interface{}:
+			for field, order := range item {
+				if field == "_score" {
+					continue
+				}
+				desc := strings.EqualFold(stringValue(esInner(order, "order")), "desc")
+				req.Sorts = append(req.Sorts, Sort{Field: qb.esField(field), Desc: desc})
+			}
+		default:
+			return fmt.Errorf("invalid elasticsearch sort: %v", item)
+		}
+	}
+	return nil
+}
+
+// esPage 将 from / size 转换为分页，from 需为 size 的整数倍
+func esPage(req *FilterRequest, from, size interface{}) error {
+	if size == nil {
+		if from != nil {
+			return fmt.Errorf("unsupported elasticsearch query: from without size")
+		}
+		return nil
+	}
+	pageSize, err := strconv.Atoi(stringValue(size))
+	if err != nil || pageSize <= 0 {
+		return fmt.Errorf("invalid elasticsearch size: %v", size)
+	}
+	offset := 0
+	if from != nil {
+		if offset, err = strconv.Atoi(stringValue(from)); err != nil || offset < 0 {
+			return fmt.Errorf("invalid elasticsearch from: %v", from)
+		}
+	}
+	if offset%pageSize != 0 {
+		return fmt.Errorf("unsupported elasticsearch query: from %d is not a multiple of size %d", offset, pageSize)
+	}
+	req.Page = &Pagination{Page: offset/pageSize + 1, PageSize: pageSize}
+	return nil
+}
+
+// esField 将 Elasticsearch 字段名转换为模型字段名，无法识别时原样返回
+func (qb *QueryBuilder[T]) esField(name string) string {
+	if field := qb.schema.LookUpField(name); field != nil && field.DBName != "" {
+		return field.Name
+	}
+	return name
+}
+
+// esClauses 将单个子句或子句数组统一为子句列表
+func esClauses(body interface{}) []map[string]interface{} {
+	switch body := body.(type) {
+	case map[string]interface{}:
+		return []map[string]interface{}{body}
+	case []interface{}:
+		clauses := make([]map[string]interface{}, 0, len(body))
+		for _, item := range body {
+			if clause, ok := item.(map[string]interface{}); ok {
+				clauses = append(clauses, clause)
+			}
+		}
+		return clauses
+	}
+	return nil
+}
+
+// esFieldClause 取出以字段名为键的叶子查询中的字段与取值
+func esFieldClause(name string, body interface{}) (string, interface{}, error) {
+	b, ok := body.(map[string]interface{})
+	if !ok {
+		return "", nil, fmt.Errorf("invalid elasticsearch query: %s must be an object", name)
+	}
+	var field string
+	var value interface{}
+	for key, v := range b {
+		if key == "boost" {
+			continue
+		}
+		if field != "" {
+			return "", nil, fmt.Errorf("invalid elasticsearch query: %s on multiple fields", name)
+		}
+		field, value = key, v
+	}
+	if field == "" {
+		return "", nil, fmt.Errorf("invalid elasticsearch query: %s requires a field", name)
+	}
+	return field, value, nil
+}
+
+// esInner 取出完整写法中的取值，如 {"value": "x"} 或 {"query": "x"}，简写时原样返回
+func esInner(value interface{}, key string) interface{} {
+	if m, ok := value.(map[string]interface{}); ok {
+		return m[key]
+	}
+	return value
+}
+
+// stringValue 将 JSON 解码得到的取值转换为过滤请求中的字符串
+func stringValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(value)
+}
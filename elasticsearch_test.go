@@ -0,0 +1,72 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_FromElasticsearch(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+
+	t.Run("Bool query", func(t *testing.T) {
+		req, err := builder.FromElasticsearch([]byte(`{
+			"query": {"bool": {
+				"must": [{"match": {"name": "o"}}],
+				"filter": {"range": {"age": {"gte": 20, "lte": 40}}},
+				"must_not": [{"term": {"status": {"value": "inactive"}}}, {"range": {"age": {"gt": 30}}}]
+			}},
+			"sort": [{"age": {"order": "desc"}}, "_score"],
+			"from": 0,
+			"size": 10
+		}`))
+		assert.NoError(t, err)
+		assert.Equal(t, []Filter{
+			{Field: "Name", Op: CONTAINS, Value: "o"},
+			{Field: "Age", Op: BETWEEN, Value: "20,40"},
+			{Field: "Status", Op: NE, Value: "inactive"},
+			{Field: "Age", Op: LE, Value: "30"},
+		}, req.Filters)
+		assert.Equal(t, []Sort{{Field: "Age", Desc: true}}, req.Sorts)
+		assert.Equal(t, &Pagination{Page: 1, PageSize: 10}, req.Page)
+
+		var users []TestUser
+		assert.NoError(t, builder.FindAll(req, &users))
+		assert.Len(t, users, 1)
+		assert.Equal(t, "John Doe", users[0].Name)
+	})
+
+	t.Run("Should terms", func(t *testing.T) {
+		req, err := builder.FromElasticsearch([]byte(`{"bool": {"should": [
+			{"term": {"name": "John Doe"}},
+			{"terms": {"name": ["Bob Johnson"]}}
+		]}}`))
+		assert.NoError(t, err)
+		assert.Equal(t, []Filter{{Field: "Name", Op: IN, Value: "John Doe,Bob Johnson"}}, req.Filters)
+	})
+
+	t.Run("Exists", func(t *testing.T) {
+		req, err := builder.FromElasticsearch([]byte(`{"bool": {"must_not": {"exists": {"field": "email"}}}}`))
+		assert.NoError(t, err)
+		assert.Equal(t, []Filter{{Field: "Email", Op: IS_NULL}}, req.Filters)
+	})
+
+	t.Run("Match all", func(t *testing.T) {
+		req, err := builder.FromElasticsearch([]byte(`{"query": {"match_all": {}}}`))
+		assert.NoError(t, err)
+		assert.Empty(t, req.Filters)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		for _, query := range []string{
+			`{"bool": {"should": [{"term": {"name": "a"}}, {"term": {"age": 1}}]}}`,
+			`{"bool": {"must_not": {"bool": {"must": {"term": {"name": "a"}}}}}}`,
+			`{"fuzzy": {"name": "a"}}`,
+			`{"query": {"match_all": {}}, "from": 5, "size": 10}`,
+		} {
+			_, err := builder.FromElasticsearch([]byte(query))
+			assert.Error(t, err, query)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package querybuild
+
+import "strings"
+
+// skipEmpty 判断请求是否跳过取值为空的过滤条件，请求的 SkipEmpty 优先于 WithSkipEmpty 配置
+func (qb *QueryBuilder[T]) skipEmpty(req *FilterRequest) bool {
+	if req.SkipEmpty != nil {
+		return *req.SkipEmpty
+	}
+	return qb.opts.skipEmpty
+}
+
+// isEmptyValue 判断需要取值的过滤条件是否未填写取值，列表取值的各项均为空时视为未填写
+func isEmptyValue(filter Filter) bool {
+	switch filter.Op {
+	case IS_NULL, NOT_NULL:
+		return false
+	}
+	return strings.Trim(filter.Value, ", \t") == ""
+}
+
+// skipEmptyFilters 返回去除未填写取值的过滤条件后的请求副本，无需跳过时返回原请求
+func (qb *QueryBuilder[T]) skipEmptyFilters(req *FilterRequest) *FilterRequest {
+	if !qb.skipEmpty(req) {
+		return req
+	}
+	filters := nonEmptyFilters(req.Filters)
+	if len(filters) == len(req.Filters) {
+		return req
+	}
+	skipped := *req
+	skipped.Filters = filters
+	return &skipped
+}
+
+// nonEmptyFilters 返回去除未填写取值的过滤条件，没有需要去除的条件时返回原切片
+func nonEmptyFilters(filters []Filter) []Filter {
+	for i, filter := range filters {
+		if !isEmptyValue(filter) {
+			continue
+		}
+		out := append([]Filter(nil), filters[:i]...)
+		for _, filter := range filters[i+1:] {
+			if !isEmptyValue(filter) {
+				out = append(out, filter)
+			}
+		}
+		return out
+	}
+	return filters
+}
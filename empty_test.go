@@ -0,0 +1,51 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_SkipEmpty(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db, WithSkipEmpty(true), WithValueValidation())
+
+	form := func() *FilterRequest {
+		return &FilterRequest{Filters: []Filter{
+			{Field: "Name", Op: LIKE, Value: ""},
+			{Field: "Age", Op: GT, Value: " "},
+			{Field: "Status", Op: IN, Value: ","},
+			{Field: "Status", Op: EQ, Value: "active"},
+			{Field: "Email", Op: NOT_NULL},
+		}}
+	}
+
+	t.Run("Skipped", func(t *testing.T) {
+		req := form()
+		var users []TestUser
+		assert.NoError(t, builder.FindAll(req, &users))
+		assert.Len(t, users, 2)
+		assert.Len(t, req.Filters, 5, "request is not modified")
+		assert.NoError(t, builder.Validate(req))
+
+		count, err := builder.Count(req)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("Request opt-out", func(t *testing.T) {
+		keep := false
+		req := &FilterRequest{Filters: []Filter{{Field: "Email", Op: EQ, Value: ""}}, SkipEmpty: &keep}
+		var users []TestUser
+		assert.NoError(t, builder.FindAll(req, &users))
+		assert.Empty(t, users)
+	})
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		var users []TestUser
+		assert.NoError(t, NewQueryBuilder[TestUser](db).FindAll(&FilterRequest{
+			Filters: []Filter{{Field: "Email", Op: EQ, Value: ""}},
+		}, &users))
+		assert.Empty(t, users)
+	})
+}
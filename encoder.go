@@ -0,0 +1,131 @@
+package querybuild
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ValueEncoder 将请求中的过滤取值转换为绑定到查询的取值，如对邮箱取哈希、规范化电话号码或将金额转换为最小货币单位
+type ValueEncoder func(raw string) (interface{}, error)
+
+// ValueEncoderRegistry 字段取值编码器注册表
+type ValueEncoderRegistry struct {
+	encoders map[string]ValueEncoder
+	mu       sync.RWMutex
+}
+
+// NewValueEncoderRegistry 创建新的取值编码器注册表
+func NewValueEncoderRegistry() *ValueEncoderRegistry {
+	return &ValueEncoderRegistry{
+		encoders: make(map[string]ValueEncoder),
+	}
+}
+
+// Register 注册字段的取值编码器
+func (r *ValueEncoderRegistry) Register(field string, encode ValueEncoder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.encoders[field] = encode
+}
+
+// Get 获取字段的取值编码器
+func (r *ValueEncoderRegistry) Get(field string) (ValueEncoder, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	encode, ok := r.encoders[field]
+	return encode, ok
+}
+
+// Clone 复制取值编码器注册表
+func (r *ValueEncoderRegistry) Clone() *ValueEncoderRegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clone := NewValueEncoderRegistry()
+	for field, encode := range r.encoders {
+		clone.encoders[field] = encode
+	}
+	return clone
+}
+
+// RegisterValueEncoder 注册模型字段的取值编码器，字段的过滤取值经编码器转换后绑定，重复注册时覆盖
+//
+// 编码器作用于比较、区间与 IN 操作符的每个取值以及 FirstOrCreate、Upsert 的查找键，取值在枚举标签转换之后编码，
+// 编码失败时返回 InvalidValueError；编码字段不支持模糊匹配、数组与网段等其他取值操作符，忽略大小写的条件按编码后的取值精确比较。
+// 以函数包装的条件按原样绑定取值。加密字段不能注册编码器。
+func (qb *QueryBuilder[T]) RegisterValueEncoder(field string, encode ValueEncoder) error {
+	if encode == nil {
+		return fmt.Errorf("value encoder is nil: %s", field)
+	}
+	f := qb.schema.LookUpField(field)
+	if f == nil || f.DBName == "" {
+		return fmt.Errorf("invalid field name: %s", field)
+	}
+	if qb.encryptedField(f.Name) != nil {
+		return fmt.Errorf("value encoder not supported on encrypted field: %s", field)
+	}
+	qb.encoders.Register(f.Name, encode)
+	return nil
+}
+
+// valueEncoder 返回过滤条件字段的取值编码器，未注册时返回 nil
+func (qb *QueryBuilder[T]) valueEncoder(name string) ValueEncoder {
+	field := qb.schema.LookUpField(name)
+	if field == nil || field.DBName == "" {
+		return nil
+	}
+	encode, _ := qb.encoders.Get(field.Name)
+	return encode
+}
+
+// encodeValue 以编码器转换单个取值
+func encodeValue(encode ValueEncoder, field, value string) (interface{}, error) {
+	v, err := encode(value)
+	if err != nil {
+		return nil, &InvalidValueError{Field: field, Value: value, Reason: err.Error()}
+	}
+	return v, nil
+}
+
+// encodedCondition 构建注册了取值编码器的字段的过滤条件，每个取值编码后绑定
+func encodedCondition(field string, filter Filter, encode ValueEncoder) (string, []interface{}, error) {
+	filter.NoCase = false
+	switch filter.Op {
+	case IS_NULL, NOT_NULL:
+		cond, args := buildCondition(field, filter)
+		return cond, args, nil
+	case IN, NOT_IN:
+		items := strings.Split(filter.Value, ",")
+		values := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			v, err := encodeValue(encode, filter.Field, item)
+			if err != nil {
+				return "", nil, err
+			}
+			values = append(values, v)
+		}
+		return conditionSQL(field, filter), []interface{}{values}, nil
+	case BETWEEN, NOT_BETWEEN:
+		lower, upper, ok := strings.Cut(filter.Value, ",")
+		if !ok || strings.Contains(upper, ",") {
+			return "", nil, &InvalidValueError{Field: filter.Field, Value: filter.Value, Reason: "not a range"}
+		}
+		from, err := encodeValue(encode, filter.Field, lower)
+		if err != nil {
+			return "", nil, err
+		}
+		to, err := encodeValue(encode, filter.Field, upper)
+		if err != nil {
+			return "", nil, err
+		}
+		return conditionSQL(field, filter), []interface{}{from, to}, nil
+	case EQ, NE, GT, GE, LT, LE, GT_OR_NULL, GE_OR_NULL, LT_OR_NULL, LE_OR_NULL:
+		v, err := encodeValue(encode, filter.Field, filter.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		return conditionSQL(field, filter), []interface{}{v}, nil
+	}
+	return "", nil, fmt.Errorf("operator %s unsupported on encoded field: %s", filter.Op, filter.Field)
+}
@@ -0,0 +1,98 @@
+package querybuild
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func normalizeEmail(raw string) (interface{}, error) {
+	email := strings.ToLower(strings.TrimSpace(raw))
+	if !strings.Contains(email, "@") {
+		return nil, errors.New("not an email")
+	}
+	return email, nil
+}
+
+func minorUnits(raw string) (interface{}, error) {
+	amount, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil {
+		return nil, errors.New("not an amount")
+	}
+	return int64(math.Round(amount * 100)), nil
+}
+
+func TestQueryBuilder_ValueEncoders(t *testing.T) {
+	db := setupTestDB(t)
+	users := NewQueryBuilder[TestUser](db, WithPlanCache(16))
+	assert.NoError(t, users.RegisterValueEncoder("Email", normalizeEmail))
+	orders := NewQueryBuilder[TestOrder](db)
+	assert.NoError(t, orders.RegisterValueEncoder("amount", minorUnits))
+
+	t.Run("Comparison and IN", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			var found []TestUser
+			assert.NoError(t, users.FindAll(&FilterRequest{Filters: []Filter{{Field: "Email", Op: EQ, Value: " John@Example.COM "}}}, &found))
+			if assert.Len(t, found, 1) {
+				assert.Equal(t, "John Doe", found[0].Name)
+			}
+		}
+
+		count, err := users.Count(&FilterRequest{Filters: []Filter{{Field: "Email", Op: IN, Value: "JANE@example.com, bob@EXAMPLE.com"}}})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		var found []TestOrder
+		assert.NoError(t, orders.FindAll(&FilterRequest{
+			Filters: []Filter{{Field: "Amount", Op: BETWEEN, Value: "0.5,0.9"}},
+			Sorts:   []Sort{{Field: "Amount"}},
+		}, &found))
+		if assert.Len(t, found, 2) {
+			assert.Equal(t, float64(50), found[0].Amount)
+			assert.Equal(t, float64(80), found[1].Amount)
+		}
+	})
+
+	t.Run("Lookup keys", func(t *testing.T) {
+		item, created, err := users.FirstOrCreate(&FilterRequest{Filters: []Filter{{Field: "Email", Op: EQ, Value: "BOB@example.com"}}}, TestUser{})
+		assert.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, "Bob Johnson", item.Name)
+	})
+
+	t.Run("Invalid value", func(t *testing.T) {
+		req := &FilterRequest{Filters: []Filter{{Field: "Email", Op: NE, Value: "nobody"}}}
+		var found []TestUser
+		var invalid *InvalidValueError
+		if assert.ErrorAs(t, users.FindAll(req, &found), &invalid) {
+			assert.Equal(t, "not an email", invalid.Reason)
+		}
+		assert.ErrorAs(t, users.Validate(req), &invalid)
+	})
+
+	t.Run("Unsupported operator", func(t *testing.T) {
+		var found []TestUser
+		err := users.FindAll(&FilterRequest{Filters: []Filter{{Field: "Email", Op: CONTAINS, Value: "example"}}}, &found)
+		assert.ErrorContains(t, err, "operator CONTAINS unsupported on encoded field: Email")
+	})
+
+	t.Run("Invalid field", func(t *testing.T) {
+		assert.Error(t, users.RegisterValueEncoder("Phone", normalizeEmail))
+		assert.Error(t, users.RegisterValueEncoder("Email", nil))
+	})
+
+	t.Run("Clone", func(t *testing.T) {
+		clone := users.Clone()
+		assert.NoError(t, clone.RegisterValueEncoder("Status", func(raw string) (interface{}, error) {
+			return strings.ToLower(raw), nil
+		}))
+		assert.Nil(t, users.valueEncoder("Status"))
+		assert.NotNil(t, clone.valueEncoder("Email"))
+	})
+}
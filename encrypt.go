@@ -0,0 +1,256 @@
+package querybuild
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// KeyProvider 加密字段的密钥提供方，如对接密钥管理服务
+type KeyProvider interface {
+	// FieldKey 返回字段使用的 32 字节密钥，可按字段或上下文中的调用方（如租户）返回不同的密钥
+	FieldKey(ctx context.Context, field string) ([]byte, error)
+}
+
+// WithEncryptedFields 将字符串字段标记为加密字段，密钥由 keys 提供
+//
+// 加密字段以确定性加密存储，相同明文总是得到相同密文：EQ、NE、IN、NOT_IN 与空值过滤条件的取值加密后比较，
+// 其他操作符、函数与忽略大小写返回错误；FindAll 等查询结果与 FindMaps、Pluck、DistinctValues 的取值自动解密。
+// 写入时需使用 EncryptValue 以相同的方式加密取值。
+func WithEncryptedFields(keys KeyProvider, fields ...string) Option {
+	return func(o *options) {
+		o.encryptKeys = keys
+		if o.encrypted == nil {
+			o.encrypted = make(map[string]bool, len(fields))
+		}
+		for _, field := range fields {
+			o.encrypted[field] = true
+		}
+	}
+}
+
+// EncryptValue 以加密字段的密钥确定性加密取值，用于写入加密字段
+func (qb *QueryBuilder[T]) EncryptValue(ctx context.Context, field, value string) (string, error) {
+	f, key, err := qb.fieldKey(ctx, field)
+	if err != nil {
+		return "", err
+	}
+	return sealDeterministic(key, f.Name, value)
+}
+
+// DecryptValue 以加密字段的密钥解密取值
+func (qb *QueryBuilder[T]) DecryptValue(ctx context.Context, field, value string) (string, error) {
+	f, key, err := qb.fieldKey(ctx, field)
+	if err != nil {
+		return "", err
+	}
+	return openDeterministic(key, f.Name, value)
+}
+
+// encryptedField 返回字段对应的加密字段，非加密字段返回 nil
+func (qb *QueryBuilder[T]) encryptedField(name string) *schema.Field {
+	if len(qb.opts.encrypted) == 0 {
+		return nil
+	}
+	field := qb.schema.LookUpField(name)
+	if field == nil || field.DBName == "" || !qb.opts.encrypted[field.Name] {
+		return nil
+	}
+	return field
+}
+
+// fieldKey 返回加密字段及其密钥
+func (qb *QueryBuilder[T]) fieldKey(ctx context.Context, name string) (*schema.Field, []byte, error) {
+	field := qb.encryptedField(name)
+	if field == nil {
+		return nil, nil, fmt.Errorf("field is not encrypted: %s", name)
+	}
+	if field.DataType != schema.String {
+		return nil, nil, fmt.Errorf("encrypted field must be a string: %s", name)
+	}
+	key, err := qb.opts.encryptKeys.FieldKey(ctx, field.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return field, key, nil
+}
+
+// encryptedCondition 构建加密字段的过滤条件，取值加密后以密文比较
+func (qb *QueryBuilder[T]) encryptedCondition(query *gorm.DB, field string, filter Filter) (string, []interface{}, error) {
+	if filter.Fn != "" || filter.NoCase {
+		return "", nil, fmt.Errorf("functions and case-insensitive matching are not supported on encrypted field: %s", filter.Field)
+	}
+	ctx := query.Statement.Context
+	switch filter.Op {
+	case IS_NULL, NOT_NULL:
+	case EQ, NE, IN, NOT_IN:
+		values := []string{filter.Value}
+		if filter.Op == IN || filter.Op == NOT_IN {
+			values = strings.Split(filter.Value, ",")
+		}
+		for i, value := range values {
+			encrypted, err := qb.EncryptValue(ctx, filter.Field, value)
+			if err != nil {
+				return "", nil, err
+			}
+			values[i] = encrypted
+		}
+		filter.Value = strings.Join(values, ",")
+	default:
+		return "", nil, fmt.Errorf("operator %s is not supported on encrypted field: %s", filter.Op, filter.Field)
+	}
+	cond, args := buildCondition(field, filter)
+	return cond, args, nil
+}
+
+// decryptItems 解密查询结果中的加密字段，空值不处理
+func (qb *QueryBuilder[T]) decryptItems(items []T) error {
+	if len(qb.opts.encrypted) == 0 || len(items) == 0 {
+		return nil
+	}
+	ctx := qb.db.Statement.Context
+	for name := range qb.opts.encrypted {
+		if qb.sqlMasked(ctx, name) {
+			continue
+		}
+		field, key, err := qb.fieldKey(ctx, name)
+		if err != nil {
+			return err
+		}
+		for i := range items {
+			item := reflect.ValueOf(&items[i]).Elem()
+			value, _ := field.ValueOf(ctx, item)
+			s, ok := value.(string)
+			if !ok || s == "" {
+				continue
+			}
+			plain, err := openDeterministic(key, field.Name, s)
+			if err != nil {
+				return err
+			}
+			if err := field.Set(ctx, item, plain); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// decryptValue 解密单个字段的取值，非加密字段与空值原样返回
+func (qb *QueryBuilder[T]) decryptValue(ctx context.Context, field string, value interface{}) (interface{}, error) {
+	if qb.encryptedField(field) == nil || textValue(value) == "" {
+		return value, nil
+	}
+	return qb.DecryptValue(ctx, field, textValue(value))
+}
+
+// decryptValues 解密单个字段的取值列表，非加密字段与以SQL脱敏表达式选择的字段不处理
+func (qb *QueryBuilder[T]) decryptValues(query *gorm.DB, field string, values []interface{}) error {
+	ctx := query.Statement.Context
+	f := qb.encryptedField(field)
+	if f == nil || qb.sqlMasked(ctx, f.Name) {
+		return nil
+	}
+	_, key, err := qb.fieldKey(ctx, field)
+	if err != nil {
+		return err
+	}
+	for i, value := range values {
+		if text := textValue(value); text != "" {
+			if values[i], err = openDeterministic(key, f.Name, text); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// textValue 返回驱动以文本返回的取值，其他类型返回空字符串
+func textValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return ""
+}
+
+// sqlMasked 判断字段在当前查询中是否以SQL脱敏表达式选择，这类取值已被替换，无需解密
+func (qb *QueryBuilder[T]) sqlMasked(ctx context.Context, field string) bool {
+	rule, ok := qb.opts.masks[field]
+	return ok && rule.sql != "" && qb.masked(ctx)
+}
+
+// deterministicKeys 由字段密钥派生加密密钥与生成随机数的密钥
+func deterministicKeys(key []byte) (encKey, nonceKey []byte, err error) {
+	if len(key) != 32 {
+		return nil, nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+	derive := func(label string) []byte {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(label))
+		return mac.Sum(nil)
+	}
+	return derive("querybuild:encrypt"), derive("querybuild:nonce"), nil
+}
+
+// sealDeterministic 以 AES-256-GCM 确定性加密取值，随机数由明文的 HMAC 派生，字段名作为附加数据
+//
+// 结果为随机数与密文拼接后的 Base64 文本。
+func sealDeterministic(key []byte, field, plaintext string) (string, error) {
+	encKey, nonceKey, err := deterministicKeys(key)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newGCM(encKey)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, nonceKey)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(plaintext))
+	nonce := mac.Sum(nil)[:aead.NonceSize()]
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(field))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// openDeterministic 解密 sealDeterministic 生成的密文
+func openDeterministic(key []byte, field, ciphertext string) (string, error) {
+	encKey, _, err := deterministicKeys(key)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newGCM(encKey)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("invalid ciphertext for field %s", field)
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(field))
+	if err != nil {
+		return "", fmt.Errorf("decrypt field %s: %w", field, err)
+	}
+	return string(plain), nil
+}
+
+// newGCM 创建 AES-GCM 加密器
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,105 @@
+package querybuild
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// staticKeys 所有字段使用同一密钥的测试密钥提供方
+type staticKeys []byte
+
+func (k staticKeys) FieldKey(ctx context.Context, field string) ([]byte, error) {
+	return k, nil
+}
+
+func TestQueryBuilder_EncryptedFields(t *testing.T) {
+	db := setupTestDB(t)
+	keys := staticKeys(bytes.Repeat([]byte{7}, 32))
+	builder := NewQueryBuilder[TestUser](db, WithEncryptedFields(keys, "Email"))
+	ctx := context.Background()
+
+	// 以加密形式存储测试数据中的邮箱
+	var users []TestUser
+	assert.NoError(t, db.Find(&users).Error)
+	for _, user := range users {
+		encrypted, err := builder.EncryptValue(ctx, "Email", user.Email)
+		assert.NoError(t, err)
+		assert.NotEqual(t, user.Email, encrypted)
+		assert.NoError(t, db.Model(&user).Update("email", encrypted).Error)
+	}
+
+	t.Run("Deterministic", func(t *testing.T) {
+		a, err := builder.EncryptValue(ctx, "Email", "john@example.com")
+		assert.NoError(t, err)
+		b, err := builder.EncryptValue(ctx, "Email", "john@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, a, b)
+
+		plain, err := builder.DecryptValue(ctx, "Email", a)
+		assert.NoError(t, err)
+		assert.Equal(t, "john@example.com", plain)
+
+		_, err = builder.EncryptValue(ctx, "Name", "John Doe")
+		assert.ErrorContains(t, err, "not encrypted")
+	})
+
+	t.Run("Filter and decrypt", func(t *testing.T) {
+		var found []TestUser
+		assert.NoError(t, builder.FindAll(&FilterRequest{
+			Filters: []Filter{{Field: "Email", Op: EQ, Value: "john@example.com"}},
+		}, &found))
+		if assert.Len(t, found, 1) {
+			assert.Equal(t, "John Doe", found[0].Name)
+			assert.Equal(t, "john@example.com", found[0].Email)
+		}
+
+		assert.NoError(t, builder.FindAll(&FilterRequest{
+			Filters: []Filter{{Field: "Email", Op: IN, Value: "john@example.com,bob@example.com"}},
+			Sorts:   []Sort{{Field: "Age"}},
+		}, &found))
+		if assert.Len(t, found, 2) {
+			assert.Equal(t, "bob@example.com", found[1].Email)
+		}
+	})
+
+	t.Run("Maps and values", func(t *testing.T) {
+		rows, err := builder.FindMaps(&FilterRequest{Filters: []Filter{{Field: "Name", Op: EQ, Value: "Jane Smith"}}})
+		assert.NoError(t, err)
+		if assert.Len(t, rows, 1) {
+			assert.Equal(t, "jane@example.com", rows[0]["email"])
+		}
+
+		values, err := builder.Pluck(&FilterRequest{Filters: []Filter{{Field: "Name", Op: EQ, Value: "Jane Smith"}}}, "Email")
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{"jane@example.com"}, values)
+
+		facets, err := builder.Facets(&FilterRequest{Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}}}, "Email")
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []FacetBucket{
+			{Value: "john@example.com", Count: 1},
+			{Value: "bob@example.com", Count: 1},
+		}, facets["Email"])
+	})
+
+	t.Run("Unsupported operators", func(t *testing.T) {
+		var found []TestUser
+		err := builder.FindAll(&FilterRequest{
+			Filters: []Filter{{Field: "Email", Op: CONTAINS, Value: "example"}},
+		}, &found)
+		assert.ErrorContains(t, err, "not supported on encrypted field")
+
+		err = builder.Validate(&FilterRequest{
+			Filters: []Filter{{Field: "Email", Op: EQ, Value: "john@example.com", NoCase: true}},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid key", func(t *testing.T) {
+		invalid := NewQueryBuilder[TestUser](db, WithEncryptedFields(staticKeys("short"), "Email"))
+		_, err := invalid.EncryptValue(ctx, "Email", "john@example.com")
+		assert.ErrorContains(t, err, "32 bytes")
+	})
+}
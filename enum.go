@@ -0,0 +1,214 @@
+package querybuild
+
+import (
+	"sort"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// enumValues 返回字段允许的枚举取值，未声明时返回 nil
+//
+// 取值依次来自 WithEnum 配置、字段的 enum 标签、enum('a','b') 形式的列类型与 WithEnumLabels 声明的标签对应的取值。
+func (qb *QueryBuilder[T]) enumValues(field *schema.Field) map[string]bool {
+	if values, ok := qb.opts.enums[field.Name]; ok {
+		return values
+	}
+	if tag, ok := field.Tag.Lookup("enum"); ok {
+		values := make(map[string]bool)
+		for _, item := range strings.Split(tag, ",") {
+			value, _, _ := strings.Cut(item, ":")
+			values[strings.TrimSpace(value)] = true
+		}
+		return values
+	}
+	if items := (FieldInfo{DataType: field.DataType}).enumValues(); items != nil {
+		values := make(map[string]bool, len(items))
+		for _, item := range items {
+			values[item] = true
+		}
+		return values
+	}
+	if labels, ok := qb.opts.enumLabels[field.Name]; ok {
+		values := make(map[string]bool, len(labels))
+		for _, value := range labels {
+			values[value] = true
+		}
+		return values
+	}
+	return nil
+}
+
+// enumLabels 返回字段的枚举标签到枚举取值的映射，来自 WithEnumLabels 配置或 enum 标签中的 取值:标签 写法
+func (qb *QueryBuilder[T]) enumLabels(field *schema.Field) map[string]string {
+	if labels, ok := qb.opts.enumLabels[field.Name]; ok {
+		return labels
+	}
+	tag, ok := field.Tag.Lookup("enum")
+	if !ok || !strings.Contains(tag, ":") {
+		return nil
+	}
+	labels := make(map[string]string)
+	for _, item := range strings.Split(tag, ",") {
+		if value, label, ok := strings.Cut(item, ":"); ok {
+			labels[strings.TrimSpace(label)] = strings.TrimSpace(value)
+		}
+	}
+	return labels
+}
+
+// enumCode 将枚举标签转换为对应的枚举取值，非标签的取值原样返回
+func enumCode(labels map[string]string, value string) string {
+	if code, ok := labels[value]; ok {
+		return code
+	}
+	return value
+}
+
+// translateEnums 将等值与 IN 过滤条件中的枚举标签转换为枚举取值，如 "已激活" 转换为 "active"
+//
+// 转换包括嵌套的过滤条件组与组合键过滤条件。存在需要转换的取值时返回请求的副本，不修改原请求。
+func (qb *QueryBuilder[T]) translateEnums(req *FilterRequest) *FilterRequest {
+	filters := qb.translateFilters(req.Filters)
+	groups := qb.translateGroups(req.FilterGroups)
+	tuples := qb.translateTuples(req.TupleFilters)
+	if filters == nil && groups == nil && tuples == nil {
+		return req
+	}
+
+	translated := *req
+	if filters != nil {
+		translated.Filters = filters
+	}
+	if groups != nil {
+		translated.FilterGroups = groups
+	}
+	if tuples != nil {
+		translated.TupleFilters = tuples
+	}
+	return &translated
+}
+
+// translateFilters 转换过滤条件中的枚举标签，存在需要转换的取值时返回新的切片，否则返回 nil
+func (qb *QueryBuilder[T]) translateFilters(filters []Filter) []Filter {
+	var out []Filter
+	for i, filter := range filters {
+		switch filter.Op {
+		case EQ, NE, IN, NOT_IN:
+		default:
+			continue
+		}
+		field := qb.schema.LookUpField(filter.Field)
+		if field == nil || filter.Fn != "" {
+			continue
+		}
+		labels := qb.enumLabels(field)
+		if len(labels) == 0 {
+			continue
+		}
+
+		value := enumCode(labels, filter.Value)
+		if filter.Op == IN || filter.Op == NOT_IN {
+			items := strings.Split(filter.Value, ",")
+			for j, item := range items {
+				items[j] = enumCode(labels, item)
+			}
+			value = strings.Join(items, ",")
+		}
+		if value == filter.Value {
+			continue
+		}
+		if out == nil {
+			out = append([]Filter(nil), filters...)
+		}
+		out[i].Value = value
+	}
+	return out
+}
+
+// translateGroups 转换过滤条件组及其子组中的枚举标签，存在需要转换的取值时返回新的切片，否则返回 nil
+func (qb *QueryBuilder[T]) translateGroups(groups []FilterGroup) []FilterGroup {
+	var out []FilterGroup
+	for i, group := range groups {
+		filters := qb.translateFilters(group.Filters)
+		children := qb.translateGroups(group.Groups)
+		if filters == nil && children == nil {
+			continue
+		}
+		if out == nil {
+			out = append([]FilterGroup(nil), groups...)
+		}
+		if filters != nil {
+			out[i].Filters = filters
+		}
+		if children != nil {
+			out[i].Groups = children
+		}
+	}
+	return out
+}
+
+// translateTuples 按字段转换组合键过滤条件取值中的枚举标签，存在需要转换的取值时返回新的切片，否则返回 nil
+func (qb *QueryBuilder[T]) translateTuples(tuples []TupleFilter) []TupleFilter {
+	var out []TupleFilter
+	for i, tuple := range tuples {
+		var values [][]string
+		for j, name := range tuple.Fields {
+			field := qb.schema.LookUpField(name)
+			if field == nil {
+				continue
+			}
+			labels := qb.enumLabels(field)
+			if len(labels) == 0 {
+				continue
+			}
+			for k, row := range tuple.Values {
+				if j >= len(row) || enumCode(labels, row[j]) == row[j] {
+					continue
+				}
+				if values == nil {
+					values = make([][]string, len(tuple.Values))
+					for n, row := range tuple.Values {
+						values[n] = append([]string(nil), row...)
+					}
+				}
+				values[k][j] = enumCode(labels, row[j])
+			}
+		}
+		if values == nil {
+			continue
+		}
+		if out == nil {
+			out = append([]TupleFilter(nil), tuples...)
+		}
+		out[i].Values = values
+	}
+	return out
+}
+
+// enumList 返回字段按字母顺序排列的枚举取值，未声明时返回 nil
+func (qb *QueryBuilder[T]) enumList(field *schema.Field) []string {
+	values := qb.enumValues(field)
+	if values == nil {
+		return nil
+	}
+	list := make([]string, 0, len(values))
+	for value := range values {
+		list = append(list, value)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// enumLabelList 返回字段枚举取值到标签的映射，未声明标签时返回 nil
+func (qb *QueryBuilder[T]) enumLabelList(field *schema.Field) map[string]string {
+	labels := qb.enumLabels(field)
+	if len(labels) == 0 {
+		return nil
+	}
+	list := make(map[string]string, len(labels))
+	for label, value := range labels {
+		list[value] = label
+	}
+	return list
+}
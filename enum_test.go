@@ -0,0 +1,107 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// labeledUser 枚举标签中声明了显示标签的测试模型
+type labeledUser struct {
+	ID     uint   `gorm:"primarykey"`
+	Name   string `gorm:"column:name"`
+	Status string `gorm:"column:status" enum:"active:已激活,inactive:已停用"`
+}
+
+func (labeledUser) TableName() string {
+	return "test_users"
+}
+
+func TestQueryBuilder_EnumLabels(t *testing.T) {
+	db := setupTestDB(t)
+
+	t.Run("Struct tag", func(t *testing.T) {
+		builder := NewQueryBuilder[labeledUser](db, WithValueValidation())
+
+		var users []labeledUser
+		assert.NoError(t, builder.FindAll(&FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "已激活"}},
+		}, &users))
+		assert.Len(t, users, 2)
+
+		assert.NoError(t, builder.FindAll(&FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: IN, Value: "已停用,active"}},
+		}, &users))
+		assert.Len(t, users, 3)
+
+		assert.NoError(t, builder.Validate(&FilterRequest{Filters: []Filter{{Field: "Status", Op: NE, Value: "已停用"}}}))
+
+		err := builder.FindAll(&FilterRequest{Filters: []Filter{{Field: "Status", Op: EQ, Value: "已删除"}}}, &users)
+		var valueErr *InvalidValueError
+		assert.ErrorAs(t, err, &valueErr)
+		assert.Equal(t, []string{"active", "inactive"}, valueErr.Allowed)
+		assert.Contains(t, err.Error(), "allowed: active, inactive")
+	})
+
+	t.Run("Option", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db, WithEnumLabels("Status", map[string]string{"启用": "active", "停用": "inactive"}))
+		req := &FilterRequest{Filters: []Filter{{Field: "Status", Op: EQ, Value: "停用"}}}
+
+		var users []TestUser
+		assert.NoError(t, builder.FindAll(req, &users))
+		assert.Len(t, users, 1)
+		assert.Equal(t, "停用", req.Filters[0].Value, "request is not modified")
+
+		status := builder.Metadata().Fields[4]
+		assert.Equal(t, "Status", status.Name)
+		assert.Equal(t, []string{"active", "inactive"}, status.Enum)
+		assert.Equal(t, map[string]string{"active": "启用", "inactive": "停用"}, status.EnumLabels)
+	})
+
+	t.Run("Groups and tuples", func(t *testing.T) {
+		builder := NewQueryBuilder[labeledUser](db, WithValueValidation())
+		req := &FilterRequest{FilterGroups: []FilterGroup{{Groups: []FilterGroup{{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "已停用"}},
+		}}}}}
+
+		var users []labeledUser
+		assert.NoError(t, builder.FindAll(req, &users))
+		if assert.Len(t, users, 1) {
+			assert.Equal(t, "Jane Smith", users[0].Name)
+		}
+		assert.Equal(t, "已停用", req.FilterGroups[0].Groups[0].Filters[0].Value, "request is not modified")
+
+		req = &FilterRequest{TupleFilters: []TupleFilter{{
+			Fields: []string{"Name", "Status"},
+			Values: [][]string{{"John Doe", "已激活"}, {"Jane Smith", "已激活"}},
+		}}}
+		assert.NoError(t, builder.FindAll(req, &users))
+		if assert.Len(t, users, 1) {
+			assert.Equal(t, "John Doe", users[0].Name)
+		}
+		assert.Equal(t, "已激活", req.TupleFilters[0].Values[0][1])
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db, WithValueValidation())
+		config, err := ParseConfig([]byte(`
+enums:
+  Status: [active, inactive]
+enum_labels:
+  Status: {已激活: active}
+`))
+		assert.NoError(t, err)
+		assert.NoError(t, builder.ApplyConfig(config))
+
+		var users []TestUser
+		assert.NoError(t, builder.FindAll(&FilterRequest{Filters: []Filter{{Field: "Status", Op: EQ, Value: "已激活"}}}, &users))
+		assert.Len(t, users, 2)
+		assert.Error(t, builder.Validate(&FilterRequest{Filters: []Filter{{Field: "Status", Op: EQ, Value: "deleted"}}}))
+
+		config, err = ParseConfig([]byte(`{"enums": {"Status": ["active"]}, "enum_labels": {"Status": {"已停用": "inactive"}, "Missing": {"x": "y"}}}`))
+		assert.NoError(t, err)
+		var verr *ValidationError
+		assert.ErrorAs(t, builder.ApplyConfig(config), &verr)
+		assert.Len(t, verr.Errors, 2)
+	})
+}
@@ -0,0 +1,89 @@
+package querybuild
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SortNotAllowedError 排序字段不在允许排序的字段列表中
+type SortNotAllowedError struct {
+	Field string
+}
+
+func (e *SortNotAllowedError) Error() string {
+	return fmt.Sprintf("sort not allowed on field: %s", e.Field)
+}
+
+// ScopeNotAllowedError 调用方未获授权使用作用域
+type ScopeNotAllowedError struct {
+	Type ScopeType
+	Name string
+}
+
+func (e *ScopeNotAllowedError) Error() string {
+	return fmt.Sprintf("scope not allowed: %s", e.Name)
+}
+
+// QueryTooComplexError 请求的查询复杂度超出上限
+type QueryTooComplexError struct {
+	Score int // 请求的复杂度
+	Max   int // 允许的复杂度上限
+}
+
+func (e *QueryTooComplexError) Error() string {
+	return fmt.Sprintf("query too complex: score %d exceeds limit %d", e.Score, e.Max)
+}
+
+// ValidationError 请求校验发现的全部问题
+type ValidationError struct {
+	Errors []error
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap 返回全部校验错误，支持 errors.Is 与 errors.As
+func (e *ValidationError) Unwrap() []error {
+	return e.Errors
+}
+
+// LimitExceededError 请求中某类条目的数量超出上限
+type LimitExceededError struct {
+	Kind  string // 条目类型，如 filters、sorts、joins、in values
+	Count int    // 请求中的数量
+	Max   int    // 允许的上限
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("too many %s: %d exceeds limit %d", e.Kind, e.Count, e.Max)
+}
+
+// InvalidValueError 过滤取值不符合字段的类型、长度或枚举取值
+type InvalidValueError struct {
+	Field   string   // 过滤字段
+	Value   string   // 无效的取值
+	Reason  string   // 无效的原因
+	Allowed []string // 字段允许的枚举取值，非枚举字段为空
+}
+
+func (e *InvalidValueError) Error() string {
+	if len(e.Allowed) > 0 {
+		return fmt.Sprintf("invalid value for field %s: %q %s, allowed: %s", e.Field, e.Value, e.Reason, strings.Join(e.Allowed, ", "))
+	}
+	return fmt.Sprintf("invalid value for field %s: %q %s", e.Field, e.Value, e.Reason)
+}
+
+// UnsupportedOperatorError 过滤操作符不受当前数据库方言支持
+type UnsupportedOperatorError struct {
+	Op      Operator
+	Dialect string
+}
+
+func (e *UnsupportedOperatorError) Error() string {
+	return fmt.Sprintf("operator %s unsupported on dialect %s", e.Op, e.Dialect)
+}
@@ -0,0 +1,128 @@
+package querybuild
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 执行信息在查询中的存储键及执行回调名称
+const (
+	execKey          = "querybuild:exec"
+	execStartKey     = "querybuild:exec_start"
+	execCallbackName = "querybuild:exec"
+)
+
+// execInfo 查询中记录的执行信息，供审计与慢查询回调使用
+type execInfo struct {
+	auditor       Auditor
+	slowThreshold time.Duration
+	onSlow        SlowQueryFunc
+	model         string
+	req           *FilterRequest
+}
+
+// execRegisterMu 保护执行回调的注册
+var execRegisterMu sync.Mutex
+
+// registerExecCallbacks 在数据库连接上注册执行回调，已注册时跳过
+//
+// 回调仅处理带有执行信息的查询，其他查询不受影响。
+func registerExecCallbacks(db *gorm.DB) error {
+	execRegisterMu.Lock()
+	defer execRegisterMu.Unlock()
+
+	callback := db.Callback()
+	if callback.Query().Get(execCallbackName) != nil {
+		return nil
+	}
+
+	for _, p := range []struct {
+		before, after interface {
+			Register(name string, fn func(*gorm.DB)) error
+		}
+	}{
+		{callback.Query().Before("gorm:query"), callback.Query().After("gorm:query")},
+		{callback.Row().Before("gorm:row"), callback.Row().After("gorm:row")},
+		{callback.Update().Before("gorm:update"), callback.Update().After("gorm:update")},
+		{callback.Delete().Before("gorm:delete"), callback.Delete().After("gorm:delete")},
+		{callback.Create().Before("gorm:create"), callback.Create().After("gorm:create")},
+	} {
+		if err := p.before.Register(execCallbackName+"_start", execStart); err != nil {
+			return err
+		}
+		if err := p.after.Register(execCallbackName, execFinish); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execStart 记录查询开始执行的时间
+func execStart(db *gorm.DB) {
+	if _, ok := db.Get(execKey); ok {
+		db.Statement.Settings.Store(execStartKey, time.Now())
+	}
+}
+
+// execFinish 查询执行后生成审计记录并检查慢查询，DryRun 查询不处理
+func execFinish(db *gorm.DB) {
+	if db.DryRun {
+		return
+	}
+	v, ok := db.Get(execKey)
+	if !ok {
+		return
+	}
+	info, ok := v.(*execInfo)
+	if !ok {
+		return
+	}
+
+	var duration time.Duration
+	if start, ok := db.Get(execStartKey); ok {
+		duration = time.Since(start.(time.Time))
+	}
+
+	ctx := db.Statement.Context
+	sql := db.Statement.SQL.String()
+	if info.auditor != nil {
+		sum := sha256.Sum256([]byte(sql))
+		info.auditor.Audit(ctx, AuditEntry{
+			Model:    info.model,
+			Request:  info.req,
+			SQL:      sql,
+			SQLHash:  hex.EncodeToString(sum[:]),
+			Rows:     db.RowsAffected,
+			Duration: duration,
+			Identity: IdentityFromContext(ctx),
+			Err:      db.Error,
+		})
+	}
+	if info.onSlow != nil && duration >= info.slowThreshold {
+		info.onSlow(ctx, SlowQuery{
+			Model:    info.model,
+			Request:  info.req,
+			SQL:      sql,
+			Vars:     db.Statement.Vars,
+			Duration: duration,
+		})
+	}
+}
+
+// withExecInfo 为查询附加执行信息，未配置审计与慢查询回调时原样返回
+func (qb *QueryBuilder[T]) withExecInfo(query *gorm.DB, req *FilterRequest) *gorm.DB {
+	if qb.opts.auditor == nil && qb.opts.onSlow == nil {
+		return query
+	}
+	return query.Set(execKey, &execInfo{
+		auditor:       qb.opts.auditor,
+		slowThreshold: qb.opts.slowThreshold,
+		onSlow:        qb.opts.onSlow,
+		model:         qb.tableName(),
+		req:           req,
+	})
+}
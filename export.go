@@ -0,0 +1,279 @@
+package querybuild
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+// ExportFormat 导出格式
+type ExportFormat string
+
+const (
+	CSV    ExportFormat = "csv"    // 逗号分隔值
+	NDJSON ExportFormat = "ndjson" // 每行一个 JSON 对象
+	XLSX   ExportFormat = "xlsx"   // Excel 工作簿
+)
+
+// DefaultExportBatchSize 导出时每批处理的记录数
+const DefaultExportBatchSize = 500
+
+// exportOptions 导出配置
+type exportOptions struct {
+	fields    []string          // 导出的字段，为空时导出全部模型字段
+	headers   map[string]string // 字段对应的表头
+	maxRows   int               // 导出行数上限，0 表示不限制
+	batchSize int               // 每批处理的记录数
+}
+
+// ExportOption 导出配置项
+type ExportOption func(*exportOptions)
+
+// ExportFields 指定导出的字段及其顺序
+func ExportFields(fields ...string) ExportOption {
+	return func(o *exportOptions) {
+		o.fields = fields
+	}
+}
+
+// ExportHeaders 指定字段对应的表头，未指定的字段使用字段名
+func ExportHeaders(headers map[string]string) ExportOption {
+	return func(o *exportOptions) {
+		o.headers = headers
+	}
+}
+
+// ExportLimit 指定导出行数上限
+func ExportLimit(maxRows int) ExportOption {
+	return func(o *exportOptions) {
+		o.maxRows = maxRows
+	}
+}
+
+// ExportBatchSize 指定每批处理的记录数，查询后钩子与脱敏函数按批执行
+func ExportBatchSize(size int) ExportOption {
+	return func(o *exportOptions) {
+		o.batchSize = size
+	}
+}
+
+// rowWriter 按行写入导出内容
+type rowWriter interface {
+	WriteHeader(headers []string) error
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// Export 将查询结果以流的方式写入 w，支持 CSV、NDJSON 与 XLSX 格式
+//
+// 结果逐行读取，不会一次性加载到内存；请求的分页参数被忽略，行数通过 ExportLimit 限制。
+// 查询前后钩子与脱敏规则同样作用于导出结果。
+func (qb *QueryBuilder[T]) Export(ctx context.Context, req *FilterRequest, w io.Writer, format ExportFormat, opts ...ExportOption) error {
+	o := exportOptions{batchSize: DefaultExportBatchSize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.batchSize <= 0 {
+		o.batchSize = DefaultExportBatchSize
+	}
+
+	fields, headers, err := qb.exportFields(o)
+	if err != nil {
+		return err
+	}
+
+	var out rowWriter
+	switch format {
+	case CSV:
+		out = &csvWriter{w: csv.NewWriter(w)}
+	case NDJSON:
+		out = &ndjsonWriter{w: w}
+	case XLSX:
+		out = newXLSXWriter(w)
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	builder := qb.WithContext(ctx)
+	req, err = builder.beforeFind(req)
+	if err != nil {
+		return err
+	}
+	exportReq := *req
+	exportReq.Page = nil
+	query := builder.Build(&exportReq)
+	if o.maxRows > 0 {
+		query = query.Limit(o.maxRows)
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if err := out.WriteHeader(headers); err != nil {
+		return err
+	}
+
+	batch := make([]T, 0, o.batchSize)
+	flush := func() error {
+		items, err := builder.afterFind(batch)
+		if err != nil {
+			return err
+		}
+		for i := range items {
+			rv := reflect.ValueOf(&items[i]).Elem()
+			values := make([]interface{}, len(fields))
+			for j, field := range fields {
+				values[j], _ = field.ValueOf(ctx, rv)
+			}
+			if err := out.WriteRow(values); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for rows.Next() {
+		var item T
+		if err := query.ScanRows(rows, &item); err != nil {
+			return err
+		}
+		batch = append(batch, item)
+		if len(batch) == o.batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// exportFields 解析导出的模型字段与表头
+func (qb *QueryBuilder[T]) exportFields(o exportOptions) ([]*schema.Field, []string, error) {
+	names := o.fields
+	if len(names) == 0 {
+		for _, field := range qb.schema.Fields {
+			if field.DBName != "" {
+				names = append(names, field.Name)
+			}
+		}
+	}
+
+	fields := make([]*schema.Field, 0, len(names))
+	headers := make([]string, 0, len(names))
+	for _, name := range names {
+		field := qb.schema.LookUpField(name)
+		if field == nil || field.DBName == "" {
+			return nil, nil, fmt.Errorf("invalid field name: %s", name)
+		}
+		fields = append(fields, field)
+		header := field.Name
+		if h, ok := o.headers[name]; ok {
+			header = h
+		}
+		headers = append(headers, header)
+	}
+	return fields, headers, nil
+}
+
+// exportString 将字段值转换为导出的文本，时间使用 RFC3339 格式，NULL 为空字符串
+func exportString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return ""
+		}
+		return exportString(rv.Elem().Interface())
+	}
+	return fmt.Sprint(value)
+}
+
+// csvWriter CSV 格式输出
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) WriteHeader(headers []string) error {
+	return c.w.Write(headers)
+}
+
+func (c *csvWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = exportString(value)
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// ndjsonWriter NDJSON 格式输出，对象的键按导出字段的顺序排列
+type ndjsonWriter struct {
+	w       io.Writer
+	headers []string
+	buf     bytes.Buffer
+}
+
+func (n *ndjsonWriter) WriteHeader(headers []string) error {
+	n.headers = headers
+	return nil
+}
+
+func (n *ndjsonWriter) WriteRow(values []interface{}) error {
+	n.buf.Reset()
+	n.buf.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			n.buf.WriteByte(',')
+		}
+		n.buf.WriteString(strconv.Quote(n.headers[i]))
+		n.buf.WriteByte(':')
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		n.buf.Write(data)
+	}
+	n.buf.WriteString("}\n")
+	_, err := n.w.Write(n.buf.Bytes())
+	return err
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,68 @@
+package querybuild
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_Export(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+	ctx := context.Background()
+	req := &FilterRequest{Sorts: []Sort{{Field: "Age"}}, Page: &Pagination{Page: 1, PageSize: 1}}
+	opts := []ExportOption{
+		ExportFields("Name", "Age"),
+		ExportHeaders(map[string]string{"Name": "User Name"}),
+		ExportLimit(2),
+		ExportBatchSize(1),
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, builder.Export(ctx, req, &buf, CSV, opts...))
+		assert.Equal(t, "User Name,Age\nJohn Doe,25\nJane Smith,30\n", buf.String())
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, builder.Export(ctx, req, &buf, NDJSON, opts...))
+		assert.Equal(t, `{"User Name":"John Doe","Age":25}`+"\n"+`{"User Name":"Jane Smith","Age":30}`+"\n", buf.String())
+	})
+
+	t.Run("XLSX", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, builder.Export(ctx, req, &buf, XLSX, opts...))
+
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		assert.NoError(t, err)
+		var sheet string
+		for _, f := range zr.File {
+			if f.Name == "xl/worksheets/sheet1.xml" {
+				rc, _ := f.Open()
+				data, _ := io.ReadAll(rc)
+				sheet = string(data)
+			}
+		}
+		assert.Contains(t, sheet, `<row><c t="inlineStr"><is><t xml:space="preserve">User Name</t></is></c>`)
+		assert.Contains(t, sheet, `<c><v>30</v></c></row>`)
+		assert.Equal(t, 3, strings.Count(sheet, "<row>"))
+	})
+
+	t.Run("Masking", func(t *testing.T) {
+		masked := builder.Clone(WithMaskFunc("Name", func(v interface{}) interface{} { return "***" }))
+		var buf bytes.Buffer
+		assert.NoError(t, masked.Export(ctx, req, &buf, CSV, opts...))
+		assert.Equal(t, "User Name,Age\n***,25\n***,30\n", buf.String())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		assert.Error(t, builder.Export(ctx, req, io.Discard, "pdf"))
+		assert.Error(t, builder.Export(ctx, req, io.Discard, CSV, ExportFields("Password")))
+	})
+}
@@ -0,0 +1,97 @@
+package querybuild
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// expressionFieldPattern 表达式中的字段占位符，如 {Price}
+var expressionFieldPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Expression 已注册的SQL表达式
+type Expression struct {
+	SQL  string        // 字段占位符已替换为安全字段引用的SQL
+	Args []interface{} // 绑定参数
+}
+
+// ExpressionRegistry SQL表达式注册表
+type ExpressionRegistry struct {
+	expressions map[string]Expression
+	mu          sync.RWMutex
+}
+
+// NewExpressionRegistry 创建新的表达式注册表
+func NewExpressionRegistry() *ExpressionRegistry {
+	return &ExpressionRegistry{
+		expressions: make(map[string]Expression),
+	}
+}
+
+// Register 注册表达式
+func (r *ExpressionRegistry) Register(name string, expr Expression) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expressions[name] = expr
+}
+
+// Get 获取表达式
+func (r *ExpressionRegistry) Get(name string) (Expression, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	expr, ok := r.expressions[name]
+	return expr, ok
+}
+
+// Clone 复制表达式注册表
+func (r *ExpressionRegistry) Clone() *ExpressionRegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clone := NewExpressionRegistry()
+	for name, expr := range r.expressions {
+		clone.expressions[name] = expr
+	}
+	return clone
+}
+
+// RegisterExpression 注册可在聚合与附加选择中引用的SQL表达式
+//
+// 表达式通过 {Field} 引用模型字段，注册时校验字段并替换为安全的字段引用，
+// 其余参数使用 ? 占位并通过 args 绑定，例如：
+//
+//	qb.RegisterExpression("revenue", "{Price} * {Quantity}")
+//	qb.RegisterExpression("discounted", "{Price} * ?", 0.9)
+func (qb *QueryBuilder[T]) RegisterExpression(name, sql string, args ...interface{}) error {
+	if !isIdentifier(name) {
+		return fmt.Errorf("invalid expression name: %s", name)
+	}
+	if strings.Count(sql, "?") != len(args) {
+		return fmt.Errorf("expression %s expects %d args, got %d", name, strings.Count(sql, "?"), len(args))
+	}
+
+	var fieldErr error
+	resolved := expressionFieldPattern.ReplaceAllStringFunc(sql, func(match string) string {
+		info, err := qb.validateField(match[1 : len(match)-1])
+		if err != nil && fieldErr == nil {
+			fieldErr = err
+		}
+		return quoteField(info)
+	})
+	if fieldErr != nil {
+		return fieldErr
+	}
+
+	qb.expressions.Register(name, Expression{SQL: resolved, Args: args})
+	return nil
+}
+
+// expression 获取已注册的表达式
+func (qb *QueryBuilder[T]) expression(name string) (Expression, error) {
+	expr, ok := qb.expressions.Get(name)
+	if !ok {
+		return Expression{}, fmt.Errorf("invalid expression name: %s", name)
+	}
+	return expr, nil
+}
@@ -0,0 +1,83 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_RegisterExpression(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestOrder](db)
+
+	assert.NoError(t, builder.RegisterExpression("doubled", "{Amount} * ?", 2))
+	assert.NoError(t, builder.RegisterExpression("weighted", "{Amount} + {UserID}"))
+
+	t.Run("Aggregate over expression", func(t *testing.T) {
+		type Result struct {
+			Doubled float64 `gorm:"column:doubled"`
+		}
+		var result Result
+		err := builder.FindOne(&FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "paid"}},
+			Aggrs:   []Aggregation{{Expr: "doubled", Op: SUM}},
+		}, &result)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(300), result.Doubled)
+	})
+
+	t.Run("Conditional aggregate over expression", func(t *testing.T) {
+		type Result struct {
+			PendingDoubled float64 `gorm:"column:pending_doubled"`
+		}
+		var result Result
+		err := builder.FindOne(&FilterRequest{
+			Aggrs: []Aggregation{{
+				Expr:      "doubled",
+				Op:        SUM,
+				Alias:     "pending_doubled",
+				Condition: &Filter{Field: "Status", Op: EQ, Value: "pending"},
+			}},
+		}, &result)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(160), result.PendingDoubled)
+	})
+
+	t.Run("Expression in AddSelects", func(t *testing.T) {
+		type Result struct {
+			ID      uint    `gorm:"column:id"`
+			Doubled float64 `gorm:"column:doubled"`
+		}
+		var results []Result
+		err := builder.FindAll(&FilterRequest{
+			Groups: []Group{{Field: "ID"}},
+			Aggrs:  []Aggregation{{Field: "ID", Op: COUNT, Alias: "n", AddSelects: []string{"doubled"}}},
+			Sorts:  []Sort{{Field: "ID"}},
+		}, &results)
+		assert.NoError(t, err)
+		assert.Len(t, results, 3)
+		assert.Equal(t, float64(200), results[0].Doubled)
+	})
+
+	t.Run("Unknown expression", func(t *testing.T) {
+		var result map[string]interface{}
+		err := builder.FindOne(&FilterRequest{
+			Aggrs: []Aggregation{{Expr: "profit", Op: SUM}},
+		}, &result)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid expression name")
+	})
+
+	t.Run("Registration validates fields and args", func(t *testing.T) {
+		err := builder.RegisterExpression("bad", "{Price} * {Quantity}")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid field name")
+
+		err = builder.RegisterExpression("bad", "{Amount} * ?")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "expects 1 args")
+
+		err = builder.RegisterExpression("bad name", "{Amount}")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,88 @@
+package querybuild
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// ErrFunctionExists 注册的函数名称已存在
+//
+// 查询计划按函数名称缓存，已注册的函数不允许覆盖，避免缓存的计划使用旧的函数SQL。
+var ErrFunctionExists = errors.New("function already registered")
+
+// FunctionRegistry 过滤字段函数注册表
+type FunctionRegistry struct {
+	functions map[string]string
+	mu        sync.RWMutex
+}
+
+// NewFunctionRegistry 创建新的函数注册表
+func NewFunctionRegistry() *FunctionRegistry {
+	return &FunctionRegistry{
+		functions: make(map[string]string),
+	}
+}
+
+// Register 注册函数，名称已存在时返回 ErrFunctionExists
+func (r *FunctionRegistry) Register(name, sql string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.functions[name]; ok {
+		return fmt.Errorf("%w: %s", ErrFunctionExists, name)
+	}
+	r.functions[name] = sql
+	return nil
+}
+
+// Get 获取函数
+func (r *FunctionRegistry) Get(name string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sql, ok := r.functions[name]
+	return sql, ok
+}
+
+// Clone 复制函数注册表
+func (r *FunctionRegistry) Clone() *FunctionRegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clone := NewFunctionRegistry()
+	for name, sql := range r.functions {
+		clone.functions[name] = sql
+	}
+	return clone
+}
+
+// RegisterFunction 注册可在过滤条件中包装字段的SQL函数，名称已存在时返回 ErrFunctionExists
+//
+// 函数SQL中唯一的 ? 为字段占位符，构建时替换为安全的字段引用，过滤条件通过 Fn 引用，例如：
+//
+//	qb.RegisterFunction("domain_of", "SUBSTRING_INDEX(?, '@', -1)")
+//	// {Field: "Email", Fn: "domain_of", Op: EQ, Value: "example.com"}
+func (qb *QueryBuilder[T]) RegisterFunction(name, sql string) error {
+	if !isIdentifier(name) {
+		return fmt.Errorf("invalid function name: %s", name)
+	}
+	if strings.Count(sql, "?") != 1 {
+		return fmt.Errorf("function %s must contain exactly one field placeholder", name)
+	}
+	return qb.functions.Register(name, sql)
+}
+
+// filterField 解析过滤条件的字段引用，指定了函数时以函数包装字段
+func (qb *QueryBuilder[T]) filterField(query *gorm.DB, filter Filter) (string, error) {
+	field, err := qb.resolveField(query, filter.Field)
+	if err != nil || filter.Fn == "" {
+		return field, err
+	}
+	sql, ok := qb.functions.Get(filter.Fn)
+	if !ok {
+		return "", fmt.Errorf("unknown function: %s", filter.Fn)
+	}
+	return strings.Replace(sql, "?", field, 1), nil
+}
@@ -0,0 +1,62 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_FilterFunctions(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db, WithPlanCache(16))
+	assert.NoError(t, builder.RegisterFunction("initial", "UPPER(SUBSTR(?, 1, 1))"))
+
+	names := func(t *testing.T, req *FilterRequest) []string {
+		req.Sorts = []Sort{{Field: "ID"}}
+		var users []TestUser
+		assert.NoError(t, builder.FindAll(req, &users))
+		var result []string
+		for _, user := range users {
+			result = append(result, user.Name)
+		}
+		return result
+	}
+
+	t.Run("Filter", func(t *testing.T) {
+		assert.Equal(t, []string{"John Doe", "Jane Smith"}, names(t, &FilterRequest{
+			Filters: []Filter{{Field: "Name", Fn: "initial", Op: EQ, Value: "J"}},
+		}))
+		// 计划缓存按函数区分
+		assert.Equal(t, []string{"Bob Johnson"}, names(t, &FilterRequest{
+			Filters: []Filter{{Field: "Name", Op: EQ, Value: "Bob Johnson"}},
+		}))
+		assert.Equal(t, []string{"Bob Johnson"}, names(t, &FilterRequest{
+			Filters: []Filter{{Field: "Name", Fn: "initial", Op: EQ, Value: "B"}},
+		}))
+	})
+
+	t.Run("Filter group", func(t *testing.T) {
+		assert.Equal(t, []string{"Bob Johnson"}, names(t, &FilterRequest{
+			FilterGroups: []FilterGroup{{Not: true, Filters: []Filter{{Field: "Name", Fn: "initial", Op: EQ, Value: "J"}}}},
+		}))
+	})
+
+	t.Run("Unknown function", func(t *testing.T) {
+		req := &FilterRequest{Filters: []Filter{{Field: "Name", Fn: "missing", Op: EQ, Value: "J"}}}
+		var users []TestUser
+		assert.Error(t, builder.FindAll(req, &users))
+		assert.Error(t, builder.Validate(req))
+	})
+
+	t.Run("Register", func(t *testing.T) {
+		assert.Error(t, builder.RegisterFunction("bad name", "LOWER(?)"))
+		assert.Error(t, builder.RegisterFunction("pair", "COALESCE(?, ?)"))
+		assert.Error(t, builder.RegisterFunction("constant", "1"))
+
+		// 已注册的函数不能覆盖，缓存的计划始终与函数SQL一致
+		assert.ErrorIs(t, builder.RegisterFunction("initial", "LOWER(SUBSTR(?, 1, 1))"), ErrFunctionExists)
+		assert.Equal(t, []string{"Bob Johnson"}, names(t, &FilterRequest{
+			Filters: []Filter{{Field: "Name", Fn: "initial", Op: EQ, Value: "B"}},
+		}))
+	})
+}
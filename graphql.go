@@ -0,0 +1,261 @@
+package querybuild
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// graphqlOp GraphQL 过滤输入中的操作符字段
+type graphqlOp struct {
+	name string   // 输入字段名
+	op   Operator // 对应的过滤操作符
+	list bool     // 取值为列表
+}
+
+// graphqlOps GraphQL 过滤输入支持的操作符字段，isNull 为 true 时生成 IS_NULL，为 false 时生成 NOT_NULL
+var graphqlOps = []graphqlOp{
+	{name: "eq", op: EQ},
+	{name: "ne", op: NE},
+	{name: "gt", op: GT},
+	{name: "gte", op: GE},
+	{name: "lt", op: LT},
+	{name: "lte", op: LE},
+	{name: "in", op: IN, list: true},
+	{name: "notIn", op: NOT_IN, list: true},
+	{name: "between", op: BETWEEN, list: true},
+	{name: "notBetween", op: NOT_BETWEEN, list: true},
+	{name: "like", op: LIKE},
+	{name: "notLike", op: NOT_LIKE},
+	{name: "contains", op: CONTAINS},
+	{name: "startsWith", op: STARTS_WITH},
+	{name: "endsWith", op: ENDS_WITH},
+	{name: "isNull", op: IS_NULL},
+}
+
+// graphqlScalars 各类字段对应的 GraphQL 标量与过滤输入类型
+var graphqlScalars = []struct {
+	fieldType FieldType
+	scalar    string
+	input     string
+}{
+	{StringField, "String", "StringFilter"},
+	{IntegerField, "Int", "IntFilter"},
+	{NumberField, "Float", "FloatFilter"},
+	{BooleanField, "Boolean", "BooleanFilter"},
+	{TimeField, "Time", "TimeFilter"},
+}
+
+// GraphQLFilterSchema 生成各模型共用的 GraphQL 过滤输入、排序方向与分页输入类型
+//
+// 生成的类型与 gqlgen 兼容，TimeFilter 使用 gqlgen 内置的 Time 标量，整个服务只需声明一次。
+func GraphQLFilterSchema() string {
+	var b strings.Builder
+	for _, s := range graphqlScalars {
+		ops := fieldOperators(s.fieldType, false)
+		fmt.Fprintf(&b, "input %s {\n", s.input)
+		for _, op := range graphqlOps {
+			if !containsOperator(ops, op.op) {
+				continue
+			}
+			switch {
+			case op.op == IS_NULL:
+				fmt.Fprintf(&b, "  %s: Boolean\n", op.name)
+			case op.list:
+				fmt.Fprintf(&b, "  %s: [%s!]\n", op.name, s.scalar)
+			default:
+				fmt.Fprintf(&b, "  %s: %s\n", op.name, s.scalar)
+			}
+		}
+		b.WriteString("}\n\n")
+	}
+	b.WriteString("enum SortDirection {\n  ASC\n  DESC\n}\n\n")
+	b.WriteString("input PageInput {\n  page: Int\n  pageSize: Int\n}\n")
+	return b.String()
+}
+
+// GraphQLSchema 生成当前模型的 Where、OrderField 与 OrderBy 输入类型，类型名以 name 为前缀
+//
+// 需配合 GraphQLFilterSchema 生成的共用类型使用，类型为 other 与 array 的字段不生成过滤字段。
+func (qb *QueryBuilder[T]) GraphQLSchema(name string) string {
+	meta := qb.Metadata()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "input %sWhere {\n", name)
+	for _, field := range meta.Fields {
+		if input := graphqlInput(field.Type); input != "" {
+			fmt.Fprintf(&b, "  %s: %s\n", graphqlName(field.Name), input)
+		}
+	}
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "enum %sOrderField {\n", name)
+	for _, field := range meta.Fields {
+		if field.Sortable {
+			fmt.Fprintf(&b, "  %s\n", strings.ToUpper(field.Column))
+		}
+	}
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "input %sOrderBy {\n  field: %sOrderField!\n  direction: SortDirection\n}\n", name, name)
+	return b.String()
+}
+
+// FromGraphQL 将 GraphQL 解析器的 where、orderBy 与 page 参数转换为过滤请求
+//
+// 参数可以是 gqlgen 生成的输入结构体或 map[string]interface{}，为 nil 时忽略。
+func (qb *QueryBuilder[T]) FromGraphQL(where, orderBy, page interface{}) (*FilterRequest, error) {
+	req := &FilterRequest{}
+
+	var whereMap map[string]map[string]interface{}
+	if err := graphqlDecode(where, &whereMap); err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(qb.schema.Fields))
+	for _, field := range qb.schema.Fields {
+		if field.DBName != "" {
+			names[graphqlName(field.Name)] = field.Name
+		}
+	}
+	for _, key := range sortedKeys(whereMap) {
+		field, ok := names[key]
+		if !ok {
+			return nil, fmt.Errorf("invalid field name: %s", key)
+		}
+		filters, err := graphqlFilters(field, whereMap[key])
+		if err != nil {
+			return nil, err
+		}
+		req.Filters = append(req.Filters, filters...)
+	}
+
+	var orders []struct {
+		Field     string `json:"field"`
+		Direction string `json:"direction"`
+	}
+	if err := graphqlDecode(orderBy, &orders); err != nil {
+		return nil, err
+	}
+	for _, order := range orders {
+		field := qb.schema.LookUpField(strings.ToLower(order.Field))
+		if field == nil || field.DBName == "" {
+			return nil, fmt.Errorf("invalid field name: %s", order.Field)
+		}
+		req.Sorts = append(req.Sorts, Sort{Field: field.Name, Desc: order.Direction == "DESC"})
+	}
+
+	var pageInput *struct {
+		Page     int `json:"page"`
+		PageSize int `json:"pageSize"`
+	}
+	if err := graphqlDecode(page, &pageInput); err != nil {
+		return nil, err
+	}
+	if pageInput != nil {
+		req.Page = &Pagination{Page: pageInput.Page, PageSize: pageInput.PageSize}
+	}
+	return req, nil
+}
+
+// graphqlFilters 将单个字段的过滤输入转换为过滤条件
+func graphqlFilters(field string, input map[string]interface{}) ([]Filter, error) {
+	var filters []Filter
+	for _, op := range graphqlOps {
+		value, ok := input[op.name]
+		if !ok || value == nil {
+			continue
+		}
+		switch {
+		case op.op == IS_NULL:
+			isNull, ok := value.(bool)
+			if !ok {
+				return nil, fmt.Errorf("invalid value for %s.%s: %v", field, op.name, value)
+			}
+			if isNull {
+				filters = append(filters, Filter{Field: field, Op: IS_NULL})
+			} else {
+				filters = append(filters, Filter{Field: field, Op: NOT_NULL})
+			}
+		case op.list:
+			items, ok := value.([]interface{})
+			if !ok || ((op.op == BETWEEN || op.op == NOT_BETWEEN) && len(items) != 2) {
+				return nil, fmt.Errorf("invalid value for %s.%s: %v", field, op.name, value)
+			}
+			values := make([]string, 0, len(items))
+			for _, item := range items {
+				values = append(values, stringValue(item))
+			}
+			filters = append(filters, Filter{Field: field, Op: op.op, Value: strings.Join(values, ",")})
+		default:
+			filters = append(filters, Filter{Field: field, Op: op.op, Value: stringValue(value)})
+		}
+	}
+	return filters, nil
+}
+
+// graphqlDecode 经由 JSON 将解析器参数转换为目标类型
+func graphqlDecode(arg, dest interface{}) error {
+	if arg == nil {
+		return nil
+	}
+	data, err := json.Marshal(arg)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(dest); err != nil {
+		return fmt.Errorf("invalid graphql argument: %w", err)
+	}
+	return nil
+}
+
+// graphqlInput 返回字段类型对应的过滤输入类型，不支持的类型返回空字符串
+func graphqlInput(fieldType FieldType) string {
+	for _, s := range graphqlScalars {
+		if s.fieldType == fieldType {
+			return s.input
+		}
+	}
+	return ""
+}
+
+// graphqlName 将字段名转换为 GraphQL 风格的小驼峰名称，如 ID -> id、UserID -> userID、URLPath -> urlPath
+func graphqlName(name string) string {
+	runes := []rune(name)
+	n := 0
+	for n < len(runes) && unicode.IsUpper(runes[n]) {
+		n++
+	}
+	if n > 1 && n < len(runes) {
+		// 连续大写后接小写时，最后一个大写字母属于下一个单词
+		n--
+	}
+	for i := 0; i < n; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// containsOperator 判断操作符列表是否包含指定操作符
+func containsOperator(ops []Operator, op Operator) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys 返回按字母顺序排列的键
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,60 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphQLSchema(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db, AllowSortFields("Name", "CreatedAt"))
+
+	common := GraphQLFilterSchema()
+	assert.Contains(t, common, "input StringFilter {\n  eq: String\n")
+	assert.Contains(t, common, "  in: [Int!]\n")
+	assert.Contains(t, common, "input BooleanFilter {\n  eq: Boolean\n  ne: Boolean\n  isNull: Boolean\n}")
+	assert.Contains(t, common, "enum SortDirection")
+
+	s := builder.GraphQLSchema("User")
+	assert.Contains(t, s, "input UserWhere {\n  id: IntFilter\n  name: StringFilter\n")
+	assert.Contains(t, s, "  createdAt: TimeFilter\n")
+	assert.NotContains(t, s, "orders")
+	assert.Contains(t, s, "enum UserOrderField {\n  ID\n  NAME\n  CREATED_AT\n}")
+	assert.Contains(t, s, "input UserOrderBy {\n  field: UserOrderField!\n  direction: SortDirection\n}")
+}
+
+func TestQueryBuilder_FromGraphQL(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+
+	type pageInput struct {
+		Page     int `json:"page"`
+		PageSize int `json:"pageSize"`
+	}
+
+	req, err := builder.FromGraphQL(
+		map[string]interface{}{
+			"age":    map[string]interface{}{"between": []interface{}{20, 32}},
+			"status": map[string]interface{}{"eq": "active", "isNull": false},
+		},
+		[]map[string]interface{}{{"field": "CREATED_AT", "direction": "DESC"}},
+		&pageInput{Page: 1, PageSize: 10},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []Filter{
+		{Field: "Age", Op: BETWEEN, Value: "20,32"},
+		{Field: "Status", Op: EQ, Value: "active"},
+		{Field: "Status", Op: NOT_NULL},
+	}, req.Filters)
+	assert.Equal(t, []Sort{{Field: "CreatedAt", Desc: true}}, req.Sorts)
+	assert.Equal(t, &Pagination{Page: 1, PageSize: 10}, req.Page)
+
+	result, err := builder.FindPage(req)
+	assert.NoError(t, err)
+	assert.Len(t, result.Items, 1)
+	assert.Equal(t, "John Doe", result.Items[0].Name)
+
+	_, err = builder.FromGraphQL(map[string]interface{}{"unknown": map[string]interface{}{"eq": "x"}}, nil, nil)
+	assert.Error(t, err)
+}
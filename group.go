@@ -0,0 +1,96 @@
+package querybuild
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// FilterGroup 过滤条件组，组内的过滤条件与子组以 AND 连接
+//
+// Not 为 true 时对整个组取反，如 NOT (status = 'closed' AND resolved_at IS NULL)，无需调用方手动展开德摩根律。
+// 取反时组内条件结果为 NULL 的记录同样不匹配，与 SQL 的三值逻辑一致。
+type FilterGroup struct {
+	Filters []Filter      `json:"filters"`
+	Groups  []FilterGroup `json:"groups"` // 嵌套的子组
+	Not     bool          `json:"not"`    // 为 true 时生成 NOT (...)
+}
+
+// applyFilterGroups 应用过滤条件组
+func (qb *QueryBuilder[T]) applyFilterGroups(query *gorm.DB, groups []FilterGroup) *gorm.DB {
+	for _, group := range groups {
+		cond, args, err := qb.groupCondition(query, group)
+		if err != nil {
+			query.AddError(err)
+			continue
+		}
+		if cond != "" {
+			query = whereCondition(query, cond, args)
+		}
+	}
+	return query
+}
+
+// groupCondition 构建过滤条件组的SQL片段及参数，空组返回空字符串
+func (qb *QueryBuilder[T]) groupCondition(query *gorm.DB, group FilterGroup) (string, []interface{}, error) {
+	conds := make([]string, 0, len(group.Filters)+len(group.Groups))
+	var args []interface{}
+	for _, filter := range group.Filters {
+		field, err := qb.filterField(query, filter)
+		if err != nil {
+			return "", nil, err
+		}
+		cond, condArgs, err := qb.filterCondition(query, field, filter)
+		if err != nil {
+			return "", nil, err
+		}
+		if cond != "" {
+			conds = append(conds, "("+cond+")")
+			args = append(args, condArgs...)
+		}
+	}
+	for _, sub := range group.Groups {
+		cond, condArgs, err := qb.groupCondition(query, sub)
+		if err != nil {
+			return "", nil, err
+		}
+		if cond != "" {
+			conds = append(conds, cond)
+			args = append(args, condArgs...)
+		}
+	}
+
+	if len(conds) == 0 {
+		return "", nil, nil
+	}
+	cond := "(" + strings.Join(conds, " AND ") + ")"
+	if group.Not {
+		cond = "NOT " + cond
+	}
+	return cond, args, nil
+}
+
+// checkFilterGroup 校验过滤条件组内的全部过滤条件
+func (qb *QueryBuilder[T]) checkFilterGroup(query *gorm.DB, group FilterGroup) error {
+	for _, filter := range group.Filters {
+		if err := qb.checkFilter(query, filter); err != nil {
+			return err
+		}
+	}
+	for _, sub := range group.Groups {
+		if err := qb.checkFilterGroup(query, sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupFilters 返回过滤条件组及其子组内的全部过滤条件
+func groupFilters(groups []FilterGroup) []Filter {
+	var filters []Filter
+	for _, group := range groups {
+		filters = append(filters, group.Filters...)
+		filters = append(filters, groupFilters(group.Groups)...)
+	}
+	return filters
+}
@@ -0,0 +1,60 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_FilterGroups(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+
+	names := func(t *testing.T, req *FilterRequest) []string {
+		req.Sorts = []Sort{{Field: "ID"}}
+		var users []TestUser
+		assert.NoError(t, builder.FindAll(req, &users))
+		var result []string
+		for _, user := range users {
+			result = append(result, user.Name)
+		}
+		return result
+	}
+
+	t.Run("NOT", func(t *testing.T) {
+		// NOT (status = 'active' AND age > 30)
+		assert.Equal(t, []string{"John Doe", "Jane Smith"}, names(t, &FilterRequest{
+			FilterGroups: []FilterGroup{{
+				Not: true,
+				Filters: []Filter{
+					{Field: "Status", Op: EQ, Value: "active"},
+					{Field: "Age", Op: GT, Value: "30"},
+				},
+			}},
+		}))
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		// status = 'active' AND NOT (age < 30)
+		assert.Equal(t, []string{"Bob Johnson"}, names(t, &FilterRequest{
+			FilterGroups: []FilterGroup{{
+				Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+				Groups:  []FilterGroup{{Not: true, Filters: []Filter{{Field: "Age", Op: LT, Value: "30"}}}},
+			}},
+		}))
+	})
+
+	t.Run("Combined with filters", func(t *testing.T) {
+		assert.Equal(t, []string{"Jane Smith"}, names(t, &FilterRequest{
+			Filters:      []Filter{{Field: "Age", Op: GE, Value: "30"}},
+			FilterGroups: []FilterGroup{{Not: true, Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}}}},
+		}))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		req := &FilterRequest{FilterGroups: []FilterGroup{{Not: true, Filters: []Filter{{Field: "Missing", Op: EQ, Value: "x"}}}}}
+		var users []TestUser
+		assert.Error(t, builder.FindAll(req, &users))
+		assert.Error(t, builder.Validate(req))
+	})
+}
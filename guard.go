@@ -0,0 +1,85 @@
+package querybuild
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// ScopeOption 作用域注册配置项
+type ScopeOption func(*scopeGuard)
+
+// scopeGuard 作用域的授权判断
+type scopeGuard struct {
+	allowed func(ctx context.Context) bool // 判断调用方是否可使用作用域
+	ignore  bool                           // 未获授权时忽略作用域而非返回错误
+}
+
+// ScopeAllowed 指定作用域的授权判断，参数为查询的上下文，调用方未获授权时查询返回 *ScopeNotAllowedError
+//
+// 适用于仅向特权调用方开放的作用域，如包含已删除记录、跨租户查询，上下文通过 WithContext 传入。
+func ScopeAllowed(allowed func(ctx context.Context) bool) ScopeOption {
+	return func(g *scopeGuard) {
+		g.allowed = allowed
+	}
+}
+
+// ScopeIgnoreDenied 调用方未获授权时忽略作用域而非返回错误，需与 ScopeAllowed 一起使用
+func ScopeIgnoreDenied() ScopeOption {
+	return func(g *scopeGuard) {
+		g.ignore = true
+	}
+}
+
+// scopeKey 作用域在注册表中的标识
+type scopeKey struct {
+	scopeType ScopeType
+	name      string
+}
+
+// newScopeGuard 按作用域配置项生成授权判断，未配置授权判断时返回 nil
+func newScopeGuard(opts []ScopeOption) *scopeGuard {
+	var guard scopeGuard
+	for _, opt := range opts {
+		opt(&guard)
+	}
+	if guard.allowed == nil {
+		return nil
+	}
+	return &guard
+}
+
+// guardScope 以授权判断包装作用域函数，未配置授权判断时原样返回
+func guardScope(scopeType ScopeType, name string, scope ParamScopeFunc, guard *scopeGuard) ParamScopeFunc {
+	if guard == nil {
+		return scope
+	}
+	return func(db *gorm.DB, values ...interface{}) *gorm.DB {
+		if guard.allowed(db.Statement.Context) {
+			return scope(db, values...)
+		}
+		if !guard.ignore {
+			db.AddError(&ScopeNotAllowedError{Type: scopeType, Name: name})
+		}
+		return db
+	}
+}
+
+// deniedScopes 返回调用方未获授权的作用域，按类型与名称排序，用于区分不同授权的调用方的缓存结果
+//
+// 授权判断在构建查询时执行，缓存命中时不会执行，因此缓存键需包含授权结果。
+func (r *ScopeRegistry) deniedScopes(ctx context.Context) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var denied []string
+	for key, guard := range r.guards {
+		if !guard.allowed(ctx) {
+			denied = append(denied, fmt.Sprintf("%d/%s", key.scopeType, key.name))
+		}
+	}
+	sort.Strings(denied)
+	return denied
+}
@@ -0,0 +1,279 @@
+package querybuild
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// bucketTimeLayout 时间分桶键的文本格式
+const bucketTimeLayout = "2006-01-02 15:04:05"
+
+// maxFilledBuckets 补齐空桶后允许的最大分桶数量
+const maxFilledBuckets = 10000
+
+// Histogram 分桶聚合条件
+type Histogram struct {
+	Field    string  `json:"field"`
+	Bucket   float64 `json:"bucket"`   // 数值分桶宽度
+	Interval string  `json:"interval"` // 时间分桶粒度：hour、day、month、year
+	Fill     bool    `json:"fill"`     // 补齐没有记录的桶，计数为 0
+}
+
+// HistogramBucket 分桶聚合结果，数值分桶的边界为 float64，时间分桶的边界为 time.Time
+type HistogramBucket struct {
+	Lower interface{} `json:"lower"` // 下界（包含）
+	Upper interface{} `json:"upper"` // 上界（不包含）
+	Count int64       `json:"count"`
+}
+
+// Histogram 按数值宽度或时间粒度统计当前过滤条件下的记录分布
+func (qb *QueryBuilder[T]) Histogram(req *FilterRequest, h Histogram) ([]HistogramBucket, error) {
+	query := qb.filterQuery(req)
+	column, err := qb.resolveField(query, h.Field)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		expr string
+		args []interface{}
+	)
+	switch {
+	case h.Interval != "":
+		expr, args, err = qb.timeBucketExpr(column, h.Interval, queryLocation(query))
+		if err != nil {
+			return nil, err
+		}
+	case h.Bucket > 0:
+		expr, args = qb.numberBucketExpr(column, h.Bucket)
+	default:
+		return nil, fmt.Errorf("histogram requires a positive bucket or an interval")
+	}
+
+	var rows []map[string]interface{}
+	err = query.
+		Select(fmt.Sprintf("%s AS bucket_key, COUNT(*) AS bucket_count", expr), args...).
+		Group("bucket_key").
+		Order("bucket_key ASC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make([]HistogramBucket, 0, len(rows))
+	for _, row := range rows {
+		bucket := HistogramBucket{Count: toInt64(row["bucket_count"])}
+		if h.Interval != "" {
+			lower, err := parseBucketTime(row["bucket_key"], queryLocation(query))
+			if err != nil {
+				return nil, err
+			}
+			bucket.Lower, bucket.Upper = lower, addInterval(lower, h.Interval)
+		} else {
+			lower := toFloat64(row["bucket_key"])
+			bucket.Lower, bucket.Upper = lower, lower+h.Bucket
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	if h.Fill {
+		if h.Interval != "" {
+			return qb.fillTimeBuckets(req, h, buckets, queryLocation(query))
+		}
+		return fillNumberBuckets(buckets, h.Bucket)
+	}
+	return buckets, nil
+}
+
+// fillTimeBuckets 补齐时间分桶之间没有记录的桶
+//
+// 请求限定了分桶字段的时间范围时补齐整个范围内的桶，否则补齐第一个与最后一个有记录的桶之间的桶。
+func (qb *QueryBuilder[T]) fillTimeBuckets(req *FilterRequest, h Histogram, buckets []HistogramBucket, loc *time.Location) ([]HistogramBucket, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	field := qb.schema.LookUpField(h.Field)
+	if field == nil || field.DBName == "" {
+		return nil, fmt.Errorf("gap filling requires a model field: %s", h.Field)
+	}
+	r, err := qb.timeRange(field, req, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	var start, end time.Time
+	if len(buckets) > 0 {
+		start, end = buckets[0].Lower.(time.Time), buckets[len(buckets)-1].Upper.(time.Time)
+	}
+	if r.From != nil {
+		if from := truncateInterval(*r.From, h.Interval, loc); start.IsZero() || from.Before(start) {
+			start = from
+		}
+	}
+	if r.To != nil && (end.IsZero() || r.To.After(end)) {
+		end = *r.To
+	}
+	if start.IsZero() || end.IsZero() {
+		return buckets, nil
+	}
+
+	counts := make(map[int64]int64, len(buckets))
+	for _, bucket := range buckets {
+		counts[bucket.Lower.(time.Time).Unix()] = bucket.Count
+	}
+	var filled []HistogramBucket
+	for lower := start; lower.Before(end); lower = addInterval(lower, h.Interval) {
+		if len(filled) == maxFilledBuckets {
+			return nil, fmt.Errorf("too many histogram buckets to fill, limit is %d", maxFilledBuckets)
+		}
+		upper := addInterval(lower, h.Interval)
+		filled = append(filled, HistogramBucket{Lower: lower, Upper: upper, Count: counts[lower.Unix()]})
+	}
+	return filled, nil
+}
+
+// fillNumberBuckets 补齐数值分桶之间没有记录的桶
+func fillNumberBuckets(buckets []HistogramBucket, size float64) ([]HistogramBucket, error) {
+	if len(buckets) == 0 {
+		return buckets, nil
+	}
+	index := func(lower interface{}) int64 {
+		return int64(math.Round(lower.(float64) / size))
+	}
+	first, last := index(buckets[0].Lower), index(buckets[len(buckets)-1].Lower)
+	if last-first >= maxFilledBuckets {
+		return nil, fmt.Errorf("too many histogram buckets to fill, limit is %d", maxFilledBuckets)
+	}
+
+	counts := make(map[int64]int64, len(buckets))
+	for _, bucket := range buckets {
+		counts[index(bucket.Lower)] = bucket.Count
+	}
+	filled := make([]HistogramBucket, 0, last-first+1)
+	for i := first; i <= last; i++ {
+		lower := float64(i) * size
+		filled = append(filled, HistogramBucket{Lower: lower, Upper: lower + size, Count: counts[i]})
+	}
+	return filled, nil
+}
+
+// truncateInterval 将时间截断到业务时区中所在时间分桶的下界
+func truncateInterval(t time.Time, interval string, loc *time.Location) time.Time {
+	t = t.In(loc)
+	switch interval {
+	case "hour":
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+	case "day":
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, loc)
+	}
+}
+
+// numberBucketExpr 生成数值向下取整分桶表达式
+func (qb *QueryBuilder[T]) numberBucketExpr(column string, size float64) (string, []interface{}) {
+	if qb.dialect() == dialectSQLite {
+		// SQLite 默认未启用 FLOOR，使用 CAST 截断并修正负数
+		x := fmt.Sprintf("(%s / ?)", column)
+		return fmt.Sprintf("(CAST(%s AS INTEGER) - (%s < CAST(%s AS INTEGER))) * ?", x, x, x),
+			[]interface{}{size, size, size, size}
+	}
+	return fmt.Sprintf("FLOOR(%s / ?) * ?", column), []interface{}{size, size}
+}
+
+// timeBucketExpr 生成时间截断分桶表达式，loc 不为空时先将时间转换到业务时区
+func (qb *QueryBuilder[T]) timeBucketExpr(column, interval string, loc *time.Location) (string, []interface{}, error) {
+	formats := map[string]string{
+		"hour":  "%Y-%m-%d %H:00:00",
+		"day":   "%Y-%m-%d 00:00:00",
+		"month": "%Y-%m-01 00:00:00",
+		"year":  "%Y-01-01 00:00:00",
+	}
+	format, ok := formats[interval]
+	if !ok {
+		return "", nil, fmt.Errorf("invalid histogram interval: %s", interval)
+	}
+
+	var args []interface{}
+	switch qb.dialect() {
+	case dialectPostgres:
+		if loc != nil {
+			column += " AT TIME ZONE ?"
+			args = []interface{}{loc.String()}
+		}
+		return fmt.Sprintf("date_trunc('%s', %s)", interval, column), args, nil
+	case dialectMySQL:
+		if loc != nil {
+			column = fmt.Sprintf("CONVERT_TZ(%s, @@session.time_zone, ?)", column)
+			args = []interface{}{loc.String()}
+		}
+		return fmt.Sprintf("DATE_FORMAT(%s, '%s')", column, format), args, nil
+	default:
+		if loc != nil {
+			// SQLite 不支持时区名称，使用业务时区当前的UTC偏移
+			_, offset := time.Now().In(loc).Zone()
+			return fmt.Sprintf("strftime('%s', %s, ?)", format, column), []interface{}{fmt.Sprintf("%+d minutes", offset/60)}, nil
+		}
+		return fmt.Sprintf("strftime('%s', %s)", format, column), nil, nil
+	}
+}
+
+// addInterval 计算时间分桶的上界
+func addInterval(t time.Time, interval string) time.Time {
+	switch interval {
+	case "hour":
+		return t.Add(time.Hour)
+	case "day":
+		return t.AddDate(0, 0, 1)
+	case "month":
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(1, 0, 0)
+	}
+}
+
+// parseBucketTime 解析驱动返回的时间分桶键，loc 不为空时分桶键为业务时区的本地时间
+func parseBucketTime(v interface{}, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	switch t := v.(type) {
+	case time.Time:
+		if loc == time.UTC {
+			return t, nil
+		}
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc), nil
+	case []byte:
+		return time.ParseInLocation(bucketTimeLayout, string(t), loc)
+	case string:
+		return time.ParseInLocation(bucketTimeLayout, t, loc)
+	}
+	return time.Time{}, fmt.Errorf("invalid histogram bucket value: %v", v)
+}
+
+// toFloat64 将驱动返回的数值转换为 float64
+func toFloat64(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case float32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case int:
+		return float64(n)
+	case []byte:
+		f, _ := strconv.ParseFloat(string(n), 64)
+		return f
+	case string:
+		f, _ := strconv.ParseFloat(n, 64)
+		return f
+	}
+	return 0
+}
@@ -0,0 +1,93 @@
+package querybuild
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_Histogram(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+
+	t.Run("Numeric buckets", func(t *testing.T) {
+		buckets, err := builder.Histogram(&FilterRequest{}, Histogram{Field: "Age", Bucket: 10})
+		assert.NoError(t, err)
+		assert.Equal(t, []HistogramBucket{
+			{Lower: float64(20), Upper: float64(30), Count: 1},
+			{Lower: float64(30), Upper: float64(40), Count: 2},
+		}, buckets)
+	})
+
+	t.Run("Numeric buckets with filters", func(t *testing.T) {
+		buckets, err := builder.Histogram(&FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+		}, Histogram{Field: "Age", Bucket: 5})
+		assert.NoError(t, err)
+		assert.Len(t, buckets, 2)
+		assert.Equal(t, float64(25), buckets[0].Lower)
+		assert.Equal(t, float64(35), buckets[1].Lower)
+	})
+
+	t.Run("Time buckets", func(t *testing.T) {
+		assert.NoError(t, db.Create(&[]TestUser{
+			{Name: "Jan A", CreatedAt: time.Date(2024, 1, 5, 10, 0, 0, 0, time.UTC)},
+			{Name: "Jan B", CreatedAt: time.Date(2024, 1, 20, 10, 0, 0, 0, time.UTC)},
+			{Name: "Mar A", CreatedAt: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)},
+		}).Error)
+
+		buckets, err := builder.Histogram(&FilterRequest{
+			Filters: []Filter{{Field: "CreatedAt", Op: LT, Value: "2025-01-01"}},
+		}, Histogram{Field: "CreatedAt", Interval: "month"})
+		assert.NoError(t, err)
+		assert.Equal(t, []HistogramBucket{
+			{Lower: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Upper: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Count: 2},
+			{Lower: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Upper: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), Count: 1},
+		}, buckets)
+	})
+
+	t.Run("Gap filling", func(t *testing.T) {
+		buckets, err := builder.Histogram(&FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+		}, Histogram{Field: "Age", Bucket: 5, Fill: true})
+		assert.NoError(t, err)
+		assert.Equal(t, []HistogramBucket{
+			{Lower: float64(25), Upper: float64(30), Count: 1},
+			{Lower: float64(30), Upper: float64(35), Count: 0},
+			{Lower: float64(35), Upper: float64(40), Count: 1},
+		}, buckets)
+
+		month := func(m time.Month) time.Time { return time.Date(2024, m, 1, 0, 0, 0, 0, time.UTC) }
+		buckets, err = builder.Histogram(&FilterRequest{
+			Filters: []Filter{{Field: "CreatedAt", Op: LT, Value: "2024-04-15"}},
+			FilterGroups: []FilterGroup{
+				{Filters: []Filter{{Field: "CreatedAt", Op: GE, Value: "2023-12-10"}}},
+			},
+		}, Histogram{Field: "CreatedAt", Interval: "month", Fill: true})
+		assert.NoError(t, err)
+		assert.Equal(t, []HistogramBucket{
+			{Lower: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC), Upper: month(1), Count: 0},
+			{Lower: month(1), Upper: month(2), Count: 2},
+			{Lower: month(2), Upper: month(3), Count: 0},
+			{Lower: month(3), Upper: month(4), Count: 1},
+			{Lower: month(4), Upper: month(5), Count: 0},
+		}, buckets)
+
+		_, err = builder.Histogram(&FilterRequest{
+			Filters: []Filter{{Field: "CreatedAt", Op: BETWEEN, Value: "2000-01-01,2024-01-01"}},
+		}, Histogram{Field: "CreatedAt", Interval: "hour", Fill: true})
+		assert.ErrorContains(t, err, "too many histogram buckets")
+	})
+
+	t.Run("Invalid interval", func(t *testing.T) {
+		_, err := builder.Histogram(&FilterRequest{}, Histogram{Field: "CreatedAt", Interval: "fortnight"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid histogram interval")
+	})
+
+	t.Run("Missing bucket size", func(t *testing.T) {
+		_, err := builder.Histogram(&FilterRequest{}, Histogram{Field: "Age"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,122 @@
+package querybuild
+
+import (
+	"context"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// BeforeFindFunc 查询前处理请求的钩子，可修改请求，返回错误时终止查询
+type BeforeFindFunc func(ctx context.Context, req *FilterRequest) error
+
+// AfterFindFunc 查询后处理结果的钩子，返回处理后的记录，可用于装饰、脱敏或过滤记录
+type AfterFindFunc[T any] func(ctx context.Context, items []T) ([]T, error)
+
+// findHooks 查询前后的处理钩子与结果转换管道
+type findHooks[T any] struct {
+	before     []BeforeFindFunc
+	after      []AfterFindFunc[T]
+	transforms []namedTransform[T]
+	mu         sync.RWMutex
+}
+
+// clone 复制钩子列表
+func (h *findHooks[T]) clone() *findHooks[T] {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return &findHooks[T]{
+		before:     append([]BeforeFindFunc(nil), h.before...),
+		after:      append([]AfterFindFunc[T](nil), h.after...),
+		transforms: append([]namedTransform[T](nil), h.transforms...),
+	}
+}
+
+// BeforeFind 注册查询前处理请求的钩子，钩子按注册顺序执行
+//
+// 钩子在 FindAll、FindOne、FindPage 与 FindCursor 执行前调用，接收请求的副本。
+func (qb *QueryBuilder[T]) BeforeFind(fn BeforeFindFunc) {
+	qb.hooks.mu.Lock()
+	defer qb.hooks.mu.Unlock()
+	qb.hooks.before = append(qb.hooks.before, fn)
+}
+
+// AfterFind 注册查询后处理结果的钩子，钩子按注册顺序执行
+//
+// 钩子在 FindAll、FindOne、FindPage 与 FindCursor 取得结果后调用，FindAll 仅在目标为 *[]T 时调用。
+// 启用结果缓存时钩子在读取缓存后执行，缓存中保存的是未经处理的结果。
+func (qb *QueryBuilder[T]) AfterFind(fn AfterFindFunc[T]) {
+	qb.hooks.mu.Lock()
+	defer qb.hooks.mu.Unlock()
+	qb.hooks.after = append(qb.hooks.after, fn)
+}
+
+// beforeFind 依次执行查询前钩子，未注册钩子时原样返回请求
+func (qb *QueryBuilder[T]) beforeFind(req *FilterRequest) (*FilterRequest, error) {
+	qb.hooks.mu.RLock()
+	hooks := qb.hooks.before
+	qb.hooks.mu.RUnlock()
+	if len(hooks) == 0 {
+		return req, nil
+	}
+
+	hookReq := *req
+	hookReq.Filters = append([]Filter(nil), req.Filters...)
+	hookReq.Sorts = append([]Sort(nil), req.Sorts...)
+	for _, fn := range hooks {
+		if err := fn(qb.db.Statement.Context, &hookReq); err != nil {
+			return nil, err
+		}
+	}
+	return &hookReq, nil
+}
+
+// afterFind 解密加密字段、执行结果转换并应用脱敏函数后，依次执行查询后钩子
+func (qb *QueryBuilder[T]) afterFind(items []T) ([]T, error) {
+	if err := qb.decryptItems(items); err != nil {
+		return nil, err
+	}
+	if err := qb.applyTransforms(items); err != nil {
+		return nil, err
+	}
+	qb.maskItems(items)
+
+	qb.hooks.mu.RLock()
+	hooks := qb.hooks.after
+	qb.hooks.mu.RUnlock()
+
+	var err error
+	for _, fn := range hooks {
+		if items, err = fn(qb.db.Statement.Context, items); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+// afterFindDest 对 FindAll / FindOne 的目标执行查询后钩子，目标不是 *[]T 或 *T 时跳过
+//
+// 单条记录被钩子过滤时返回 gorm.ErrRecordNotFound。
+func (qb *QueryBuilder[T]) afterFindDest(dest interface{}) error {
+	switch dest := dest.(type) {
+	case *[]T:
+		items, err := qb.afterFind(*dest)
+		if err != nil {
+			return err
+		}
+		*dest = items
+	case *T:
+		items, err := qb.afterFind([]T{*dest})
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			var zero T
+			*dest = zero
+			return gorm.ErrRecordNotFound
+		}
+		*dest = items[0]
+	}
+	return nil
+}
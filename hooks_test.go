@@ -0,0 +1,71 @@
+package querybuild
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestQueryBuilder_FindHooks(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+
+	builder.BeforeFind(func(ctx context.Context, req *FilterRequest) error {
+		req.Filters = append(req.Filters, Filter{Field: "Status", Op: EQ, Value: "active"})
+		return nil
+	})
+	builder.AfterFind(func(ctx context.Context, users []TestUser) ([]TestUser, error) {
+		for i := range users {
+			users[i].Email = ""
+		}
+		return users, nil
+	})
+
+	t.Run("FindAll", func(t *testing.T) {
+		req := &FilterRequest{}
+		var users []TestUser
+		assert.NoError(t, builder.FindAll(req, &users))
+		assert.Len(t, users, 2)
+		for _, user := range users {
+			assert.Equal(t, "active", user.Status)
+			assert.Empty(t, user.Email)
+		}
+		assert.Empty(t, req.Filters, "request is not modified")
+	})
+
+	t.Run("FindPage", func(t *testing.T) {
+		req := &FilterRequest{Page: &Pagination{Page: 1, PageSize: 10}}
+		result, err := builder.FindPage(req)
+		assert.NoError(t, err)
+		assert.Len(t, result.Items, 2)
+		assert.Empty(t, result.Items[0].Email)
+		assert.Equal(t, int64(2), req.Page.Total)
+	})
+
+	t.Run("Filtered single record", func(t *testing.T) {
+		clone := builder.Clone()
+		clone.AfterFind(func(ctx context.Context, users []TestUser) ([]TestUser, error) {
+			return users[:0], nil
+		})
+
+		var user TestUser
+		err := clone.FindOne(&FilterRequest{}, &user)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		assert.NoError(t, builder.FindOne(&FilterRequest{}, &user), "clone hooks do not affect the original")
+	})
+
+	t.Run("Hook errors", func(t *testing.T) {
+		errDenied := errors.New("denied")
+		clone := builder.Clone()
+		clone.BeforeFind(func(ctx context.Context, req *FilterRequest) error {
+			return errDenied
+		})
+
+		var users []TestUser
+		assert.ErrorIs(t, clone.FindAll(&FilterRequest{}, &users), errDenied)
+	})
+}
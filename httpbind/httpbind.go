@@ -0,0 +1,143 @@
+// Package httpbind 从 HTTP 请求解析并校验过滤请求
+//
+// 中间件基于 net/http，gin 可通过 c.Request 调用 Bind，echo 可通过 echo.WrapMiddleware 使用 Middleware。
+package httpbind
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"pkg.blksails.net/x/querybuild"
+)
+
+// Validator 过滤请求校验，*querybuild.QueryBuilder 实现了该接口
+type Validator interface {
+	Validate(req *querybuild.FilterRequest) error
+}
+
+// ErrorHandler 解析或校验失败时的错误处理函数
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+
+// DefaultMaxBodyBytes JSON 请求体默认的大小上限
+const DefaultMaxBodyBytes int64 = 1 << 20
+
+// Option 解析过滤请求的选项
+type Option func(*options)
+
+// options 解析过滤请求的配置
+type options struct {
+	maxBodyBytes int64 // JSON 请求体的大小上限，小于等于 0 时不限制
+}
+
+// WithMaxBodyBytes 指定 JSON 请求体的大小上限，默认为 DefaultMaxBodyBytes，小于等于 0 时不限制
+//
+// 请求体超过上限时解析失败，返回的错误包装了 *http.MaxBytesError。
+func WithMaxBodyBytes(n int64) Option {
+	return func(o *options) {
+		o.maxBodyBytes = n
+	}
+}
+
+// newOptions 应用选项生成配置
+func newOptions(opts []Option) options {
+	o := options{maxBodyBytes: DefaultMaxBodyBytes}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// requestKey 过滤请求的上下文键
+type requestKey struct{}
+
+// NewContext 返回记录过滤请求的上下文
+func NewContext(ctx context.Context, req *querybuild.FilterRequest) context.Context {
+	return context.WithValue(ctx, requestKey{}, req)
+}
+
+// FromContext 获取上下文中记录的过滤请求
+func FromContext(ctx context.Context) (*querybuild.FilterRequest, bool) {
+	req, ok := ctx.Value(requestKey{}).(*querybuild.FilterRequest)
+	return req, ok
+}
+
+// Decode 从 HTTP 请求解析过滤请求
+//
+// 请求体为 JSON 时从请求体解析，否则从URL查询参数解析，参数格式见 querybuild.ParseQuery。
+// 请求体的大小上限通过 WithMaxBodyBytes 指定。
+func Decode(r *http.Request, opts ...Option) (*querybuild.FilterRequest, error) {
+	if r.Body != nil && r.Body != http.NoBody && isJSON(r.Header.Get("Content-Type")) {
+		body := r.Body
+		if o := newOptions(opts); o.maxBodyBytes > 0 {
+			body = http.MaxBytesReader(nil, body, o.maxBodyBytes)
+		}
+		var req querybuild.FilterRequest
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			return nil, fmt.Errorf("invalid filter request body: %w", err)
+		}
+		return &req, nil
+	}
+	return querybuild.ParseQuery(r.URL.Query())
+}
+
+// Bind 从 HTTP 请求解析过滤请求，并按构建器的配置校验
+func Bind(r *http.Request, v Validator, opts ...Option) (*querybuild.FilterRequest, error) {
+	req, err := Decode(r, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.Validate(req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Middleware 返回解析并校验过滤请求的中间件，成功时将过滤请求记录到请求上下文，处理函数通过 FromContext 获取
+//
+// onError 为空时使用 WriteError。
+func Middleware(v Validator, onError ErrorHandler, opts ...Option) func(http.Handler) http.Handler {
+	if onError == nil {
+		onError = WriteError
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			req, err := Bind(r, v, opts...)
+			if err != nil {
+				onError(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), req)))
+		})
+	}
+}
+
+// errorResponse 默认错误响应
+type errorResponse struct {
+	Error   string   `json:"error"`
+	Details []string `json:"details,omitempty"` // 校验发现的全部问题
+}
+
+// WriteError 以 400 状态码和 JSON 格式写入错误，校验错误逐条列出在 details 中
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	resp := errorResponse{Error: err.Error()}
+	var verr *querybuild.ValidationError
+	if errors.As(err, &verr) {
+		resp.Error = "invalid filter request"
+		for _, e := range verr.Errors {
+			resp.Details = append(resp.Details, e.Error())
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// isJSON 判断内容类型是否为 JSON
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/json"
+}
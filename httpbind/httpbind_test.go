@@ -0,0 +1,99 @@
+package httpbind
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"pkg.blksails.net/x/querybuild"
+)
+
+type user struct {
+	ID     uint
+	Name   string
+	Status string
+}
+
+func TestMiddleware(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	builder := querybuild.NewQueryBuilder[user](db)
+
+	var got *querybuild.FilterRequest
+	handler := Middleware(builder, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = FromContext(r.Context())
+	}))
+
+	t.Run("Query string", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users?filter=Status:eq:active&page=1", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []querybuild.Filter{{Field: "Status", Op: querybuild.EQ, Value: "active"}}, got.Filters)
+		assert.Equal(t, 1, got.Page.Page)
+	})
+
+	t.Run("JSON body", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/users/search", strings.NewReader(`{"sorts":[{"field":"Name","desc":true}]}`))
+		r.Header.Set("Content-Type", "application/json; charset=utf-8")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []querybuild.Sort{{Field: "Name", Desc: true}}, got.Sorts)
+	})
+
+	t.Run("Validation errors", func(t *testing.T) {
+		got = nil
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users?filter=Password:eq:x&sort=Unknown", nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Nil(t, got)
+
+		var resp errorResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Len(t, resp.Details, 2)
+	})
+
+	t.Run("Body too large", func(t *testing.T) {
+		got = nil
+		limited := Middleware(builder, nil, WithMaxBodyBytes(16))(http.NotFoundHandler())
+		r := httptest.NewRequest(http.MethodPost, "/users/search", strings.NewReader(`{"sorts":[{"field":"Name","desc":true}]}`))
+		r.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		limited.ServeHTTP(rec, r)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Nil(t, got)
+	})
+}
+
+func TestDecodeBodyLimit(t *testing.T) {
+	newRequest := func(body string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/users/search", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		return r
+	}
+	body := `{"filters":[{"field":"Name","op":0,"value":"` + strings.Repeat("a", 64) + `"}]}`
+
+	req, err := Decode(newRequest(body))
+	assert.NoError(t, err)
+	assert.Len(t, req.Filters, 1)
+
+	var maxErr *http.MaxBytesError
+	_, err = Decode(newRequest(body), WithMaxBodyBytes(32))
+	assert.True(t, errors.As(err, &maxErr))
+	assert.Equal(t, int64(32), maxErr.Limit)
+
+	// 默认上限同样生效
+	large := `{"filters":[{"field":"Name","op":0,"value":"` + strings.Repeat("a", int(DefaultMaxBodyBytes)) + `"}]}`
+	_, err = Decode(newRequest(large))
+	assert.True(t, errors.As(err, &maxErr))
+
+	_, err = Decode(newRequest(large), WithMaxBodyBytes(0))
+	assert.NoError(t, err)
+}
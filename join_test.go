@@ -0,0 +1,66 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJoinBuilder(t *testing.T) {
+	db := setupTestDB(t)
+	users := NewQueryBuilder[TestUser](db)
+	orders := NewQueryBuilder[TestOrder](db)
+
+	t.Run("Inner join with filters on both sides", func(t *testing.T) {
+		jb := NewJoinBuilder(users, orders, JoinOn{Left: "ID", Right: "UserID"})
+		rows, err := jb.Find(
+			&FilterRequest{Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}}},
+			&FilterRequest{
+				Filters: []Filter{{Field: "Status", Op: EQ, Value: "paid"}},
+				Sorts:   []Sort{{Field: "Amount", Desc: true}},
+			},
+		)
+		assert.NoError(t, err)
+		assert.Len(t, rows, 2)
+		assert.Equal(t, "John Doe", rows[0].Left.Name)
+		assert.Equal(t, float64(100), rows[0].Right.Amount)
+		assert.Equal(t, "paid", rows[0].Right.Status)
+		assert.Equal(t, rows[0].Left.ID, rows[0].Right.UserID)
+	})
+
+	t.Run("Left join keeps unmatched rows", func(t *testing.T) {
+		jb := NewJoinBuilder(users, orders, JoinOn{Left: "ID", Right: "UserID"}).LeftJoin()
+		rows, err := jb.Find(&FilterRequest{
+			Filters: []Filter{{Field: "Name", Op: EQ, Value: "Bob Johnson"}},
+		}, nil)
+		assert.NoError(t, err)
+		assert.Len(t, rows, 1)
+		assert.Equal(t, "Bob Johnson", rows[0].Left.Name)
+		assert.Zero(t, rows[0].Right.ID)
+	})
+
+	t.Run("Pagination from left request", func(t *testing.T) {
+		jb := NewJoinBuilder(users, orders, JoinOn{Left: "ID", Right: "UserID"})
+		req := &FilterRequest{Page: &Pagination{Page: 1, PageSize: 2}}
+		rows, err := jb.Find(req, nil)
+		assert.NoError(t, err)
+		assert.Len(t, rows, 2)
+		assert.Equal(t, int64(3), req.Page.Total)
+	})
+
+	t.Run("Invalid join key", func(t *testing.T) {
+		jb := NewJoinBuilder(users, orders, JoinOn{Left: "ID", Right: "OwnerID"})
+		_, err := jb.Find(nil, nil)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid field name")
+	})
+
+	t.Run("Right side fields are validated against right model", func(t *testing.T) {
+		jb := NewJoinBuilder(users, orders, JoinOn{Left: "ID", Right: "UserID"})
+		_, err := jb.Find(nil, &FilterRequest{
+			Filters: []Filter{{Field: "Email", Op: EQ, Value: "john@example.com"}},
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid field name")
+	})
+}
@@ -0,0 +1,136 @@
+package querybuild
+
+import "sort"
+
+// qualifiedFieldPattern 连接表字段 alias.column 的格式
+const qualifiedFieldPattern = `^[A-Za-z_][A-Za-z0-9_]*\.[A-Za-z_][A-Za-z0-9_]*$`
+
+// RequestSchema 生成针对当前模型与构建器配置的 FilterRequest JSON Schema
+//
+// 生成的 Schema 符合 JSON Schema 2020-12，可直接作为 OpenAPI 3.1 的组件使用。
+// 字段名限定为模型字段或 alias.column 形式的连接表字段，配置了排序白名单时排序字段限定为白名单字段，
+// 排序规则限定为 WithCollation 注册的名称，每页数量与条目数量按 WithMaxPageSize 与 WithLimits 的配置约束。
+func (qb *QueryBuilder[T]) RequestSchema() map[string]interface{} {
+	meta := qb.Metadata()
+
+	fieldNames := make([]string, 0, len(meta.Fields))
+	sortNames := make([]string, 0, len(meta.Fields))
+	for _, field := range meta.Fields {
+		fieldNames = append(fieldNames, field.Name)
+		if field.Sortable {
+			sortNames = append(sortNames, field.Name)
+		}
+	}
+	fieldSchema := map[string]interface{}{
+		"anyOf": []interface{}{
+			map[string]interface{}{"enum": fieldNames},
+			map[string]interface{}{"type": "string", "pattern": qualifiedFieldPattern},
+		},
+	}
+	sortFieldSchema := fieldSchema
+	if qb.opts.sortFields != nil {
+		sortFieldSchema = map[string]interface{}{"enum": sortNames}
+	}
+
+	collations := make([]string, 0, len(qb.opts.collations))
+	for name := range qb.opts.collations {
+		collations = append(collations, name)
+	}
+	sort.Strings(collations)
+
+	ops := make([]interface{}, 0, lastOperator+1)
+	names := make([]interface{}, 0, cap(ops))
+	for op := EQ; op <= lastOperator; op++ {
+		ops = append(ops, int(op))
+		names = append(names, op.String())
+	}
+
+	filters := arraySchema(map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"field":  fieldSchema,
+			"op":     map[string]interface{}{"type": "integer", "enum": ops, "x-enum-varnames": names},
+			"value":  map[string]interface{}{"type": "string"},
+			"nocase": map[string]interface{}{"type": "boolean"},
+			"fn":     map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"field", "op"},
+	})
+	sorts := arraySchema(map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"field":     sortFieldSchema,
+			"desc":      map[string]interface{}{"type": "boolean"},
+			"nocase":    map[string]interface{}{"type": "boolean"},
+			"scope":     map[string]interface{}{"type": "string"},
+			"count_of":  map[string]interface{}{"type": "string"},
+			"values":    map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+			"collation": map[string]interface{}{"type": "string", "enum": collations},
+		},
+	})
+	joins := arraySchema(map[string]interface{}{"type": "object"})
+
+	pageSize := map[string]interface{}{"type": "integer", "minimum": 0, "default": meta.DefaultPageSize}
+	if meta.MaxPageSize > 0 {
+		pageSize["maximum"] = meta.MaxPageSize
+	}
+	page := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"page":      map[string]interface{}{"type": "integer", "minimum": 0, "default": 1},
+			"page_size": pageSize,
+			"deferred":  map[string]interface{}{"type": "boolean"},
+		},
+	}
+
+	if meta.Limits != nil {
+		setMaxItems(filters, meta.Limits.MaxFilters)
+		setMaxItems(sorts, meta.Limits.MaxSorts)
+		setMaxItems(joins, meta.Limits.MaxJoins)
+	}
+
+	return map[string]interface{}{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title":   meta.Model + " filter request",
+		"type":    "object",
+		"properties": map[string]interface{}{
+			"filters":        filters,
+			"sorts":          sorts,
+			"page":           page,
+			"joins":          joins,
+			"custom_fields":  arraySchema(map[string]interface{}{"type": "object"}),
+			"custom_filter":  map[string]interface{}{"type": []string{"object", "null"}},
+			"custom_filters": arraySchema(map[string]interface{}{"type": "object"}),
+			"aggrs":          arraySchema(map[string]interface{}{"type": "object"}),
+			"groups":         arraySchema(map[string]interface{}{"type": "object"}),
+			"sub_query":      map[string]interface{}{"type": []string{"object", "null"}},
+			"filter_groups":  arraySchema(map[string]interface{}{"type": "object"}),
+			"tuple_filters":  arraySchema(map[string]interface{}{"type": "object"}),
+			"exists":         arraySchema(map[string]interface{}{"type": "object"}),
+			"count_filters":  arraySchema(map[string]interface{}{"type": "object"}),
+			"distinct":       map[string]interface{}{"type": "boolean"},
+			"dedupe_by_pk":   map[string]interface{}{"type": "boolean"},
+			"time_zone":      map[string]interface{}{"type": "string"},
+			"unscoped":       map[string]interface{}{"type": "boolean"},
+			"sample": map[string]interface{}{
+				"type": []string{"object", "null"},
+				"properties": map[string]interface{}{
+					"percent": map[string]interface{}{"type": "number", "exclusiveMinimum": 0, "maximum": 100},
+					"rows":    map[string]interface{}{"type": "integer", "minimum": 1},
+				},
+			},
+		},
+	}
+}
+
+// arraySchema 生成元素为 items 的数组 Schema
+func arraySchema(items map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": []string{"array", "null"}, "items": items}
+}
+
+// setMaxItems 为数组 Schema 设置最大元素数量，max 小于等于 0 时不限制
+func setMaxItems(array map[string]interface{}, max int) {
+	if max > 0 {
+		array["maxItems"] = max
+	}
+}
@@ -0,0 +1,41 @@
+package querybuild
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_RequestSchema(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db,
+		WithMaxPageSize(100),
+		WithLimits(Limits{MaxFilters: 10}),
+		AllowSortFields("Name"),
+	)
+
+	s := builder.RequestSchema()
+	_, err := json.Marshal(s)
+	assert.NoError(t, err)
+
+	props := s["properties"].(map[string]interface{})
+
+	filters := props["filters"].(map[string]interface{})
+	assert.Equal(t, 10, filters["maxItems"])
+	filter := filters["items"].(map[string]interface{})["properties"].(map[string]interface{})
+	fieldNames := filter["field"].(map[string]interface{})["anyOf"].([]interface{})[0].(map[string]interface{})["enum"]
+	assert.Contains(t, fieldNames, "Email")
+	assert.NotContains(t, fieldNames, "Orders")
+	op := filter["op"].(map[string]interface{})
+	assert.Contains(t, op["x-enum-varnames"], "BETWEEN")
+	assert.Len(t, op["enum"], int(lastOperator)+1)
+
+	sorts := props["sorts"].(map[string]interface{})
+	assert.NotContains(t, sorts, "maxItems")
+	sortField := sorts["items"].(map[string]interface{})["properties"].(map[string]interface{})["field"]
+	assert.Equal(t, map[string]interface{}{"enum": []string{"ID", "Name"}}, sortField)
+
+	page := props["page"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, 100, page["page_size"].(map[string]interface{})["maximum"])
+}
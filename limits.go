@@ -0,0 +1,77 @@
+package querybuild
+
+import (
+	"errors"
+	"strings"
+)
+
+// Limits 单个请求中各类条目的数量上限，0 表示不限制
+type Limits struct {
+	MaxFilters  int `json:"max_filters"`   // 过滤条件数量，包括条件组、组合键、关联存在性与数量过滤及其中的条件
+	MaxSorts    int `json:"max_sorts"`     // 排序数量
+	MaxJoins    int `json:"max_joins"`     // 连接数量
+	MaxInValues int `json:"max_in_values"` // 单个 IN / NOT IN 条件的取值数量，组合取值条件按元组计数
+}
+
+// DefaultLimits 建议用于外部请求的数量上限
+var DefaultLimits = Limits{
+	MaxFilters:  20,
+	MaxSorts:    5,
+	MaxJoins:    3,
+	MaxInValues: 500,
+}
+
+// limitErrors 返回请求超出数量上限的全部问题
+func (qb *QueryBuilder[T]) limitErrors(req *FilterRequest) []error {
+	limits := qb.opts.limits
+	if limits == nil {
+		return nil
+	}
+
+	var errs []error
+	check := func(kind string, count, max int) {
+		if max > 0 && count > max {
+			errs = append(errs, &LimitExceededError{Kind: kind, Count: count, Max: max})
+		}
+	}
+	grouped := groupFilters(req.FilterGroups)
+	relations, related := relationFilters(req.Exists, req.CountFilters)
+	check("filters", len(req.Filters)+len(grouped)+len(req.TupleFilters)+relations+len(related), limits.MaxFilters)
+	check("sorts", len(req.Sorts), limits.MaxSorts)
+	check("joins", len(req.Joins), limits.MaxJoins)
+	for _, filters := range [][]Filter{req.Filters, grouped, related} {
+		for _, filter := range filters {
+			if filter.Op == IN || filter.Op == NOT_IN {
+				check("in values", strings.Count(filter.Value, ",")+1, limits.MaxInValues)
+			}
+		}
+	}
+	for _, tuple := range req.TupleFilters {
+		check("in values", len(tuple.Values), limits.MaxInValues)
+	}
+	return errs
+}
+
+// relationFilters 返回关联存在性与数量过滤条件的数量（包括嵌套的关联存在性过滤）及其中的全部过滤条件
+func relationFilters(exists []ExistsFilter, counts []CountFilter) (int, []Filter) {
+	n := len(exists) + len(counts)
+	var filters []Filter
+	add := func(req FilterRequest) {
+		nested, nestedFilters := relationFilters(req.Exists, nil)
+		n += nested
+		filters = append(filters, req.Filters...)
+		filters = append(filters, nestedFilters...)
+	}
+	for _, e := range exists {
+		add(e.Filter)
+	}
+	for _, c := range counts {
+		add(c.Filter)
+	}
+	return n, filters
+}
+
+// checkLimits 校验请求的数量上限
+func (qb *QueryBuilder[T]) checkLimits(req *FilterRequest) error {
+	return errors.Join(qb.limitErrors(req)...)
+}
@@ -0,0 +1,70 @@
+package querybuild
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_WithLimits(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db, WithLimits(Limits{MaxFilters: 2, MaxSorts: 1, MaxInValues: 3}))
+
+	t.Run("Within limits", func(t *testing.T) {
+		var users []TestUser
+		err := builder.Build(&FilterRequest{
+			Filters: []Filter{{Field: "Name", Op: IN, Value: "John Doe,Jane Smith,Bob Johnson"}},
+			Sorts:   []Sort{{Field: "Age"}},
+		}).Find(&users).Error
+		assert.NoError(t, err)
+		assert.Len(t, users, 3)
+	})
+
+	t.Run("Build rejects request", func(t *testing.T) {
+		var users []TestUser
+		err := builder.Build(&FilterRequest{
+			Filters: []Filter{{Field: "Name", Op: IN, Value: strings.Repeat("x,", 3) + "x"}},
+		}).Find(&users).Error
+		var limitErr *LimitExceededError
+		assert.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, "in values", limitErr.Kind)
+		assert.Equal(t, 4, limitErr.Count)
+	})
+
+	t.Run("Relation filters", func(t *testing.T) {
+		err := builder.Validate(&FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+			Exists:  []ExistsFilter{{Relation: "Orders", Filter: FilterRequest{Filters: []Filter{{Field: "Status", Op: EQ, Value: "paid"}}}}},
+		})
+		var validationErr *ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Contains(t, err.Error(), "too many filters: 3 exceeds limit 2")
+
+		var users []TestUser
+		err = builder.Build(&FilterRequest{
+			CountFilters: []CountFilter{{Relation: "Orders", Op: GE, Value: "1", Filter: FilterRequest{
+				Filters: []Filter{{Field: "Status", Op: IN, Value: "paid,pending,refunded,void"}},
+			}}},
+		}).Find(&users).Error
+		var limitErr *LimitExceededError
+		assert.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, "in values", limitErr.Kind)
+	})
+
+	t.Run("Validate reports all limits", func(t *testing.T) {
+		err := builder.Validate(&FilterRequest{
+			Filters: []Filter{
+				{Field: "Status", Op: EQ, Value: "active"},
+				{Field: "Age", Op: GT, Value: "1"},
+				{Field: "Age", Op: LT, Value: "100"},
+			},
+			Sorts: []Sort{{Field: "Age"}, {Field: "Name"}},
+		})
+		var validationErr *ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Len(t, validationErr.Errors, 2)
+		assert.Contains(t, err.Error(), "too many filters: 3 exceeds limit 2")
+		assert.Contains(t, err.Error(), "too many sorts: 2 exceeds limit 1")
+	})
+}
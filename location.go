@@ -0,0 +1,182 @@
+package querybuild
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// locationKey 查询使用的业务时区在 gorm 实例中的键
+const locationKey = "querybuild:location"
+
+// dateLayout 仅包含日期的时间取值格式
+const dateLayout = "2006-01-02"
+
+// requestLocation 返回请求使用的业务时区，请求未指定时使用 WithLocation 配置，均未配置时返回 nil
+func (qb *QueryBuilder[T]) requestLocation(req *FilterRequest) (*time.Location, error) {
+	if req != nil && req.TimeZone != "" {
+		loc, err := time.LoadLocation(req.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone: %s", req.TimeZone)
+		}
+		return loc, nil
+	}
+	return qb.opts.location, nil
+}
+
+// withLocation 记录查询使用的业务时区，loc 为空时不做处理
+func withLocation(query *gorm.DB, loc *time.Location) *gorm.DB {
+	if loc == nil {
+		return query
+	}
+	return query.InstanceSet(locationKey, loc)
+}
+
+// queryLocation 返回查询使用的业务时区，未配置时返回 nil
+func queryLocation(query *gorm.DB) *time.Location {
+	if v, ok := query.InstanceGet(locationKey); ok {
+		return v.(*time.Location)
+	}
+	return nil
+}
+
+// isTimeFilter 判断过滤条件是否为需按业务时区解析取值的时间字段比较条件
+func (qb *QueryBuilder[T]) isTimeFilter(query *gorm.DB, filter Filter) bool {
+	switch filter.Op {
+	case EQ, NE, GT, GE, LT, LE, BETWEEN, IN, NOT_IN, NOT_BETWEEN, GT_OR_NULL, GE_OR_NULL, LT_OR_NULL, LE_OR_NULL:
+	default:
+		return false
+	}
+	if queryLocation(query) == nil {
+		return false
+	}
+	field := qb.schema.LookUpField(filter.Field)
+	return field != nil && field.DBName != "" && field.DataType == schema.Time
+}
+
+// orNullOperators 可为空的比较操作符对应的比较操作符
+var orNullOperators = map[Operator]Operator{
+	GT_OR_NULL: GT,
+	GE_OR_NULL: GE,
+	LT_OR_NULL: LT,
+	LE_OR_NULL: LE,
+}
+
+// parseTimeIn 按支持的格式解析时间取值，不含时区的取值按 loc 解释，dateOnly 表示取值仅包含日期
+func parseTimeIn(value string, loc *time.Location) (t time.Time, dateOnly bool, ok bool) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, layout == dateLayout, true
+		}
+	}
+	return time.Time{}, false, false
+}
+
+// timeCondition 构建时间字段的过滤条件，取值按业务时区解析后以时间绑定
+//
+// 仅包含日期的取值表示业务时区中的一整天：EQ 与 IN 匹配当天内的时间，LE 与 BETWEEN 的上界包含当天，GT 从次日开始，
+// 否定与可为空的操作符按相同的日期范围取反或追加空值条件。
+func timeCondition(field string, filter Filter, loc *time.Location) (string, []interface{}, error) {
+	parse := func(value string) (time.Time, bool, error) {
+		t, dateOnly, ok := parseTimeIn(value, loc)
+		if !ok {
+			return time.Time{}, false, &InvalidValueError{Field: filter.Field, Value: value, Reason: "not a time"}
+		}
+		return t, dateOnly, nil
+	}
+	nextDay := func(t time.Time) time.Time {
+		return t.AddDate(0, 0, 1)
+	}
+
+	if base, ok := orNullOperators[filter.Op]; ok {
+		filter.Op = base
+		cond, args, err := timeCondition(field, filter, loc)
+		if err != nil {
+			return "", nil, err
+		}
+		return "(" + cond + " OR " + field + " IS NULL)", args, nil
+	}
+
+	switch filter.Op {
+	case IN, NOT_IN:
+		// 精确时间以 IN 列表匹配，仅包含日期的取值与 EQ 相同匹配当天内的时间
+		var (
+			values []time.Time
+			conds  []string
+			args   []interface{}
+		)
+		for _, value := range strings.Split(filter.Value, ",") {
+			t, dateOnly, err := parse(value)
+			if err != nil {
+				return "", nil, err
+			}
+			if !dateOnly {
+				values = append(values, t.UTC())
+				continue
+			}
+			if filter.Op == IN {
+				conds = append(conds, "("+field+" >= ? AND "+field+" < ?)")
+			} else {
+				conds = append(conds, "("+field+" < ? OR "+field+" >= ?)")
+			}
+			args = append(args, t.UTC(), nextDay(t).UTC())
+		}
+		if len(values) > 0 {
+			if filter.Op == IN {
+				conds = append([]string{field + " IN (?)"}, conds...)
+			} else {
+				conds = append([]string{field + " NOT IN (?)"}, conds...)
+			}
+			args = append([]interface{}{values}, args...)
+		}
+		if len(conds) == 1 {
+			return conds[0], args, nil
+		}
+		if filter.Op == IN {
+			return "(" + strings.Join(conds, " OR ") + ")", args, nil
+		}
+		return "(" + strings.Join(conds, " AND ") + ")", args, nil
+	case BETWEEN, NOT_BETWEEN:
+		lower, upper, ok := strings.Cut(filter.Value, ",")
+		if !ok || strings.Contains(upper, ",") {
+			return "", nil, &InvalidValueError{Field: filter.Field, Value: filter.Value, Reason: "not a time range"}
+		}
+		from, _, err := parse(lower)
+		if err != nil {
+			return "", nil, err
+		}
+		to, dateOnly, err := parse(upper)
+		if err != nil {
+			return "", nil, err
+		}
+		if dateOnly {
+			to = nextDay(to)
+			if filter.Op == NOT_BETWEEN {
+				return "(" + field + " < ? OR " + field + " >= ?)", []interface{}{from.UTC(), to.UTC()}, nil
+			}
+			return field + " >= ? AND " + field + " < ?", []interface{}{from.UTC(), to.UTC()}, nil
+		}
+		return conditionSQL(field, filter), []interface{}{from.UTC(), to.UTC()}, nil
+	}
+
+	t, dateOnly, err := parse(filter.Value)
+	if err != nil {
+		return "", nil, err
+	}
+	if dateOnly {
+		switch filter.Op {
+		case EQ:
+			return field + " >= ? AND " + field + " < ?", []interface{}{t.UTC(), nextDay(t).UTC()}, nil
+		case NE:
+			return "(" + field + " < ? OR " + field + " >= ?)", []interface{}{t.UTC(), nextDay(t).UTC()}, nil
+		case GT:
+			return field + " >= ?", []interface{}{nextDay(t).UTC()}, nil
+		case LE:
+			return field + " < ?", []interface{}{nextDay(t).UTC()}, nil
+		}
+	}
+	return field + operatorSQL[filter.Op], []interface{}{t.UTC()}, nil
+}
@@ -0,0 +1,92 @@
+package querybuild
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_Location(t *testing.T) {
+	db := setupTestDB(t)
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	assert.NoError(t, err)
+
+	// 2024-01-01 在上海为 2023-12-31T16:00:00Z 至 2024-01-01T16:00:00Z
+	assert.NoError(t, db.Where("1 = 1").Delete(&TestUser{}).Error)
+	for name, at := range map[string]string{
+		"before": "2023-12-31T15:30:00Z",
+		"start":  "2023-12-31T16:30:00Z",
+		"end":    "2024-01-01T15:30:00Z",
+		"after":  "2024-01-01T16:30:00Z",
+	} {
+		created, err := time.Parse(time.RFC3339, at)
+		assert.NoError(t, err)
+		assert.NoError(t, db.Create(&TestUser{Name: name, CreatedAt: created.UTC()}).Error)
+	}
+
+	builder := NewQueryBuilder[TestUser](db, WithLocation(shanghai))
+	names := func(t *testing.T, b *QueryBuilder[TestUser], req *FilterRequest) []string {
+		req.Sorts = []Sort{{Field: "CreatedAt"}}
+		var users []TestUser
+		assert.NoError(t, b.FindAll(req, &users))
+		var result []string
+		for _, user := range users {
+			result = append(result, user.Name)
+		}
+		return result
+	}
+
+	t.Run("Date only", func(t *testing.T) {
+		tests := []struct {
+			op    Operator
+			value string
+			want  []string
+		}{
+			{op: EQ, value: "2024-01-01", want: []string{"start", "end"}},
+			{op: NE, value: "2024-01-01", want: []string{"before", "after"}},
+			{op: GT, value: "2024-01-01", want: []string{"after"}},
+			{op: LE, value: "2023-12-31", want: []string{"before"}},
+			{op: BETWEEN, value: "2023-12-31,2024-01-01", want: []string{"before", "start", "end"}},
+			{op: NOT_BETWEEN, value: "2024-01-01,2024-01-01", want: []string{"before", "after"}},
+			{op: LT_OR_NULL, value: "2024-01-01", want: []string{"before"}},
+			{op: IN, value: "2024-01-01", want: []string{"start", "end"}},
+			{op: IN, value: "2023-12-31,2024-01-01T16:30:00+08:00", want: []string{"before"}},
+			{op: IN, value: "2023-12-31T23:30:00+08:00,2024-01-02", want: []string{"before", "after"}},
+			{op: NOT_IN, value: "2024-01-01", want: []string{"before", "after"}},
+			{op: NOT_IN, value: "2024-01-01,2024-01-02T00:30:00+08:00", want: []string{"before"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.op.String()+" "+tt.value, func(t *testing.T) {
+				assert.Equal(t, tt.want, names(t, builder, &FilterRequest{
+					Filters: []Filter{{Field: "CreatedAt", Op: tt.op, Value: tt.value}},
+				}))
+			})
+		}
+	})
+
+	t.Run("Local time", func(t *testing.T) {
+		assert.Equal(t, []string{"end", "after"}, names(t, builder, &FilterRequest{
+			Filters: []Filter{{Field: "CreatedAt", Op: GE, Value: "2024-01-01 12:00:00"}},
+		}))
+	})
+
+	t.Run("Request override", func(t *testing.T) {
+		assert.Equal(t, []string{"end", "after"}, names(t, builder, &FilterRequest{
+			Filters:  []Filter{{Field: "CreatedAt", Op: EQ, Value: "2024-01-01"}},
+			TimeZone: "UTC",
+		}))
+
+		var users []TestUser
+		assert.Error(t, builder.FindAll(&FilterRequest{TimeZone: "Mars/Olympus"}, &users))
+		assert.Error(t, builder.Validate(&FilterRequest{TimeZone: "Mars/Olympus"}))
+	})
+
+	t.Run("Histogram", func(t *testing.T) {
+		buckets, err := builder.Histogram(&FilterRequest{}, Histogram{Field: "CreatedAt", Interval: "day"})
+		assert.NoError(t, err)
+		assert.Len(t, buckets, 3)
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, shanghai), buckets[1].Lower)
+		assert.Equal(t, int64(2), buckets[1].Count)
+	})
+}
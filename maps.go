@@ -0,0 +1,115 @@
+package querybuild
+
+import (
+	"strings"
+	"unicode"
+)
+
+// KeyStyle FindMaps 结果中的键名风格
+type KeyStyle int
+
+const (
+	KeyColumn KeyStyle = iota // 数据库列名或查询中的别名，如 created_at
+	KeyField                  // 模型字段名，如 CreatedAt，非模型字段的列保持列名
+	KeyCamel                  // 小驼峰，如 createdAt
+)
+
+// WithMapKeys 指定 FindMaps 结果中的键名风格，默认使用数据库列名
+func WithMapKeys(style KeyStyle) Option {
+	return func(o *options) {
+		o.mapKeys = style
+	}
+}
+
+// FindMaps 查询记录并以列名到取值的映射返回，适用于聚合与报表等无需定义结果结构体的查询
+//
+// 驱动返回的 []byte 取值转换为字符串，时间与数值字段的聚合结果按字段类型解码，配置了脱敏函数的字段在结果上调用脱敏函数，键名风格通过 WithMapKeys 指定。
+func (qb *QueryBuilder[T]) FindMaps(req *FilterRequest) ([]map[string]interface{}, error) {
+	req, err := qb.beforeFind(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []map[string]interface{}
+	query := qb.Build(req)
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	decodeAggregates(rows, qb.decodedAggregates(req.Aggrs), queryLocation(query))
+
+	masked := qb.masked(query.Statement.Context)
+	for i, row := range rows {
+		result := make(map[string]interface{}, len(row))
+		for column, value := range row {
+			if b, ok := value.([]byte); ok {
+				value = string(b)
+			}
+			field := qb.schema.LookUpField(column)
+			if field != nil && field.DBName == column && !qb.sqlMasked(query.Statement.Context, field.Name) {
+				if value, err = qb.decryptValue(query.Statement.Context, field.Name, value); err != nil {
+					return nil, err
+				}
+			}
+			if masked && field != nil {
+				if rule, ok := qb.opts.masks[field.Name]; ok && rule.fn != nil {
+					value = rule.fn(value)
+				}
+			}
+			result[qb.mapKey(column)] = value
+		}
+		rows[i] = result
+	}
+	return rows, nil
+}
+
+// mapKey 按键名风格转换结果列名
+func (qb *QueryBuilder[T]) mapKey(column string) string {
+	if qb.opts.mapKeys == KeyColumn {
+		return column
+	}
+	field := qb.schema.LookUpField(column)
+	if field != nil && field.DBName != column {
+		field = nil
+	}
+
+	switch qb.opts.mapKeys {
+	case KeyField:
+		if field != nil {
+			return field.Name
+		}
+	case KeyCamel:
+		if field != nil {
+			return lowerFirst(field.Name)
+		}
+		return lowerFirst(snakeToCamel(column))
+	}
+	return column
+}
+
+// snakeToCamel 将下划线分隔的名称转换为驼峰形式，如 order_count 转换为 OrderCount
+func snakeToCamel(name string) string {
+	var b strings.Builder
+	b.Grow(len(name))
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	return b.String()
+}
+
+// lowerFirst 将名称的首个单词转换为小写，连续的大写缩写整体转换，如 ID 转换为 id、UserID 转换为 userID
+func lowerFirst(name string) string {
+	runes := []rune(name)
+	for i := 0; i < len(runes) && unicode.IsUpper(runes[i]); i++ {
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
@@ -0,0 +1,77 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_FindMaps(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+	aggrReq := func() *FilterRequest {
+		return &FilterRequest{
+			Groups: []Group{{Field: "Status"}},
+			Aggrs:  []Aggregation{{Field: "Age", Op: MAX, Alias: "max_age"}},
+			Sorts:  []Sort{{Field: "Status"}},
+		}
+	}
+
+	t.Run("Aggregation", func(t *testing.T) {
+		rows, err := builder.FindMaps(aggrReq())
+		assert.NoError(t, err)
+		if assert.Len(t, rows, 2) {
+			assert.Equal(t, "active", rows[0]["status"])
+			assert.EqualValues(t, 35, rows[0]["max_age"])
+			assert.Equal(t, "inactive", rows[1]["status"])
+		}
+	})
+
+	t.Run("Field keys", func(t *testing.T) {
+		rows, err := builder.Clone(WithMapKeys(KeyField)).FindMaps(aggrReq())
+		assert.NoError(t, err)
+		if assert.Len(t, rows, 2) {
+			assert.Equal(t, "active", rows[0]["Status"])
+			assert.EqualValues(t, 35, rows[0]["max_age"])
+		}
+	})
+
+	t.Run("Camel keys", func(t *testing.T) {
+		rows, err := builder.Clone(WithMapKeys(KeyCamel)).FindMaps(&FilterRequest{
+			Filters: []Filter{{Field: "Name", Op: EQ, Value: "John Doe"}},
+		})
+		assert.NoError(t, err)
+		if assert.Len(t, rows, 1) {
+			assert.Equal(t, "John Doe", rows[0]["name"])
+			assert.Contains(t, rows[0], "id")
+			assert.Contains(t, rows[0], "createdAt")
+		}
+
+		rows, err = builder.Clone(WithMapKeys(KeyCamel)).FindMaps(aggrReq())
+		assert.NoError(t, err)
+		if assert.Len(t, rows, 2) {
+			assert.EqualValues(t, 35, rows[0]["maxAge"])
+		}
+	})
+
+	t.Run("Masked", func(t *testing.T) {
+		masked := builder.Clone(WithMaskFunc("Email", func(interface{}) interface{} { return "***" }))
+		rows, err := masked.FindMaps(&FilterRequest{Filters: []Filter{{Field: "Name", Op: EQ, Value: "John Doe"}}})
+		assert.NoError(t, err)
+		if assert.Len(t, rows, 1) {
+			assert.Equal(t, "***", rows[0]["email"])
+		}
+	})
+}
+
+func TestLowerFirst(t *testing.T) {
+	for name, want := range map[string]string{
+		"ID":        "id",
+		"UserID":    "userID",
+		"CreatedAt": "createdAt",
+		"HTTPCode":  "httpCode",
+		"name":      "name",
+	} {
+		assert.Equal(t, want, lowerFirst(name), name)
+	}
+}
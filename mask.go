@@ -0,0 +1,141 @@
+package querybuild
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// MaskFunc 在查询结果上脱敏字段值的函数
+type MaskFunc func(value interface{}) interface{}
+
+// maskRule 字段的脱敏规则
+type maskRule struct {
+	sql string   // 替换字段的SQL表达式
+	fn  MaskFunc // 查询后处理字段值的函数
+}
+
+// unmaskedKey 允许查看未脱敏数据的上下文键
+type unmaskedKey struct{}
+
+// WithUnmasked 返回允许查看未脱敏数据的上下文，构建器在该上下文中不应用脱敏规则
+func WithUnmasked(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unmaskedKey{}, true)
+}
+
+// isUnmasked 判断上下文是否允许查看未脱敏数据
+func isUnmasked(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	unmasked, _ := ctx.Value(unmaskedKey{}).(bool)
+	return unmasked
+}
+
+// masked 判断当前查询是否需要应用脱敏规则
+func (qb *QueryBuilder[T]) masked(ctx context.Context) bool {
+	return len(qb.opts.masks) > 0 && !isUnmasked(ctx)
+}
+
+// maskKey 返回当前查询的脱敏状态，用于区分脱敏与未脱敏的缓存结果，未配置脱敏规则时返回空
+//
+// 脱敏查询的状态包含配置了SQL脱敏表达式的字段与表达式，配置不同的构建器共享缓存时不会互相命中。
+func (qb *QueryBuilder[T]) maskKey(ctx context.Context) string {
+	if len(qb.opts.masks) == 0 {
+		return ""
+	}
+	if isUnmasked(ctx) {
+		return "unmasked"
+	}
+	names := make([]string, 0, len(qb.opts.masks))
+	for name, rule := range qb.opts.masks {
+		names = append(names, name+"="+rule.sql)
+	}
+	sort.Strings(names)
+	sum := sha256.Sum256([]byte(strings.Join(names, "\n")))
+	return "masked-" + hex.EncodeToString(sum[:8])
+}
+
+// applyMasks 将查询的选择字段替换为模型字段列表，配置了SQL脱敏表达式的字段使用表达式取值，
+// 历史查询中映射到其他列名的字段以模型列名取值
+//
+// 查询已通过自定义字段或聚合指定选择字段时不做处理，聚合、分组字段与附加选择字段在构建时已替换为脱敏表达式。
+func (qb *QueryBuilder[T]) applyMasks(query *gorm.DB) *gorm.DB {
+	if len(query.Statement.Selects) > 0 {
+		return query
+	}
+
+	masked := qb.masked(query.Statement.Context)
+	selects := make([]string, 0, len(qb.schema.DBNames))
+	rewritten := false
+	for _, dbName := range qb.schema.DBNames {
+		name := qb.schema.FieldsByDBName[dbName].Name
+		info := qb.fields[name]
+		column := quoteField(info)
+		if rule, ok := qb.opts.masks[name]; masked && ok && rule.sql != "" {
+			column = rule.sql + " AS `" + dbName + "`"
+			rewritten = true
+		} else if info.Name != dbName {
+			column += " AS `" + dbName + "`"
+			rewritten = true
+		}
+		selects = append(selects, column)
+	}
+	if !rewritten {
+		return query
+	}
+	return query.Select(selects)
+}
+
+// maskedColumn 返回取单个字段值时使用的列，字段配置了SQL脱敏表达式时返回表达式
+func (qb *QueryBuilder[T]) maskedColumn(query *gorm.DB, field, column string) string {
+	if !qb.masked(query.Statement.Context) {
+		return column
+	}
+	if f := qb.schema.LookUpField(field); f != nil {
+		if rule, ok := qb.opts.masks[f.Name]; ok && rule.sql != "" {
+			return rule.sql
+		}
+	}
+	return column
+}
+
+// maskValues 对单个字段的取值调用字段的脱敏函数
+func (qb *QueryBuilder[T]) maskValues(query *gorm.DB, field string, values []interface{}) {
+	if !qb.masked(query.Statement.Context) {
+		return
+	}
+	f := qb.schema.LookUpField(field)
+	if f == nil {
+		return
+	}
+	if rule, ok := qb.opts.masks[f.Name]; ok && rule.fn != nil {
+		for i, value := range values {
+			values[i] = rule.fn(value)
+		}
+	}
+}
+
+// maskItems 对查询结果中配置了脱敏函数的字段调用脱敏函数
+func (qb *QueryBuilder[T]) maskItems(items []T) {
+	ctx := qb.db.Statement.Context
+	if !qb.masked(ctx) {
+		return
+	}
+	for name, rule := range qb.opts.masks {
+		field := qb.schema.LookUpField(name)
+		if rule.fn == nil || field == nil {
+			continue
+		}
+		for i := range items {
+			rv := reflect.ValueOf(&items[i]).Elem()
+			value, _ := field.ValueOf(ctx, rv)
+			_ = field.Set(ctx, rv, rule.fn(value))
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package querybuild
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_WithMask(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db,
+		WithMask("Email", "SUBSTR(`test_users`.`email`, 1, 2) || '***'"),
+		WithMaskFunc("Name", func(value interface{}) interface{} {
+			return strings.Repeat("*", len(value.(string)))
+		}),
+	)
+	req := &FilterRequest{
+		Filters: []Filter{{Field: "Email", Op: EQ, Value: "john@example.com"}},
+	}
+
+	t.Run("Masked", func(t *testing.T) {
+		var users []TestUser
+		assert.NoError(t, builder.FindAll(req, &users))
+		assert.Len(t, users, 1)
+		assert.Equal(t, "jo***", users[0].Email)
+		assert.Equal(t, "********", users[0].Name)
+		assert.Equal(t, 25, users[0].Age)
+
+		result, err := builder.FindPage(&FilterRequest{Page: &Pagination{Page: 1, PageSize: 10}})
+		assert.NoError(t, err)
+		assert.Len(t, result.Items, 3)
+		assert.Equal(t, int64(3), result.Pagination.Total)
+		for _, user := range result.Items {
+			assert.True(t, strings.HasSuffix(user.Email, "***"))
+		}
+	})
+
+	t.Run("Pluck", func(t *testing.T) {
+		emails, err := builder.Pluck(req, "Email")
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{"jo***"}, emails)
+
+		names, err := builder.DistinctValues(req, "Name", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{"********"}, names)
+
+		facets, err := builder.Facets(req, "Name", "Email")
+		assert.NoError(t, err)
+		assert.Equal(t, []FacetBucket{{Value: "********", Count: 1}}, facets["Name"])
+		assert.Len(t, facets["Email"], 3)
+		for _, bucket := range facets["Email"] {
+			assert.True(t, strings.HasSuffix(bucket.Value.(string), "***"), bucket.Value)
+		}
+	})
+
+	t.Run("Groups and aggregates", func(t *testing.T) {
+		rows, err := builder.FindMaps(&FilterRequest{
+			Groups: []Group{{Field: "Email"}, {Field: "Name"}},
+			Aggrs:  []Aggregation{{Field: "ID", Op: COUNT, Alias: "total"}},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, rows, 3)
+		for _, row := range rows {
+			assert.True(t, strings.HasSuffix(row["email"].(string), "***"), row["email"])
+			assert.NotContains(t, row["name"], " ")
+		}
+
+		rows, err = builder.FindMaps(&FilterRequest{Aggrs: []Aggregation{{Field: "Email", Op: MAX}}})
+		assert.NoError(t, err)
+		if assert.Len(t, rows, 1) {
+			assert.Equal(t, "jo***", rows[0]["email"])
+		}
+	})
+
+	t.Run("Unmasked", func(t *testing.T) {
+		var users []TestUser
+		assert.NoError(t, builder.WithContext(WithUnmasked(context.Background())).FindAll(req, &users))
+		assert.Len(t, users, 1)
+		assert.Equal(t, "john@example.com", users[0].Email)
+		assert.Equal(t, "John Doe", users[0].Name)
+	})
+}
+
+func TestQueryBuilder_MaskConstant(t *testing.T) {
+	builder := NewQueryBuilder[TestUser](setupTestDB(t),
+		WithMask("Email", "'***'"),
+		WithMaskFunc("Status", func(interface{}) interface{} { return "***" }),
+	)
+	req := &FilterRequest{Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}}}
+
+	emails, err := builder.Pluck(req, "Email")
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"***", "***"}, emails)
+
+	emails, err = builder.DistinctValues(req, "Email", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"***"}, emails)
+
+	counts, err := builder.CountBy(&FilterRequest{}, "Email")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"***": 3}, counts)
+
+	// 脱敏后相同的取值合并为一个分面
+	facets, err := builder.Facets(&FilterRequest{}, "Email", "Status")
+	assert.NoError(t, err)
+	assert.Equal(t, []FacetBucket{{Value: "***", Count: 3}}, facets["Email"])
+	assert.Equal(t, []FacetBucket{{Value: "***", Count: 3}}, facets["Status"])
+
+	rows, err := builder.FindMaps(&FilterRequest{
+		Groups: []Group{{Field: "Email"}},
+		Aggrs:  []Aggregation{{Field: "ID", Op: COUNT, Alias: "total"}},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 1) {
+		assert.Equal(t, "***", rows[0]["email"])
+	}
+}
+
+func TestQueryBuilder_MaskCache(t *testing.T) {
+	builder := NewQueryBuilder[TestUser](setupTestDB(t),
+		WithCache(NewMemoryCache(), time.Minute),
+		WithMask("Email", "'***'"),
+	)
+	req := func() *FilterRequest {
+		return &FilterRequest{Filters: []Filter{{Field: "Name", Op: EQ, Value: "John Doe"}}, Page: &Pagination{Page: 1, PageSize: 10}}
+	}
+
+	result, err := builder.WithContext(WithUnmasked(context.Background())).FindPage(req())
+	assert.NoError(t, err)
+	if assert.Len(t, result.Items, 1) {
+		assert.Equal(t, "john@example.com", result.Items[0].Email)
+	}
+
+	// 未脱敏的缓存结果不会返回给需要脱敏的调用方
+	result, err = builder.FindPage(req())
+	assert.NoError(t, err)
+	if assert.Len(t, result.Items, 1) {
+		assert.Equal(t, "***", result.Items[0].Email)
+	}
+}
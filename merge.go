@@ -0,0 +1,207 @@
+package querybuild
+
+import (
+	"reflect"
+	"strings"
+)
+
+// MergeRequests 将客户端请求 override 叠加到服务端强制的请求 base 上，返回新的请求，两个请求均不会被修改
+//
+// 合并规则：
+//   - 过滤条件、过滤条件组、组合键过滤、关联存在性与数量过滤、自定义过滤条件按 base 在前依次合并，全部以 AND 生效，
+//     override 无法移除或放宽 base 的条件；两者都指定 CustomFilter 时 override 的 CustomFilter 移到 CustomFilters 中 base 的条件之后
+//   - 连接按 base 在前合并并去除完全相同的连接；子查询以 base 为准，base 未指定时使用 override
+//   - 自定义字段、排序、聚合、分组、分页、时区与抽样以 override 为准，override 未指定时使用 base
+//   - 空取值处理以 base 为准，override 指定跳过空取值时只在合并前去除 override 中取值为空的过滤条件，
+//     base 中取值为空的条件（如 owner EQ ""）仍然生效
+//   - Distinct、DedupeByPK 与 Unscoped 在任一请求指定时开启，Unscoped 仍需构建器通过 WithUnscoped 允许
+//
+// 分页信息复制为新的值，查询结果信息写入合并后的请求；应用的缺省值不会合并。
+func MergeRequests(base, override *FilterRequest) *FilterRequest {
+	if base == nil {
+		base = &FilterRequest{}
+	}
+	if override == nil {
+		override = &FilterRequest{}
+	}
+
+	overrideFilters := override.Filters
+	if override.SkipEmpty != nil && *override.SkipEmpty {
+		overrideFilters = nonEmptyFilters(overrideFilters)
+	}
+	merged := &FilterRequest{
+		Filters:      concat(base.Filters, overrideFilters),
+		FilterGroups: concat(base.FilterGroups, override.FilterGroups),
+		TupleFilters: concat(base.TupleFilters, override.TupleFilters),
+		Exists:       concat(base.Exists, override.Exists),
+		CountFilters: concat(base.CountFilters, override.CountFilters),
+		SubQuery:     base.SubQuery,
+		Unscoped:     base.Unscoped || override.Unscoped,
+	}
+	if merged.SubQuery == nil {
+		merged.SubQuery = override.SubQuery
+	}
+
+	merged.CustomFilter = base.CustomFilter
+	merged.CustomFilters = append([]CustomFilter(nil), base.CustomFilters...)
+	if override.CustomFilter != nil {
+		if merged.CustomFilter == nil {
+			merged.CustomFilter = override.CustomFilter
+		} else {
+			merged.CustomFilters = append(merged.CustomFilters, *override.CustomFilter)
+		}
+	}
+	merged.CustomFilters = append(merged.CustomFilters, override.CustomFilters...)
+	if len(merged.CustomFilters) == 0 {
+		merged.CustomFilters = nil
+	}
+
+	merged.Joins = append([]Join(nil), base.Joins...)
+	for _, join := range override.Joins {
+		duplicate := false
+		for _, existing := range base.Joins {
+			if reflect.DeepEqual(existing, join) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			merged.Joins = append(merged.Joins, join)
+		}
+	}
+	if len(merged.Joins) == 0 {
+		merged.Joins = nil
+	}
+
+	merged.CustomFields = prefer(override.CustomFields, base.CustomFields)
+	merged.Sorts = prefer(override.Sorts, base.Sorts)
+	merged.Aggrs = prefer(override.Aggrs, base.Aggrs)
+	merged.Groups = prefer(override.Groups, base.Groups)
+	merged.Distinct = override.Distinct || base.Distinct
+	merged.DedupeByPK = override.DedupeByPK || base.DedupeByPK
+
+	merged.TimeZone = override.TimeZone
+	if merged.TimeZone == "" {
+		merged.TimeZone = base.TimeZone
+	}
+	merged.SkipEmpty = base.SkipEmpty
+	merged.Sample = override.Sample
+	if merged.Sample == nil {
+		merged.Sample = base.Sample
+	}
+	page := override.Page
+	if page == nil {
+		page = base.Page
+	}
+	if page != nil {
+		copied := *page
+		merged.Page = &copied
+	}
+	return merged
+}
+
+// concat 按顺序合并两个切片，返回新的切片，均为空时返回 nil
+func concat[E any](a, b []E) []E {
+	if len(a)+len(b) == 0 {
+		return nil
+	}
+	return append(append(make([]E, 0, len(a)+len(b)), a...), b...)
+}
+
+// prefer 返回 primary 的副本，primary 为空时返回 fallback 的副本
+func prefer[E any](primary, fallback []E) []E {
+	if len(primary) > 0 {
+		return append([]E(nil), primary...)
+	}
+	return concat(fallback, nil)
+}
+
+// RequestDiff 两个请求之间一个字段的差异
+type RequestDiff struct {
+	Field   string      `json:"field"`             // 请求字段的 JSON 名称，如 filters、page
+	Removed interface{} `json:"removed,omitempty"` // 仅存在于第一个请求中的取值
+	Added   interface{} `json:"added,omitempty"`   // 仅存在于第二个请求中的取值
+}
+
+// orderedRequestFields 顺序影响语义的请求字段，顺序不同时整体报告差异
+var orderedRequestFields = map[string]bool{"sorts": true, "groups": true, "custom_filters": true, "joins": true}
+
+// DiffRequests 按请求字段比较两个请求，返回存在差异的字段，按 FilterRequest 的字段顺序排列，相同时返回 nil
+//
+// 过滤条件等以 AND 组合的条目按集合比较，只报告增加与删除的条目；排序、分组、自定义过滤条件与连接的顺序影响结果，
+// 顺序不同时整体报告。分页只比较页码、每页数量与延迟连接，不比较查询结果信息，应用的缺省值不参与比较。
+// 需忽略字段名大小写、条件顺序等写法差异时，先以 QueryBuilder.Normalize 规范化两个请求。
+func DiffRequests(a, b *FilterRequest) []RequestDiff {
+	if a == nil {
+		a = &FilterRequest{}
+	}
+	if b == nil {
+		b = &FilterRequest{}
+	}
+	left, right := diffable(a), diffable(b)
+
+	var diffs []RequestDiff
+	lv, rv := reflect.ValueOf(left), reflect.ValueOf(right)
+	typ := lv.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		x, y := lv.Field(i), rv.Field(i)
+		if reflect.DeepEqual(x.Interface(), y.Interface()) || x.Kind() == reflect.Slice && x.Len() == 0 && y.Len() == 0 {
+			continue
+		}
+		if x.Kind() != reflect.Slice || orderedRequestFields[name] {
+			diffs = append(diffs, RequestDiff{Field: name, Removed: diffValue(x), Added: diffValue(y)})
+			continue
+		}
+		removed, added := sliceDiff(x, y), sliceDiff(y, x)
+		if removed == nil && added == nil {
+			// 条目相同仅顺序不同
+			continue
+		}
+		diffs = append(diffs, RequestDiff{Field: name, Removed: removed, Added: added})
+	}
+	return diffs
+}
+
+// diffable 返回用于比较的请求副本：清除应用的缺省值，分页只保留请求参数
+func diffable(req *FilterRequest) FilterRequest {
+	out := *req
+	out.Defaults = nil
+	if req.Page != nil {
+		out.Page = &Pagination{Page: req.Page.Page, PageSize: req.Page.PageSize, Deferred: req.Page.Deferred}
+	}
+	return out
+}
+
+// diffValue 返回差异中报告的取值，零值报告为 nil
+func diffValue(v reflect.Value) interface{} {
+	if v.IsZero() || v.Kind() == reflect.Slice && v.Len() == 0 {
+		return nil
+	}
+	return v.Interface()
+}
+
+// sliceDiff 返回 x 中多于 y 的条目，按 x 中的顺序排列，没有时返回 nil
+func sliceDiff(x, y reflect.Value) interface{} {
+	used := make([]bool, y.Len())
+	out := reflect.MakeSlice(x.Type(), 0, 0)
+	for i := 0; i < x.Len(); i++ {
+		matched := false
+		for j := 0; j < y.Len(); j++ {
+			if !used[j] && reflect.DeepEqual(x.Index(i).Interface(), y.Index(j).Interface()) {
+				used[j], matched = true, true
+				break
+			}
+		}
+		if !matched {
+			out = reflect.Append(out, x.Index(i))
+		}
+	}
+	if out.Len() == 0 {
+		return nil
+	}
+	return out.Interface()
+}
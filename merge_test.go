@@ -0,0 +1,116 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeRequests(t *testing.T) {
+	base := &FilterRequest{
+		Filters:      []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+		CustomFilter: &CustomFilter{ScopeName: "tenant"},
+		Sorts:        []Sort{{Field: "ID"}},
+		Page:         &Pagination{Page: 1, PageSize: 50},
+	}
+	override := &FilterRequest{
+		Filters:      []Filter{{Field: "Status", Op: EQ, Value: "inactive"}, {Field: "Age", Op: GE, Value: "30"}},
+		CustomFilter: &CustomFilter{ScopeName: "recent"},
+		Sorts:        []Sort{{Field: "Age", Desc: true}},
+	}
+
+	t.Run("Precedence", func(t *testing.T) {
+		merged := MergeRequests(base, override)
+		assert.Equal(t, []Filter{
+			{Field: "Status", Op: EQ, Value: "active"},
+			{Field: "Status", Op: EQ, Value: "inactive"},
+			{Field: "Age", Op: GE, Value: "30"},
+		}, merged.Filters)
+		assert.Equal(t, "tenant", merged.CustomFilter.ScopeName)
+		assert.Equal(t, []CustomFilter{{ScopeName: "recent"}}, merged.CustomFilters)
+		assert.Equal(t, []Sort{{Field: "Age", Desc: true}}, merged.Sorts)
+		assert.Equal(t, &Pagination{Page: 1, PageSize: 50}, merged.Page)
+
+		merged.Page.Total = 10
+		merged.Filters[0].Value = "changed"
+		assert.Zero(t, base.Page.Total)
+		assert.Equal(t, "active", base.Filters[0].Value)
+		assert.Len(t, override.Filters, 2)
+	})
+
+	t.Run("Base filters cannot be widened", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](setupTestDB(t))
+		var users []TestUser
+		assert.NoError(t, builder.FindAll(MergeRequests(
+			&FilterRequest{Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}}},
+			&FilterRequest{Filters: []Filter{{Field: "Age", Op: GE, Value: "30"}}},
+		), &users))
+		if assert.Len(t, users, 1) {
+			assert.Equal(t, "Bob Johnson", users[0].Name)
+		}
+	})
+
+	t.Run("Empty base values are kept", func(t *testing.T) {
+		skip := true
+		merged := MergeRequests(
+			&FilterRequest{Filters: []Filter{{Field: "Tags", Op: EQ, Value: ""}}},
+			&FilterRequest{Filters: []Filter{{Field: "Status", Op: EQ, Value: ""}}, SkipEmpty: &skip},
+		)
+		assert.Equal(t, []Filter{{Field: "Tags", Op: EQ, Value: ""}}, merged.Filters)
+		assert.Nil(t, merged.SkipEmpty)
+
+		builder := NewQueryBuilder[TestUser](setupTestDB(t))
+		count, err := builder.Count(merged)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("Nil requests", func(t *testing.T) {
+		merged := MergeRequests(nil, override)
+		assert.Equal(t, override.Filters, merged.Filters)
+		assert.Equal(t, &FilterRequest{}, MergeRequests(nil, nil))
+	})
+}
+
+func TestDiffRequests(t *testing.T) {
+	a := &FilterRequest{
+		Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}, {Field: "Age", Op: GE, Value: "30"}},
+		Sorts:   []Sort{{Field: "ID"}, {Field: "Age"}},
+		Page:    &Pagination{Page: 1, PageSize: 20, Total: 3},
+	}
+
+	t.Run("Identical", func(t *testing.T) {
+		b := &FilterRequest{
+			Filters: []Filter{{Field: "Age", Op: GE, Value: "30"}, {Field: "Status", Op: EQ, Value: "active"}},
+			Sorts:   []Sort{{Field: "ID"}, {Field: "Age"}},
+			Page:    &Pagination{Page: 1, PageSize: 20},
+		}
+		assert.Nil(t, DiffRequests(a, b))
+		assert.Nil(t, DiffRequests(&FilterRequest{Joins: []Join{}}, &FilterRequest{}))
+	})
+
+	t.Run("Changes", func(t *testing.T) {
+		b := &FilterRequest{
+			Filters:  []Filter{{Field: "Status", Op: EQ, Value: "active"}, {Field: "Age", Op: LT, Value: "30"}},
+			Sorts:    []Sort{{Field: "Age"}, {Field: "ID"}},
+			Distinct: true,
+		}
+		assert.Equal(t, []RequestDiff{
+			{Field: "filters", Removed: []Filter{{Field: "Age", Op: GE, Value: "30"}}, Added: []Filter{{Field: "Age", Op: LT, Value: "30"}}},
+			{Field: "sorts", Removed: a.Sorts, Added: b.Sorts},
+			{Field: "page", Removed: &Pagination{Page: 1, PageSize: 20}},
+			{Field: "distinct", Added: true},
+		}, DiffRequests(a, b))
+	})
+
+	t.Run("Normalized", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](setupTestDB(t))
+		b := &FilterRequest{
+			Filters: []Filter{{Field: "age", Op: GE, Value: "30"}, {Field: "status", Op: EQ, Value: "active"}},
+			Sorts:   []Sort{{Field: "id"}, {Field: "age"}},
+			Page:    &Pagination{Page: 1},
+		}
+		assert.Len(t, DiffRequests(a, b), 3)
+		assert.Nil(t, DiffRequests(builder.Normalize(a), builder.Normalize(b)))
+	})
+}
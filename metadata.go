@@ -0,0 +1,142 @@
+package querybuild
+
+import (
+	"gorm.io/gorm/schema"
+)
+
+// FieldType 可查询字段的取值类型
+type FieldType string
+
+const (
+	StringField  FieldType = "string"  // 字符串
+	IntegerField FieldType = "integer" // 整数
+	NumberField  FieldType = "number"  // 浮点数
+	BooleanField FieldType = "boolean" // 布尔值
+	TimeField    FieldType = "time"    // 时间
+	ArrayField   FieldType = "array"   // 数组
+	OtherField   FieldType = "other"   // 其他类型
+)
+
+// 各类字段支持的过滤操作符
+var (
+	stringOperators = []Operator{EQ, NE, GT, GE, LT, LE, LIKE, IN, BETWEEN, NOT_IN, IS_NULL, NOT_NULL,
+		STARTS_WITH, ENDS_WITH, CONTAINS, NOT_LIKE, REGEXP, NOT_REGEXP, NOT_BETWEEN}
+	orderedOperators = []Operator{EQ, NE, GT, GE, LT, LE, IN, BETWEEN, NOT_IN, IS_NULL, NOT_NULL,
+		NOT_BETWEEN, GT_OR_NULL, GE_OR_NULL, LT_OR_NULL, LE_OR_NULL}
+	booleanOperators = []Operator{EQ, NE, IS_NULL, NOT_NULL}
+	enumOperators    = []Operator{EQ, NE, IN, NOT_IN, IS_NULL, NOT_NULL}
+	arrayOperators   = []Operator{EQ, NE, IS_NULL, NOT_NULL, OVERLAP, ARRAY_CONTAINS, ARRAY_CONTAINED}
+	otherOperators   = []Operator{EQ, NE, IN, NOT_IN, IS_NULL, NOT_NULL, OVERLAP, ARRAY_CONTAINS, ARRAY_CONTAINED}
+)
+
+// FieldMetadata 可查询字段的元数据
+type FieldMetadata struct {
+	Name       string            `json:"name"`             // 请求中使用的字段名
+	Column     string            `json:"column"`           // 数据库字段名
+	Type       FieldType         `json:"type"`             // 取值类型
+	Size       int               `json:"size,omitempty"`   // 字段长度，未声明时为 0
+	Operators  []Operator        `json:"operators"`        // 支持的过滤操作符
+	Enum       []string          `json:"enum,omitempty"`   // 允许的枚举取值
+	EnumLabels map[string]string `json:"labels,omitempty"` // 枚举取值对应的显示标签
+	Sortable   bool              `json:"sortable"`         // 是否允许排序
+	PrimaryKey bool              `json:"primary_key,omitempty"`
+}
+
+// Metadata 构建器可查询字段及分页配置的元数据，可序列化后供前端动态生成过滤界面
+type Metadata struct {
+	Model           string          `json:"model"`                   // 模型表名
+	Fields          []FieldMetadata `json:"fields"`                  // 可查询字段，按模型字段顺序排列
+	DefaultPageSize int             `json:"default_page_size"`       // 默认每页数量
+	MaxPageSize     int             `json:"max_page_size,omitempty"` // 每页数量上限，0 表示不限制
+	Limits          *Limits         `json:"limits,omitempty"`        // 单个请求的数量上限
+}
+
+// Metadata 返回构建器可查询字段的名称、类型、支持的操作符、枚举取值与排序许可
+func (qb *QueryBuilder[T]) Metadata() *Metadata {
+	meta := &Metadata{
+		Model:           qb.tableName(),
+		Fields:          make([]FieldMetadata, 0, len(qb.schema.DBNames)),
+		DefaultPageSize: DefaultPageSize,
+		MaxPageSize:     qb.opts.maxPageSize,
+	}
+	if qb.opts.limits != nil {
+		limits := *qb.opts.limits
+		meta.Limits = &limits
+	}
+
+	for _, field := range qb.schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		fieldType := fieldTypeOf(field.DataType)
+		enum := qb.enumList(field)
+		meta.Fields = append(meta.Fields, FieldMetadata{
+			Name:       field.Name,
+			Column:     field.DBName,
+			Type:       fieldType,
+			Size:       field.Size,
+			Operators:  qb.dialectOperators(fieldOperators(fieldType, enum != nil)),
+			Enum:       enum,
+			EnumLabels: qb.enumLabelList(field),
+			Sortable:   qb.checkSortAllowed(field.Name) == nil,
+			PrimaryKey: field.PrimaryKey,
+		})
+	}
+	return meta
+}
+
+// fieldTypeOf 将模型字段的数据类型转换为元数据中的取值类型，citext 与 enum 列视为字符串，数组列视为数组
+func fieldTypeOf(dataType schema.DataType) FieldType {
+	info := FieldInfo{DataType: dataType}
+	switch {
+	case info.isCIText(), info.enumValues() != nil:
+		return StringField
+	case info.isArray():
+		return ArrayField
+	}
+	switch dataType {
+	case schema.String:
+		return StringField
+	case schema.Int, schema.Uint:
+		return IntegerField
+	case schema.Float:
+		return NumberField
+	case schema.Bool:
+		return BooleanField
+	case schema.Time:
+		return TimeField
+	default:
+		return OtherField
+	}
+}
+
+// dialectOperators 去除当前方言不支持的过滤操作符
+func (qb *QueryBuilder[T]) dialectOperators(ops []Operator) []Operator {
+	supported := ops[:0]
+	for _, op := range ops {
+		if qb.supportsOperator(op) {
+			supported = append(supported, op)
+		}
+	}
+	return supported
+}
+
+// fieldOperators 返回字段类型支持的过滤操作符
+func fieldOperators(fieldType FieldType, enum bool) []Operator {
+	var ops []Operator
+	switch {
+	case enum:
+		ops = enumOperators
+	case fieldType == StringField:
+		ops = stringOperators
+	case fieldType == IntegerField, fieldType == NumberField, fieldType == TimeField:
+		ops = orderedOperators
+	case fieldType == BooleanField:
+		ops = booleanOperators
+	case fieldType == ArrayField:
+		ops = arrayOperators
+	default:
+		ops = otherOperators
+	}
+	return append([]Operator(nil), ops...)
+}
@@ -0,0 +1,51 @@
+package querybuild
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_Metadata(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[sanitizeUser](db,
+		WithMaxPageSize(50),
+		WithLimits(DefaultLimits),
+		AllowSortFields("Name", "CreatedAt"),
+	)
+
+	meta := builder.Metadata()
+	assert.Equal(t, "sanitize_users", meta.Model)
+	assert.Equal(t, DefaultPageSize, meta.DefaultPageSize)
+	assert.Equal(t, 50, meta.MaxPageSize)
+	assert.Equal(t, DefaultLimits, *meta.Limits)
+
+	fields := make(map[string]FieldMetadata, len(meta.Fields))
+	for _, field := range meta.Fields {
+		fields[field.Name] = field
+	}
+	assert.Len(t, fields, 7)
+
+	assert.True(t, fields["ID"].PrimaryKey)
+	assert.True(t, fields["ID"].Sortable)
+	assert.Equal(t, IntegerField, fields["Age"].Type)
+	assert.False(t, fields["Age"].Sortable)
+	assert.NotContains(t, fields["Age"].Operators, LIKE)
+
+	assert.Equal(t, StringField, fields["Name"].Type)
+	assert.Equal(t, 5, fields["Name"].Size)
+	assert.True(t, fields["Name"].Sortable)
+	assert.Contains(t, fields["Name"].Operators, LIKE)
+
+	assert.Equal(t, []string{"active", "inactive"}, fields["Status"].Enum)
+	assert.Equal(t, []Operator{EQ, NE, IN, NOT_IN, IS_NULL, NOT_NULL}, fields["Status"].Operators)
+	assert.Equal(t, BooleanField, fields["Active"].Type)
+	assert.Equal(t, NumberField, fields["Score"].Type)
+	assert.Equal(t, TimeField, fields["CreatedAt"].Type)
+
+	data, err := json.Marshal(meta)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"name":"Status","column":"status","type":"string"`)
+	assert.Contains(t, string(data), `"max_filters":20`)
+}
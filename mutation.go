@@ -0,0 +1,288 @@
+package querybuild
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// UpdateOp 字段更新操作类型
+type UpdateOp int32
+
+const (
+	SET UpdateOp = iota // 赋值
+	INC                 // 自增
+	DEC                 // 自减
+)
+
+// FieldExpr 字段更新表达式
+type FieldExpr struct {
+	Field string      `json:"field"`
+	Op    UpdateOp    `json:"op"`
+	Value interface{} `json:"value"`
+}
+
+// ErrVersionConflict 指定了期望版本号的更新没有匹配的记录，记录已被并发修改或不存在
+var ErrVersionConflict = errors.New("version conflict")
+
+// MutationOption 批量更新、删除与写入的选项
+type MutationOption func(*mutationOptions)
+
+// mutationOptions 批量更新、删除与写入的配置
+type mutationOptions struct {
+	version         *int64      // 期望的版本号
+	returning       interface{} // 写入受影响记录的切片指针
+	returningFields []string    // 返回的字段，为空时返回全部字段
+	hardDelete      bool        // 永久删除记录
+	preview         *MutationPreview
+}
+
+// MutationPreview 批量更新或删除的预览结果
+type MutationPreview struct {
+	SQL  string        // 将要执行的语句，取值以占位符表示
+	Vars []interface{} // 语句的绑定参数
+	Rows int64         // 以相同条件统计的将受影响的行数
+}
+
+// DryRun 仅预览 UpdateAll、UpdateExpr 与 DeleteAll，不修改数据
+//
+// 将要执行的语句写入 preview，并以相同的过滤条件执行 SELECT COUNT 统计将受影响的行数，方法返回该行数。
+// 预览时忽略 Returning，指定了期望的版本号且没有匹配记录时不返回 ErrVersionConflict。
+func DryRun(preview *MutationPreview) MutationOption {
+	return func(o *mutationOptions) {
+		o.preview = preview
+	}
+}
+
+// newMutationOptions 应用选项生成配置
+func newMutationOptions(opts []MutationOption) mutationOptions {
+	var mo mutationOptions
+	for _, opt := range opts {
+		opt(&mo)
+	}
+	return mo
+}
+
+// ExpectVersion 仅更新版本号等于 version 的记录，需通过 WithVersionField 配置版本字段
+//
+// 没有记录被更新时返回 ErrVersionConflict。
+func ExpectVersion(version int64) MutationOption {
+	return func(o *mutationOptions) {
+		o.version = &version
+	}
+}
+
+// WithVersionField 指定乐观锁版本字段，UpdateAll 与 UpdateExpr 更新时将该字段加 1
+//
+// 版本字段需为整数类型的模型字段，且不能在更新中直接赋值。
+func WithVersionField(field string) Option {
+	return func(o *options) {
+		o.versionField = field
+	}
+}
+
+// UpdateAll 按过滤条件批量更新字段，updates 的键为模型字段名，返回受影响的行数
+//
+// 更新成功后使当前模型的缓存结果失效。
+func (qb *QueryBuilder[T]) UpdateAll(req *FilterRequest, updates map[string]interface{}, opts ...MutationOption) (int64, error) {
+	if len(updates) == 0 {
+		return 0, fmt.Errorf("updates are required")
+	}
+
+	columns := make(map[string]interface{}, len(updates))
+	for field, value := range updates {
+		info, err := qb.validateField(field)
+		if err != nil {
+			return 0, err
+		}
+		columns[info.Name] = value
+	}
+	return qb.update(req, columns, opts)
+}
+
+// UpdateExpr 按过滤条件批量更新字段，支持自增与自减，返回受影响的行数
+//
+// 更新成功后使当前模型的缓存结果失效。
+func (qb *QueryBuilder[T]) UpdateExpr(req *FilterRequest, exprs []FieldExpr, opts ...MutationOption) (int64, error) {
+	if len(exprs) == 0 {
+		return 0, fmt.Errorf("update expressions are required")
+	}
+
+	updates := make(map[string]interface{}, len(exprs))
+	for _, expr := range exprs {
+		info, err := qb.validateField(expr.Field)
+		if err != nil {
+			return 0, err
+		}
+
+		switch expr.Op {
+		case SET:
+			updates[info.Name] = expr.Value
+		case INC, DEC:
+			if !isNumber(expr.Value) {
+				return 0, fmt.Errorf("invalid numeric value for field %s: %v", expr.Field, expr.Value)
+			}
+			sign := "+"
+			if expr.Op == DEC {
+				sign = "-"
+			}
+			updates[info.Name] = gorm.Expr(fmt.Sprintf("%s %s ?", quoteField(info), sign), expr.Value)
+		default:
+			return 0, fmt.Errorf("unsupported update operator: %s", expr.Op)
+		}
+	}
+	return qb.update(req, updates, opts)
+}
+
+// update 按过滤条件更新列，配置了版本字段时递增版本号并按期望的版本号过滤
+func (qb *QueryBuilder[T]) update(req *FilterRequest, updates map[string]interface{}, opts []MutationOption) (int64, error) {
+	mo := newMutationOptions(opts)
+	if mo.hardDelete {
+		return 0, fmt.Errorf("hard delete only applies to DeleteAll")
+	}
+	if qb.opts.versionField != "" {
+		info, err := qb.versionField()
+		if err != nil {
+			return 0, err
+		}
+		if _, ok := updates[info.Name]; ok {
+			return 0, fmt.Errorf("version field cannot be updated directly: %s", qb.opts.versionField)
+		}
+		updates[info.Name] = gorm.Expr(quoteField(info) + " + 1")
+	}
+
+	return qb.mutate(req, mo, false, func(query *gorm.DB, model interface{}) *gorm.DB {
+		return query.Model(model).Updates(updates)
+	})
+}
+
+// DeleteAll 按过滤条件批量删除记录，返回删除的行数
+//
+// 没有过滤条件的全表删除会被拒绝，模型包含软删除字段时执行软删除，永久删除需指定 HardDelete。
+// 删除成功后使当前模型的缓存结果失效。
+func (qb *QueryBuilder[T]) DeleteAll(req *FilterRequest, opts ...MutationOption) (int64, error) {
+	mo := newMutationOptions(opts)
+	if mo.hardDelete && !qb.opts.allowHardDelete {
+		return 0, ErrHardDeleteDisabled
+	}
+	return qb.mutate(req, mo, true, func(query *gorm.DB, model interface{}) *gorm.DB {
+		return query.Delete(model)
+	})
+}
+
+// mutate 按请求的过滤条件执行批量更新或删除，before 表示 Returning 返回执行前的记录
+//
+// 指定了期望的版本号时附加版本条件，没有记录受影响时返回 ErrVersionConflict。
+func (qb *QueryBuilder[T]) mutate(req *FilterRequest, mo mutationOptions, before bool, exec func(query *gorm.DB, model interface{}) *gorm.DB) (int64, error) {
+	if err := qb.checkWritable(); err != nil {
+		return 0, err
+	}
+	loc, err := qb.requestLocation(req)
+	if err != nil {
+		return 0, err
+	}
+	if err := qb.checkUnscoped(req); err != nil {
+		return 0, err
+	}
+	// 与查询相同校验数量上限，将过滤条件中的枚举标签转换为枚举取值并校验过滤取值
+	if err := qb.checkLimits(req); err != nil {
+		return 0, err
+	}
+	req = qb.translateEnums(req)
+	for _, filter := range req.Filters {
+		if err := qb.checkFilterValue(filter); err != nil {
+			return 0, err
+		}
+	}
+	var version *FieldInfo
+	if mo.version != nil {
+		if qb.opts.versionField == "" {
+			return 0, fmt.Errorf("expected version requires a version field")
+		}
+		info, err := qb.versionField()
+		if err != nil {
+			return 0, err
+		}
+		version = &info
+	}
+	build := func(db *gorm.DB) *gorm.DB {
+		query := qb.applyConditions(qb.applyUnscoped(withLocation(qb.withExecInfo(qb.modelQuery(db), req), loc), req), req)
+		if version != nil {
+			query = query.Where(quoteField(*version)+" = ?", *mo.version)
+		}
+		if mo.hardDelete {
+			query = query.Unscoped()
+		}
+		return query
+	}
+	if mo.preview != nil {
+		return qb.preview(build, mo.preview, exec)
+	}
+
+	var rows int64
+	if mo.returning != nil {
+		rows, err = qb.execReturning(build, mo, before, exec)
+	} else {
+		result := exec(build(qb.db), new(T))
+		rows, err = result.RowsAffected, result.Error
+	}
+	if err != nil {
+		return 0, err
+	}
+	if rows > 0 {
+		_ = qb.InvalidateCache()
+	} else if mo.version != nil {
+		return 0, ErrVersionConflict
+	}
+	return rows, nil
+}
+
+// preview 生成将要执行的语句并统计将受影响的行数，不修改数据
+func (qb *QueryBuilder[T]) preview(build func(*gorm.DB) *gorm.DB, preview *MutationPreview, exec func(query *gorm.DB, model interface{}) *gorm.DB) (int64, error) {
+	stmt := exec(build(qb.db).Session(&gorm.Session{DryRun: true}), new(T))
+	if stmt.Error != nil {
+		return 0, stmt.Error
+	}
+	*preview = MutationPreview{SQL: stmt.Statement.SQL.String(), Vars: stmt.Statement.Vars}
+	if err := build(qb.db).Count(&preview.Rows).Error; err != nil {
+		return 0, err
+	}
+	return preview.Rows, nil
+}
+
+// versionField 返回配置的乐观锁版本字段，字段需为整数类型的模型字段
+func (qb *QueryBuilder[T]) versionField() (FieldInfo, error) {
+	field := qb.schema.LookUpField(qb.opts.versionField)
+	if field == nil || field.DBName == "" || (field.DataType != schema.Int && field.DataType != schema.Uint) {
+		return FieldInfo{}, fmt.Errorf("invalid version field: %s", qb.opts.versionField)
+	}
+	return qb.validateField(field.Name)
+}
+
+// isNumber 判断值是否为数值类型
+func isNumber(v interface{}) bool {
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// String 返回更新操作类型的字符串表示
+func (op UpdateOp) String() string {
+	switch op {
+	case SET:
+		return "SET"
+	case INC:
+		return "INC"
+	case DEC:
+		return "DEC"
+	default:
+		return "UNKNOWN"
+	}
+}
@@ -0,0 +1,215 @@
+package querybuild
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// testDocument 带乐观锁版本号的测试文档模型
+type testDocument struct {
+	ID      uint `gorm:"primarykey"`
+	Title   string
+	Version int
+}
+
+func TestQueryBuilder_UpdateExpr(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+
+	t.Run("Increment filtered rows", func(t *testing.T) {
+		rows, err := builder.UpdateExpr(&FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+		}, []FieldExpr{{Field: "Age", Op: INC, Value: 5}})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), rows)
+
+		var user TestUser
+		assert.NoError(t, db.Where("name = ?", "John Doe").First(&user).Error)
+		assert.Equal(t, 30, user.Age)
+	})
+
+	t.Run("Decrement and set together", func(t *testing.T) {
+		rows, err := builder.UpdateExpr(&FilterRequest{
+			Filters: []Filter{{Field: "Name", Op: EQ, Value: "Jane Smith"}},
+		}, []FieldExpr{
+			{Field: "Age", Op: DEC, Value: 10},
+			{Field: "Status", Op: SET, Value: "active"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), rows)
+
+		var user TestUser
+		assert.NoError(t, db.Where("name = ?", "Jane Smith").First(&user).Error)
+		assert.Equal(t, 20, user.Age)
+		assert.Equal(t, "active", user.Status)
+	})
+
+	t.Run("Invalid field", func(t *testing.T) {
+		_, err := builder.UpdateExpr(&FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+		}, []FieldExpr{{Field: "Credits", Op: INC, Value: 1}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid field name")
+	})
+
+	t.Run("Non numeric increment", func(t *testing.T) {
+		_, err := builder.UpdateExpr(&FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+		}, []FieldExpr{{Field: "Age", Op: INC, Value: "5"}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid numeric value")
+	})
+
+	t.Run("Missing where clause is rejected", func(t *testing.T) {
+		_, err := builder.UpdateExpr(&FilterRequest{}, []FieldExpr{{Field: "Age", Op: INC, Value: 1}})
+		assert.Error(t, err)
+	})
+}
+
+func TestQueryBuilder_UpdateAll(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+
+	rows, err := builder.UpdateAll(&FilterRequest{
+		Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+	}, map[string]interface{}{"Status": "archived", "Age": 40})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), rows)
+
+	var count int64
+	assert.NoError(t, db.Model(&TestUser{}).Where("status = ? AND age = ?", "archived", 40).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	_, err = builder.UpdateAll(&FilterRequest{
+		Filters: []Filter{{Field: "Status", Op: EQ, Value: "archived"}},
+	}, map[string]interface{}{"Credits": 1})
+	assert.ErrorContains(t, err, "invalid field name")
+
+	_, err = builder.UpdateAll(&FilterRequest{
+		Filters: []Filter{{Field: "Status", Op: EQ, Value: "archived"}},
+	}, map[string]interface{}{"Age": 1}, ExpectVersion(1))
+	assert.ErrorContains(t, err, "requires a version field")
+}
+
+func TestQueryBuilder_UpdateAllEnumLabels(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[labeledUser](db)
+
+	rows, err := builder.UpdateAll(&FilterRequest{
+		Filters: []Filter{{Field: "Status", Op: EQ, Value: "已激活"}},
+	}, map[string]interface{}{"Name": "Active User"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), rows)
+
+	rows, err = builder.DeleteAll(&FilterRequest{
+		FilterGroups: []FilterGroup{{Filters: []Filter{{Field: "Status", Op: IN, Value: "已停用"}}}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), rows)
+}
+
+func TestQueryBuilder_UpdateAllVersion(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&testDocument{}))
+	doc := testDocument{Title: "draft", Version: 1}
+	assert.NoError(t, db.Create(&doc).Error)
+
+	builder := NewQueryBuilder[testDocument](db, WithVersionField("Version"))
+	req := &FilterRequest{Filters: []Filter{{Field: "ID", Op: EQ, Value: fmt.Sprint(doc.ID)}}}
+
+	rows, err := builder.UpdateAll(req, map[string]interface{}{"Title": "review"}, ExpectVersion(1))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), rows)
+
+	// 基于旧版本号的并发更新被拒绝
+	rows, err = builder.UpdateAll(req, map[string]interface{}{"Title": "stale"}, ExpectVersion(1))
+	assert.ErrorIs(t, err, ErrVersionConflict)
+	assert.Zero(t, rows)
+
+	// 未指定期望版本号时仍递增版本号
+	_, err = builder.UpdateExpr(req, []FieldExpr{{Field: "Title", Op: SET, Value: "final"}})
+	assert.NoError(t, err)
+
+	var saved testDocument
+	assert.NoError(t, db.First(&saved, doc.ID).Error)
+	assert.Equal(t, "final", saved.Title)
+	assert.Equal(t, 3, saved.Version)
+
+	_, err = builder.UpdateAll(req, map[string]interface{}{"Version": 10})
+	assert.ErrorContains(t, err, "cannot be updated directly")
+}
+
+func TestQueryBuilder_DeleteAll(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+
+	rows, err := builder.DeleteAll(&FilterRequest{
+		Filters: []Filter{{Field: "Age", Op: GE, Value: "30"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), rows)
+
+	var count int64
+	assert.NoError(t, db.Model(&TestUser{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	_, err = builder.DeleteAll(&FilterRequest{})
+	assert.ErrorIs(t, err, gorm.ErrMissingWhereClause)
+}
+
+func TestQueryBuilder_MutationLimits(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db, WithLimits(Limits{MaxFilters: 1}), WithEnum("Status", "active", "inactive"), WithValueValidation())
+
+	var limitErr *LimitExceededError
+	_, err := builder.DeleteAll(&FilterRequest{Filters: []Filter{
+		{Field: "Status", Op: EQ, Value: "active"},
+		{Field: "Age", Op: GE, Value: "30"},
+	}})
+	assert.ErrorAs(t, err, &limitErr)
+
+	_, err = builder.UpdateAll(&FilterRequest{FilterGroups: []FilterGroup{{Filters: []Filter{
+		{Field: "Age", Op: GE, Value: "30"},
+		{Field: "Age", Op: LE, Value: "40"},
+	}}}}, map[string]interface{}{"Age": 1})
+	assert.ErrorAs(t, err, &limitErr)
+
+	_, err = builder.DeleteAll(&FilterRequest{Filters: []Filter{{Field: "Status", Op: EQ, Value: "deleted"}}})
+	var valueErr *InvalidValueError
+	assert.ErrorAs(t, err, &valueErr)
+
+	var count int64
+	assert.NoError(t, db.Model(&TestUser{}).Count(&count).Error)
+	assert.Equal(t, int64(3), count)
+}
+
+func TestQueryBuilder_MutationDryRun(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+	active := &FilterRequest{Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}}}
+
+	var preview MutationPreview
+	rows, err := builder.UpdateAll(active, map[string]interface{}{"Age": 99}, DryRun(&preview))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), rows)
+	assert.Equal(t, int64(2), preview.Rows)
+	assert.Contains(t, preview.SQL, "UPDATE `test_users` SET `age`=?")
+	assert.Contains(t, preview.Vars, "active")
+
+	rows, err = builder.DeleteAll(active, DryRun(&preview))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), rows)
+	assert.Contains(t, preview.SQL, "DELETE FROM `test_users`")
+
+	var count int64
+	assert.NoError(t, db.Model(&TestUser{}).Where("status = ? AND age <> ?", "active", 99).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	_, err = builder.DeleteAll(&FilterRequest{}, DryRun(&preview))
+	assert.ErrorIs(t, err, gorm.ErrMissingWhereClause)
+}
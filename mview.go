@@ -0,0 +1,204 @@
+package querybuild
+
+import "fmt"
+
+// MaterializedView 可替代基础表执行聚合查询的预聚合物化视图
+type MaterializedView struct {
+	Table      string    // 视图名
+	Dimensions []string  // 视图保留的模型字段，视图中的列名与模型字段的列名相同
+	Measures   []Measure // 视图中预聚合的度量列
+}
+
+// Measure 物化视图中预聚合的度量列
+type Measure struct {
+	Field  string        // 聚合的模型字段
+	Op     AggregationOp // 聚合类型，支持 COUNT、SUM、MIN、MAX
+	Column string        // 视图中保存聚合结果的列
+}
+
+// reaggregateOps 度量列按请求的分组再次聚合时使用的聚合类型
+var reaggregateOps = map[AggregationOp]AggregationOp{
+	COUNT: SUM,
+	SUM:   SUM,
+	MIN:   MIN,
+	MAX:   MAX,
+}
+
+// WithMaterializedView 注册可提供聚合查询的物化视图，可多次使用注册多个视图
+//
+// 请求包含聚合，且分组、过滤与排序仅引用视图的维度字段，每个聚合都有对应的度量列时，
+// 查询改为在视图上按请求的分组再次聚合度量列（COUNT 求和，SUM、MIN、MAX 不变），结果列名与基础表查询相同；
+// 否则查询基础表。多个视图匹配时使用先注册的视图，因此应先注册粒度较粗的视图。
+// 视图需已排除软删除的记录；包含连接、子查询、自定义条件、去重、历史查询或 Unscoped 的请求总是查询基础表。
+func WithMaterializedView(view MaterializedView) Option {
+	return func(o *options) {
+		o.views = append(o.views, view)
+	}
+}
+
+// MaterializedViewFor 返回请求会路由到的物化视图名，查询基础表时返回空字符串
+func (qb *QueryBuilder[T]) MaterializedViewFor(req *FilterRequest) (string, error) {
+	routed, _, err := qb.routeView(req)
+	if err != nil || routed == nil {
+		return "", err
+	}
+	return routed.opts.table, nil
+}
+
+// routeView 返回请求匹配的物化视图构建器及改写后的请求，没有匹配的视图时返回 nil
+func (qb *QueryBuilder[T]) routeView(req *FilterRequest) (*QueryBuilder[T], *FilterRequest, error) {
+	if len(qb.opts.views) == 0 || !qb.viewRoutable(req) {
+		return nil, nil, nil
+	}
+	for _, view := range qb.opts.views {
+		fields, measures, err := qb.viewFields(view)
+		if err != nil {
+			return nil, nil, err
+		}
+		routed, ok := qb.rewriteForView(req, fields, measures)
+		if !ok {
+			continue
+		}
+
+		clone := *qb
+		clone.opts.table = view.Table
+		clone.opts.views = nil
+		clone.opts.defaultRules = nil
+		clone.opts.partitionKey = ""
+		// 视图中的软删除记录已被排除，改写后的请求以 Unscoped 查询
+		clone.opts.allowUnscoped = true
+		clone.fields = fields
+		clone.plans = nil
+		return &clone, routed, nil
+	}
+	return nil, nil, nil
+}
+
+// viewRoutable 判断请求的结构是否可能由物化视图提供
+func (qb *QueryBuilder[T]) viewRoutable(req *FilterRequest) bool {
+	return len(req.Aggrs) > 0 && qb.temporal == nil && !req.Unscoped &&
+		len(req.CustomFields) == 0 && req.CustomFilter == nil && len(req.CustomFilters) == 0 &&
+		len(req.Joins) == 0 && req.SubQuery == nil && len(req.TupleFilters) == 0 &&
+		len(req.Exists) == 0 && len(req.CountFilters) == 0 && !req.Distinct && !req.DedupeByPK && req.Sample == nil
+}
+
+// viewFields 生成视图的字段映射：维度字段映射到视图中的同名列，度量列以列名为键
+//
+// 返回的度量映射以“模型字段名/聚合类型”为键。
+func (qb *QueryBuilder[T]) viewFields(view MaterializedView) (map[string]FieldInfo, map[string]string, error) {
+	if !isIdentifier(view.Table) {
+		return nil, nil, fmt.Errorf("invalid materialized view name: %s", view.Table)
+	}
+	fields := make(map[string]FieldInfo, len(view.Dimensions)+len(view.Measures))
+	for _, name := range view.Dimensions {
+		field := qb.schema.LookUpField(name)
+		if field == nil || field.DBName == "" {
+			return nil, nil, fmt.Errorf("invalid dimension %s in materialized view %s", name, view.Table)
+		}
+		fields[field.Name] = FieldInfo{Name: field.DBName, TableName: view.Table, DataType: field.DataType}
+	}
+
+	measures := make(map[string]string, len(view.Measures))
+	for _, measure := range view.Measures {
+		field := qb.schema.LookUpField(measure.Field)
+		if field == nil || field.DBName == "" {
+			return nil, nil, fmt.Errorf("invalid measure field %s in materialized view %s", measure.Field, view.Table)
+		}
+		if _, ok := reaggregateOps[measure.Op]; !ok {
+			return nil, nil, fmt.Errorf("unsupported measure aggregation %s in materialized view %s", measure.Op, view.Table)
+		}
+		if !isIdentifier(measure.Column) {
+			return nil, nil, fmt.Errorf("invalid measure column %s in materialized view %s", measure.Column, view.Table)
+		}
+		if _, ok := fields[measure.Column]; ok {
+			return nil, nil, fmt.Errorf("measure column %s conflicts with a dimension in materialized view %s", measure.Column, view.Table)
+		}
+		fields[measure.Column] = FieldInfo{Name: measure.Column, TableName: view.Table}
+		measures[measureKey(field.Name, measure.Op)] = measure.Column
+	}
+	return fields, measures, nil
+}
+
+// rewriteForView 将请求改写为在视图上再次聚合度量列的请求，请求引用了视图之外的字段或聚合时返回 false
+func (qb *QueryBuilder[T]) rewriteForView(req *FilterRequest, fields map[string]FieldInfo, measures map[string]string) (*FilterRequest, bool) {
+	dimension := func(name string) bool {
+		field := qb.schema.LookUpField(name)
+		if field == nil || field.Name != name {
+			return false
+		}
+		_, ok := fields[name]
+		return ok && !isMeasureColumn(measures, name)
+	}
+	var filtersOK func(filters []Filter, groups []FilterGroup) bool
+	filtersOK = func(filters []Filter, groups []FilterGroup) bool {
+		for _, filter := range filters {
+			if !dimension(filter.Field) {
+				return false
+			}
+		}
+		for _, group := range groups {
+			if !filtersOK(group.Filters, group.Groups) {
+				return false
+			}
+		}
+		return true
+	}
+	if !filtersOK(req.Filters, req.FilterGroups) {
+		return nil, false
+	}
+	for _, group := range req.Groups {
+		if group.ScopeName != "" || group.Having != "" || !dimension(group.Field) {
+			return nil, false
+		}
+	}
+	for _, sort := range req.Sorts {
+		if sort.ScopeName != "" || sort.CountOf != "" || !dimension(sort.Field) {
+			return nil, false
+		}
+	}
+	for _, sort := range qb.opts.defaultSorts {
+		if len(req.Sorts) == 0 && !dimension(sort.Field) {
+			return nil, false
+		}
+	}
+
+	aggrs := make([]Aggregation, 0, len(req.Aggrs))
+	for _, aggr := range req.Aggrs {
+		if aggr.Expr != "" || aggr.Condition != nil || aggr.NoCase || len(aggr.AddSelects) > 0 {
+			return nil, false
+		}
+		field := qb.schema.LookUpField(aggr.Field)
+		if field == nil || field.DBName == "" {
+			return nil, false
+		}
+		column, ok := measures[measureKey(field.Name, aggr.Op)]
+		if !ok {
+			return nil, false
+		}
+		alias := aggr.Alias
+		if alias == "" {
+			alias = "`" + field.DBName + "`"
+		}
+		aggrs = append(aggrs, Aggregation{Field: column, Op: reaggregateOps[aggr.Op], Alias: alias, Window: aggr.Window})
+	}
+
+	routed := *req
+	routed.Aggrs = aggrs
+	routed.Unscoped = true
+	return &routed, true
+}
+
+// measureKey 度量映射的键
+func measureKey(field string, op AggregationOp) string {
+	return field + "/" + op.String()
+}
+
+// isMeasureColumn 判断字段映射中的名称是否为度量列
+func isMeasureColumn(measures map[string]string, name string) bool {
+	for _, column := range measures {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// setupRollupDB 创建按用户与状态预聚合订单的汇总表，模拟物化视图
+func setupRollupDB(t *testing.T) *gorm.DB {
+	db := setupTestDB(t)
+	assert.NoError(t, db.Exec(`CREATE TABLE order_totals AS
+		SELECT user_id, status, SUM(amount) AS amount_sum, COUNT(amount) AS amount_count, MAX(amount) AS amount_max
+		FROM test_orders GROUP BY user_id, status`).Error)
+	// 汇总表中增加一行标记数据，用于区分查询的是视图还是基础表
+	assert.NoError(t, db.Exec(`INSERT INTO order_totals VALUES (99, 'refunded', 7, 1, 7)`).Error)
+	return db
+}
+
+func TestQueryBuilder_MaterializedView(t *testing.T) {
+	db := setupRollupDB(t)
+	builder := NewQueryBuilder[TestOrder](db, WithMaterializedView(MaterializedView{
+		Table:      "order_totals",
+		Dimensions: []string{"UserID", "Status"},
+		Measures: []Measure{
+			{Field: "Amount", Op: SUM, Column: "amount_sum"},
+			{Field: "Amount", Op: COUNT, Column: "amount_count"},
+			{Field: "Amount", Op: MAX, Column: "amount_max"},
+		},
+	}))
+
+	type result struct {
+		Status string
+		Total  float64
+		Orders int64
+		Amount float64
+	}
+
+	t.Run("Routed", func(t *testing.T) {
+		req := &FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: IN, Value: "paid,pending,refunded"}},
+			Groups:  []Group{{Field: "Status"}},
+			Aggrs: []Aggregation{
+				{Field: "Amount", Op: SUM, Alias: "total"},
+				{Field: "Amount", Op: COUNT, Alias: "orders"},
+				{Field: "Amount", Op: MAX},
+			},
+			Sorts: []Sort{{Field: "Status"}},
+		}
+		table, err := builder.MaterializedViewFor(req)
+		assert.NoError(t, err)
+		assert.Equal(t, "order_totals", table)
+
+		var results []result
+		assert.NoError(t, builder.FindAll(req, &results))
+		assert.Equal(t, []result{
+			{Status: "paid", Total: 150, Orders: 2, Amount: 100},
+			{Status: "pending", Total: 80, Orders: 1, Amount: 80},
+			{Status: "refunded", Total: 7, Orders: 1, Amount: 7},
+		}, results)
+
+		sql := builder.WithDB(db.Session(&gorm.Session{DryRun: true})).Build(req).Find(&[]result{}).Statement.SQL.String()
+		assert.Contains(t, sql, "SUM(`order_totals`.`amount_count`) as orders")
+		assert.Contains(t, sql, "FROM `order_totals`")
+	})
+
+	t.Run("Fallback", func(t *testing.T) {
+		for name, req := range map[string]*FilterRequest{
+			"non-dimension filter": {
+				Filters: []Filter{{Field: "Amount", Op: GT, Value: "60"}},
+				Groups:  []Group{{Field: "Status"}},
+				Aggrs:   []Aggregation{{Field: "Amount", Op: SUM, Alias: "total"}},
+			},
+			"missing measure": {
+				Groups: []Group{{Field: "Status"}},
+				Aggrs:  []Aggregation{{Field: "Amount", Op: AVG, Alias: "total"}},
+			},
+			"no aggregation": {
+				Filters: []Filter{{Field: "Status", Op: EQ, Value: "paid"}},
+			},
+		} {
+			table, err := builder.MaterializedViewFor(req)
+			assert.NoError(t, err, name)
+			assert.Empty(t, table, name)
+		}
+
+		var results []result
+		assert.NoError(t, builder.FindAll(&FilterRequest{
+			Groups: []Group{{Field: "Status"}},
+			Aggrs:  []Aggregation{{Field: "Amount", Op: MIN, Alias: "total"}},
+			Sorts:  []Sort{{Field: "Status"}},
+		}, &results))
+		assert.Equal(t, []result{{Status: "paid", Total: 50}, {Status: "pending", Total: 80}}, results)
+	})
+
+	t.Run("Invalid view", func(t *testing.T) {
+		invalid := NewQueryBuilder[TestOrder](db, WithMaterializedView(MaterializedView{
+			Table:      "order_totals",
+			Dimensions: []string{"Missing"},
+		}))
+		_, err := invalid.MaterializedViewFor(&FilterRequest{Aggrs: []Aggregation{{Field: "Amount", Op: SUM}}})
+		assert.ErrorContains(t, err, "invalid dimension Missing")
+	})
+}
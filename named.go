@@ -0,0 +1,188 @@
+package querybuild
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// namedParamPattern 命名查询过滤取值中的参数占位符，如 {{due_before}}
+var namedParamPattern = regexp.MustCompile(`\{\{([A-Za-z_][A-Za-z0-9_]*)\}\}`)
+
+// ParamSpec 命名查询的参数说明
+type ParamSpec struct {
+	Name     string   `json:"name"`               // 参数名，在过滤取值中以 {{name}} 引用
+	Required bool     `json:"required,omitempty"` // 是否必须提供
+	Default  string   `json:"default,omitempty"`  // 未提供时使用的默认值
+	Values   []string `json:"values,omitempty"`   // 允许的取值，为空时不限制
+}
+
+// NamedQuery 已注册的命名查询
+type NamedQuery struct {
+	Name    string        // 查询名称
+	Request FilterRequest // 过滤取值中可包含参数占位符的请求模板
+	Params  []ParamSpec   // 参数说明
+}
+
+// NamedQueryRegistry 命名查询注册表
+type NamedQueryRegistry struct {
+	queries map[string]NamedQuery
+	mu      sync.RWMutex
+}
+
+// NewNamedQueryRegistry 创建新的命名查询注册表
+func NewNamedQueryRegistry() *NamedQueryRegistry {
+	return &NamedQueryRegistry{
+		queries: make(map[string]NamedQuery),
+	}
+}
+
+// Register 注册命名查询
+func (r *NamedQueryRegistry) Register(query NamedQuery) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queries[query.Name] = query
+}
+
+// Get 获取命名查询
+func (r *NamedQueryRegistry) Get(name string) (NamedQuery, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	query, ok := r.queries[name]
+	return query, ok
+}
+
+// Names 返回按名称排序的全部命名查询名称
+func (r *NamedQueryRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return sortedKeys(r.queries)
+}
+
+// Clone 复制命名查询注册表
+func (r *NamedQueryRegistry) Clone() *NamedQueryRegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clone := NewNamedQueryRegistry()
+	for name, query := range r.queries {
+		clone.queries[name] = query
+	}
+	return clone
+}
+
+// RegisterNamedQuery 注册带参数的命名查询，调用方只能按名称提供参数执行，无需开放完整的过滤能力
+//
+// 请求模板的过滤取值通过 {{name}} 引用参数，例如：
+//
+//	qb.RegisterNamedQuery("overdue_invoices", &FilterRequest{
+//		Filters: []Filter{{Field: "DueAt", Op: LT, Value: "{{before}}"}},
+//	}, ParamSpec{Name: "before", Required: true})
+//
+// 占位符引用了未声明的参数时返回错误。
+func (qb *QueryBuilder[T]) RegisterNamedQuery(name string, req *FilterRequest, params ...ParamSpec) error {
+	if err := checkNamedParams(name, req, params); err != nil {
+		return err
+	}
+
+	template := *req
+	template.Filters = append([]Filter(nil), req.Filters...)
+	template.Sorts = append([]Sort(nil), req.Sorts...)
+	if req.Page != nil {
+		page := *req.Page
+		template.Page = &page
+	}
+	qb.named.Register(NamedQuery{
+		Name:    name,
+		Request: template,
+		Params:  append([]ParamSpec(nil), params...),
+	})
+	return nil
+}
+
+// checkNamedParams 校验命名查询的名称、参数名，以及占位符是否引用了已声明的参数
+func checkNamedParams(name string, req *FilterRequest, params []ParamSpec) error {
+	if !isIdentifier(name) {
+		return fmt.Errorf("invalid named query name: %s", name)
+	}
+
+	declared := make(map[string]bool, len(params))
+	for _, param := range params {
+		if !isIdentifier(param.Name) {
+			return fmt.Errorf("invalid parameter name for named query %s: %s", name, param.Name)
+		}
+		declared[param.Name] = true
+	}
+	for _, filter := range req.Filters {
+		for _, match := range namedParamPattern.FindAllStringSubmatch(filter.Value, -1) {
+			if !declared[match[1]] {
+				return fmt.Errorf("undeclared parameter in named query %s: %s", name, match[1])
+			}
+		}
+	}
+	return nil
+}
+
+// NamedQuery 按名称与参数生成过滤请求，生成的请求按构建器的配置校验
+//
+// 未声明的参数、缺少的必填参数与不在允许取值中的参数返回错误。
+func (qb *QueryBuilder[T]) NamedQuery(name string, params map[string]string) (*FilterRequest, error) {
+	query, ok := qb.named.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("invalid named query: %s", name)
+	}
+
+	values := make(map[string]string, len(query.Params))
+	for _, spec := range query.Params {
+		value, ok := params[spec.Name]
+		switch {
+		case !ok && spec.Required:
+			return nil, fmt.Errorf("missing parameter for named query %s: %s", name, spec.Name)
+		case !ok:
+			value = spec.Default
+		case len(spec.Values) > 0 && !containsString(spec.Values, value):
+			return nil, fmt.Errorf("invalid value for parameter %s of named query %s: %s", spec.Name, name, value)
+		}
+		values[spec.Name] = value
+	}
+	for param := range params {
+		if _, ok := values[param]; !ok {
+			return nil, fmt.Errorf("unknown parameter for named query %s: %s", name, param)
+		}
+	}
+
+	req := query.Request
+	req.Filters = make([]Filter, 0, len(query.Request.Filters))
+	for _, filter := range query.Request.Filters {
+		filter.Value = namedParamPattern.ReplaceAllStringFunc(filter.Value, func(match string) string {
+			return values[strings.Trim(match, "{}")]
+		})
+		req.Filters = append(req.Filters, filter)
+	}
+	req.Sorts = append([]Sort(nil), query.Request.Sorts...)
+	if query.Request.Page != nil {
+		page := *query.Request.Page
+		req.Page = &page
+	}
+
+	if err := qb.Validate(&req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// NamedQueries 返回按名称排序的全部命名查询名称
+func (qb *QueryBuilder[T]) NamedQueries() []string {
+	return qb.named.Names()
+}
+
+// containsString 判断字符串列表是否包含指定字符串
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
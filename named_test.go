@@ -0,0 +1,58 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_NamedQuery(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+
+	err := builder.RegisterNamedQuery("older_users", &FilterRequest{
+		Filters: []Filter{
+			{Field: "Age", Op: GT, Value: "{{min_age}}"},
+			{Field: "Status", Op: EQ, Value: "{{status}}"},
+		},
+		Sorts: []Sort{{Field: "Age", Desc: true}},
+	},
+		ParamSpec{Name: "min_age", Required: true},
+		ParamSpec{Name: "status", Default: "active", Values: []string{"active", "inactive"}},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"older_users"}, builder.NamedQueries())
+
+	t.Run("Invoke", func(t *testing.T) {
+		req, err := builder.NamedQuery("older_users", map[string]string{"min_age": "20"})
+		assert.NoError(t, err)
+		assert.Equal(t, "20", req.Filters[0].Value)
+		assert.Equal(t, "active", req.Filters[1].Value)
+
+		var users []TestUser
+		assert.NoError(t, builder.FindAll(req, &users))
+		assert.Len(t, users, 2)
+		assert.Equal(t, "Bob Johnson", users[0].Name)
+	})
+
+	t.Run("Invalid parameters", func(t *testing.T) {
+		for _, params := range []map[string]string{
+			{},
+			{"min_age": "20", "status": "deleted"},
+			{"min_age": "20", "extra": "x"},
+		} {
+			_, err := builder.NamedQuery("older_users", params)
+			assert.Error(t, err, params)
+		}
+
+		_, err := builder.NamedQuery("unknown", nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("Undeclared placeholder", func(t *testing.T) {
+		err := builder.RegisterNamedQuery("broken", &FilterRequest{
+			Filters: []Filter{{Field: "Age", Op: GT, Value: "{{age}}"}},
+		})
+		assert.Error(t, err)
+	})
+}
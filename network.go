@@ -0,0 +1,79 @@
+package querybuild
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// isNetworkOperator 判断操作符是否为IP网段操作符
+func isNetworkOperator(op Operator) bool {
+	return op == INET_IN_CIDR || op == CIDR_CONTAINS
+}
+
+// parseNetwork 解析IP地址或CIDR网段，IP地址视为只包含自身的网段
+func parseNetwork(value string) (netip.Prefix, error) {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		if prefix.Addr().Is4In6() {
+			return netip.Prefix{}, fmt.Errorf("IPv4-mapped IPv6 prefix is not supported")
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// networkRange 返回网段的首个与最后一个地址的字节表示
+func networkRange(prefix netip.Prefix) (first, last []byte) {
+	first = prefix.Addr().AsSlice()
+	last = append([]byte(nil), first...)
+	for i := prefix.Bits(); i < len(last)*8; i++ {
+		last[i/8] |= 0x80 >> (i % 8)
+	}
+	return first, last
+}
+
+// networkCondition 构建IP网段过滤条件
+//
+// INET_IN_CIDR 匹配字段中的IP地址位于取值网段内的记录，CIDR_CONTAINS 匹配字段中的网段包含取值地址或网段的记录。
+// PostgreSQL 使用 inet 运算符；MySQL 使用 INET_ATON 区间比较，CIDR_CONTAINS 仅支持 IPv4。
+func (qb *QueryBuilder[T]) networkCondition(field string, filter Filter) (string, []interface{}, error) {
+	prefix, err := parseNetwork(filter.Value)
+	if err != nil {
+		return "", nil, &InvalidValueError{Field: filter.Field, Value: filter.Value, Reason: "not an IP address or CIDR network"}
+	}
+
+	dialect := qb.dialect()
+	switch dialect {
+	case dialectPostgres:
+		if filter.Op == INET_IN_CIDR {
+			return "CAST(" + field + " AS inet) <<= CAST(? AS inet)", []interface{}{prefix.String()}, nil
+		}
+		return "CAST(" + field + " AS inet) >>= CAST(? AS inet)", []interface{}{prefix.String()}, nil
+	case dialectMySQL:
+		first, last := networkRange(prefix)
+		if prefix.Addr().Is4() {
+			lo, hi := binary.BigEndian.Uint32(first), binary.BigEndian.Uint32(last)
+			if filter.Op == INET_IN_CIDR {
+				return "INET_ATON(" + field + ") BETWEEN ? AND ?", []interface{}{lo, hi}, nil
+			}
+			start := "INET_ATON(SUBSTRING_INDEX(" + field + ", '/', 1))"
+			size := "POW(2, 32 - SUBSTRING_INDEX(" + field + ", '/', -1))"
+			return start + " <= ? AND " + start + " + " + size + " > ?", []interface{}{lo, hi}, nil
+		}
+		if filter.Op == INET_IN_CIDR {
+			return "INET6_ATON(" + field + ") BETWEEN ? AND ?", []interface{}{first, last}, nil
+		}
+	}
+	return "", nil, fmt.Errorf("operator %s on %s is not supported by dialect: %s", filter.Op, prefix.Addr(), dialect)
+}
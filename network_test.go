@@ -0,0 +1,63 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_NetworkOperators(t *testing.T) {
+	build := func(dialect string, filter Filter) (string, []interface{}, error) {
+		builder := NewQueryBuilder[TestUser](setupDialectDB(t, dialect))
+		var users []TestUser
+		query := builder.Build(&FilterRequest{Filters: []Filter{filter}}).Find(&users)
+		return query.Statement.SQL.String(), query.Statement.Vars, query.Error
+	}
+
+	t.Run("Postgres", func(t *testing.T) {
+		sql, vars, err := build("postgres", Filter{Field: "Tags", Op: INET_IN_CIDR, Value: "10.1.2.3/8"})
+		assert.NoError(t, err)
+		assert.Contains(t, sql, "CAST(`test_users`.`tags` AS inet) <<= CAST(? AS inet)")
+		assert.Equal(t, []interface{}{"10.0.0.0/8"}, vars)
+
+		sql, vars, err = build("postgres", Filter{Field: "Tags", Op: CIDR_CONTAINS, Value: "192.168.1.7"})
+		assert.NoError(t, err)
+		assert.Contains(t, sql, "CAST(`test_users`.`tags` AS inet) >>= CAST(? AS inet)")
+		assert.Equal(t, []interface{}{"192.168.1.7/32"}, vars)
+	})
+
+	t.Run("MySQL IPv4", func(t *testing.T) {
+		sql, vars, err := build("mysql", Filter{Field: "Tags", Op: INET_IN_CIDR, Value: "192.168.0.0/16"})
+		assert.NoError(t, err)
+		assert.Contains(t, sql, "INET_ATON(`test_users`.`tags`) BETWEEN ? AND ?")
+		assert.Equal(t, []interface{}{uint32(0xC0A80000), uint32(0xC0A8FFFF)}, vars)
+
+		sql, vars, err = build("mysql", Filter{Field: "Tags", Op: CIDR_CONTAINS, Value: "10.0.0.1"})
+		assert.NoError(t, err)
+		assert.Contains(t, sql, "INET_ATON(SUBSTRING_INDEX(`test_users`.`tags`, '/', 1)) <= ?")
+		assert.Equal(t, []interface{}{uint32(0x0A000001), uint32(0x0A000001)}, vars)
+	})
+
+	t.Run("MySQL IPv6", func(t *testing.T) {
+		sql, _, err := build("mysql", Filter{Field: "Tags", Op: INET_IN_CIDR, Value: "2001:db8::/32"})
+		assert.NoError(t, err)
+		assert.Contains(t, sql, "INET6_ATON(`test_users`.`tags`) BETWEEN ? AND ?")
+
+		_, _, err = build("mysql", Filter{Field: "Tags", Op: CIDR_CONTAINS, Value: "2001:db8::1"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid value", func(t *testing.T) {
+		_, _, err := build("postgres", Filter{Field: "Tags", Op: INET_IN_CIDR, Value: "10.0.0.0/33"})
+		var invalid *InvalidValueError
+		assert.ErrorAs(t, err, &invalid)
+
+		builder := NewQueryBuilder[TestUser](setupDialectDB(t, "postgres"))
+		assert.Error(t, builder.Validate(&FilterRequest{Filters: []Filter{{Field: "Tags", Op: CIDR_CONTAINS, Value: "not-an-ip"}}}))
+	})
+
+	t.Run("Unsupported dialect", func(t *testing.T) {
+		_, _, err := build("sqlite", Filter{Field: "Tags", Op: INET_IN_CIDR, Value: "10.0.0.0/8"})
+		assert.Error(t, err)
+	})
+}
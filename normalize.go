@@ -0,0 +1,98 @@
+package querybuild
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Normalize 返回请求的规范形式，语义相同的请求规范化后完全相同
+//
+// 字段名不区分大小写地解析为模型字段名（数据库字段名同样适用），过滤条件按字段、操作符与取值排序，
+// IN / NOT IN 的取值排序去重，未指定排序时使用默认排序，分页参数按构建器配置规范化并清除查询结果信息与应用的缺省值。
+// 原请求不会被修改。
+func (qb *QueryBuilder[T]) Normalize(req *FilterRequest) *FilterRequest {
+	norm := *req
+
+	norm.Filters = nil
+	for _, filter := range req.Filters {
+		filter.Field = qb.canonicalField(filter.Field)
+		if filter.Op == IN || filter.Op == NOT_IN {
+			filter.Value = strings.Join(uniqueSorted(strings.Split(filter.Value, ",")), ",")
+		}
+		norm.Filters = append(norm.Filters, filter)
+	}
+	sort.SliceStable(norm.Filters, func(i, j int) bool {
+		a, b := norm.Filters[i], norm.Filters[j]
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		if a.Op != b.Op {
+			return a.Op < b.Op
+		}
+		if a.Value != b.Value {
+			return a.Value < b.Value
+		}
+		if a.Fn != b.Fn {
+			return a.Fn < b.Fn
+		}
+		return !a.NoCase && b.NoCase
+	})
+
+	sorts := req.Sorts
+	if len(sorts) == 0 {
+		sorts = qb.opts.defaultSorts
+	}
+	norm.Sorts = nil
+	for _, s := range sorts {
+		if s.ScopeName == "" && s.CountOf == "" {
+			s.Field = qb.canonicalField(s.Field)
+		}
+		norm.Sorts = append(norm.Sorts, s)
+	}
+
+	if req.Page != nil {
+		page := Pagination{Page: req.Page.Page, PageSize: req.Page.PageSize, Deferred: req.Page.Deferred}
+		qb.normalizePage(&page)
+		norm.Page = &page
+	}
+	norm.Defaults = nil
+	return &norm
+}
+
+// Hash 返回请求规范形式的 SHA-256 哈希，可用作缓存键、审计记录或去重依据
+func (qb *QueryBuilder[T]) Hash(req *FilterRequest) (string, error) {
+	data, err := json.Marshal(qb.Normalize(req))
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// canonicalField 将字段名解析为模型字段名，不区分大小写并支持数据库字段名，无法识别时原样返回
+func (qb *QueryBuilder[T]) canonicalField(name string) string {
+	if field := qb.schema.LookUpField(name); field != nil && field.DBName != "" {
+		return field.Name
+	}
+	for _, field := range qb.schema.Fields {
+		if field.DBName != "" && (strings.EqualFold(field.Name, name) || strings.EqualFold(field.DBName, name)) {
+			return field.Name
+		}
+	}
+	return name
+}
+
+// uniqueSorted 返回排序去重后的取值
+func uniqueSorted(values []string) []string {
+	sort.Strings(values)
+	result := values[:0]
+	for i, value := range values {
+		if i == 0 || value != values[i-1] {
+			result = append(result, value)
+		}
+	}
+	return result
+}
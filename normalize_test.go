@@ -0,0 +1,50 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_Normalize(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db, WithDefaultSort(Sort{Field: "CreatedAt", Desc: true}), WithMaxPageSize(50))
+
+	a := &FilterRequest{
+		Filters: []Filter{
+			{Field: "status", Op: IN, Value: "b,a,b"},
+			{Field: "Age", Op: GT, Value: "20"},
+		},
+		Page: &Pagination{Page: 0, PageSize: 100, Total: 42},
+	}
+	b := &FilterRequest{
+		Filters: []Filter{
+			{Field: "age", Op: GT, Value: "20"},
+			{Field: "Status", Op: IN, Value: "a,b"},
+		},
+		Sorts: []Sort{{Field: "created_at", Desc: true}},
+		Page:  &Pagination{Page: 1, PageSize: 50},
+	}
+
+	norm := builder.Normalize(a)
+	assert.Equal(t, []Filter{
+		{Field: "Age", Op: GT, Value: "20"},
+		{Field: "Status", Op: IN, Value: "a,b"},
+	}, norm.Filters)
+	assert.Equal(t, []Sort{{Field: "CreatedAt", Desc: true}}, norm.Sorts)
+	assert.Equal(t, &Pagination{Page: 1, PageSize: 50}, norm.Page)
+	assert.Equal(t, "status", a.Filters[0].Field, "original request is not modified")
+	assert.Equal(t, int64(42), a.Page.Total)
+
+	hashA, err := builder.Hash(a)
+	assert.NoError(t, err)
+	hashB, err := builder.Hash(b)
+	assert.NoError(t, err)
+	assert.Equal(t, hashA, hashB)
+	assert.Len(t, hashA, 64)
+
+	b.Filters[0].Value = "21"
+	hashC, err := builder.Hash(b)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hashA, hashC)
+}
@@ -0,0 +1,461 @@
+package querybuild
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Option 查询构建器配置项
+type Option func(*options)
+
+// options 查询构建器配置
+type options struct {
+	table        string            // 覆盖模型默认表名
+	readOnly     bool              // 只读构建器，如视图
+	keyFields    []string          // 唯一标识记录的字段，为空时使用主键
+	defaultSorts []Sort            // 请求未指定排序时使用的默认排序
+	defaultRules []DefaultRule     // 请求缺省规则
+	maxPageSize  int               // 每页数量上限，0 表示不限制
+	noTieBreak   bool              // 分页查询不追加主键排序
+	sortFields   map[string]bool   // 允许排序的字段，为空时不限制
+	collations   map[string]string // 排序可使用的排序规则名称到数据库排序规则的映射
+
+	deferredJoin   bool // 分页查询使用延迟连接
+	deferredOffset int  // 使用延迟连接的最小偏移量
+
+	cursorSecret []byte // 游标签名密钥
+
+	syncField string // 增量同步使用的更新时间字段
+
+	history *History // 模型的历史表
+
+	views []MaterializedView // 可提供聚合查询的物化视图
+
+	partitionKey      string // 按时间分区的表的分区键字段
+	partitionRequired bool   // 要求请求限定分区键的上下界
+
+	location *time.Location // 解析时间取值与时间分桶使用的业务时区
+
+	skipEmpty bool // 跳过取值为空的过滤条件
+
+	parallelCount bool // 分页查询并发执行计数查询与数据查询
+
+	cache     Cache         // 查询结果缓存
+	cacheTTL  time.Duration // 缓存有效期
+	cacheTags []string      // 缓存结果附加的标签
+
+	countCache Cache         // 总记录数缓存
+	countTTL   time.Duration // 总记录数缓存有效期
+
+	maxComplexity     int                // 查询复杂度上限，0 表示不限制
+	complexityWeights *ComplexityWeights // 查询复杂度权重，为空时使用默认权重
+
+	readDB *gorm.DB // 读取查询使用的只读副本
+
+	planCacheSize int // 查询计划缓存的最大条目数，0 表示不缓存
+
+	mode      Mode        // 请求存在无效条目时的处理方式
+	onWarning func(error) // 宽松模式下跳过无效条目时的警告处理函数
+
+	limits *Limits // 单个请求的数量上限，为空时不限制
+
+	validateValues bool                         // 按字段类型校验过滤取值
+	enums          map[string]map[string]bool   // 字段允许的枚举取值
+	enumLabels     map[string]map[string]string // 字段的枚举标签到枚举取值的映射
+
+	auditor       Auditor       // 查询审计
+	slowThreshold time.Duration // 慢查询阈值
+	onSlow        SlowQueryFunc // 慢查询回调
+
+	masks map[string]maskRule // 字段的脱敏规则
+
+	dialectOps map[Operator]bool // 当前连接额外支持的过滤操作符
+
+	encryptKeys KeyProvider     // 加密字段的密钥提供方
+	encrypted   map[string]bool // 加密字段
+
+	uuids map[string]UUIDStorage // UUID 字段的存储方式
+
+	mapKeys         KeyStyle // FindMaps 结果中的键名风格
+	checkProjection bool     // 校验选择列与结果结构体字段是否匹配
+
+	versionField string // 批量更新使用的乐观锁版本字段
+
+	allowUnscoped   bool // 允许请求包含软删除的记录
+	allowHardDelete bool // 允许永久删除记录
+
+	debug       bool        // 构建查询时记录调试信息
+	debugLogger DebugLogger // 调试日志输出函数，为空时使用 gorm 日志
+	debugRedact bool        // 调试日志中隐藏过滤取值与绑定参数
+}
+
+// clone 复制配置，避免副本之间共享切片
+func (o options) clone() options {
+	o.defaultSorts = append([]Sort(nil), o.defaultSorts...)
+	o.defaultRules = append([]DefaultRule(nil), o.defaultRules...)
+	o.cacheTags = append([]string(nil), o.cacheTags...)
+	o.keyFields = append([]string(nil), o.keyFields...)
+	o.views = append([]MaterializedView(nil), o.views...)
+	if o.enums != nil {
+		enums := make(map[string]map[string]bool, len(o.enums))
+		for field, values := range o.enums {
+			enums[field] = values
+		}
+		o.enums = enums
+	}
+	if o.enumLabels != nil {
+		enumLabels := make(map[string]map[string]string, len(o.enumLabels))
+		for field, labels := range o.enumLabels {
+			enumLabels[field] = labels
+		}
+		o.enumLabels = enumLabels
+	}
+	if o.masks != nil {
+		masks := make(map[string]maskRule, len(o.masks))
+		for field, rule := range o.masks {
+			masks[field] = rule
+		}
+		o.masks = masks
+	}
+	if o.collations != nil {
+		collations := make(map[string]string, len(o.collations))
+		for name, collation := range o.collations {
+			collations[name] = collation
+		}
+		o.collations = collations
+	}
+	if o.sortFields != nil {
+		sortFields := make(map[string]bool, len(o.sortFields))
+		for field := range o.sortFields {
+			sortFields[field] = true
+		}
+		o.sortFields = sortFields
+	}
+	if o.dialectOps != nil {
+		dialectOps := make(map[Operator]bool, len(o.dialectOps))
+		for op := range o.dialectOps {
+			dialectOps[op] = true
+		}
+		o.dialectOps = dialectOps
+	}
+	if o.encrypted != nil {
+		encrypted := make(map[string]bool, len(o.encrypted))
+		for field := range o.encrypted {
+			encrypted[field] = true
+		}
+		o.encrypted = encrypted
+	}
+	if o.uuids != nil {
+		uuids := make(map[string]UUIDStorage, len(o.uuids))
+		for field, storage := range o.uuids {
+			uuids[field] = storage
+		}
+		o.uuids = uuids
+	}
+	return o
+}
+
+// WithTable 指定查询使用的表名
+func WithTable(table string) Option {
+	return func(o *options) {
+		o.table = table
+	}
+}
+
+// WithDefaultSort 指定请求未包含排序时使用的默认排序
+func WithDefaultSort(sorts ...Sort) Option {
+	return func(o *options) {
+		o.defaultSorts = sorts
+	}
+}
+
+// WithMaxPageSize 指定每页数量上限，超出时自动截断
+func WithMaxPageSize(size int) Option {
+	return func(o *options) {
+		o.maxPageSize = size
+	}
+}
+
+// WithTieBreak 设置分页查询是否追加主键作为最后的排序键，默认开启以保证分页结果稳定
+func WithTieBreak(enabled bool) Option {
+	return func(o *options) {
+		o.noTieBreak = !enabled
+	}
+}
+
+// WithDeferredJoin 对偏移量不小于 minOffset 的分页查询使用延迟连接
+//
+// 延迟连接先分页查询主键再按主键回表，适合 MySQL 上记录较宽的深分页；
+// 单个请求也可以通过 Pagination.Deferred 显式开启。
+func WithDeferredJoin(minOffset int) Option {
+	return func(o *options) {
+		o.deferredJoin = true
+		o.deferredOffset = minOffset
+	}
+}
+
+// WithCursorSecret 指定游标签名使用的密钥，使用游标分页时必须设置
+func WithCursorSecret(secret []byte) Option {
+	return func(o *options) {
+		o.cursorSecret = append([]byte(nil), secret...)
+	}
+}
+
+// WithParallelCount 设置 FindPage 是否在独立的会话中并发执行计数查询与数据查询
+//
+// 数据库响应较慢时可显著降低分页查询的延迟，但每次查询会同时占用两个连接。
+func WithParallelCount(enabled bool) Option {
+	return func(o *options) {
+		o.parallelCount = enabled
+	}
+}
+
+// WithCache 指定查询结果缓存，结果按模型表名与请求内容缓存 ttl 时长
+//
+// 缓存结果总是带有模型表名标签，tags 为附加的标签，可通过 InvalidateCache 或 Cache.Invalidate 失效。
+func WithCache(cache Cache, ttl time.Duration, tags ...string) Option {
+	return func(o *options) {
+		o.cache = cache
+		o.cacheTTL = ttl
+		o.cacheTags = tags
+	}
+}
+
+// WithCountCache 指定总记录数缓存，FindPage 与 Count 按过滤条件缓存总记录数 ttl 时长
+//
+// 计数缓存独立于结果缓存，缓存键忽略排序与分页，适合总数无需实时准确的昂贵计数。
+func WithCountCache(cache Cache, ttl time.Duration) Option {
+	return func(o *options) {
+		o.countCache = cache
+		o.countTTL = ttl
+	}
+}
+
+// WithMaxComplexity 指定查询复杂度上限，超出时 Build 返回 QueryTooComplexError
+//
+// weights 为空时使用 DefaultComplexityWeights。
+func WithMaxComplexity(max int, weights ...ComplexityWeights) Option {
+	return func(o *options) {
+		o.maxComplexity = max
+		if len(weights) > 0 {
+			w := weights[0]
+			o.complexityWeights = &w
+		}
+	}
+}
+
+// WithReadDB 指定读取查询使用的只读副本
+//
+// 查询、计数与统计使用副本，UpdateExpr 等写入操作始终使用主库；
+// 需要读取刚写入的数据时可通过 UsePrimary 标记上下文改为读取主库。
+func WithReadDB(replica *gorm.DB) Option {
+	return func(o *options) {
+		o.readDB = replica
+	}
+}
+
+// WithPlanCache 开启查询计划缓存，最多缓存 size 种请求结构
+//
+// 仅包含过滤条件、普通排序与分页的请求会按字段、操作符与排序结构缓存解析结果，
+// 结构相同的请求跳过字段校验与SQL拼接，仅重新绑定取值。缓存已满时不再加入新的结构。
+func WithPlanCache(size int) Option {
+	return func(o *options) {
+		o.planCacheSize = size
+	}
+}
+
+// WithStrict 开启严格模式，请求存在任何问题时整个请求失败并返回 *ValidationError
+//
+// 适用于包含权限过滤条件的请求，避免部分条件失效后扩大结果集。
+func WithStrict() Option {
+	return func(o *options) {
+		o.mode = ModeStrict
+	}
+}
+
+// WithLenient 开启宽松模式，跳过请求中的无效条目，并将每个被跳过的条目交给 onWarning 处理
+func WithLenient(onWarning func(error)) Option {
+	return func(o *options) {
+		o.mode = ModeLenient
+		o.onWarning = onWarning
+	}
+}
+
+// WithLimits 指定单个请求中过滤条件、排序、连接与 IN 取值的数量上限
+//
+// 超出上限的请求在 Build 时整体失败，Validate 会报告全部超出的上限。
+func WithLimits(limits Limits) Option {
+	return func(o *options) {
+		o.limits = &limits
+	}
+}
+
+// WithValueValidation 开启过滤取值校验
+//
+// 取值需能转换为字段类型（整数、浮点数、布尔值、时间），字符串不超过字段的 size，
+// 声明了枚举取值的字段仅允许枚举中的取值。
+func WithValueValidation() Option {
+	return func(o *options) {
+		o.validateValues = true
+	}
+}
+
+// WithEnum 声明字段允许的枚举取值，覆盖字段的 enum 标签，需配合 WithValueValidation 使用
+func WithEnum(field string, values ...string) Option {
+	return func(o *options) {
+		if o.enums == nil {
+			o.enums = make(map[string]map[string]bool)
+		}
+		allowed := make(map[string]bool, len(values))
+		for _, value := range values {
+			allowed[value] = true
+		}
+		o.enums[field] = allowed
+	}
+}
+
+// WithEnumLabels 声明字段的枚举标签到枚举取值的映射，如 {"已激活": "active"}
+//
+// Build 时等值与 IN 过滤条件中的标签转换为对应的取值；字段未通过 WithEnum 或 enum 标签声明枚举取值时，
+// 映射中的取值即为允许的枚举取值。enum 标签也可使用 取值:标签 的写法，如 enum:"active:已激活,disabled:已停用"。
+func WithEnumLabels(field string, labels map[string]string) Option {
+	return func(o *options) {
+		if o.enumLabels == nil {
+			o.enumLabels = make(map[string]map[string]string)
+		}
+		copied := make(map[string]string, len(labels))
+		for label, value := range labels {
+			copied[label] = value
+		}
+		o.enumLabels[field] = copied
+	}
+}
+
+// WithAuditor 指定查询审计，每次执行由构建器构建的查询后生成审计记录
+//
+// 审计通过 gorm 回调实现，首次使用时在数据库连接上注册回调，构建器之外的查询不受影响。
+func WithAuditor(auditor Auditor) Option {
+	return func(o *options) {
+		o.auditor = auditor
+	}
+}
+
+// WithDebugLogger 指定调试日志的输出函数，未指定时使用数据库连接的 gorm 日志
+func WithDebugLogger(logger DebugLogger) Option {
+	return func(o *options) {
+		o.debugLogger = logger
+	}
+}
+
+// WithDebugRedaction 在调试日志中隐藏过滤取值与绑定参数
+func WithDebugRedaction() Option {
+	return func(o *options) {
+		o.debugRedact = true
+	}
+}
+
+// WithSlowQueryThreshold 指定慢查询阈值，构建器构建的查询执行耗时不小于 threshold 时调用 fn
+//
+// 回调可取得原始请求，便于定位滥用的过滤模式。
+func WithSlowQueryThreshold(threshold time.Duration, fn SlowQueryFunc) Option {
+	return func(o *options) {
+		o.slowThreshold = threshold
+		o.onSlow = fn
+	}
+}
+
+// WithMask 指定字段的SQL脱敏表达式，上下文未通过 WithUnmasked 授权时选择表达式的结果替代字段值
+//
+// 表达式直接拼接到SQL中，不能包含来自请求的内容，例如 CONCAT(LEFT(email, 2), '***')。
+func WithMask(field, sql string) Option {
+	return func(o *options) {
+		if o.masks == nil {
+			o.masks = make(map[string]maskRule)
+		}
+		rule := o.masks[field]
+		rule.sql = sql
+		o.masks[field] = rule
+	}
+}
+
+// WithMaskFunc 指定字段的脱敏函数，上下文未通过 WithUnmasked 授权时在查询结果上调用
+func WithMaskFunc(field string, fn MaskFunc) Option {
+	return func(o *options) {
+		if o.masks == nil {
+			o.masks = make(map[string]maskRule)
+		}
+		rule := o.masks[field]
+		rule.fn = fn
+		o.masks[field] = rule
+	}
+}
+
+// WithSyncField 指定增量同步使用的更新时间字段，默认为 UpdatedAt
+func WithSyncField(field string) Option {
+	return func(o *options) {
+		o.syncField = field
+	}
+}
+
+// WithHistory 指定模型的历史表，通过 AsOf 与 During 查询历史版本
+func WithHistory(h History) Option {
+	return func(o *options) {
+		o.history = &h
+	}
+}
+
+// WithLocation 指定业务时区，时间字段的过滤取值与时间分桶按该时区计算，而非数据库会话的时区
+//
+// 不含时区的时间取值按业务时区解析，仅包含日期的取值表示业务时区中的一整天。
+// 请求可通过 FilterRequest.TimeZone 覆盖。
+func WithLocation(loc *time.Location) Option {
+	return func(o *options) {
+		o.location = loc
+	}
+}
+
+// WithSkipEmpty 设置查询是否跳过取值为空的过滤条件，适用于表单将未填写的字段以空字符串提交的场景
+//
+// 仅跳过需要取值的操作符，IS_NULL 与 NOT_NULL 不受影响；请求可通过 FilterRequest.SkipEmpty 覆盖。
+// UpdateExpr 总是使用全部过滤条件，避免跳过条件后扩大更新范围。
+func WithSkipEmpty(enabled bool) Option {
+	return func(o *options) {
+		o.skipEmpty = enabled
+	}
+}
+
+// AllowSortFields 限制允许排序的字段，独立于过滤字段校验
+//
+// 字段可为模型字段名、alias.column 或 CountOf 的关联名称，主键始终允许排序。
+// 多次调用时取并集。
+func AllowSortFields(fields ...string) Option {
+	return func(o *options) {
+		if o.sortFields == nil {
+			o.sortFields = make(map[string]bool, len(fields))
+		}
+		for _, field := range fields {
+			o.sortFields[field] = true
+		}
+	}
+}
+
+// Clone 复制构建器并应用额外配置，副本拥有独立的作用域、表达式、命名查询、函数与取值编码器注册表及查询钩子与结果转换管道
+func (qb *QueryBuilder[T]) Clone(opts ...Option) *QueryBuilder[T] {
+	clone := &QueryBuilder[T]{
+		db:          qb.db,
+		registry:    qb.registry.Clone(),
+		expressions: qb.expressions.Clone(),
+		named:       qb.named.Clone(),
+		functions:   qb.functions.Clone(),
+		encoders:    qb.encoders.Clone(),
+		model:       qb.model,
+		opts:        qb.opts.clone(),
+		hooks:       qb.hooks.clone(),
+	}
+	for _, opt := range opts {
+		opt(&clone.opts)
+	}
+	clone.initFields()
+	return clone
+}
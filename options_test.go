@@ -0,0 +1,78 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestQueryBuilder_Options(t *testing.T) {
+	db := setupTestDB(t)
+
+	t.Run("WithTable", func(t *testing.T) {
+		assert.NoError(t, db.Table("archived_users").AutoMigrate(&TestUser{}))
+		assert.NoError(t, db.Table("archived_users").Create(&TestUser{Name: "Old User", Status: "archived"}).Error)
+
+		builder := NewQueryBuilder[TestUser](db, WithTable("archived_users"))
+		var users []TestUser
+		err := builder.FindAll(&FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "archived"}},
+		}, &users)
+		assert.NoError(t, err)
+		assert.Len(t, users, 1)
+		assert.Equal(t, "Old User", users[0].Name)
+	})
+
+	t.Run("WithDefaultSort", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db, WithDefaultSort(Sort{Field: "Age", Desc: true}))
+		var users []TestUser
+		assert.NoError(t, builder.FindAll(&FilterRequest{}, &users))
+		assert.Equal(t, 35, users[0].Age)
+
+		// 请求中的排序优先于默认排序
+		assert.NoError(t, builder.FindAll(&FilterRequest{Sorts: []Sort{{Field: "Age"}}}, &users))
+		assert.Equal(t, 25, users[0].Age)
+	})
+
+	t.Run("WithMaxPageSize", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db, WithMaxPageSize(2))
+		var users []TestUser
+		req := &FilterRequest{Page: &Pagination{Page: 1, PageSize: 100}}
+		assert.NoError(t, builder.FindAll(req, &users))
+		assert.Len(t, users, 2)
+		assert.Equal(t, 2, req.Page.PageSize)
+	})
+}
+
+func TestQueryBuilder_Clone(t *testing.T) {
+	db := setupTestDB(t)
+	base := NewQueryBuilder[TestUser](db, WithMaxPageSize(10))
+	base.RegisterScope(FilterScope, "activeUsers", func(db *gorm.DB) *gorm.DB {
+		return db.Where("status = ?", "active")
+	})
+
+	admin := base.Clone(WithDefaultSort(Sort{Field: "Age", Desc: true}))
+	admin.RegisterScope(FilterScope, "adults", func(db *gorm.DB) *gorm.DB {
+		return db.Where("age >= ?", 30)
+	})
+
+	t.Run("Clone inherits scopes and options", func(t *testing.T) {
+		var users []TestUser
+		req := &FilterRequest{
+			CustomFilter: &CustomFilter{ScopeName: "activeUsers"},
+			Page:         &Pagination{Page: 1, PageSize: 50},
+		}
+		assert.NoError(t, admin.FindAll(req, &users))
+		assert.Len(t, users, 2)
+		assert.Equal(t, 35, users[0].Age)
+		assert.Equal(t, 10, req.Page.PageSize)
+	})
+
+	t.Run("Clone registrations do not leak to base", func(t *testing.T) {
+		_, ok := base.registry.Get(FilterScope, "adults")
+		assert.False(t, ok)
+		_, ok = admin.registry.Get(FilterScope, "adults")
+		assert.True(t, ok)
+	})
+}
@@ -0,0 +1,194 @@
+package querybuild
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// DefaultPageSize 未指定每页数量时使用的默认值
+const DefaultPageSize = 20
+
+// deferredJoinAlias 延迟连接分页中主键子查询的别名
+const deferredJoinAlias = "qb_page"
+
+// PageResult 分页查询结果
+type PageResult[T any] struct {
+	Items      []T              `json:"items"`
+	Pagination *Pagination      `json:"pagination"`
+	Defaults   []AppliedDefault `json:"defaults,omitempty"` // 按缺省规则补全的内容
+}
+
+// normalizePage 规范化页码与每页数量
+func (qb *QueryBuilder[T]) normalizePage(page *Pagination) {
+	if page.Page < 1 {
+		page.Page = 1
+	}
+	if page.PageSize <= 0 {
+		page.PageSize = DefaultPageSize
+	}
+
+	// 限制每页数量上限
+	if qb.opts.maxPageSize > 0 && page.PageSize > qb.opts.maxPageSize {
+		page.PageSize = qb.opts.maxPageSize
+	}
+}
+
+// countPage 计算总记录数并规范化分页参数，返回当前页的偏移量
+//
+// count 为 false 时由调用方另行计算总记录数与分页信息。
+func (qb *QueryBuilder[T]) countPage(query *gorm.DB, page *Pagination, count bool) int {
+	// 计算总记录数
+	if count {
+		query.Count(&page.Total)
+	}
+
+	// 规范化页码与每页数量，并计算分页信息
+	qb.normalizePage(page)
+	if count {
+		page.computeMeta()
+	}
+
+	return (page.Page - 1) * page.PageSize
+}
+
+// canDeferJoin 判断请求是否可以使用延迟连接分页
+//
+// 分组、聚合、去重与自定义连接会改变结果行与主键的对应关系，此时使用普通分页。
+func (qb *QueryBuilder[T]) canDeferJoin(req *FilterRequest) bool {
+	if req.Page == nil || (!req.Page.Deferred && !qb.opts.deferredJoin) {
+		return false
+	}
+	return !req.Distinct && len(req.Groups) == 0 && len(req.Aggrs) == 0 &&
+		len(req.Joins) == 0 && len(qb.keys) > 0
+}
+
+// applyDeferredPagination 应用延迟连接分页
+//
+// 先在子查询中按相同的条件与排序分页选出主键，再与原表按主键连接取回完整记录，
+// 偏移量较大且记录较宽时可避免扫描并丢弃大量完整行。偏移量低于 WithDeferredJoin
+// 指定的阈值且请求未显式开启时使用普通分页。
+func (qb *QueryBuilder[T]) applyDeferredPagination(query *gorm.DB, page *Pagination, count bool) *gorm.DB {
+	offset := qb.countPage(query, page, count)
+	if !page.Deferred && offset < qb.opts.deferredOffset {
+		return query.Offset(offset).Limit(page.PageSize)
+	}
+
+	table := qb.tableName()
+	selects := make([]string, 0, len(qb.keys))
+	conds := make([]string, 0, len(qb.keys))
+	for _, pk := range qb.keys {
+		column := quoteField(FieldInfo{TableName: table, Name: pk.DBName})
+		selects = append(selects, column)
+		conds = append(conds, fmt.Sprintf("%s = %s", column,
+			quoteField(FieldInfo{TableName: deferredJoinAlias, Name: pk.DBName})))
+	}
+
+	keys := query.Session(&gorm.Session{}).
+		Select(strings.Join(selects, ", ")).
+		Offset(offset).
+		Limit(page.PageSize)
+	return query.Joins(fmt.Sprintf("INNER JOIN (?) AS `%s` ON %s",
+		deferredJoinAlias, strings.Join(conds, " AND ")), keys)
+}
+
+// computeMeta 根据总记录数计算总页数与前后页信息
+func (p *Pagination) computeMeta() {
+	p.TotalPages = int((p.Total + int64(p.PageSize) - 1) / int64(p.PageSize))
+	p.HasPrev = p.Page > 1
+	p.HasNext = p.Page < p.TotalPages
+}
+
+// FindPage 分页查询记录，返回当前页数据及分页信息，请求未指定分页时使用第一页
+func (qb *QueryBuilder[T]) FindPage(req *FilterRequest) (*PageResult[T], error) {
+	page := req.Page
+	req, err := qb.beforeFind(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Page == nil {
+		pageReq := *req
+		pageReq.Page = &Pagination{}
+		req = &pageReq
+	}
+
+	var result *PageResult[T]
+	if qb.opts.cache != nil {
+		result, err = qb.cachedPage(req)
+	} else {
+		result, err = qb.findPage(req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if page != nil && result.Pagination != page && !pageDefaulted(result.Defaults) {
+		// 钩子替换了分页信息时写回原请求，按缺省规则补全的分页信息只通过返回结果提供
+		*page = *result.Pagination
+		result.Pagination = page
+	}
+
+	if result.Items, err = qb.afterFind(result.Items); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// findPage 执行分页查询
+func (qb *QueryBuilder[T]) findPage(req *FilterRequest) (*PageResult[T], error) {
+	req, err := qb.prepareRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if qb.opts.parallelCount {
+		return qb.findPageParallel(req)
+	}
+
+	var items []T
+	if err := qb.Build(req).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return &PageResult[T]{Items: items, Pagination: req.Page, Defaults: req.Defaults}, nil
+}
+
+// findPageParallel 在独立的会话中并发执行计数查询与数据查询，任一查询失败时取消另一个
+func (qb *QueryBuilder[T]) findPageParallel(req *FilterRequest) (*PageResult[T], error) {
+	ctx, cancel := context.WithCancel(qb.db.Statement.Context)
+	defer cancel()
+	builder := qb.WithContext(ctx)
+
+	countReq := *req
+	countReq.Page = nil
+
+	var (
+		wg            sync.WaitGroup
+		total         int64
+		items         []T
+		countErr, err error
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if total, countErr = builder.Count(&countReq); countErr != nil {
+			cancel()
+		}
+	}()
+
+	if err = builder.build(req, false).Find(&items).Error; err != nil {
+		cancel()
+	}
+	wg.Wait()
+
+	if err == nil {
+		err = countErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	req.Page.Total = total
+	req.Page.computeMeta()
+	return &PageResult[T]{Items: items, Pagination: req.Page, Defaults: req.Defaults}, nil
+}
@@ -0,0 +1,146 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestQueryBuilder_FindPage(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db, WithMaxPageSize(50))
+
+	tests := []struct {
+		name     string
+		page     *Pagination
+		expected Pagination
+		items    int
+	}{
+		{
+			name:     "First page",
+			page:     &Pagination{Page: 1, PageSize: 2},
+			expected: Pagination{Page: 1, PageSize: 2, Total: 3, TotalPages: 2, HasNext: true},
+			items:    2,
+		},
+		{
+			name:     "Last page",
+			page:     &Pagination{Page: 2, PageSize: 2},
+			expected: Pagination{Page: 2, PageSize: 2, Total: 3, TotalPages: 2, HasPrev: true},
+			items:    1,
+		},
+		{
+			name:     "Normalized values",
+			page:     &Pagination{Page: 0, PageSize: 500},
+			expected: Pagination{Page: 1, PageSize: 50, Total: 3, TotalPages: 1},
+			items:    3,
+		},
+		{
+			name:     "Default page",
+			expected: Pagination{Page: 1, PageSize: DefaultPageSize, Total: 3, TotalPages: 1},
+			items:    3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &FilterRequest{Page: tt.page}
+			result, err := builder.FindPage(req)
+			assert.NoError(t, err)
+			assert.Len(t, result.Items, tt.items)
+			assert.Equal(t, tt.expected, *result.Pagination)
+		})
+	}
+
+	t.Run("Empty result", func(t *testing.T) {
+		result, err := builder.FindPage(&FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "unknown"}},
+			Page:    &Pagination{Page: 1, PageSize: 10},
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, result.Items)
+		assert.Equal(t, 0, result.Pagination.TotalPages)
+		assert.False(t, result.Pagination.HasNext)
+	})
+}
+
+func TestQueryBuilder_DeferredJoin(t *testing.T) {
+	db := setupTestDB(t)
+
+	t.Run("Per request", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db)
+		req := &FilterRequest{
+			Filters: []Filter{{Field: "Age", Op: GE, Value: "25"}},
+			Sorts:   []Sort{{Field: "Age", Desc: true}},
+			Page:    &Pagination{Page: 2, PageSize: 1, Deferred: true},
+		}
+
+		var users []TestUser
+		stmt := builder.Build(req).Session(&gorm.Session{DryRun: true}).Find(&users).Statement
+		sql := stmt.SQL.String()
+		assert.Contains(t, sql, "INNER JOIN (SELECT `test_users`.`id` FROM `test_users`")
+		assert.Contains(t, sql, "AS `qb_page` ON `test_users`.`id` = `qb_page`.`id`")
+
+		users = nil
+		assert.NoError(t, builder.Build(req).Find(&users).Error)
+		assert.Len(t, users, 1)
+		assert.Equal(t, "Jane Smith", users[0].Name)
+		assert.Equal(t, int64(3), req.Page.Total)
+	})
+
+	t.Run("Offset threshold", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db, WithDeferredJoin(2))
+
+		var users []TestUser
+		req := &FilterRequest{Page: &Pagination{Page: 1, PageSize: 2}}
+		stmt := builder.Build(req).Session(&gorm.Session{DryRun: true}).Find(&users).Statement
+		assert.NotContains(t, stmt.SQL.String(), "qb_page")
+
+		req = &FilterRequest{Page: &Pagination{Page: 2, PageSize: 2}}
+		assert.NoError(t, builder.Build(req).Find(&users).Error)
+		assert.Len(t, users, 1)
+		assert.Equal(t, uint(3), users[0].ID)
+	})
+
+	t.Run("Skipped for aggregations", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db, WithDeferredJoin(0))
+
+		var results []map[string]interface{}
+		req := &FilterRequest{
+			Groups: []Group{{Field: "Status"}},
+			Aggrs:  []Aggregation{{Field: "Age", Op: COUNT}},
+			Page:   &Pagination{Page: 1, PageSize: 10},
+		}
+		stmt := builder.Build(req).Session(&gorm.Session{DryRun: true}).Find(&results).Statement
+		assert.NotContains(t, stmt.SQL.String(), "qb_page")
+	})
+}
+
+func TestQueryBuilder_FindPageParallel(t *testing.T) {
+	db := setupTestDB(t)
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	// 内存数据库的每个连接相互独立，限制为单连接以共享测试数据
+	sqlDB.SetMaxOpenConns(1)
+
+	builder := NewQueryBuilder[TestUser](db, WithParallelCount(true))
+
+	t.Run("Page with metadata", func(t *testing.T) {
+		result, err := builder.FindPage(&FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+			Sorts:   []Sort{{Field: "Age"}},
+			Page:    &Pagination{Page: 2, PageSize: 1},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, result.Items, 1)
+		assert.Equal(t, "Bob Johnson", result.Items[0].Name)
+		assert.Equal(t, Pagination{Page: 2, PageSize: 1, Total: 2, TotalPages: 2, HasPrev: true}, *result.Pagination)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		_, err := builder.FindPage(&FilterRequest{
+			Filters: []Filter{{Field: "Unknown", Op: EQ, Value: "x"}},
+		})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,213 @@
+package querybuild
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ErrUnboundedScan 请求未限定分区键的上下界，但构建器要求限定
+var ErrUnboundedScan = errors.New("request must bound the partition key")
+
+// PartitionRange 请求的过滤条件限定的分区键取值范围，From 包含在内，To 不包含在内，为空表示不限
+type PartitionRange struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// Bounded 判断范围是否同时限定了上下界
+func (r PartitionRange) Bounded() bool {
+	return r.From != nil && r.To != nil
+}
+
+// WithPartitionKey 声明按时间分区的表的分区键字段
+//
+// 请求中分区键的比较、区间与 IN 过滤条件（包括嵌套的非取反过滤条件组）按业务时区解析后合并为一个取值范围，
+// 并以 key >= ? AND key < ? 的形式附加到查询的顶层条件，使数据库能够按范围裁剪分区。
+// required 为 true 时未同时限定上下界的请求返回 ErrUnboundedScan，避免扫描全部分区。
+func WithPartitionKey(field string, required bool) Option {
+	return func(o *options) {
+		o.partitionKey = field
+		o.partitionRequired = required
+	}
+}
+
+// PartitionRange 返回请求的过滤条件限定的分区键取值范围，未配置分区键时返回错误
+func (qb *QueryBuilder[T]) PartitionRange(req *FilterRequest) (PartitionRange, error) {
+	field, err := qb.partitionField()
+	if err != nil {
+		return PartitionRange{}, err
+	}
+	loc, err := qb.requestLocation(req)
+	if err != nil {
+		return PartitionRange{}, err
+	}
+	return qb.timeRange(field, req, loc)
+}
+
+// partitionField 返回分区键字段
+func (qb *QueryBuilder[T]) partitionField() (*schema.Field, error) {
+	if qb.opts.partitionKey == "" {
+		return nil, fmt.Errorf("partition key is not configured")
+	}
+	field := qb.schema.LookUpField(qb.opts.partitionKey)
+	if field == nil || field.DBName == "" {
+		return nil, fmt.Errorf("invalid partition key: %s", qb.opts.partitionKey)
+	}
+	if field.DataType != schema.Time {
+		return nil, fmt.Errorf("partition key must be a time field: %s", qb.opts.partitionKey)
+	}
+	return field, nil
+}
+
+// applyPartitionRange 以请求限定的分区键范围约束查询，未配置分区键时不做处理
+func (qb *QueryBuilder[T]) applyPartitionRange(query *gorm.DB, req *FilterRequest, loc *time.Location) *gorm.DB {
+	if qb.opts.partitionKey == "" {
+		return query
+	}
+	field, err := qb.partitionField()
+	if err != nil {
+		query.AddError(err)
+		return query
+	}
+	r, err := qb.timeRange(field, req, loc)
+	if err != nil {
+		query.AddError(err)
+		return query
+	}
+	if qb.opts.partitionRequired && !r.Bounded() {
+		query.AddError(fmt.Errorf("%w: %s", ErrUnboundedScan, field.Name))
+		return query
+	}
+
+	column := quoteField(qb.fields[field.Name])
+	var conds []string
+	var args []interface{}
+	if r.From != nil {
+		conds = append(conds, column+" >= ?")
+		args = append(args, r.From.UTC())
+	}
+	if r.To != nil {
+		conds = append(conds, column+" < ?")
+		args = append(args, r.To.UTC())
+	}
+	if len(conds) == 0 {
+		return query
+	}
+	return whereCondition(query, strings.Join(conds, " AND "), args)
+}
+
+// timeRange 合并过滤条件与嵌套的非取反过滤条件组中时间字段的取值范围
+func (qb *QueryBuilder[T]) timeRange(field *schema.Field, req *FilterRequest, loc *time.Location) (PartitionRange, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	var r PartitionRange
+	var walk func(filters []Filter, groups []FilterGroup) error
+	walk = func(filters []Filter, groups []FilterGroup) error {
+		for _, filter := range filters {
+			if filter.Fn != "" || filter.NoCase {
+				continue
+			}
+			// 与构建过滤条件相同按模型字段名匹配，列名等无效的字段不视为限定了范围
+			if _, err := qb.validateField(filter.Field); err != nil || filter.Field != field.Name {
+				continue
+			}
+			from, to, err := filterRange(filter, loc)
+			if err != nil {
+				return err
+			}
+			if from != nil && (r.From == nil || from.After(*r.From)) {
+				r.From = from
+			}
+			if to != nil && (r.To == nil || to.Before(*r.To)) {
+				r.To = to
+			}
+		}
+		for _, group := range groups {
+			if group.Not {
+				continue
+			}
+			if err := walk(group.Filters, group.Groups); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(req.Filters, req.FilterGroups); err != nil {
+		return PartitionRange{}, err
+	}
+	return r, nil
+}
+
+// filterRange 返回单个时间过滤条件限定的范围，仅包含日期的取值表示业务时区中的一整天，
+// 不限定范围的操作符返回 nil
+func filterRange(filter Filter, loc *time.Location) (from, to *time.Time, err error) {
+	parse := func(value string) (time.Time, bool, error) {
+		t, dateOnly, ok := parseTimeIn(strings.TrimSpace(value), loc)
+		if !ok {
+			return time.Time{}, false, &InvalidValueError{Field: filter.Field, Value: value, Reason: "not a time"}
+		}
+		return t, dateOnly, nil
+	}
+	// after 返回取值之后的第一个时刻：日期取值为次日零点，时间取值为下一纳秒
+	after := func(t time.Time, dateOnly bool) *time.Time {
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		} else {
+			t = t.Add(time.Nanosecond)
+		}
+		return &t
+	}
+
+	switch filter.Op {
+	case EQ, IN:
+		for _, value := range strings.Split(filter.Value, ",") {
+			t, dateOnly, err := parse(value)
+			if err != nil {
+				return nil, nil, err
+			}
+			if from == nil || t.Before(*from) {
+				from = &t
+			}
+			if end := after(t, dateOnly); to == nil || end.After(*to) {
+				to = end
+			}
+		}
+		return from, to, nil
+	case BETWEEN:
+		lower, upper, ok := strings.Cut(filter.Value, ",")
+		if !ok || strings.Contains(upper, ",") {
+			return nil, nil, &InvalidValueError{Field: filter.Field, Value: filter.Value, Reason: "not a time range"}
+		}
+		start, _, err := parse(lower)
+		if err != nil {
+			return nil, nil, err
+		}
+		end, dateOnly, err := parse(upper)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &start, after(end, dateOnly), nil
+	case GT, GE, LT, LE:
+		t, dateOnly, err := parse(filter.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch filter.Op {
+		case GT:
+			return after(t, dateOnly), nil, nil
+		case GE:
+			return &t, nil, nil
+		case LT:
+			return nil, &t, nil
+		default:
+			return nil, after(t, dateOnly), nil
+		}
+	}
+	return nil, nil, nil
+}
@@ -0,0 +1,83 @@
+package querybuild
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestQueryBuilder_PartitionKey(t *testing.T) {
+	db := setupTestDB(t)
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	assert.NoError(t, err)
+	builder := NewQueryBuilder[TestUser](db, WithPartitionKey("CreatedAt", true), WithLocation(shanghai))
+
+	t.Run("Range", func(t *testing.T) {
+		r, err := builder.PartitionRange(&FilterRequest{
+			Filters: []Filter{{Field: "CreatedAt", Op: BETWEEN, Value: "2026-01-01,2026-03-31"}},
+			FilterGroups: []FilterGroup{
+				{Filters: []Filter{{Field: "CreatedAt", Op: GE, Value: "2026-02-01"}}},
+				{Not: true, Filters: []Filter{{Field: "CreatedAt", Op: LT, Value: "2026-03-01"}}},
+			},
+		})
+		assert.NoError(t, err)
+		if assert.True(t, r.Bounded()) {
+			assert.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, shanghai), *r.From)
+			assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, shanghai), *r.To)
+		}
+
+		r, err = builder.PartitionRange(&FilterRequest{
+			Filters: []Filter{{Field: "CreatedAt", Op: IN, Value: "2026-01-05,2026-01-02 08:00:00"}},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2026, 1, 2, 8, 0, 0, 0, shanghai), *r.From)
+		assert.Equal(t, time.Date(2026, 1, 6, 0, 0, 0, 0, shanghai), *r.To)
+
+		r, err = builder.PartitionRange(&FilterRequest{
+			Filters: []Filter{{Field: "CreatedAt", Op: GT_OR_NULL, Value: "2026-01-01"}},
+		})
+		assert.NoError(t, err)
+		assert.False(t, r.Bounded())
+	})
+
+	t.Run("Constrain query", func(t *testing.T) {
+		dryRun := builder.WithDB(db.Session(&gorm.Session{DryRun: true}))
+		stmt := dryRun.Build(&FilterRequest{
+			Filters: []Filter{{Field: "CreatedAt", Op: EQ, Value: "2026-01-05"}},
+		}).Find(&[]TestUser{}).Statement
+		assert.Contains(t, stmt.SQL.String(), "`test_users`.`created_at` >= ? AND `test_users`.`created_at` < ?")
+		assert.Contains(t, stmt.Vars, time.Date(2026, 1, 4, 16, 0, 0, 0, time.UTC))
+		assert.Contains(t, stmt.Vars, time.Date(2026, 1, 5, 16, 0, 0, 0, time.UTC))
+
+		count, err := builder.Count(&FilterRequest{
+			Filters: []Filter{{Field: "CreatedAt", Op: GE, Value: time.Now().Add(-36 * time.Hour).Format(time.RFC3339)}},
+			FilterGroups: []FilterGroup{
+				{Filters: []Filter{{Field: "CreatedAt", Op: LT, Value: time.Now().Add(time.Hour).Format(time.RFC3339)}}},
+			},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("Unbounded scans", func(t *testing.T) {
+		_, err := builder.Count(&FilterRequest{})
+		assert.True(t, errors.Is(err, ErrUnboundedScan))
+
+		_, err = builder.Count(&FilterRequest{Filters: []Filter{{Field: "CreatedAt", Op: GE, Value: "2026-01-01"}}})
+		assert.ErrorIs(t, err, ErrUnboundedScan)
+
+		optional := NewQueryBuilder[TestUser](db, WithPartitionKey("CreatedAt", false))
+		count, err := optional.Count(&FilterRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+	})
+
+	t.Run("Invalid key", func(t *testing.T) {
+		invalid := NewQueryBuilder[TestUser](db, WithPartitionKey("Name", false))
+		_, err := invalid.Count(&FilterRequest{})
+		assert.ErrorContains(t, err, "partition key must be a time field")
+	})
+}
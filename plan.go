@@ -0,0 +1,160 @@
+package querybuild
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// queryPlan 已解析的查询计划，包含过滤条件的SQL片段与排序项，不包含取值
+type queryPlan struct {
+	conds  []string    // 与请求过滤条件一一对应的SQL片段，不支持的操作符为空
+	orders []orderItem // 排序项
+}
+
+// apply 将查询计划应用到查询，并绑定请求的取值
+func (p *queryPlan) apply(query *gorm.DB, filters []Filter) *gorm.DB {
+	for i, filter := range filters {
+		if p.conds[i] == "" {
+			continue
+		}
+		if args, ok := conditionArgs(filter); ok {
+			query = whereCondition(query, p.conds[i], args)
+		}
+	}
+	return applyOrders(query, p.orders)
+}
+
+// planCache 按请求结构缓存的查询计划
+type planCache struct {
+	plans map[string]*queryPlan
+	size  int
+	mu    sync.RWMutex
+}
+
+// newPlanCache 创建最多缓存 size 个查询计划的缓存
+func newPlanCache(size int) *planCache {
+	return &planCache{plans: make(map[string]*queryPlan), size: size}
+}
+
+// get 获取查询计划
+func (c *planCache) get(key string) (*queryPlan, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	plan, ok := c.plans[key]
+	return plan, ok
+}
+
+// put 保存查询计划，缓存已满时忽略
+func (c *planCache) put(key string, plan *queryPlan) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.plans) < c.size {
+		c.plans[key] = plan
+	}
+}
+
+// queryPlan 获取请求的查询计划，请求不适用计划缓存或解析失败时返回 nil
+//
+// 解析失败的请求由常规构建流程报告错误。
+func (qb *QueryBuilder[T]) queryPlan(query *gorm.DB, req *FilterRequest, sorts []Sort) *queryPlan {
+	if qb.plans == nil || !plannable(req, sorts) {
+		return nil
+	}
+	for _, filter := range req.Filters {
+		if qb.typedFilter(query, filter) || !qb.supportsOperator(filter.Op) {
+			return nil
+		}
+	}
+
+	key := planKey(req.Filters, sorts)
+	if plan, ok := qb.plans.get(key); ok {
+		return plan
+	}
+
+	plan := &queryPlan{
+		conds:  make([]string, 0, len(req.Filters)),
+		orders: make([]orderItem, 0, len(sorts)),
+	}
+	for _, filter := range req.Filters {
+		field, err := qb.filterField(query, filter)
+		if err != nil {
+			return nil
+		}
+		plan.conds = append(plan.conds, conditionSQL(field, filter))
+	}
+	for _, sort := range sorts {
+		if qb.checkSortAllowed(sort.Field) != nil {
+			return nil
+		}
+		field, err := qb.resolveField(query, sort.Field)
+		if err != nil {
+			return nil
+		}
+		field, err = qb.sortField(field, sort)
+		if err != nil {
+			return nil
+		}
+		if sort.Desc {
+			plan.orders = append(plan.orders, rawOrder(field+" DESC"))
+		} else {
+			plan.orders = append(plan.orders, rawOrder(field+" ASC"))
+		}
+	}
+
+	qb.plans.put(key, plan)
+	return plan
+}
+
+// plannable 判断请求是否仅包含可缓存计划的过滤条件、普通排序与分页
+func plannable(req *FilterRequest, sorts []Sort) bool {
+	if len(req.CustomFields) > 0 || req.CustomFilter != nil || len(req.CustomFilters) > 0 || len(req.Aggrs) > 0 ||
+		len(req.Groups) > 0 || len(req.Joins) > 0 || req.SubQuery != nil ||
+		len(req.FilterGroups) > 0 || len(req.TupleFilters) > 0 || len(req.Exists) > 0 || len(req.CountFilters) > 0 || req.Distinct || req.DedupeByPK || req.Sample != nil {
+		return false
+	}
+	for _, sort := range sorts {
+		if sort.ScopeName != "" || sort.CountOf != "" || len(sort.Values) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// planKey 根据过滤条件与排序的结构生成计划缓存键，不包含取值
+func planKey(filters []Filter, sorts []Sort) string {
+	var b strings.Builder
+	b.Grow(16 * (len(filters) + len(sorts)))
+	for _, filter := range filters {
+		b.WriteString(filter.Field)
+		b.WriteByte(0)
+		b.WriteString(strconv.Itoa(int(filter.Op)))
+		if filter.NoCase {
+			b.WriteByte('i')
+		}
+		if filter.Fn != "" {
+			b.WriteByte(0)
+			b.WriteString(filter.Fn)
+		}
+		b.WriteByte(1)
+	}
+	b.WriteByte(2)
+	for _, sort := range sorts {
+		b.WriteString(sort.Field)
+		b.WriteByte(0)
+		if sort.Desc {
+			b.WriteByte('d')
+		}
+		if sort.NoCase {
+			b.WriteByte('i')
+		}
+		if sort.Collation != "" {
+			b.WriteByte(0)
+			b.WriteString(sort.Collation)
+		}
+		b.WriteByte(1)
+	}
+	return b.String()
+}
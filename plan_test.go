@@ -0,0 +1,72 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestQueryBuilder_PlanCache(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db, WithPlanCache(2))
+
+	find := func(req *FilterRequest) []TestUser {
+		var users []TestUser
+		assert.NoError(t, builder.Build(req).Find(&users).Error)
+		return users
+	}
+
+	t.Run("Same shape with different values", func(t *testing.T) {
+		req := func(status, names string) *FilterRequest {
+			return &FilterRequest{
+				Filters: []Filter{
+					{Field: "Status", Op: EQ, Value: status},
+					{Field: "Name", Op: IN, Value: names},
+				},
+				Sorts: []Sort{{Field: "Age", Desc: true}},
+			}
+		}
+
+		users := find(req("active", "John Doe,Bob Johnson"))
+		assert.Len(t, users, 2)
+		assert.Equal(t, "Bob Johnson", users[0].Name)
+
+		users = find(req("inactive", "Jane Smith"))
+		assert.Len(t, users, 1)
+		assert.Equal(t, "Jane Smith", users[0].Name)
+		assert.Len(t, builder.plans.plans, 1)
+	})
+
+	t.Run("Invalid values are skipped per request", func(t *testing.T) {
+		users := find(&FilterRequest{Filters: []Filter{{Field: "Age", Op: BETWEEN, Value: "20,30"}}})
+		assert.Len(t, users, 2)
+
+		users = find(&FilterRequest{Filters: []Filter{{Field: "Age", Op: BETWEEN, Value: "20"}}})
+		assert.Len(t, users, 3)
+	})
+
+	t.Run("Invalid fields are not cached", func(t *testing.T) {
+		var users []TestUser
+		err := builder.Build(&FilterRequest{
+			Filters: []Filter{{Field: "Unknown", Op: EQ, Value: "x"}},
+		}).Find(&users).Error
+		assert.Error(t, err)
+	})
+
+	t.Run("Cache size limit", func(t *testing.T) {
+		find(&FilterRequest{Filters: []Filter{{Field: "Email", Op: NOT_NULL}}})
+		assert.Len(t, builder.plans.plans, 2)
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		req := &FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+			Sorts:   []Sort{{Field: "Age", Desc: true}},
+			Page:    &Pagination{Page: 2, PageSize: 1},
+		}
+		stmt := builder.Build(req).Session(&gorm.Session{DryRun: true}).Find(&[]TestUser{}).Statement
+		assert.Contains(t, stmt.SQL.String(), "ORDER BY `test_users`.`age` DESC,`test_users`.`id` ASC")
+		assert.Equal(t, int64(2), req.Page.Total)
+	})
+}
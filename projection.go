@@ -0,0 +1,152 @@
+package querybuild
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ProjectionError 查询结果列与目标结构体字段不匹配
+type ProjectionError struct {
+	Dest     string   // 目标结构体类型
+	Unmapped []string // 查询结果中没有对应字段的列
+	Missing  []string // 目标结构体中没有对应结果列的字段列名，扫描后为零值
+}
+
+func (e *ProjectionError) Error() string {
+	var parts []string
+	if len(e.Unmapped) > 0 {
+		parts = append(parts, "unmapped columns: "+strings.Join(e.Unmapped, ", "))
+	}
+	if len(e.Missing) > 0 {
+		parts = append(parts, "fields without columns: "+strings.Join(e.Missing, ", "))
+	}
+	return fmt.Sprintf("projection mismatch for %s: %s", e.Dest, strings.Join(parts, "; "))
+}
+
+// WithProjectionCheck 开启结果结构体映射校验
+//
+// FindAll 与 FindOne 扫描到模型以外的结构体时，比较查询的选择列与结构体字段的列名，存在不匹配时返回 *ProjectionError；
+// 宽松模式下改为交给 onWarning 处理并继续查询。选择全部列（*）的查询不校验。
+func WithProjectionCheck(enabled bool) Option {
+	return func(o *options) {
+		o.checkProjection = enabled
+	}
+}
+
+// checkProjection 校验查询的选择列与目标结构体字段是否一一对应
+func (qb *QueryBuilder[T]) checkProjection(query *gorm.DB, dest interface{}) error {
+	if !qb.opts.checkProjection || query.Error != nil {
+		return nil
+	}
+	stmt := &gorm.Statement{DB: qb.db}
+	if err := stmt.Parse(dest); err != nil || stmt.Schema.ModelType == qb.schema.ModelType {
+		return nil
+	}
+
+	dryRun := query.Session(&gorm.Session{DryRun: true}).Find(dest)
+	columns, ok := selectColumns(dryRun.Statement.SQL.String())
+	if !ok {
+		return nil
+	}
+
+	err := &ProjectionError{Dest: stmt.Schema.ModelType.String()}
+	selected := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		selected[column] = true
+		if field := stmt.Schema.LookUpField(column); field == nil || field.DBName != column {
+			err.Unmapped = append(err.Unmapped, column)
+		}
+	}
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName != "" && field.Readable && !selected[field.DBName] {
+			err.Missing = append(err.Missing, field.DBName)
+		}
+	}
+	if len(err.Unmapped) == 0 && len(err.Missing) == 0 {
+		return nil
+	}
+	sort.Strings(err.Unmapped)
+	sort.Strings(err.Missing)
+
+	if qb.opts.mode == ModeLenient {
+		if qb.opts.onWarning != nil {
+			qb.opts.onWarning(err)
+		}
+		return nil
+	}
+	return err
+}
+
+// selectColumns 解析 SELECT 语句的结果列名，选择全部列或无法解析时返回 false
+func selectColumns(sql string) ([]string, bool) {
+	rest, ok := cutPrefixFold(sql, "SELECT ")
+	if !ok {
+		return nil, false
+	}
+	rest, _ = cutPrefixFold(rest, "DISTINCT ")
+
+	var items []string
+	var quote byte
+	depth, start, i := 0, 0, 0
+scan:
+	for ; i < len(rest); i++ {
+		c := rest[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && c == ',':
+			items = append(items, rest[start:i])
+			start = i + 1
+		case depth == 0 && hasPrefixFold(rest[i:], " FROM "):
+			break scan
+		}
+	}
+	items = append(items, rest[start:i])
+
+	columns := make([]string, 0, len(items))
+	for _, item := range items {
+		column := selectColumnName(strings.TrimSpace(item))
+		if column == "" {
+			return nil, false
+		}
+		columns = append(columns, column)
+	}
+	return columns, true
+}
+
+// selectColumnName 解析单个选择项的结果列名，选择全部列时返回空字符串
+func selectColumnName(item string) string {
+	if item == "" || strings.HasSuffix(item, "*") {
+		return ""
+	}
+	if i := strings.LastIndex(strings.ToUpper(item), " AS "); i >= 0 {
+		item = strings.TrimSpace(item[i+4:])
+	} else if i := strings.LastIndexByte(item, '.'); i >= 0 && !strings.ContainsAny(item, "() ") {
+		item = item[i+1:]
+	}
+	return strings.Trim(item, "`\"")
+}
+
+// cutPrefixFold 忽略大小写去除前缀
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if !hasPrefixFold(s, prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
+
+// hasPrefixFold 忽略大小写判断前缀
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
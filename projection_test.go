@@ -0,0 +1,78 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_ProjectionCheck(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db, WithProjectionCheck(true))
+	req := func() *FilterRequest {
+		return &FilterRequest{
+			Groups: []Group{{Field: "Status"}},
+			Aggrs:  []Aggregation{{Field: "Age", Op: MAX, Alias: "maxAge"}},
+		}
+	}
+
+	t.Run("Matched", func(t *testing.T) {
+		type Result struct {
+			Status string
+			MaxAge int `gorm:"column:maxAge"`
+		}
+		var results []Result
+		assert.NoError(t, builder.FindAll(req(), &results))
+		assert.Len(t, results, 2)
+	})
+
+	t.Run("Mismatched", func(t *testing.T) {
+		type Result struct {
+			Status string
+			MaxAge int
+			Count  int
+		}
+		var results []Result
+		err := builder.FindAll(req(), &results)
+		var projection *ProjectionError
+		if assert.ErrorAs(t, err, &projection) {
+			assert.Equal(t, []string{"maxAge"}, projection.Unmapped)
+			assert.Equal(t, []string{"count", "max_age"}, projection.Missing)
+		}
+
+		var result Result
+		assert.ErrorAs(t, builder.FindOne(req(), &result), &projection)
+	})
+
+	t.Run("Lenient", func(t *testing.T) {
+		type Result struct {
+			Status string
+		}
+		var warnings []error
+		lenient := builder.Clone(WithLenient(func(err error) { warnings = append(warnings, err) }))
+		var results []Result
+		assert.NoError(t, lenient.FindAll(req(), &results))
+		assert.Len(t, warnings, 1)
+	})
+
+	t.Run("Model and select all are not checked", func(t *testing.T) {
+		var users []TestUser
+		assert.NoError(t, builder.FindAll(&FilterRequest{}, &users))
+
+		type Brief struct {
+			ID   uint
+			Name string
+		}
+		var briefs []Brief
+		assert.NoError(t, builder.FindAll(&FilterRequest{}, &briefs))
+	})
+}
+
+func TestSelectColumns(t *testing.T) {
+	columns, ok := selectColumns("SELECT DISTINCT `t`.`status`, MAX(`t`.`age`) AS max_age, COALESCE(a, 'x, FROM y') as `label` FROM `t` WHERE 1")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"status", "max_age", "label"}, columns)
+
+	_, ok = selectColumns("SELECT `t`.* FROM `t`")
+	assert.False(t, ok)
+}
@@ -1,11 +1,15 @@
 package querybuild
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
 )
 
 // AggregationOp 聚合操作类型
@@ -18,6 +22,10 @@ const (
 	AVG
 	MAX
 	MIN
+	STDDEV     // 样本标准差
+	VARIANCE   // 样本方差
+	PERCENTILE // 百分位数，使用 Aggregation.Fraction 指定分位
+	MEDIAN     // 中位数
 )
 
 // Operator 过滤操作符
@@ -45,14 +53,25 @@ const (
 	OVERLAP                         // 数组重叠
 	ARRAY_CONTAINS                  // 数组包含
 	ARRAY_CONTAINED                 // 数组被包含
+	INET_IN_CIDR                    // IP地址位于网段内
+	CIDR_CONTAINS                   // 网段包含IP地址或网段
+	NOT_BETWEEN                     // 不在区间内
+	GT_OR_NULL                      // 大于或为空
+	GE_OR_NULL                      // 大于等于或为空
+	LT_OR_NULL                      // 小于或为空
+	LE_OR_NULL                      // 小于等于或为空
 )
 
+// lastOperator 最后一个过滤操作符，用于遍历全部操作符
+const lastOperator = LE_OR_NULL
+
 // Filter 过滤条件
 type Filter struct {
 	Field  string   `json:"field"`
 	Op     Operator `json:"op"`
 	Value  string   `json:"value"`
 	NoCase bool     `json:"nocase"`
+	Fn     string   `json:"fn,omitempty"` // 包装字段的已注册函数名
 }
 
 // ScopeType 定义作用域类型
@@ -69,88 +88,209 @@ const (
 // ScopeFunc 定义查询作用域函数类型
 type ScopeFunc func(db *gorm.DB) *gorm.DB
 
+// ParamScopeFunc 定义带参数的查询作用域函数类型，参数来自请求中对应条目的 Values
+type ParamScopeFunc func(db *gorm.DB, values ...interface{}) *gorm.DB
+
 // ScopeRegistry 作用域函数注册表
 type ScopeRegistry struct {
-	filterScopes map[string]ScopeFunc // 过滤作用域
-	sortScopes   map[string]ScopeFunc // 排序作用域
-	groupScopes  map[string]ScopeFunc // 分组作用域
-	selectScopes map[string]ScopeFunc // 选择字段作用域
-	joinScopes   map[string]ScopeFunc // 连接作用域
+	filterScopes map[string]ParamScopeFunc // 过滤作用域
+	sortScopes   map[string]ParamScopeFunc // 排序作用域
+	groupScopes  map[string]ParamScopeFunc // 分组作用域
+	selectScopes map[string]ParamScopeFunc // 选择字段作用域
+	joinScopes   map[string]ParamScopeFunc // 连接作用域
+	guards       map[scopeKey]*scopeGuard  // 配置了授权判断的作用域
+	frozen       bool                      // 冻结后不再允许注册、替换与删除作用域
 	mu           sync.RWMutex
 }
 
+var (
+	// ErrScopeExists 注册的作用域名称已存在，需使用 Replace 显式覆盖
+	ErrScopeExists = errors.New("scope already registered")
+	// ErrScopeNotFound 删除或替换的作用域不存在
+	ErrScopeNotFound = errors.New("scope not found")
+	// ErrRegistryFrozen 作用域注册表已冻结
+	ErrRegistryFrozen = errors.New("scope registry is frozen")
+)
+
 // NewScopeRegistry 创建新的作用域注册表
 func NewScopeRegistry() *ScopeRegistry {
 	return &ScopeRegistry{
-		filterScopes: make(map[string]ScopeFunc),
-		sortScopes:   make(map[string]ScopeFunc),
-		groupScopes:  make(map[string]ScopeFunc),
-		selectScopes: make(map[string]ScopeFunc),
-		joinScopes:   make(map[string]ScopeFunc),
+		filterScopes: make(map[string]ParamScopeFunc),
+		sortScopes:   make(map[string]ParamScopeFunc),
+		groupScopes:  make(map[string]ParamScopeFunc),
+		selectScopes: make(map[string]ParamScopeFunc),
+		joinScopes:   make(map[string]ParamScopeFunc),
+		guards:       make(map[scopeKey]*scopeGuard),
 	}
 }
 
-// Register 注册作用域函数
-func (r *ScopeRegistry) Register(scopeType ScopeType, name string, scope ScopeFunc) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
+// scopes 获取作用域类型对应的作用域表，调用方需持有锁
+func (r *ScopeRegistry) scopes(scopeType ScopeType) map[string]ParamScopeFunc {
 	switch scopeType {
 	case FilterScope:
-		r.filterScopes[name] = scope
+		return r.filterScopes
 	case SortScope:
-		r.sortScopes[name] = scope
+		return r.sortScopes
 	case GroupScope:
-		r.groupScopes[name] = scope
+		return r.groupScopes
 	case SelectScope:
-		r.selectScopes[name] = scope
+		return r.selectScopes
 	case JoinScope:
-		r.joinScopes[name] = scope
+		return r.joinScopes
 	}
+	return nil
 }
 
-// Get 获取作用域函数
-func (r *ScopeRegistry) Get(scopeType ScopeType, name string) (ScopeFunc, bool) {
+// Register 注册作用域函数，名称已存在时返回 ErrScopeExists
+func (r *ScopeRegistry) Register(scopeType ScopeType, name string, scope ScopeFunc) error {
+	return r.RegisterParam(scopeType, name, paramScope(scope))
+}
+
+// RegisterParam 注册带参数的作用域函数，名称已存在时返回 ErrScopeExists
+func (r *ScopeRegistry) RegisterParam(scopeType ScopeType, name string, scope ParamScopeFunc) error {
+	return r.set(scopeType, name, scope, nil, false)
+}
+
+// Replace 替换已注册的作用域函数，名称不存在时返回 ErrScopeNotFound
+func (r *ScopeRegistry) Replace(scopeType ScopeType, name string, scope ScopeFunc) error {
+	return r.ReplaceParam(scopeType, name, paramScope(scope))
+}
+
+// ReplaceParam 替换已注册的作用域函数为带参数的作用域函数，名称不存在时返回 ErrScopeNotFound
+func (r *ScopeRegistry) ReplaceParam(scopeType ScopeType, name string, scope ParamScopeFunc) error {
+	return r.set(scopeType, name, scope, nil, true)
+}
+
+// Unregister 删除已注册的作用域函数，名称不存在时返回 ErrScopeNotFound
+func (r *ScopeRegistry) Unregister(scopeType ScopeType, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.frozen {
+		return ErrRegistryFrozen
+	}
+	scopes := r.scopes(scopeType)
+	if _, ok := scopes[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrScopeNotFound, name)
+	}
+	delete(scopes, name)
+	delete(r.guards, scopeKey{scopeType, name})
+	return nil
+}
+
+// Freeze 冻结注册表，之后注册、替换与删除作用域均返回 ErrRegistryFrozen
+func (r *ScopeRegistry) Freeze() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.frozen = true
+}
+
+// Frozen 判断注册表是否已冻结
+func (r *ScopeRegistry) Frozen() bool {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
+	return r.frozen
+}
 
-	var scope ScopeFunc
-	var ok bool
+// set 写入作用域函数及其授权判断，replace 为 true 时要求名称已存在，否则要求名称不存在
+func (r *ScopeRegistry) set(scopeType ScopeType, name string, scope ParamScopeFunc, guard *scopeGuard, replace bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	switch scopeType {
-	case FilterScope:
-		scope, ok = r.filterScopes[name]
-	case SortScope:
-		scope, ok = r.sortScopes[name]
-	case GroupScope:
-		scope, ok = r.groupScopes[name]
-	case SelectScope:
-		scope, ok = r.selectScopes[name]
-	case JoinScope:
-		scope, ok = r.joinScopes[name]
+	if r.frozen {
+		return ErrRegistryFrozen
+	}
+	scopes := r.scopes(scopeType)
+	if scopes == nil {
+		return fmt.Errorf("invalid scope type: %d", scopeType)
+	}
+	_, exists := scopes[name]
+	switch {
+	case replace && !exists:
+		return fmt.Errorf("%w: %s", ErrScopeNotFound, name)
+	case !replace && exists:
+		return fmt.Errorf("%w: %s", ErrScopeExists, name)
+	}
+	scopes[name] = scope
+	if guard != nil {
+		r.guards[scopeKey{scopeType, name}] = guard
+	} else {
+		delete(r.guards, scopeKey{scopeType, name})
+	}
+	return nil
+}
+
+// paramScope 将不带参数的作用域函数转换为忽略参数的带参数作用域函数
+func paramScope(scope ScopeFunc) ParamScopeFunc {
+	return func(db *gorm.DB, _ ...interface{}) *gorm.DB {
+		return scope(db)
+	}
+}
+
+// Get 获取作用域函数，带参数的作用域函数以空参数调用
+func (r *ScopeRegistry) Get(scopeType ScopeType, name string) (ScopeFunc, bool) {
+	scope, ok := r.GetParam(scopeType, name)
+	if !ok {
+		return nil, false
 	}
+	return func(db *gorm.DB) *gorm.DB { return scope(db) }, true
+}
+
+// GetParam 获取作用域函数，不带参数的作用域函数忽略传入的参数
+func (r *ScopeRegistry) GetParam(scopeType ScopeType, name string) (ParamScopeFunc, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
+	scope, ok := r.scopes(scopeType)[name]
 	return scope, ok
 }
 
+// Clone 复制作用域注册表，副本保留冻结状态
+func (r *ScopeRegistry) Clone() *ScopeRegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clone := NewScopeRegistry()
+	clone.frozen = r.frozen
+	for _, pair := range []struct{ src, dst map[string]ParamScopeFunc }{
+		{r.filterScopes, clone.filterScopes},
+		{r.sortScopes, clone.sortScopes},
+		{r.groupScopes, clone.groupScopes},
+		{r.selectScopes, clone.selectScopes},
+		{r.joinScopes, clone.joinScopes},
+	} {
+		for name, scope := range pair.src {
+			pair.dst[name] = scope
+		}
+	}
+	for key, guard := range r.guards {
+		clone.guards[key] = guard
+	}
+	return clone
+}
+
 // CustomField 自定义字段定义
 type CustomField struct {
-	Name      string `json:"name"`  // 字段别名
-	ScopeName string `json:"scope"` // 作用域函数名称
+	Name      string        `json:"name"`   // 字段别名
+	ScopeName string        `json:"scope"`  // 作用域函数名称
+	Values    []interface{} `json:"values"` // 作用域参数值
 }
 
 // CustomFilter 自定义过滤条件
 type CustomFilter struct {
 	ScopeName string        `json:"scope"`  // 作用域函数名称
-	Values    []interface{} `json:"values"` // 条件参数值
+	Values    []interface{} `json:"values"` // 条件参数值，传给带参数的作用域函数
 }
 
 // Sort 排序条件
 type Sort struct {
-	Field     string `json:"field"`
-	Desc      bool   `json:"desc"`
-	NoCase    bool   `json:"nocase"`
-	ScopeName string `json:"scope"` // 作用域函数名称
+	Field     string   `json:"field"`
+	Desc      bool     `json:"desc"`
+	NoCase    bool     `json:"nocase"`
+	ScopeName string   `json:"scope"`               // 作用域函数名称
+	CountOf   string   `json:"count_of"`            // 按关联记录数量排序的关联名称
+	Values    []string `json:"values"`              // 按给定取值顺序排序，未列出的取值排在最后
+	Collation string   `json:"collation,omitempty"` // 通过 WithCollation 注册的排序规则名称
 }
 
 // Aggregation 聚合条件
@@ -158,30 +298,41 @@ type Aggregation struct {
 	Field      string        `json:"field"`
 	Op         AggregationOp `json:"op"`
 	NoCase     bool          `json:"nocase"`
-	AddSelects []string      `json:"add_selects"`
-	Alias      string        `json:"alias"` // 聚合结果的别名
+	AddSelects []string      `json:"add_selects"` // 附加选择的字段，需为模型字段、alias.column 或已注册的表达式
+	Alias      string        `json:"alias"`       // 聚合结果的别名
+	Fraction   float64       `json:"fraction"`    // PERCENTILE 的分位，取值范围 [0, 1]
+	Condition  *Filter       `json:"condition"`   // 条件聚合，仅统计满足条件的记录
+	Expr       string        `json:"expr"`        // 聚合已注册的表达式，设置后忽略 Field
+	Window     *Window       `json:"window"`      // 按周期字段对聚合结果做累计或环比计算
 }
 
 // Pagination 分页参数
 type Pagination struct {
-	Page     int   `json:"page"`      // 页码，从1开始
-	PageSize int   `json:"page_size"` // 每页数量
-	Total    int64 `json:"total"`     // 总记录数
+	Page       int   `json:"page"`        // 页码，从1开始
+	PageSize   int   `json:"page_size"`   // 每页数量
+	Total      int64 `json:"total"`       // 总记录数
+	TotalPages int   `json:"total_pages"` // 总页数
+	HasNext    bool  `json:"has_next"`    // 是否有下一页
+	HasPrev    bool  `json:"has_prev"`    // 是否有上一页
+	Deferred   bool  `json:"deferred"`    // 使用延迟连接分页，先分页查询主键再回表
 }
 
 // Group 分组条件
 type Group struct {
-	Field     string `json:"field"`
-	Having    string `json:"having"`
-	ScopeName string `json:"scope"` // 作用域函数名称
+	Field     string        `json:"field"`
+	Having    string        `json:"having"`
+	ScopeName string        `json:"scope"`  // 作用域函数名称
+	Values    []interface{} `json:"values"` // 作用域参数值，如分桶大小
 }
 
 // Join 连接条件
 type Join struct {
-	Type      string `json:"type"`      // LEFT, RIGHT, INNER
-	Table     string `json:"table"`     // 要连接的表名
-	Condition string `json:"condition"` // 连接条件
-	ScopeName string `json:"scope"`     // 作用域函数名称
+	Type      string        `json:"type"`      // LEFT, RIGHT, INNER
+	Table     string        `json:"table"`     // 要连接的表名
+	Alias     string        `json:"alias"`     // 连接表别名，可通过 alias.column 引用字段
+	Condition string        `json:"condition"` // 连接条件
+	ScopeName string        `json:"scope"`     // 作用域函数名称
+	Values    []interface{} `json:"values"`    // 作用域参数值
 }
 
 // SubQuery 子查询
@@ -194,60 +345,183 @@ type SubQuery struct {
 
 // FilterRequest 查询请求
 type FilterRequest struct {
-	Filters      []Filter      `json:"filters"`
-	CustomFields []CustomField `json:"custom_fields"` // 自定义字段
-	CustomFilter *CustomFilter `json:"custom_filter"` // 自定义过滤条件
-	Sorts        []Sort        `json:"sorts"`
-	Aggrs        []Aggregation `json:"aggrs"`
-	Page         *Pagination   `json:"page"`
-	Groups       []Group       `json:"groups"`
-	Joins        []Join        `json:"joins"`
-	SubQuery     *SubQuery     `json:"sub_query"`
-	Distinct     bool          `json:"distinct"`
+	Filters       []Filter       `json:"filters"`
+	CustomFields  []CustomField  `json:"custom_fields"`  // 自定义字段
+	CustomFilter  *CustomFilter  `json:"custom_filter"`  // 自定义过滤条件，保留以兼容单个作用域的请求
+	CustomFilters []CustomFilter `json:"custom_filters"` // 按顺序应用的多个自定义过滤条件，在 CustomFilter 之后应用
+	Sorts         []Sort         `json:"sorts"`
+	Aggrs         []Aggregation  `json:"aggrs"`
+	Page          *Pagination    `json:"page"`
+	Groups        []Group        `json:"groups"`
+	Joins         []Join         `json:"joins"`
+	SubQuery      *SubQuery      `json:"sub_query"`
+	FilterGroups  []FilterGroup  `json:"filter_groups"` // 过滤条件组，支持 NOT 取反
+	TupleFilters  []TupleFilter  `json:"tuple_filters"` // 多字段组合取值过滤条件
+	Exists        []ExistsFilter `json:"exists"`        // 关联存在性过滤条件
+	CountFilters  []CountFilter  `json:"count_filters"` // 关联数量过滤条件
+	Distinct      bool           `json:"distinct"`
+	DedupeByPK    bool           `json:"dedupe_by_pk"` // 按主键去重，避免连接一对多关联时重复计数与分页
+	TimeZone      string         `json:"time_zone"`    // 业务时区名称，如 Asia/Shanghai，覆盖 WithLocation 配置
+	Unscoped      bool           `json:"unscoped"`     // 包含软删除的记录，需通过 WithUnscoped 开启
+	SkipEmpty     *bool          `json:"skip_empty"`   // 是否跳过取值为空的过滤条件，覆盖 WithSkipEmpty 配置
+	Sample        *Sample        `json:"sample"`       // 随机抽样
+
+	Defaults []AppliedDefault `json:"defaults,omitempty"` // 构建时按缺省规则补全的内容，由构建器写入补全后的请求副本
+	prepared bool             // 已按构建器的策略整理并补全缺省值，构建时不再重复整理
 }
 
 // FieldInfo 字段信息
 type FieldInfo struct {
-	Name      string // 数据库字段名
-	TableName string // 表名
+	Name      string          // 数据库字段名
+	TableName string          // 表名
+	DataType  schema.DataType // 模型字段的数据类型，声明了 type 标签时为列类型，如 citext、text[]
 }
 
 // QueryBuilder GORM查询构建器
 type QueryBuilder[T any] struct {
-	db       *gorm.DB
-	registry *ScopeRegistry
-	fields   map[string]FieldInfo // 模型字段映射
-	model    T                    // 模型实例
+	db          *gorm.DB
+	registry    *ScopeRegistry
+	expressions *ExpressionRegistry   // 已注册的SQL表达式
+	named       *NamedQueryRegistry   // 已注册的命名查询
+	functions   *FunctionRegistry     // 已注册的过滤字段函数
+	encoders    *ValueEncoderRegistry // 已注册的字段取值编码器
+	fields      map[string]FieldInfo  // 模型字段映射
+	schema      *schema.Schema        // 模型结构信息
+	model       T                     // 模型实例
+	opts        options               // 构建器配置
+	plans       *planCache            // 查询计划缓存，未开启时为空
+	hooks       *findHooks[T]         // 查询前后的处理钩子
+	temporal    *temporalScope        // 历史查询的时间范围，非历史查询时为空
+	keys        []*schema.Field       // 唯一标识记录的字段，默认为主键
+	keysErr     error                 // 指定的标识字段无效时的错误
+	bound       bool                  // 通过 WithDB 绑定了数据库会话，读取查询不使用只读副本
 }
 
 // NewQueryBuilder 创建新的查询构建器
-func NewQueryBuilder[T any](db *gorm.DB) *QueryBuilder[T] {
+func NewQueryBuilder[T any](db *gorm.DB, opts ...Option) *QueryBuilder[T] {
 	var model T
 	qb := &QueryBuilder[T]{
-		db:       db,
-		registry: NewScopeRegistry(),
-		fields:   make(map[string]FieldInfo),
-		model:    model,
+		db:          db,
+		registry:    NewScopeRegistry(),
+		expressions: NewExpressionRegistry(),
+		named:       NewNamedQueryRegistry(),
+		functions:   NewFunctionRegistry(),
+		encoders:    NewValueEncoderRegistry(),
+		fields:      make(map[string]FieldInfo),
+		model:       model,
+		hooks:       &findHooks[T]{},
+	}
+	for _, opt := range opts {
+		opt(&qb.opts)
 	}
 	qb.initFields()
 	return qb
 }
 
+// WithDB 返回绑定到指定数据库会话（如事务）的构建器副本，共享作用域注册表与字段映射
+//
+// 绑定后读取查询同样使用该会话，不使用 WithReadDB 配置的只读副本。
+func (qb *QueryBuilder[T]) WithDB(db *gorm.DB) *QueryBuilder[T] {
+	clone := *qb
+	clone.db = db
+	clone.bound = true
+	return &clone
+}
+
+// WithContext 返回绑定上下文的构建器副本
+func (qb *QueryBuilder[T]) WithContext(ctx context.Context) *QueryBuilder[T] {
+	clone := *qb
+	clone.db = qb.db.WithContext(ctx)
+	return &clone
+}
+
 // initFields 初始化字段映射
 func (qb *QueryBuilder[T]) initFields() {
 	var model T
 	stmt := &gorm.Statement{DB: qb.db}
 	_ = stmt.Parse(&model)
 
-	for _, field := range stmt.Schema.Fields {
+	qb.schema = stmt.Schema
+	qb.fields = tableSchemaFields(stmt.Schema, qb.tableName())
+	qb.keys, qb.keysErr = qb.resolveKeys()
+
+	// 注册审计与慢查询回调
+	if qb.opts.auditor != nil || qb.opts.onSlow != nil {
+		_ = registerExecCallbacks(qb.db)
+		if qb.opts.readDB != nil {
+			_ = registerExecCallbacks(qb.opts.readDB)
+		}
+	}
+
+	// 查询计划依赖字段映射，字段映射变化时重新创建
+	qb.plans = nil
+	if qb.opts.planCacheSize > 0 {
+		qb.plans = newPlanCache(qb.opts.planCacheSize)
+	}
+}
+
+// tableName 获取查询使用的表名
+func (qb *QueryBuilder[T]) tableName() string {
+	if qb.opts.table != "" {
+		return qb.opts.table
+	}
+	return qb.schema.Table
+}
+
+// newQuery 创建绑定模型及表名的读取查询，历史查询附加有效期条件
+func (qb *QueryBuilder[T]) newQuery() *gorm.DB {
+	query := qb.modelQuery(qb.readDB())
+	if qb.keysErr != nil {
+		query.AddError(qb.keysErr)
+	}
+	if t := qb.temporal; t != nil {
+		if t.err != nil {
+			query.AddError(t.err)
+			return query
+		}
+		query = whereCondition(query, t.sql, t.args)
+	}
+	return query
+}
+
+// newWriteQuery 创建写入查询，始终使用主库
+func (qb *QueryBuilder[T]) newWriteQuery() *gorm.DB {
+	return qb.modelQuery(qb.db)
+}
+
+// modelQuery 在指定连接上创建模型查询
+func (qb *QueryBuilder[T]) modelQuery(db *gorm.DB) *gorm.DB {
+	query := db.Model(&qb.model)
+	if qb.opts.table != "" {
+		query = query.Table(qb.opts.table)
+	}
+	return query
+}
+
+// schemaFields 根据模型结构生成字段映射
+func schemaFields(s *schema.Schema) map[string]FieldInfo {
+	return tableSchemaFields(s, s.Table)
+}
+
+// tableSchemaFields 根据模型结构生成指定表名下的字段映射
+func tableSchemaFields(s *schema.Schema, table string) map[string]FieldInfo {
+	fields := make(map[string]FieldInfo, len(s.Fields))
+	for _, field := range s.Fields {
 		dbName := field.DBName
 		if dbName != "" {
-			qb.fields[field.Name] = FieldInfo{
+			fields[field.Name] = FieldInfo{
 				Name:      dbName,
-				TableName: stmt.Schema.Table,
+				TableName: table,
+				DataType:  field.DataType,
 			}
 		}
 	}
+	return fields
+}
+
+// quoteField 获取带表名的字段引用
+func quoteField(info FieldInfo) string {
+	return "`" + info.TableName + "`.`" + info.Name + "`"
 }
 
 // validateField 验证字段名是否安全
@@ -258,65 +532,256 @@ func (qb *QueryBuilder[T]) validateField(fieldName string) (FieldInfo, error) {
 	return FieldInfo{}, fmt.Errorf("invalid field name: %s", fieldName)
 }
 
-// safeField 获取安全的字段引用
-func (qb *QueryBuilder[T]) safeField(fieldName string) (string, error) {
-	info, err := qb.validateField(fieldName)
+// RegisterScope 注册作用域函数，可通过 ScopeAllowed 限定可使用作用域的调用方
+//
+// 名称已存在时返回 ErrScopeExists，覆盖已注册的作用域需使用 ReplaceScope；注册表冻结后返回 ErrRegistryFrozen。
+func (qb *QueryBuilder[T]) RegisterScope(scopeType ScopeType, name string, scope ScopeFunc, opts ...ScopeOption) error {
+	return qb.RegisterParamScope(scopeType, name, paramScope(scope), opts...)
+}
+
+// ReplaceScope 替换已注册的作用域函数，名称不存在时返回 ErrScopeNotFound
+func (qb *QueryBuilder[T]) ReplaceScope(scopeType ScopeType, name string, scope ScopeFunc, opts ...ScopeOption) error {
+	return qb.ReplaceParamScope(scopeType, name, paramScope(scope), opts...)
+}
+
+// UnregisterScope 删除已注册的作用域函数，名称不存在时返回 ErrScopeNotFound
+func (qb *QueryBuilder[T]) UnregisterScope(scopeType ScopeType, name string) error {
+	return qb.registry.Unregister(scopeType, name)
+}
+
+// Freeze 冻结构建器的作用域注册表，应在启动完成后调用，避免运行期间意外修改查询语义
+//
+// 冻结后注册、替换与删除作用域均返回 ErrRegistryFrozen，通过 Clone 派生的构建器同样处于冻结状态。
+func (qb *QueryBuilder[T]) Freeze() {
+	qb.registry.Freeze()
+}
+
+// RegisterParamScope 注册带参数的作用域函数，请求中过滤、分组、连接与自定义字段条目的 Values 作为参数传入，例如：
+//
+//	qb.RegisterParamScope(FilterScope, "min_age", func(db *gorm.DB, values ...interface{}) *gorm.DB {
+//		return db.Where("age >= ?", values...)
+//	})
+func (qb *QueryBuilder[T]) RegisterParamScope(scopeType ScopeType, name string, scope ParamScopeFunc, opts ...ScopeOption) error {
+	guard := newScopeGuard(opts)
+	return qb.registry.set(scopeType, name, guardScope(scopeType, name, scope, guard), guard, false)
+}
+
+// ReplaceParamScope 以带参数的作用域函数替换已注册的作用域函数，名称不存在时返回 ErrScopeNotFound
+func (qb *QueryBuilder[T]) ReplaceParamScope(scopeType ScopeType, name string, scope ParamScopeFunc, opts ...ScopeOption) error {
+	guard := newScopeGuard(opts)
+	return qb.registry.set(scopeType, name, guardScope(scopeType, name, scope, guard), guard, true)
+}
+
+// Build 构建查询
+func (qb *QueryBuilder[T]) Build(req *FilterRequest) *gorm.DB {
+	return qb.build(req, true)
+}
+
+// build 构建查询，count 为 false 时分页不计算总记录数
+func (qb *QueryBuilder[T]) build(req *FilterRequest, count bool) *gorm.DB {
+	// 按构建器的策略整理请求并补全缺省值
+	req, err := qb.prepareRequest(req)
 	if err != nil {
-		return "", err
+		query := qb.newQuery()
+		query.AddError(err)
+		return query
+	}
+
+	// 匹配物化视图的聚合请求改为查询视图
+	routed, routedReq, err := qb.routeView(req)
+	if err != nil {
+		query := qb.newQuery()
+		query.AddError(err)
+		return query
+	}
+	if routed != nil {
+		return routed.build(routedReq, count)
+	}
+
+	query := qb.buildQuery(req, count)
+	if qb.opts.debug {
+		qb.logDebug(query, req)
 	}
-	return fmt.Sprintf("`%s`.`%s`", info.TableName, info.Name), nil
+	return query
 }
 
-// simpleField 获取简单的字段引用
-func (qb *QueryBuilder[T]) simpleField(fieldName string) (string, error) {
-	info, err := qb.validateField(fieldName)
+// prepareRequest 按构建器的策略整理请求：跳过空取值、校验数量上限、按处理方式校验或过滤无效条目、
+// 按缺省规则补全并将枚举标签转换为枚举取值
+//
+// 缺省规则在整理后的请求上应用，时间范围规则只认可实际生效的过滤条件。请求被修改时返回标记为已整理的副本，
+// 已整理的请求原样返回，未修改时返回原请求。
+func (qb *QueryBuilder[T]) prepareRequest(req *FilterRequest) (*FilterRequest, error) {
+	if req.prepared {
+		return req, nil
+	}
+	prepared := qb.skipEmptyFilters(req)
+	if err := qb.checkLimits(prepared); err != nil {
+		return nil, err
+	}
+	prepared, err := qb.applyMode(prepared)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return fmt.Sprintf("`%s`", info.Name), nil
+	if prepared, err = qb.applyDefaults(prepared); err != nil {
+		return nil, err
+	}
+	prepared = qb.translateEnums(prepared)
+	if prepared != req {
+		prepared.prepared = true
+	}
+	return prepared, nil
 }
 
-// RegisterScope 注册作用域函数
-func (qb *QueryBuilder[T]) RegisterScope(scopeType ScopeType, name string, scope ScopeFunc) {
-	qb.registry.Register(scopeType, name, scope)
+// buildQuery 依次应用请求中的各部分构建查询
+func (qb *QueryBuilder[T]) buildQuery(req *FilterRequest, count bool) *gorm.DB {
+	query, req, ok := qb.prepareQuery(req)
+	if !ok {
+		return query
+	}
+
+	// 随机抽样
+	query = qb.applySample(query, req)
+
+	// 排序，未指定时使用默认排序，按记录数抽样时以随机顺序取代排序
+	sorts := req.Sorts
+	if len(sorts) == 0 && !req.Sample.byRows() {
+		sorts = qb.opts.defaultSorts
+	}
+	sorts = qb.tieBreakSorts(req, sorts)
+
+	if plan := qb.queryPlan(query, req, sorts); plan != nil {
+		// 结构相同的简单请求复用已解析的查询计划，仅重新绑定取值
+		query = plan.apply(query, req.Filters)
+	} else {
+		// 应用自定义字段
+		query = qb.applyCustomFields(query, req.CustomFields)
+
+		// 应用DISTINCT
+		if req.Distinct {
+			query = query.Distinct()
+		}
+
+		if req.DedupeByPK {
+			// 在主键子查询中应用连接与过滤条件
+			query = qb.applyDedupedConditions(query, req)
+		} else {
+			// 应用JOIN
+			query = qb.applyJoins(query, req.Joins)
+
+			// 应用子查询
+			query = qb.applySubQuery(query, req.SubQuery)
+
+			// 应用过滤条件
+			query = qb.applyConditions(query, req)
+		}
+
+		// 应用分组
+		query = qb.applyGroups(query, req.Groups)
+
+		// 应用排序
+		query = qb.applySorts(query, sorts)
+
+		// 应用聚合
+		query = qb.applyAggregations(query, req.Aggrs, req.Groups)
+	}
+
+	// 计数缓存命中或写入后不再在分页中计数
+	cachedTotal := false
+	if count && req.Page != nil && qb.opts.countCache != nil {
+		total, err := qb.cachedCount(req, func() (int64, error) {
+			var total int64
+			err := query.Count(&total).Error
+			return total, err
+		})
+		if err != nil {
+			query.AddError(err)
+		}
+		req.Page.Total = total
+		count, cachedTotal = false, true
+	}
+
+	// 应用分页
+	if qb.canDeferJoin(req) {
+		query = qb.applyDeferredPagination(query, req.Page, count)
+	} else {
+		query = qb.applyPagination(query, req.Page, count)
+	}
+	if cachedTotal {
+		req.Page.computeMeta()
+	}
+
+	// 应用脱敏规则
+	query = qb.applyMasks(query)
+
+	return query
 }
 
-// Build 构建查询
-func (qb *QueryBuilder[T]) Build(req *FilterRequest) *gorm.DB {
+// prepareQuery 创建查询并整理请求，校验查询复杂度与过滤取值，应用软删除与分区范围
+//
+// 构建完整查询与仅包含过滤条件的查询共用这一步骤，返回的 ok 为 false 时查询已记录错误，不再继续构建。
+func (qb *QueryBuilder[T]) prepareQuery(req *FilterRequest) (*gorm.DB, *FilterRequest, bool) {
 	// 首先设置模型
-	query := qb.db.Model(&qb.model)
+	query := qb.newQuery()
+
+	// 附加审计与慢查询回调使用的执行信息
+	query = qb.withExecInfo(query, req)
+
+	// 记录解析时间取值与时间分桶使用的业务时区
+	loc, err := qb.requestLocation(req)
+	if err != nil {
+		query.AddError(err)
+		return query, req, false
+	}
+	query = withLocation(query, loc)
 
-	// 应用自定义字段
-	query = qb.applyCustomFields(query, req.CustomFields)
+	// 跳过空取值、校验数量上限、处理无效条目、补全缺省值并转换枚举标签
+	req, err = qb.prepareRequest(req)
+	if err != nil {
+		query.AddError(err)
+		return query, req, false
+	}
 
-	// 应用DISTINCT
-	if req.Distinct {
-		query = query.Distinct()
+	// 校验查询复杂度
+	if !qb.checkComplexity(query, req) {
+		return query, req, false
 	}
 
-	// 应用JOIN
-	query = qb.applyJoins(query, req.Joins)
+	// 包含软删除的记录
+	query = qb.applyUnscoped(query, req)
 
-	// 应用子查询
-	query = qb.applySubQuery(query, req.SubQuery)
+	// 校验过滤取值
+	for _, filter := range req.Filters {
+		if err := qb.checkFilterValue(filter); err != nil {
+			query.AddError(err)
+		}
+	}
 
+	// 按分区键的取值范围约束扫描的分区
+	return qb.applyPartitionRange(query, req, loc), req, true
+}
+
+// applyConditions 应用请求中的全部过滤条件
+func (qb *QueryBuilder[T]) applyConditions(query *gorm.DB, req *FilterRequest) *gorm.DB {
 	// 应用标准过滤条件
 	query = qb.applyFilters(query, req.Filters)
 
-	// 应用自定义过滤条件
-	query = qb.applyCustomFilter(query, req.CustomFilter)
+	// 应用过滤条件组
+	query = qb.applyFilterGroups(query, req.FilterGroups)
 
-	// 应用分组
-	query = qb.applyGroups(query, req.Groups)
+	// 应用多字段组合取值过滤条件
+	query = qb.applyTupleFilters(query, req.TupleFilters)
 
-	// 应用排序
-	query = qb.applySorts(query, req.Sorts)
+	// 应用关联存在性过滤条件
+	query = qb.applyExists(query, req.Exists)
 
-	// 应用聚合
-	query = qb.applyAggregations(query, req.Aggrs)
+	// 应用关联数量过滤条件
+	query = qb.applyCountFilters(query, req.CountFilters)
 
-	// 应用分页
-	query = qb.applyPagination(query, req.Page)
+	// 应用自定义过滤条件
+	query = qb.applyCustomFilter(query, req.CustomFilter)
+	for i := range req.CustomFilters {
+		query = qb.applyCustomFilter(query, &req.CustomFilters[i])
+	}
 
 	return query
 }
@@ -324,184 +789,399 @@ func (qb *QueryBuilder[T]) Build(req *FilterRequest) *gorm.DB {
 // applyFilters 应用过滤条件
 func (qb *QueryBuilder[T]) applyFilters(query *gorm.DB, filters []Filter) *gorm.DB {
 	for _, filter := range filters {
-		safeField, err := qb.safeField(filter.Field)
+		safeField, err := qb.filterField(query, filter)
 		if err != nil {
 			query.AddError(err)
 			continue
 		}
 
-		field := safeField
-		if filter.NoCase {
-			field = fmt.Sprintf("LOWER(%s)", field)
+		cond, args, err := qb.filterCondition(query, safeField, filter)
+		if err != nil {
+			query.AddError(err)
+			continue
+		}
+		if cond != "" {
+			query = whereCondition(query, cond, args)
 		}
+	}
+	return query
+}
 
-		value := filter.Value
-		if filter.NoCase && value != "" {
-			value = strings.ToLower(value)
-		}
-
-		switch filter.Op {
-		case EQ:
-			query = query.Where(fmt.Sprintf("%s = ?", field), value)
-		case NE:
-			query = query.Where(fmt.Sprintf("%s != ?", field), value)
-		case GT:
-			query = query.Where(fmt.Sprintf("%s > ?", field), value)
-		case GE:
-			query = query.Where(fmt.Sprintf("%s >= ?", field), value)
-		case LT:
-			query = query.Where(fmt.Sprintf("%s < ?", field), value)
-		case LE:
-			query = query.Where(fmt.Sprintf("%s <= ?", field), value)
-		case LIKE:
-			query = query.Where(fmt.Sprintf("%s LIKE ?", field), "%"+value+"%")
-		case IN:
-			values := strings.Split(value, ",")
-			query = query.Where(fmt.Sprintf("%s IN (?)", field), values)
-		case BETWEEN:
-			values := strings.Split(value, ",")
-			if len(values) == 2 {
-				query = query.Where(fmt.Sprintf("%s BETWEEN ? AND ?", field), values[0], values[1])
-			}
-		case NOT_IN:
-			values := strings.Split(value, ",")
-			query = query.Where(fmt.Sprintf("%s NOT IN (?)", field), values)
-		case IS_NULL:
-			query = query.Where(fmt.Sprintf("%s IS NULL", field))
-		case NOT_NULL:
-			query = query.Where(fmt.Sprintf("%s IS NOT NULL", field))
-		case STARTS_WITH:
-			query = query.Where(fmt.Sprintf("%s LIKE ?", field), value+"%")
-		case ENDS_WITH:
-			query = query.Where(fmt.Sprintf("%s LIKE ?", field), "%"+value)
-		case CONTAINS:
-			query = query.Where(fmt.Sprintf("%s LIKE ?", field), "%"+value+"%")
-		case NOT_LIKE:
-			query = query.Where(fmt.Sprintf("%s NOT LIKE ?", field), "%"+value+"%")
-		case REGEXP:
-			query = query.Where(fmt.Sprintf("%s REGEXP ?", field), value)
-		case NOT_REGEXP:
-			query = query.Where(fmt.Sprintf("%s NOT REGEXP ?", field), value)
-		case OVERLAP:
-			query = query.Where(fmt.Sprintf("%s && ?", field), value)
-		case ARRAY_CONTAINS:
-			query = query.Where(fmt.Sprintf("%s @> ?", field), value)
-		case ARRAY_CONTAINED:
-			query = query.Where(fmt.Sprintf("%s <@ ?", field), value)
+// filterCondition 构建模型字段的过滤条件，按数据库方言处理IP网段操作符，
+// 布尔字段的取值绑定为布尔值，配置了业务时区时时间字段的取值按业务时区解析，以函数包装的字段按原样绑定取值，加密字段的取值加密后绑定，
+// 注册了取值编码器的字段的取值编码后绑定
+func (qb *QueryBuilder[T]) filterCondition(query *gorm.DB, field string, filter Filter) (string, []interface{}, error) {
+	if err := qb.checkOperator(filter.Op); err != nil {
+		return "", nil, err
+	}
+	info := qb.columnInfo(query, filter.Field)
+	if filter.NoCase && info.isCIText() {
+		// citext 列的比较本身不区分大小写，无需 LOWER 包装
+		filter.NoCase = false
+	}
+	switch {
+	case qb.encryptedField(filter.Field) != nil:
+		return qb.encryptedCondition(query, field, filter)
+	case filter.Fn != "":
+	case qb.valueEncoder(filter.Field) != nil:
+		return encodedCondition(field, filter, qb.valueEncoder(filter.Field))
+	case isNetworkOperator(filter.Op):
+		return qb.networkCondition(field, filter)
+	case qb.isArrayFilter(query, filter):
+		cond, args := arrayCondition(field, info, filter)
+		return cond, args, nil
+	case qb.isBoolFilter(filter):
+		return boolCondition(field, filter)
+	case qb.isTimeFilter(query, filter):
+		return timeCondition(field, filter, queryLocation(query))
+	case qb.uuidStorage(filter) != 0:
+		return uuidCondition(field, filter, qb.uuidStorage(filter))
+	case qb.customValueField(filter) != nil:
+		return qb.customCondition(query, field, qb.customValueField(filter), filter)
+	}
+	cond, args := buildCondition(field, filter)
+	return cond, args, nil
+}
+
+// typedFilter 判断过滤条件是否需按方言、字段类型、列类型、UUID 与自定义类型、取值编码器、业务时区或加密构建，这类条件不使用查询计划缓存
+func (qb *QueryBuilder[T]) typedFilter(query *gorm.DB, filter Filter) bool {
+	if qb.encryptedField(filter.Field) != nil {
+		return true
+	}
+	if filter.Fn != "" {
+		return false
+	}
+	if qb.valueEncoder(filter.Field) != nil || filter.NoCase && qb.columnInfo(query, filter.Field).isCIText() {
+		return true
+	}
+	return isNetworkOperator(filter.Op) || qb.isArrayFilter(query, filter) || qb.isBoolFilter(filter) || qb.isTimeFilter(query, filter) ||
+		qb.uuidStorage(filter) != 0 || qb.customValueField(filter) != nil
+}
+
+// applyFieldFilters 基于给定的字段映射应用过滤条件
+func applyFieldFilters(query *gorm.DB, fields map[string]FieldInfo, filters []Filter) *gorm.DB {
+	for _, filter := range filters {
+		info, ok := fields[filter.Field]
+		if !ok {
+			query.AddError(fmt.Errorf("invalid field name: %s", filter.Field))
+			continue
+		}
+
+		if cond, args := buildCondition(quoteField(info), filter); cond != "" {
+			query = whereCondition(query, cond, args)
 		}
 	}
 	return query
 }
 
+// whereCondition 以表达式形式追加过滤条件，省去 gorm 对字符串条件的解析
+func whereCondition(query *gorm.DB, sql string, args []interface{}) *gorm.DB {
+	return query.Clauses(clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: sql, Vars: args}}})
+}
+
+// operatorSQL 单值比较操作符对应的SQL片段
+var operatorSQL = [...]string{
+	EQ:              " = ?",
+	NE:              " != ?",
+	GT:              " > ?",
+	GE:              " >= ?",
+	LT:              " < ?",
+	LE:              " <= ?",
+	REGEXP:          " REGEXP ?",
+	NOT_REGEXP:      " NOT REGEXP ?",
+	OVERLAP:         " && ?",
+	ARRAY_CONTAINS:  " @> ?",
+	ARRAY_CONTAINED: " <@ ?",
+	GT_OR_NULL:      " > ?",
+	GE_OR_NULL:      " >= ?",
+	LT_OR_NULL:      " < ?",
+	LE_OR_NULL:      " <= ?",
+}
+
+// buildCondition 构建单个过滤条件的SQL片段及参数，无法构建时返回空字符串
+//
+// 该函数位于过滤条件的热路径上，使用字符串拼接而非 fmt.Sprintf 以减少内存分配。
+func buildCondition(field string, filter Filter) (string, []interface{}) {
+	args, ok := conditionArgs(filter)
+	if !ok {
+		return "", nil
+	}
+	return conditionSQL(field, filter), args
+}
+
+// conditionSQL 构建过滤条件的SQL片段，仅取决于字段与操作符，不支持的操作符返回空字符串
+func conditionSQL(field string, filter Filter) string {
+	if filter.NoCase {
+		field = "LOWER(" + field + ")"
+	}
+
+	switch filter.Op {
+	case EQ, NE, GT, GE, LT, LE, REGEXP, NOT_REGEXP, OVERLAP, ARRAY_CONTAINS, ARRAY_CONTAINED:
+		return field + operatorSQL[filter.Op]
+	case LIKE, CONTAINS, STARTS_WITH, ENDS_WITH:
+		return field + " LIKE ?"
+	case NOT_LIKE:
+		return field + " NOT LIKE ?"
+	case IN:
+		return field + " IN (?)"
+	case NOT_IN:
+		return field + " NOT IN (?)"
+	case BETWEEN:
+		return field + " BETWEEN ? AND ?"
+	case NOT_BETWEEN:
+		return field + " NOT BETWEEN ? AND ?"
+	case GT_OR_NULL, GE_OR_NULL, LT_OR_NULL, LE_OR_NULL:
+		return "(" + field + operatorSQL[filter.Op] + " OR " + field + " IS NULL)"
+	case IS_NULL:
+		return field + " IS NULL"
+	case NOT_NULL:
+		return field + " IS NOT NULL"
+	}
+	return ""
+}
+
+// conditionArgs 构建过滤条件的绑定参数，取值无效或操作符不支持时返回 false
+func conditionArgs(filter Filter) ([]interface{}, bool) {
+	value := filter.Value
+	if filter.NoCase && value != "" {
+		value = strings.ToLower(value)
+	}
+
+	switch filter.Op {
+	case EQ, NE, GT, GE, LT, LE, REGEXP, NOT_REGEXP, OVERLAP, ARRAY_CONTAINS, ARRAY_CONTAINED,
+		GT_OR_NULL, GE_OR_NULL, LT_OR_NULL, LE_OR_NULL:
+		return []interface{}{value}, true
+	case LIKE, CONTAINS, NOT_LIKE:
+		return []interface{}{"%" + value + "%"}, true
+	case STARTS_WITH:
+		return []interface{}{value + "%"}, true
+	case ENDS_WITH:
+		return []interface{}{"%" + value}, true
+	case IN, NOT_IN:
+		return []interface{}{strings.Split(value, ",")}, true
+	case BETWEEN, NOT_BETWEEN:
+		if i := strings.IndexByte(value, ','); i >= 0 && strings.IndexByte(value[i+1:], ',') < 0 {
+			return []interface{}{value[:i], value[i+1:]}, true
+		}
+	case IS_NULL, NOT_NULL:
+		return nil, true
+	}
+	return nil, false
+}
+
 // applySorts 应用排序条件
 func (qb *QueryBuilder[T]) applySorts(query *gorm.DB, sorts []Sort) *gorm.DB {
+	// 保留查询中已有的排序项，排序作用域添加的排序按出现顺序收集
+	orders := takeOrders(query)
 	for _, sort := range sorts {
 		if sort.ScopeName != "" {
 			if scope, ok := qb.registry.Get(SortScope, sort.ScopeName); ok {
 				query = scope(query)
+				orders = append(orders, takeOrders(query)...)
+				continue
+			}
+		}
+
+		field := sort.Field
+		if sort.CountOf != "" {
+			field = sort.CountOf
+		}
+		if err := qb.checkSortAllowed(field); err != nil {
+			query.AddError(err)
+			continue
+		}
+
+		if sort.CountOf != "" {
+			expr, err := qb.countSortExpr(sort)
+			if err != nil {
+				query.AddError(err)
 				continue
 			}
+			orders = append(orders, orderItem{expr: expr})
+			continue
 		}
 
-		safeField, err := qb.safeField(sort.Field)
+		safeField, err := qb.resolveField(query, sort.Field)
 		if err != nil {
 			query.AddError(err)
 			continue
 		}
 
-		field := safeField
-		if sort.NoCase {
-			field = fmt.Sprintf("LOWER(%s)", field)
+		if len(sort.Values) > 0 {
+			orders = append(orders, orderItem{expr: qb.valueSortExpr(safeField, sort)})
+			continue
+		}
+
+		field, err = qb.sortField(safeField, sort)
+		if err != nil {
+			query.AddError(err)
+			continue
 		}
 
 		if sort.Desc {
-			query = query.Order(fmt.Sprintf("%s DESC", field))
+			orders = append(orders, rawOrder(field+" DESC"))
 		} else {
-			query = query.Order(fmt.Sprintf("%s ASC", field))
+			orders = append(orders, rawOrder(field+" ASC"))
 		}
 	}
-	return query
+	return applyOrders(query, orders)
 }
 
-// applyAggregations 应用聚合条件
-func (qb *QueryBuilder[T]) applyAggregations(query *gorm.DB, aggrs []Aggregation) *gorm.DB {
+// applyAggregations 应用聚合条件，分组字段会自动加入选择列表
+func (qb *QueryBuilder[T]) applyAggregations(query *gorm.DB, aggrs []Aggregation, groups []Group) *gorm.DB {
 	if len(aggrs) == 0 {
 		return query
 	}
 
 	selects := []string{}
+	added := make(map[string]bool)
+	var args []interface{}
+
+	// 选择分组字段，使分组结果无需额外配置即可解码
+	for _, group := range groups {
+		if group.ScopeName != "" || group.Field == "" || added[group.Field] {
+			continue
+		}
+		sel, err := qb.selectColumn(query, group.Field)
+		if err != nil {
+			// 无效的分组字段已在 applyGroups 中报告
+			continue
+		}
+		added[group.Field] = true
+		selects = append(selects, sel)
+	}
+
 	for _, aggr := range aggrs {
-		safeField, err := qb.safeField(aggr.Field)
+		field, fieldArgs, err := qb.aggregateField(query, aggr)
 		if err != nil {
 			query.AddError(err)
 			continue
 		}
 
-		field := safeField
 		if aggr.NoCase {
 			field = fmt.Sprintf("LOWER(%s)", field)
 		}
 
-		var expr string
-		switch aggr.Op {
-		case COUNT:
-			expr = fmt.Sprintf("COUNT(%s)", field)
-		case SUM:
-			expr = fmt.Sprintf("SUM(%s)", field)
-		case AVG:
-			expr = fmt.Sprintf("AVG(%s)", field)
-		case MAX:
-			expr = fmt.Sprintf("MAX(%s)", field)
-		case MIN:
-			expr = fmt.Sprintf("MIN(%s)", field)
+		expr, exprArgs, err := qb.conditionalAggregateExpr(query, field, fieldArgs, aggr)
+		if err != nil {
+			query.AddError(err)
+			continue
 		}
-
-		if expr != "" {
-			// 如果设置了别名就使用别名，否则使用原字段名
-			alias, err := qb.simpleField(aggr.Field)
+		if aggr.Window != nil && expr != "" {
+			var refs int
+			expr, refs, err = qb.windowExpr(query, expr, aggr.Window, groups)
 			if err != nil {
 				query.AddError(err)
 				continue
 			}
+			var windowArgs []interface{}
+			for ; refs > 0; refs-- {
+				windowArgs = append(windowArgs, exprArgs...)
+			}
+			exprArgs = windowArgs
+		}
 
-			if aggr.Alias != "" {
-				alias = aggr.Alias
+		if expr != "" {
+			// 如果设置了别名就使用别名，否则使用原字段名或表达式名
+			alias := aggr.Alias
+			if alias == "" && aggr.Expr != "" {
+				alias = aggr.Expr
+			}
+			if alias == "" {
+				alias, err = qb.resolveSimpleField(query, aggr.Field)
+				if err != nil {
+					query.AddError(err)
+					continue
+				}
 			}
 			expr = fmt.Sprintf("%s as %s", expr, alias)
 			selects = append(selects, expr)
+			args = append(args, exprArgs...)
 		}
 
-		// AddSelects 需要通过 ScopeFunc 来实现以确保安全性
-		if len(aggr.AddSelects) > 0 {
-			query.AddError(fmt.Errorf("additional selects must be implemented via ScopeFunc"))
+		// 附加的选择字段需通过字段校验，重复的字段只选择一次
+		for _, name := range aggr.AddSelects {
+			if added[name] {
+				continue
+			}
+			if expr, ok := qb.expressions.Get(name); ok {
+				added[name] = true
+				selects = append(selects, fmt.Sprintf("(%s) as %s", expr.SQL, name))
+				args = append(args, expr.Args...)
+				continue
+			}
+			sel, err := qb.selectColumn(query, name)
+			if err != nil {
+				query.AddError(err)
+				continue
+			}
+			added[name] = true
+			selects = append(selects, sel)
 		}
 	}
 
 	if len(selects) > 0 {
-		query = query.Select(strings.Join(selects, ", "))
+		query = query.Select(strings.Join(selects, ", "), args...)
 	}
 
 	return query
 }
 
+// selectColumn 生成以字段名为别名的选择项，配置了SQL脱敏表达式的字段选择表达式
+func (qb *QueryBuilder[T]) selectColumn(query *gorm.DB, fieldName string) (string, error) {
+	column, err := qb.resolveField(query, fieldName)
+	if err != nil {
+		return "", err
+	}
+	column = qb.maskedColumn(query, fieldName, column)
+	alias, err := qb.resolveSimpleField(query, fieldName)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s as %s", column, alias), nil
+}
+
 // applyJoins 应用连接条件
+//
+// 相同的连接只应用一次，作用域中添加的连接与请求中的连接SQL相同时同样只保留一次。
 func (qb *QueryBuilder[T]) applyJoins(query *gorm.DB, joins []Join) *gorm.DB {
-	for _, join := range joins {
+	aliases := make(map[string]string)
+	for _, join := range uniqueJoins(joins) {
 		if join.Type == "" && join.ScopeName != "" {
-			if scope, ok := qb.registry.Get(JoinScope, join.ScopeName); ok {
-				query = scope(query)
+			if scope, ok := qb.registry.GetParam(JoinScope, join.ScopeName); ok {
+				query = scope(query, join.Values...)
+				continue
+			}
+		}
+
+		table := join.Table
+		if join.Alias != "" {
+			if !isIdentifier(join.Alias) {
+				query.AddError(fmt.Errorf("invalid join alias: %s", join.Alias))
+				continue
+			}
+			if _, ok := aliases[join.Alias]; ok {
+				query.AddError(fmt.Errorf("duplicate join alias: %s", join.Alias))
 				continue
 			}
+			aliases[join.Alias] = join.Table
+			table = fmt.Sprintf("%s AS %s", join.Table, join.Alias)
 		}
+
 		switch strings.ToUpper(join.Type) {
 		case "LEFT":
-			query = query.Joins(fmt.Sprintf("LEFT JOIN %s ON %s", join.Table, join.Condition))
+			query = query.Joins(fmt.Sprintf("LEFT JOIN %s ON %s", table, join.Condition))
 		case "RIGHT":
-			query = query.Joins(fmt.Sprintf("RIGHT JOIN %s ON %s", join.Table, join.Condition))
+			query = query.Joins(fmt.Sprintf("RIGHT JOIN %s ON %s", table, join.Condition))
 		case "INNER":
-			query = query.Joins(fmt.Sprintf("INNER JOIN %s ON %s", join.Table, join.Condition))
+			query = query.Joins(fmt.Sprintf("INNER JOIN %s ON %s", table, join.Condition))
 		}
 	}
+
+	dedupeStatementJoins(query)
+	if len(aliases) > 0 {
+		query = query.InstanceSet(joinAliasesKey, aliases)
+	}
 	return query
 }
 
@@ -532,19 +1212,20 @@ func (qb *QueryBuilder[T]) applyGroups(query *gorm.DB, groups []Group) *gorm.DB
 	groupFields := make([]string, 0, len(groups))
 	for _, group := range groups {
 		if group.ScopeName != "" {
-			if scope, ok := qb.registry.Get(GroupScope, group.ScopeName); ok {
-				query = scope(query)
+			if scope, ok := qb.registry.GetParam(GroupScope, group.ScopeName); ok {
+				query = scope(query, group.Values...)
 				continue
 			}
 		}
 
-		safeField, err := qb.safeField(group.Field)
+		safeField, err := qb.resolveField(query, group.Field)
 		if err != nil {
 			query.AddError(err)
 			continue
 		}
 
-		groupFields = append(groupFields, safeField)
+		// 配置了SQL脱敏表达式的字段按脱敏后的取值分组
+		groupFields = append(groupFields, qb.maskedColumn(query, group.Field, safeField))
 		if group.Having != "" {
 			// Having 条件需要通过 ScopeFunc 来实现以确保安全性
 			query.AddError(fmt.Errorf("having conditions must be implemented via ScopeFunc"))
@@ -552,22 +1233,20 @@ func (qb *QueryBuilder[T]) applyGroups(query *gorm.DB, groups []Group) *gorm.DB
 	}
 
 	if len(groupFields) > 0 {
-		return query.Group(strings.Join(groupFields, ", "))
+		// 分组项可能为脱敏表达式，按原样写入而不作为列名引用
+		return query.Clauses(clause.GroupBy{Columns: []clause.Column{{Name: strings.Join(groupFields, ", "), Raw: true}}})
 	}
 	return query
 }
 
 // applyPagination 应用分页
-func (qb *QueryBuilder[T]) applyPagination(query *gorm.DB, page *Pagination) *gorm.DB {
+func (qb *QueryBuilder[T]) applyPagination(query *gorm.DB, page *Pagination, count bool) *gorm.DB {
 	if page == nil {
 		return query
 	}
 
-	// 计算总记录数
-	query.Count(&page.Total)
-
 	// 应用分页
-	offset := (page.Page - 1) * page.PageSize
+	offset := qb.countPage(query, page, count)
 	return query.Offset(offset).Limit(page.PageSize)
 }
 
@@ -578,8 +1257,8 @@ func (qb *QueryBuilder[T]) applyCustomFields(query *gorm.DB, fields []CustomFiel
 	}
 
 	for _, field := range fields {
-		if scope, ok := qb.registry.Get(SelectScope, field.ScopeName); ok {
-			query = scope(query)
+		if scope, ok := qb.registry.GetParam(SelectScope, field.ScopeName); ok {
+			query = scope(query, field.Values...)
 		}
 	}
 	return query
@@ -591,28 +1270,128 @@ func (qb *QueryBuilder[T]) applyCustomFilter(query *gorm.DB, filter *CustomFilte
 		return query
 	}
 
-	if scope, ok := qb.registry.Get(FilterScope, filter.ScopeName); ok {
-		query = scope(query)
+	if scope, ok := qb.registry.GetParam(FilterScope, filter.ScopeName); ok {
+		query = scope(query, filter.Values...)
 	}
 	return query
 }
 
 // Count 获取记录总数
 func (qb *QueryBuilder[T]) Count(req *FilterRequest) (int64, error) {
-	var count int64
-	query := qb.Build(req)
-	err := query.Count(&count).Error
-	return count, err
+	load := func() (int64, error) {
+		var count int64
+		query := qb.Build(req)
+		err := query.Count(&count).Error
+		return count, err
+	}
+	if qb.opts.countCache != nil {
+		return qb.cachedCount(req, load)
+	}
+	return load()
 }
 
 // FindAll 查询所有记录
 func (qb *QueryBuilder[T]) FindAll(req *FilterRequest, dest interface{}) error {
-	return qb.Build(req).Find(dest).Error
+	req, err := qb.beforeFind(req)
+	if err != nil {
+		return err
+	}
+	query := qb.Build(req)
+	if err := qb.checkProjection(query, dest); err != nil {
+		return err
+	}
+	if columns := qb.decodedAggregates(req.Aggrs); len(columns) > 0 {
+		err = qb.findDecoded(query, dest, columns, false)
+	} else {
+		err = query.Find(dest).Error
+	}
+	if err != nil {
+		return err
+	}
+	return qb.afterFindDest(dest)
 }
 
 // FindOne 查询单条记录
 func (qb *QueryBuilder[T]) FindOne(req *FilterRequest, dest interface{}) error {
-	return qb.Build(req).First(dest).Error
+	req, err := qb.beforeFind(req)
+	if err != nil {
+		return err
+	}
+	query := qb.Build(req)
+	if err := qb.checkProjection(query, dest); err != nil {
+		return err
+	}
+	if columns := qb.decodedAggregates(req.Aggrs); len(columns) > 0 {
+		err = qb.findDecoded(query, dest, columns, true)
+	} else {
+		err = query.First(dest).Error
+	}
+	if err != nil {
+		return err
+	}
+	return qb.afterFindDest(dest)
+}
+
+// FindOneOrNil 查询单条记录，没有符合条件的记录时返回 nil 而非 gorm.ErrRecordNotFound
+func (qb *QueryBuilder[T]) FindOneOrNil(req *FilterRequest) (*T, error) {
+	var item T
+	if err := qb.FindOne(req, &item); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// First 查询排序后的第一条记录，没有符合条件的记录时返回 gorm.ErrRecordNotFound
+//
+// 请求未指定排序时使用默认排序，排序后总是追加主键排序，请求没有排序时返回主键最小的记录。
+func (qb *QueryBuilder[T]) First(req *FilterRequest) (*T, error) {
+	return qb.edge(req, false)
+}
+
+// Last 查询排序后的最后一条记录，即按反转后的排序查询第一条记录，没有符合条件的记录时返回 gorm.ErrRecordNotFound
+//
+// 排序作用域无法反转，请求包含排序作用域时返回错误。
+func (qb *QueryBuilder[T]) Last(req *FilterRequest) (*T, error) {
+	return qb.edge(req, true)
+}
+
+// edge 按确定的排序查询第一条记录，last 为 true 时反转排序方向
+func (qb *QueryBuilder[T]) edge(req *FilterRequest, last bool) (*T, error) {
+	sorts := req.Sorts
+	if len(sorts) == 0 {
+		sorts = qb.opts.defaultSorts
+	}
+	sorts = qb.primaryKeySorts(sorts)
+	if last {
+		reversed := make([]Sort, len(sorts))
+		for i, sort := range sorts {
+			if _, ok := qb.registry.Get(SortScope, sort.ScopeName); sort.ScopeName != "" && ok {
+				return nil, fmt.Errorf("sort scope cannot be reversed: %s", sort.ScopeName)
+			}
+			sort.Desc = !sort.Desc
+			reversed[i] = sort
+		}
+		sorts = reversed
+	}
+
+	edgeReq := *req
+	edgeReq.Sorts = sorts
+	edgeReq.Page = nil
+	req, err := qb.beforeFind(&edgeReq)
+	if err != nil {
+		return nil, err
+	}
+	var item T
+	if err := qb.Build(req).Take(&item).Error; err != nil {
+		return nil, err
+	}
+	if err := qb.afterFindDest(&item); err != nil {
+		return nil, err
+	}
+	return &item, nil
 }
 
 // 添加操作符的字符串表示方法
@@ -660,6 +1439,20 @@ func (op Operator) String() string {
 		return "ARRAY_CONTAINS"
 	case ARRAY_CONTAINED:
 		return "ARRAY_CONTAINED"
+	case INET_IN_CIDR:
+		return "INET_IN_CIDR"
+	case CIDR_CONTAINS:
+		return "CIDR_CONTAINS"
+	case NOT_BETWEEN:
+		return "NOT_BETWEEN"
+	case GT_OR_NULL:
+		return "GT_OR_NULL"
+	case GE_OR_NULL:
+		return "GE_OR_NULL"
+	case LT_OR_NULL:
+		return "LT_OR_NULL"
+	case LE_OR_NULL:
+		return "LE_OR_NULL"
 	default:
 		return "UNKNOWN"
 	}
@@ -1,6 +1,8 @@
 package querybuild
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -11,13 +13,22 @@ import (
 
 // TestUser 测试用户模型
 type TestUser struct {
-	ID        uint      `gorm:"primarykey"`
-	Name      string    `gorm:"column:name"`
-	Email     string    `gorm:"column:email"`
-	Age       int       `gorm:"column:age"`
-	Status    string    `gorm:"column:status"`
-	Tags      string    `gorm:"column:tags"`
-	CreatedAt time.Time `gorm:"column:created_at"`
+	ID        uint        `gorm:"primarykey"`
+	Name      string      `gorm:"column:name"`
+	Email     string      `gorm:"column:email"`
+	Age       int         `gorm:"column:age"`
+	Status    string      `gorm:"column:status"`
+	Tags      string      `gorm:"column:tags"`
+	CreatedAt time.Time   `gorm:"column:created_at"`
+	Orders    []TestOrder `gorm:"foreignKey:UserID"`
+}
+
+// TestOrder 测试订单模型
+type TestOrder struct {
+	ID     uint    `gorm:"primarykey"`
+	UserID uint    `gorm:"column:user_id"`
+	Amount float64 `gorm:"column:amount"`
+	Status string  `gorm:"column:status"`
 }
 
 func setupTestDB(t *testing.T) *gorm.DB {
@@ -25,7 +36,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	assert.NoError(t, err)
 
 	// 创建表
-	err = db.AutoMigrate(&TestUser{})
+	err = db.AutoMigrate(&TestUser{}, &TestOrder{})
 	assert.NoError(t, err)
 
 	// 插入测试数据
@@ -37,6 +48,15 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	err = db.Create(&users).Error
 	assert.NoError(t, err)
 
+	// 插入订单数据：John 有两笔已支付订单，Jane 有一笔待支付订单，Bob 没有订单
+	orders := []TestOrder{
+		{UserID: users[0].ID, Amount: 100, Status: "paid"},
+		{UserID: users[0].ID, Amount: 50, Status: "paid"},
+		{UserID: users[1].ID, Amount: 80, Status: "pending"},
+	}
+	err = db.Create(&orders).Error
+	assert.NoError(t, err)
+
 	return db
 }
 
@@ -64,6 +84,21 @@ func TestQueryBuilder_Basic(t *testing.T) {
 		assert.Equal(t, "John Doe", user.Name)
 	})
 
+	t.Run("FindOneOrNil", func(t *testing.T) {
+		user, err := builder.FindOneOrNil(&FilterRequest{Filters: []Filter{{Field: "Name", Op: EQ, Value: "John Doe"}}})
+		assert.NoError(t, err)
+		if assert.NotNil(t, user) {
+			assert.Equal(t, "John Doe", user.Name)
+		}
+
+		user, err = builder.FindOneOrNil(&FilterRequest{Filters: []Filter{{Field: "Name", Op: EQ, Value: "Nobody"}}})
+		assert.NoError(t, err)
+		assert.Nil(t, user)
+
+		_, err = builder.FindOneOrNil(&FilterRequest{Filters: []Filter{{Field: "Missing", Op: EQ, Value: "x"}}})
+		assert.Error(t, err)
+	})
+
 	t.Run("Count", func(t *testing.T) {
 		req := &FilterRequest{}
 		count, err := builder.Count(req)
@@ -136,6 +171,11 @@ func TestQueryBuilder_Filters(t *testing.T) {
 			filter:   Filter{Field: "Email", Op: NOT_NULL},
 			expected: 3,
 		},
+		{
+			name:     "NOT_BETWEEN operator",
+			filter:   Filter{Field: "Age", Op: NOT_BETWEEN, Value: "26,34"},
+			expected: 2,
+		},
 	}
 
 	for _, tt := range tests {
@@ -317,6 +357,30 @@ func TestQueryBuilder_InvalidField(t *testing.T) {
 	})
 }
 
+func TestQueryBuilder_OrNullOperators(t *testing.T) {
+	db := setupTestDB(t)
+	assert.NoError(t, db.Exec("UPDATE test_users SET age = NULL WHERE name = ?", "Jane Smith").Error)
+	builder := NewQueryBuilder[TestUser](db)
+
+	tests := []struct {
+		op       Operator
+		value    string
+		expected int64
+	}{
+		{GT_OR_NULL, "30", 2},
+		{GE_OR_NULL, "25", 3},
+		{LT_OR_NULL, "30", 2},
+		{LE_OR_NULL, "20", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.op.String(), func(t *testing.T) {
+			count, err := builder.Count(&FilterRequest{Filters: []Filter{{Field: "Age", Op: tt.op, Value: tt.value}}})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, count)
+		})
+	}
+}
+
 func TestOperator_String(t *testing.T) {
 	tests := []struct {
 		op       Operator
@@ -329,6 +393,8 @@ func TestOperator_String(t *testing.T) {
 		{IN, "IN"},
 		{IS_NULL, "IS_NULL"},
 		{STARTS_WITH, "STARTS_WITH"},
+		{NOT_BETWEEN, "NOT_BETWEEN"},
+		{GE_OR_NULL, "GE_OR_NULL"},
 		{Operator(999), "UNKNOWN"},
 	}
 
@@ -651,3 +717,98 @@ func TestQueryBuilder_ScopedOperations(t *testing.T) {
 		assert.Equal(t, "active", results[0].Status)
 	})
 }
+
+func TestQueryBuilder_WithDB(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+	builder.RegisterScope(FilterScope, "activeUsers", func(db *gorm.DB) *gorm.DB {
+		return db.Where("status = ?", "active")
+	})
+
+	t.Run("Bound to transaction", func(t *testing.T) {
+		errRollback := errors.New("rollback")
+		err := db.Transaction(func(tx *gorm.DB) error {
+			assert.NoError(t, tx.Create(&TestUser{Name: "Tx User", Status: "active"}).Error)
+
+			txBuilder := builder.WithDB(tx)
+			count, err := txBuilder.Count(&FilterRequest{
+				CustomFilter: &CustomFilter{ScopeName: "activeUsers"},
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, int64(3), count)
+			return errRollback
+		})
+		assert.ErrorIs(t, err, errRollback)
+
+		count, err := builder.Count(&FilterRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+	})
+
+	t.Run("Shares registry with original builder", func(t *testing.T) {
+		clone := builder.WithContext(context.Background())
+		clone.RegisterScope(FilterScope, "adults", func(db *gorm.DB) *gorm.DB {
+			return db.Where("age >= ?", 30)
+		})
+		count, err := builder.Count(&FilterRequest{
+			CustomFilter: &CustomFilter{ScopeName: "adults"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+}
+
+func TestQueryBuilder_FirstLast(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+
+	t.Run("Primary key order", func(t *testing.T) {
+		first, err := builder.First(&FilterRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, "John Doe", first.Name)
+
+		last, err := builder.Last(&FilterRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, "Bob Johnson", last.Name)
+	})
+
+	t.Run("Request sorts", func(t *testing.T) {
+		req := &FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+			Sorts:   []Sort{{Field: "Age", Desc: true}},
+		}
+		first, err := builder.First(req)
+		assert.NoError(t, err)
+		assert.Equal(t, "Bob Johnson", first.Name)
+
+		last, err := builder.Last(req)
+		assert.NoError(t, err)
+		assert.Equal(t, "John Doe", last.Name)
+		assert.Equal(t, []Sort{{Field: "Age", Desc: true}}, req.Sorts)
+	})
+
+	t.Run("Default sorts", func(t *testing.T) {
+		sorted := builder.Clone(WithDefaultSort(Sort{Field: "Name"}))
+		first, err := sorted.First(&FilterRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, "Bob Johnson", first.Name)
+
+		last, err := sorted.Last(&FilterRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, "John Doe", last.Name)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		_, err := builder.First(&FilterRequest{Filters: []Filter{{Field: "Name", Op: EQ, Value: "Nobody"}}})
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("Sort scope cannot be reversed", func(t *testing.T) {
+		scoped := builder.Clone()
+		assert.NoError(t, scoped.RegisterScope(SortScope, "by_name", func(db *gorm.DB) *gorm.DB {
+			return db.Order("name")
+		}))
+		_, err := scoped.Last(&FilterRequest{Sorts: []Sort{{ScopeName: "by_name"}}})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,370 @@
+package querybuild
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ExistsFilter 关联记录存在性过滤条件
+type ExistsFilter struct {
+	Relation string        `json:"relation"`  // 模型关联名称，如 Orders
+	Filter   FilterRequest `json:"filter"`    // 关联记录上的过滤条件，仅使用 Filters 和 Exists
+	Not      bool          `json:"not"`       // 为 true 时生成 NOT EXISTS
+	LeftJoin bool          `json:"left_join"` // 与 Not 同时使用，以 LEFT JOIN ... IS NULL 代替 NOT EXISTS
+	All      bool          `json:"all"`       // 要求关联记录覆盖 IN 过滤条件的每个取值，与 Not 同时使用时表示未全部覆盖
+}
+
+// applyExists 应用 EXISTS / NOT EXISTS 关联子查询过滤
+func (qb *QueryBuilder[T]) applyExists(query *gorm.DB, filters []ExistsFilter) *gorm.DB {
+	for i, filter := range filters {
+		if filter.LeftJoin {
+			join, isNull, err := qb.antiJoin(filter, fmt.Sprintf("anti_%d", i))
+			if err != nil {
+				query.AddError(err)
+				continue
+			}
+			query = query.Joins(join.SQL, join.Vars...).Where(isNull)
+			continue
+		}
+		if filter.All {
+			cond, args, err := qb.allOfCondition(filter)
+			if err != nil {
+				query.AddError(err)
+				continue
+			}
+			query = query.Where(cond, args...)
+			continue
+		}
+
+		sub, err := qb.existsSubQuery(qb.schema, qb.tableName(), filter)
+		if err != nil {
+			query.AddError(err)
+			continue
+		}
+
+		query = whereExists(query, sub, filter.Not)
+	}
+	return query
+}
+
+// whereExists 以 EXISTS 或 NOT EXISTS 形式追加子查询条件
+func whereExists(query, sub *gorm.DB, not bool) *gorm.DB {
+	if not {
+		return query.Where("NOT EXISTS (?)", sub)
+	}
+	return query.Where("EXISTS (?)", sub)
+}
+
+// antiJoin 构建以别名连接关联表的 LEFT JOIN 及关联记录不存在的判断条件
+//
+// 关联记录的过滤条件写入连接条件，因此关联记录不存在或均不满足过滤条件时保留外层记录。
+func (qb *QueryBuilder[T]) antiJoin(filter ExistsFilter, alias string) (clause.Expr, string, error) {
+	if !filter.Not {
+		return clause.Expr{}, "", fmt.Errorf("left join requires a NOT exists filter: %s", filter.Relation)
+	}
+	if len(filter.Filter.Exists) > 0 {
+		return clause.Expr{}, "", fmt.Errorf("left join does not support nested exists filters: %s", filter.Relation)
+	}
+	rel, ok := qb.schema.Relationships.Relations[filter.Relation]
+	if !ok {
+		return clause.Expr{}, "", fmt.Errorf("invalid relation name: %s", filter.Relation)
+	}
+	pk := rel.FieldSchema.PrioritizedPrimaryField
+	if pk == nil {
+		return clause.Expr{}, "", fmt.Errorf("left join requires a primary key on relation: %s", filter.Relation)
+	}
+
+	conds, condArgs, err := relationConditions(rel, qb.tableName(), alias)
+	if err != nil {
+		return clause.Expr{}, "", err
+	}
+	var args []interface{}
+	for _, a := range condArgs {
+		args = append(args, a...)
+	}
+
+	fields := schemaFields(rel.FieldSchema)
+	for _, f := range filter.Filter.Filters {
+		if err := qb.checkOperator(f.Op); err != nil {
+			return clause.Expr{}, "", err
+		}
+		info, ok := fields[f.Field]
+		if !ok {
+			return clause.Expr{}, "", fmt.Errorf("invalid field name: %s", f.Field)
+		}
+		cond, condArgs := buildCondition(quoteField(FieldInfo{TableName: alias, Name: info.Name}), f)
+		if cond == "" {
+			return clause.Expr{}, "", fmt.Errorf("invalid filter on relation field: %s", f.Field)
+		}
+		conds = append(conds, cond)
+		args = append(args, condArgs...)
+	}
+
+	join := clause.Expr{
+		SQL:  fmt.Sprintf("LEFT JOIN `%s` `%s` ON %s", rel.FieldSchema.Table, alias, strings.Join(conds, " AND ")),
+		Vars: args,
+	}
+	return join, quoteField(FieldInfo{TableName: alias, Name: pk.DBName}) + " IS NULL", nil
+}
+
+// allOfCondition 构建关联记录覆盖 IN 过滤条件全部取值的条件：统计满足过滤条件的关联记录中不同取值的数量，
+// 与 IN 取值中不同取值的数量比较
+func (qb *QueryBuilder[T]) allOfCondition(filter ExistsFilter) (string, []interface{}, error) {
+	if filter.LeftJoin {
+		return "", nil, fmt.Errorf("left join does not support all-of exists filters: %s", filter.Relation)
+	}
+	rel, ok := qb.schema.Relationships.Relations[filter.Relation]
+	if !ok {
+		return "", nil, fmt.Errorf("invalid relation name: %s", filter.Relation)
+	}
+
+	var in *Filter
+	for i, f := range filter.Filter.Filters {
+		if f.Op != IN {
+			continue
+		}
+		if in != nil {
+			return "", nil, fmt.Errorf("all-of exists filter requires exactly one IN filter: %s", filter.Relation)
+		}
+		in = &filter.Filter.Filters[i]
+	}
+	if in == nil {
+		return "", nil, fmt.Errorf("all-of exists filter requires exactly one IN filter: %s", filter.Relation)
+	}
+	info, ok := tableSchemaFields(rel.FieldSchema, relationAlias(rel, qb.tableName()))[in.Field]
+	if !ok {
+		return "", nil, fmt.Errorf("invalid field name: %s", in.Field)
+	}
+
+	column := quoteField(info)
+	value := in.Value
+	if in.NoCase {
+		column = "LOWER(" + column + ")"
+		value = strings.ToLower(value)
+	}
+	distinct := make(map[string]bool)
+	for _, v := range strings.Split(value, ",") {
+		distinct[v] = true
+	}
+
+	sub, err := qb.relationSubQuery(qb.schema, qb.tableName(), filter.Relation, filter.Filter, "COUNT(DISTINCT "+column+")")
+	if err != nil {
+		return "", nil, err
+	}
+	if filter.Not {
+		return "(?) < ?", []interface{}{sub, len(distinct)}, nil
+	}
+	return "(?) = ?", []interface{}{sub, len(distinct)}, nil
+}
+
+// existsSubQuery 构建与外层模型关联的 SELECT 1 子查询，outerTable 为外层查询中模型的表名或别名
+func (qb *QueryBuilder[T]) existsSubQuery(owner *schema.Schema, outerTable string, filter ExistsFilter) (*gorm.DB, error) {
+	return qb.relationSubQuery(owner, outerTable, filter.Relation, filter.Filter, "1")
+}
+
+// relationSubQuery 构建与外层模型关联的相关子查询
+//
+// 自引用关联（如评论的父评论）的关联表与外层表相同，子查询中的关联表以“外层表名_关联名”为别名，
+// 关联条件与关联记录上的过滤条件均按别名限定。多对多关联经连接表关联，
+// 没有关联记录上的过滤条件时仅查询连接表。
+func (qb *QueryBuilder[T]) relationSubQuery(owner *schema.Schema, outerTable, relation string, filter FilterRequest, selectExpr string) (*gorm.DB, error) {
+	rel, ok := owner.Relationships.Relations[relation]
+	if !ok {
+		return nil, fmt.Errorf("invalid relation name: %s", relation)
+	}
+
+	innerTable := relationAlias(rel, outerTable)
+	table := rel.FieldSchema.Table
+	if innerTable != table {
+		table = fmt.Sprintf("`%s` `%s`", rel.FieldSchema.Table, innerTable)
+	}
+
+	sub := qb.db.Session(&gorm.Session{NewDB: true})
+	if rel.JoinTable != nil {
+		conds, joinConds := joinTableConditions(rel, outerTable, innerTable)
+		if len(filter.Filters) == 0 && len(filter.Exists) == 0 {
+			sub = sub.Table(rel.JoinTable.Table)
+		} else {
+			sub = sub.Table(table).
+				Joins(fmt.Sprintf("JOIN `%s` ON %s", rel.JoinTable.Table, strings.Join(joinConds, " AND ")))
+		}
+		sub = sub.Select(selectExpr)
+		for _, cond := range conds {
+			sub = sub.Where(cond)
+		}
+	} else {
+		conds, args, err := relationConditions(rel, outerTable, innerTable)
+		if err != nil {
+			return nil, err
+		}
+		sub = sub.Table(table).Select(selectExpr)
+		for i, cond := range conds {
+			sub = sub.Where(cond, args[i]...)
+		}
+	}
+
+	for _, f := range filter.Filters {
+		if err := qb.checkOperator(f.Op); err != nil {
+			return nil, err
+		}
+	}
+	sub = applyFieldFilters(sub, tableSchemaFields(rel.FieldSchema, innerTable), filter.Filters)
+	for _, nested := range filter.Exists {
+		nestedSub, err := qb.existsSubQuery(rel.FieldSchema, innerTable, nested)
+		if err != nil {
+			return nil, err
+		}
+		sub = whereExists(sub, nestedSub, nested.Not)
+	}
+
+	if sub.Error != nil {
+		return nil, sub.Error
+	}
+	return sub, nil
+}
+
+// CountFilter 关联记录数量过滤条件
+type CountFilter struct {
+	Relation string        `json:"relation"` // 模型关联名称，如 Orders
+	Op       Operator      `json:"op"`       // 比较操作符，支持 EQ/NE/GT/GE/LT/LE/BETWEEN
+	Value    string        `json:"value"`    // 比较的数量，BETWEEN 时使用逗号分隔
+	Filter   FilterRequest `json:"filter"`   // 参与计数的关联记录过滤条件，仅使用 Filters 和 Exists
+}
+
+// applyCountFilters 应用关联记录数量过滤条件
+func (qb *QueryBuilder[T]) applyCountFilters(query *gorm.DB, filters []CountFilter) *gorm.DB {
+	for _, filter := range filters {
+		sub, err := qb.relationSubQuery(qb.schema, qb.tableName(), filter.Relation, filter.Filter, "COUNT(*)")
+		if err != nil {
+			query.AddError(err)
+			continue
+		}
+
+		cond, args, err := countCondition(filter)
+		if err != nil {
+			query.AddError(err)
+			continue
+		}
+		query = query.Where(cond, append([]interface{}{sub}, args...)...)
+	}
+	return query
+}
+
+// countCondition 构建关联数量的比较条件，数量值按整数绑定
+func countCondition(filter CountFilter) (string, []interface{}, error) {
+	values := strings.Split(filter.Value, ",")
+	nums := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid count value: %s", filter.Value)
+		}
+		nums = append(nums, n)
+	}
+
+	var op string
+	switch filter.Op {
+	case EQ:
+		op = "="
+	case NE:
+		op = "!="
+	case GT:
+		op = ">"
+	case GE:
+		op = ">="
+	case LT:
+		op = "<"
+	case LE:
+		op = "<="
+	case BETWEEN:
+		if len(nums) != 2 {
+			return "", nil, fmt.Errorf("invalid count value: %s", filter.Value)
+		}
+		return "(?) BETWEEN ? AND ?", nums, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported count operator: %s", filter.Op)
+	}
+
+	if len(nums) != 1 {
+		return "", nil, fmt.Errorf("invalid count value: %s", filter.Value)
+	}
+	return fmt.Sprintf("(?) %s ?", op), nums, nil
+}
+
+// relationAlias 返回子查询中关联表的名称，自引用关联以“外层表名_关联名”为别名
+func relationAlias(rel *schema.Relationship, outerTable string) string {
+	if rel.FieldSchema.Table == outerTable {
+		return outerTable + "_" + strings.ToLower(rel.Name)
+	}
+	return rel.FieldSchema.Table
+}
+
+// joinTableConditions 根据多对多关联的引用生成连接表与外层表之间的条件，以及连接表与关联表之间的连接条件
+func joinTableConditions(rel *schema.Relationship, outerTable, innerTable string) (conds, joinConds []string) {
+	for _, ref := range rel.References {
+		joinField := quoteField(FieldInfo{TableName: rel.JoinTable.Table, Name: ref.ForeignKey.DBName})
+		if ref.OwnPrimaryKey {
+			conds = append(conds, fmt.Sprintf("%s = %s", joinField,
+				quoteField(FieldInfo{TableName: outerTable, Name: ref.PrimaryKey.DBName})))
+		} else {
+			joinConds = append(joinConds, fmt.Sprintf("%s = %s", joinField,
+				quoteField(FieldInfo{TableName: innerTable, Name: ref.PrimaryKey.DBName})))
+		}
+	}
+	return conds, joinConds
+}
+
+// relationConditions 根据关联引用生成关联表与外层表之间的关联条件
+func relationConditions(rel *schema.Relationship, outerTable, innerTable string) ([]string, [][]interface{}, error) {
+	if rel.JoinTable != nil {
+		return nil, nil, fmt.Errorf("many to many relation is not supported: %s", rel.Name)
+	}
+
+	conds := make([]string, 0, len(rel.References))
+	args := make([][]interface{}, 0, len(rel.References))
+	for _, ref := range rel.References {
+		switch {
+		case ref.PrimaryValue != "":
+			// 多态关联的类型字段
+			conds = append(conds, fmt.Sprintf("%s = ?",
+				quoteField(FieldInfo{TableName: innerTable, Name: ref.ForeignKey.DBName})))
+			args = append(args, []interface{}{ref.PrimaryValue})
+		case ref.OwnPrimaryKey:
+			// has one / has many：关联表持有外键
+			conds = append(conds, fmt.Sprintf("%s = %s",
+				quoteField(FieldInfo{TableName: innerTable, Name: ref.ForeignKey.DBName}),
+				quoteField(FieldInfo{TableName: outerTable, Name: ref.PrimaryKey.DBName})))
+			args = append(args, nil)
+		default:
+			// belongs to：外层表持有外键
+			conds = append(conds, fmt.Sprintf("%s = %s",
+				quoteField(FieldInfo{TableName: innerTable, Name: ref.PrimaryKey.DBName}),
+				quoteField(FieldInfo{TableName: outerTable, Name: ref.ForeignKey.DBName})))
+			args = append(args, nil)
+		}
+	}
+	return conds, args, nil
+}
+
+// countSortExpr 生成按关联记录数量排序的表达式
+func (qb *QueryBuilder[T]) countSortExpr(sort Sort) (clause.Expression, error) {
+	sub, err := qb.relationSubQuery(qb.schema, qb.tableName(), sort.CountOf, FilterRequest{}, "COUNT(*)")
+	if err != nil {
+		return nil, err
+	}
+
+	direction := "ASC"
+	if sort.Desc {
+		direction = "DESC"
+	}
+	return clause.Expr{
+		SQL:                fmt.Sprintf("(?) %s", direction),
+		Vars:               []interface{}{sub},
+		WithoutParentheses: true,
+	}, nil
+}
@@ -0,0 +1,381 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestQueryBuilder_Exists(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+
+	t.Run("Users with paid orders", func(t *testing.T) {
+		var users []TestUser
+		req := &FilterRequest{
+			Exists: []ExistsFilter{
+				{
+					Relation: "Orders",
+					Filter: FilterRequest{
+						Filters: []Filter{{Field: "Status", Op: EQ, Value: "paid"}},
+					},
+				},
+			},
+		}
+		err := builder.FindAll(req, &users)
+		assert.NoError(t, err)
+		assert.Len(t, users, 1)
+		assert.Equal(t, "John Doe", users[0].Name)
+	})
+
+	t.Run("Users without orders", func(t *testing.T) {
+		var users []TestUser
+		req := &FilterRequest{
+			Exists: []ExistsFilter{
+				{Relation: "Orders", Not: true},
+			},
+		}
+		err := builder.FindAll(req, &users)
+		assert.NoError(t, err)
+		assert.Len(t, users, 1)
+		assert.Equal(t, "Bob Johnson", users[0].Name)
+	})
+
+	t.Run("Users without paid orders via left join", func(t *testing.T) {
+		var users []TestUser
+		req := &FilterRequest{
+			Sorts: []Sort{{Field: "Name"}},
+			Exists: []ExistsFilter{
+				{
+					Relation: "Orders",
+					Not:      true,
+					LeftJoin: true,
+					Filter: FilterRequest{
+						Filters: []Filter{{Field: "Status", Op: EQ, Value: "paid"}},
+					},
+				},
+			},
+		}
+		err := builder.FindAll(req, &users)
+		assert.NoError(t, err)
+		if assert.Len(t, users, 2) {
+			assert.Equal(t, "Bob Johnson", users[0].Name)
+			assert.Equal(t, uint(3), users[0].ID)
+			assert.Equal(t, "Jane Smith", users[1].Name)
+			assert.Equal(t, uint(2), users[1].ID)
+		}
+
+		count, err := builder.Count(req)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+
+		query := builder.WithDB(db.Session(&gorm.Session{DryRun: true})).Build(req).Find(&[]TestUser{})
+		assert.Contains(t, query.Statement.SQL.String(),
+			"LEFT JOIN `test_orders` `anti_0` ON `anti_0`.`user_id` = `test_users`.`id` AND `anti_0`.`status` = ?")
+		assert.Contains(t, query.Statement.SQL.String(), "`anti_0`.`id` IS NULL")
+	})
+
+	t.Run("Left join requires not", func(t *testing.T) {
+		err := builder.Validate(&FilterRequest{
+			Exists: []ExistsFilter{{Relation: "Orders", LeftJoin: true}},
+		})
+		assert.ErrorContains(t, err, "left join requires a NOT exists filter")
+	})
+
+	t.Run("Combined with outer filters", func(t *testing.T) {
+		count, err := builder.Count(&FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "inactive"}},
+			Exists:  []ExistsFilter{{Relation: "Orders"}},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("Invalid relation", func(t *testing.T) {
+		var users []TestUser
+		req := &FilterRequest{
+			Exists: []ExistsFilter{{Relation: "Unknown"}},
+		}
+		err := builder.FindAll(req, &users)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid relation name")
+	})
+
+	t.Run("Invalid field in relation filter", func(t *testing.T) {
+		var users []TestUser
+		req := &FilterRequest{
+			Exists: []ExistsFilter{
+				{
+					Relation: "Orders",
+					Filter: FilterRequest{
+						Filters: []Filter{{Field: "Name", Op: EQ, Value: "x"}},
+					},
+				},
+			},
+		}
+		err := builder.FindAll(req, &users)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid field name")
+	})
+}
+
+func TestQueryBuilder_CountFilters(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+
+	t.Run("Users with more than one order", func(t *testing.T) {
+		var users []TestUser
+		req := &FilterRequest{
+			CountFilters: []CountFilter{
+				{Relation: "Orders", Op: GT, Value: "1"},
+			},
+		}
+		err := builder.FindAll(req, &users)
+		assert.NoError(t, err)
+		assert.Len(t, users, 1)
+		assert.Equal(t, "John Doe", users[0].Name)
+	})
+
+	t.Run("Count with relation filter", func(t *testing.T) {
+		count, err := builder.Count(&FilterRequest{
+			CountFilters: []CountFilter{
+				{
+					Relation: "Orders",
+					Op:       EQ,
+					Value:    "1",
+					Filter: FilterRequest{
+						Filters: []Filter{{Field: "Status", Op: EQ, Value: "pending"}},
+					},
+				},
+			},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("Between", func(t *testing.T) {
+		count, err := builder.Count(&FilterRequest{
+			CountFilters: []CountFilter{{Relation: "Orders", Op: BETWEEN, Value: "0,1"}},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("Sort by relation count", func(t *testing.T) {
+		var users []TestUser
+		req := &FilterRequest{
+			Sorts: []Sort{{CountOf: "Orders", Desc: true}},
+		}
+		err := builder.FindAll(req, &users)
+		assert.NoError(t, err)
+		assert.Len(t, users, 3)
+		assert.Equal(t, "John Doe", users[0].Name)
+		assert.Equal(t, "Jane Smith", users[1].Name)
+		assert.Equal(t, "Bob Johnson", users[2].Name)
+	})
+
+	t.Run("Sort by relation count with secondary sort", func(t *testing.T) {
+		var users []TestUser
+		req := &FilterRequest{
+			Sorts: []Sort{{CountOf: "Orders"}, {Field: "Age", Desc: true}},
+		}
+		assert.NoError(t, db.Create(&TestUser{Name: "Zero Orders", Age: 50}).Error)
+		err := builder.FindAll(req, &users)
+		assert.NoError(t, err)
+		assert.Len(t, users, 4)
+		assert.Equal(t, "Zero Orders", users[0].Name)
+		assert.Equal(t, "Bob Johnson", users[1].Name)
+		assert.Equal(t, "John Doe", users[3].Name)
+	})
+
+	t.Run("Invalid count value", func(t *testing.T) {
+		_, err := builder.Count(&FilterRequest{
+			CountFilters: []CountFilter{{Relation: "Orders", Op: GT, Value: "many"}},
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid count value")
+	})
+
+	t.Run("Unsupported operator", func(t *testing.T) {
+		_, err := builder.Count(&FilterRequest{
+			CountFilters: []CountFilter{{Relation: "Orders", Op: LIKE, Value: "1"}},
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported count operator")
+	})
+}
+
+type TestComment struct {
+	ID       uint          `gorm:"primarykey"`
+	UserID   uint          `gorm:"column:user_id"`
+	ParentID *uint         `gorm:"column:parent_id"`
+	Body     string        `gorm:"column:body"`
+	Parent   *TestComment  `gorm:"foreignKey:ParentID"`
+	Replies  []TestComment `gorm:"foreignKey:ParentID"`
+}
+
+func TestQueryBuilder_SelfReferentialRelations(t *testing.T) {
+	db := setupTestDB(t)
+	assert.NoError(t, db.AutoMigrate(&TestComment{}))
+	root, reply := uint(1), uint(2)
+	assert.NoError(t, db.Create([]TestComment{
+		{ID: 1, UserID: 1, Body: "root"},
+		{ID: 2, UserID: 2, ParentID: &root, Body: "reply to john"},
+		{ID: 3, UserID: 1, ParentID: &reply, Body: "reply to jane"},
+		{ID: 4, UserID: 3, Body: "lonely"},
+	}).Error)
+	builder := NewQueryBuilder[TestComment](db)
+
+	t.Run("Parent by user", func(t *testing.T) {
+		var comments []TestComment
+		err := builder.FindAll(&FilterRequest{
+			Exists: []ExistsFilter{{
+				Relation: "Parent",
+				Filter:   FilterRequest{Filters: []Filter{{Field: "UserID", Op: EQ, Value: "1"}}},
+			}},
+		}, &comments)
+		assert.NoError(t, err)
+		if assert.Len(t, comments, 1) {
+			assert.Equal(t, uint(2), comments[0].ID)
+		}
+	})
+
+	t.Run("Grandparent by user", func(t *testing.T) {
+		var comments []TestComment
+		err := builder.FindAll(&FilterRequest{
+			Exists: []ExistsFilter{{
+				Relation: "Parent",
+				Filter: FilterRequest{Exists: []ExistsFilter{{
+					Relation: "Parent",
+					Filter:   FilterRequest{Filters: []Filter{{Field: "UserID", Op: EQ, Value: "1"}}},
+				}}},
+			}},
+		}, &comments)
+		assert.NoError(t, err)
+		if assert.Len(t, comments, 1) {
+			assert.Equal(t, uint(3), comments[0].ID)
+		}
+
+		query := builder.WithDB(db.Session(&gorm.Session{DryRun: true})).Build(&FilterRequest{
+			Exists: []ExistsFilter{{Relation: "Parent"}},
+		}).Find(&[]TestComment{})
+		assert.Contains(t, query.Statement.SQL.String(),
+			"FROM `test_comments` `test_comments_parent` WHERE `test_comments_parent`.`id` = `test_comments`.`parent_id`")
+	})
+
+	t.Run("Children", func(t *testing.T) {
+		count, err := builder.Count(&FilterRequest{
+			Exists: []ExistsFilter{{Relation: "Replies", Not: true}},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+
+		var comments []TestComment
+		err = builder.FindAll(&FilterRequest{
+			CountFilters: []CountFilter{{
+				Relation: "Replies", Op: EQ, Value: "1",
+				Filter: FilterRequest{Filters: []Filter{{Field: "UserID", Op: NE, Value: "1"}}},
+			}},
+		}, &comments)
+		assert.NoError(t, err)
+		if assert.Len(t, comments, 1) {
+			assert.Equal(t, uint(1), comments[0].ID)
+		}
+
+		err = builder.FindAll(&FilterRequest{
+			Sorts:  []Sort{{Field: "ID"}},
+			Exists: []ExistsFilter{{Relation: "Replies", Not: true, LeftJoin: true}},
+		}, &comments)
+		assert.NoError(t, err)
+		if assert.Len(t, comments, 2) {
+			assert.Equal(t, uint(3), comments[0].ID)
+			assert.Equal(t, uint(4), comments[1].ID)
+		}
+	})
+}
+
+type TestTeam struct {
+	ID   uint   `gorm:"primarykey"`
+	Name string `gorm:"column:name"`
+}
+
+type TestMember struct {
+	ID    uint       `gorm:"primarykey"`
+	Name  string     `gorm:"column:name"`
+	Teams []TestTeam `gorm:"many2many:test_member_teams"`
+}
+
+func TestQueryBuilder_ManyToManyRelations(t *testing.T) {
+	db := setupTestDB(t)
+	assert.NoError(t, db.AutoMigrate(&TestMember{}, &TestTeam{}))
+	teams := []TestTeam{{ID: 1, Name: "go"}, {ID: 2, Name: "rust"}, {ID: 3, Name: "zig"}}
+	assert.NoError(t, db.Create(&teams).Error)
+	assert.NoError(t, db.Create([]TestMember{
+		{ID: 1, Name: "alice", Teams: teams[:2]},
+		{ID: 2, Name: "bob", Teams: teams[1:2]},
+		{ID: 3, Name: "carol"},
+	}).Error)
+	builder := NewQueryBuilder[TestMember](db)
+
+	names := func(req *FilterRequest) []string {
+		req.Sorts = []Sort{{Field: "ID"}}
+		var members []TestMember
+		assert.NoError(t, builder.FindAll(req, &members))
+		var result []string
+		for _, m := range members {
+			result = append(result, m.Name)
+		}
+		return result
+	}
+
+	t.Run("Any of", func(t *testing.T) {
+		assert.Equal(t, []string{"alice", "bob"}, names(&FilterRequest{
+			Exists: []ExistsFilter{{
+				Relation: "Teams",
+				Filter:   FilterRequest{Filters: []Filter{{Field: "Name", Op: IN, Value: "go,rust"}}},
+			}},
+		}))
+	})
+
+	t.Run("All of", func(t *testing.T) {
+		assert.Equal(t, []string{"alice"}, names(&FilterRequest{
+			Exists: []ExistsFilter{{
+				Relation: "Teams",
+				All:      true,
+				Filter:   FilterRequest{Filters: []Filter{{Field: "Name", Op: IN, Value: "go,rust"}}},
+			}},
+		}))
+		assert.Equal(t, []string{"bob", "carol"}, names(&FilterRequest{
+			Exists: []ExistsFilter{{
+				Relation: "Teams",
+				All:      true,
+				Not:      true,
+				Filter:   FilterRequest{Filters: []Filter{{Field: "Name", Op: IN, Value: "go,rust"}}},
+			}},
+		}))
+
+		err := builder.Validate(&FilterRequest{
+			Exists: []ExistsFilter{{Relation: "Teams", All: true}},
+		})
+		assert.ErrorContains(t, err, "requires exactly one IN filter")
+	})
+
+	t.Run("Pivot only", func(t *testing.T) {
+		assert.Equal(t, []string{"carol"}, names(&FilterRequest{
+			Exists: []ExistsFilter{{Relation: "Teams", Not: true}},
+		}))
+
+		query := builder.WithDB(db.Session(&gorm.Session{DryRun: true})).Build(&FilterRequest{
+			Exists: []ExistsFilter{{Relation: "Teams"}},
+		}).Find(&[]TestMember{})
+		assert.Contains(t, query.Statement.SQL.String(),
+			"EXISTS (SELECT 1 FROM `test_member_teams` WHERE `test_member_teams`.`test_member_id` = `test_members`.`id`)")
+	})
+
+	t.Run("Count", func(t *testing.T) {
+		assert.Equal(t, []string{"alice"}, names(&FilterRequest{
+			CountFilters: []CountFilter{{Relation: "Teams", Op: GE, Value: "2"}},
+		}))
+	})
+}
@@ -0,0 +1,35 @@
+package querybuild
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// usePrimaryKey 标记读取查询使用主库的上下文键
+type usePrimaryKey struct{}
+
+// UsePrimary 返回标记读取查询使用主库的上下文，用于读取刚写入的数据
+func UsePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, usePrimaryKey{}, true)
+}
+
+// readDB 返回读取查询使用的连接
+//
+// 未配置副本、构建器通过 WithDB 绑定了会话、当前会话处于事务中或上下文要求读取主库时使用主库，
+// 保证事务内的读取能看到事务中的写入。
+func (qb *QueryBuilder[T]) readDB() *gorm.DB {
+	if qb.opts.readDB == nil || qb.bound {
+		return qb.db
+	}
+	if _, ok := qb.db.Statement.ConnPool.(gorm.TxCommitter); ok {
+		return qb.db
+	}
+	ctx := qb.db.Statement.Context
+	if ctx != nil {
+		if primary, _ := ctx.Value(usePrimaryKey{}).(bool); primary {
+			return qb.db
+		}
+	}
+	return qb.opts.readDB.WithContext(ctx)
+}
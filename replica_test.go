@@ -0,0 +1,48 @@
+package querybuild
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestQueryBuilder_WithReadDB(t *testing.T) {
+	primary := setupTestDB(t)
+	replica := setupTestDB(t)
+	// 副本落后于主库，缺少一条记录
+	assert.NoError(t, replica.Where("name = ?", "Bob Johnson").Delete(&TestUser{}).Error)
+
+	builder := NewQueryBuilder[TestUser](primary, WithReadDB(replica))
+
+	count, err := builder.Count(&FilterRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count, "reads go to replica")
+
+	affected, err := builder.UpdateExpr(&FilterRequest{
+		Filters: []Filter{{Field: "Name", Op: EQ, Value: "Bob Johnson"}},
+	}, []FieldExpr{{Field: "Age", Op: INC, Value: 1}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), affected, "writes go to primary")
+
+	count, err = builder.WithContext(UsePrimary(context.Background())).Count(&FilterRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count, "read your writes from primary")
+
+	t.Run("Transaction", func(t *testing.T) {
+		err := primary.Transaction(func(tx *gorm.DB) error {
+			assert.NoError(t, tx.Create(&TestUser{Name: "Alice", Email: "alice@example.com", Age: 28, Status: "active"}).Error)
+
+			count, err := builder.WithDB(tx).Count(&FilterRequest{})
+			assert.NoError(t, err)
+			assert.Equal(t, int64(4), count, "reads inside a transaction see its writes")
+
+			count, err = NewQueryBuilder[TestUser](tx, WithReadDB(replica)).Count(&FilterRequest{})
+			assert.NoError(t, err)
+			assert.Equal(t, int64(4), count)
+			return nil
+		})
+		assert.NoError(t, err)
+	})
+}
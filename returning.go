@@ -0,0 +1,118 @@
+package querybuild
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Returning 将 UpdateAll、UpdateExpr、DeleteAll 与 Upsert 影响的记录写入 dest，dest 需为模型切片的指针 *[]T
+//
+// fields 为返回的模型字段，为空时返回全部字段；Upsert 总是返回完整记录。
+// PostgreSQL 与 SQLite 使用 RETURNING 子句，其他方言在事务中查询匹配记录的主键（MySQL 同时锁定记录）并按主键重新查询：
+// 更新返回更新后的记录，删除返回删除前的记录。
+func Returning(dest interface{}, fields ...string) MutationOption {
+	return func(o *mutationOptions) {
+		o.returning = dest
+		o.returningFields = fields
+	}
+}
+
+// nativeReturning 判断当前方言是否支持 RETURNING 子句
+func (qb *QueryBuilder[T]) nativeReturning() bool {
+	switch qb.dialect() {
+	case dialectPostgres, dialectSQLite:
+		return true
+	}
+	return false
+}
+
+// returningDest 校验并清空返回目标
+func (qb *QueryBuilder[T]) returningDest(mo mutationOptions) (*[]T, error) {
+	dest, ok := mo.returning.(*[]T)
+	if !ok || dest == nil {
+		return nil, fmt.Errorf("returning destination must be *[]%s, got %T", qb.schema.ModelType.Name(), mo.returning)
+	}
+	*dest = nil
+	return dest, nil
+}
+
+// returningColumns 解析返回的字段，为空时返回 nil 表示全部字段
+func (qb *QueryBuilder[T]) returningColumns(fields []string) ([]clause.Column, error) {
+	var columns []clause.Column
+	for _, field := range fields {
+		info, err := qb.validateField(field)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, clause.Column{Name: info.Name})
+	}
+	return columns, nil
+}
+
+// execReturning 执行批量更新或删除，并将受影响的记录写入返回目标，before 为 true 时返回执行前的记录
+func (qb *QueryBuilder[T]) execReturning(build func(*gorm.DB) *gorm.DB, mo mutationOptions, before bool, exec func(query *gorm.DB, model interface{}) *gorm.DB) (int64, error) {
+	dest, err := qb.returningDest(mo)
+	if err != nil {
+		return 0, err
+	}
+	columns, err := qb.returningColumns(mo.returningFields)
+	if err != nil {
+		return 0, err
+	}
+
+	if qb.nativeReturning() {
+		result := exec(build(qb.db).Clauses(clause.Returning{Columns: columns}), dest)
+		return result.RowsAffected, result.Error
+	}
+
+	pk := qb.schema.PrioritizedPrimaryField
+	if pk == nil {
+		return 0, fmt.Errorf("returning requires a primary key on dialect %s", qb.dialect())
+	}
+	var rows int64
+	err = qb.db.Transaction(func(tx *gorm.DB) error {
+		query := build(tx)
+		if qb.dialect() == dialectMySQL {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+		}
+		var ids []interface{}
+		if err := query.Pluck(quoteField(qb.fields[pk.Name]), &ids).Error; err != nil || len(ids) == 0 {
+			return err
+		}
+		byID := clause.IN{Column: clause.Column{Table: clause.CurrentTable, Name: pk.DBName}, Values: ids}
+		reselect := func() error {
+			// 主键已按请求的条件筛选，重新查询时包含软删除的记录
+			query := qb.modelQuery(tx).Unscoped().Where(byID)
+			if len(columns) > 0 {
+				names := make([]string, len(columns))
+				for i, column := range columns {
+					names[i] = column.Name
+				}
+				query = query.Select(names)
+			}
+			return query.Find(dest).Error
+		}
+
+		if before {
+			if err := reselect(); err != nil {
+				return err
+			}
+		}
+		result := exec(build(tx).Where(byID), new(T))
+		if result.Error != nil {
+			return result.Error
+		}
+		rows = result.RowsAffected
+		if !before {
+			return reselect()
+		}
+		return nil
+	})
+	if err != nil {
+		*dest = nil
+		return 0, err
+	}
+	return rows, nil
+}
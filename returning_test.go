@@ -0,0 +1,101 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestQueryBuilder_Returning(t *testing.T) {
+	active := func() *FilterRequest {
+		return &FilterRequest{Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}}}
+	}
+
+	t.Run("Update", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](setupTestDB(t))
+		var users []TestUser
+		rows, err := builder.UpdateAll(active(), map[string]interface{}{"Age": 50}, Returning(&users))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), rows)
+		if assert.Len(t, users, 2) {
+			assert.Equal(t, 50, users[0].Age)
+			assert.NotEmpty(t, users[0].Email)
+		}
+	})
+
+	t.Run("Delete selected fields", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](setupTestDB(t))
+		var users []TestUser
+		rows, err := builder.DeleteAll(active(), Returning(&users, "ID", "Name"))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), rows)
+		if assert.Len(t, users, 2) {
+			assert.NotZero(t, users[0].ID)
+			assert.NotEmpty(t, users[0].Name)
+			assert.Empty(t, users[0].Email)
+		}
+	})
+
+	t.Run("Upsert", func(t *testing.T) {
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+		assert.NoError(t, err)
+		assert.NoError(t, db.AutoMigrate(&testAccount{}))
+		builder := NewQueryBuilder[testAccount](db)
+		req := &FilterRequest{Filters: []Filter{{Field: "Email", Op: EQ, Value: "alice@example.com"}}}
+
+		assert.NoError(t, builder.Upsert(req, &testAccount{Name: "Alice", Score: 1}))
+		var accounts []testAccount
+		assert.NoError(t, builder.Upsert(req, &testAccount{Name: "Alice", Score: 2}, Returning(&accounts)))
+		if assert.Len(t, accounts, 1) {
+			assert.NotZero(t, accounts[0].ID)
+			assert.Equal(t, 2, accounts[0].Score)
+		}
+	})
+
+	t.Run("Invalid destination", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](setupTestDB(t))
+		var orders []TestOrder
+		_, err := builder.DeleteAll(active(), Returning(&orders))
+		assert.ErrorContains(t, err, "returning destination")
+
+		var users []TestUser
+		_, err = builder.DeleteAll(active(), Returning(&users, "Credits"))
+		assert.ErrorContains(t, err, "invalid field name")
+	})
+
+	t.Run("Reselect fallback", func(t *testing.T) {
+		db, err := gorm.Open(namedDialector{Dialector: sqlite.Open(":memory:"), name: "sqlserver"}, &gorm.Config{})
+		assert.NoError(t, err)
+		assert.NoError(t, db.AutoMigrate(&TestUser{}))
+		assert.NoError(t, db.Create(&[]TestUser{
+			{Name: "John Doe", Age: 25, Status: "active"},
+			{Name: "Jane Smith", Age: 30, Status: "inactive"},
+			{Name: "Bob Johnson", Age: 35, Status: "active"},
+		}).Error)
+		builder := NewQueryBuilder[TestUser](db)
+
+		var updated []TestUser
+		rows, err := builder.UpdateExpr(active(), []FieldExpr{{Field: "Age", Op: INC, Value: 1}}, Returning(&updated))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), rows)
+		if assert.Len(t, updated, 2) {
+			assert.Equal(t, 26, updated[0].Age)
+			assert.Equal(t, 36, updated[1].Age)
+		}
+
+		var deleted []TestUser
+		rows, err = builder.DeleteAll(active(), Returning(&deleted, "Name"))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), rows)
+		if assert.Len(t, deleted, 2) {
+			assert.Equal(t, "John Doe", deleted[0].Name)
+			assert.Zero(t, deleted[0].Age)
+		}
+
+		var count int64
+		assert.NoError(t, db.Model(&TestUser{}).Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+	})
+}
@@ -0,0 +1,71 @@
+package querybuild
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Sample 随机抽样参数，Percent 与 Rows 只能设置一个
+type Sample struct {
+	Percent float64 `json:"percent"` // 按百分比抽样，取值范围 (0, 100]
+	Rows    int     `json:"rows"`    // 随机抽取的记录数
+}
+
+// byRows 判断是否按记录数抽样
+func (s *Sample) byRows() bool {
+	return s != nil && s.Rows > 0
+}
+
+// checkSample 校验抽样参数及与请求其他部分的组合
+func checkSample(req *FilterRequest) error {
+	s := req.Sample
+	if s == nil {
+		return nil
+	}
+	switch {
+	case s.Percent != 0 && s.Rows != 0:
+		return fmt.Errorf("sample requires either percent or rows")
+	case s.Rows < 0 || s.Percent < 0 || s.Percent > 100 || (s.Rows == 0 && s.Percent == 0):
+		return fmt.Errorf("invalid sample: percent %v, rows %d", s.Percent, s.Rows)
+	case s.Rows > 0 && (req.Page != nil || len(req.Sorts) > 0 || len(req.Groups) > 0 || len(req.Aggrs) > 0 || req.Distinct):
+		return fmt.Errorf("row sampling does not support pagination, sorts, groups, aggregations or distinct")
+	}
+	return nil
+}
+
+// applySample 应用随机抽样
+//
+// 按百分比抽样时 PostgreSQL 使用 TABLESAMPLE BERNOULLI，其他方言按随机数过滤记录；
+// 按记录数抽样时以随机顺序排序并限制记录数。
+func (qb *QueryBuilder[T]) applySample(query *gorm.DB, req *FilterRequest) *gorm.DB {
+	if req.Sample == nil {
+		return query
+	}
+	if err := checkSample(req); err != nil {
+		query.AddError(err)
+		return query
+	}
+
+	s := req.Sample
+	if s.byRows() {
+		return query.Clauses(clause.OrderBy{Expression: clause.Expr{SQL: qb.randomExpr()}}).Limit(s.Rows)
+	}
+	if qb.dialect() == dialectPostgres {
+		return query.Table(query.Statement.Quote(qb.tableName())+" TABLESAMPLE BERNOULLI (?)", s.Percent)
+	}
+	return query.Where(qb.randomExpr()+" < ?", s.Percent/100)
+}
+
+// randomExpr 返回取值在 [0, 1) 内均匀分布的随机数表达式
+func (qb *QueryBuilder[T]) randomExpr() string {
+	switch qb.dialect() {
+	case dialectMySQL:
+		return "RAND()"
+	case dialectSQLite:
+		// RANDOM() 返回 64 位有符号整数
+		return "(RANDOM() / 18446744073709551616.0 + 0.5)"
+	}
+	return "RANDOM()"
+}
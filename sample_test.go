@@ -0,0 +1,65 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestQueryBuilder_Sample(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db, WithDefaultSort(Sort{Field: "Name"}))
+
+	t.Run("Rows", func(t *testing.T) {
+		var users []TestUser
+		err := builder.FindAll(&FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+			Sample:  &Sample{Rows: 1},
+		}, &users)
+		assert.NoError(t, err)
+		if assert.Len(t, users, 1) {
+			assert.Equal(t, "active", users[0].Status)
+		}
+
+		stmt := builder.WithDB(db.Session(&gorm.Session{DryRun: true})).Build(&FilterRequest{
+			Sample: &Sample{Rows: 2},
+		}).Find(&[]TestUser{}).Statement
+		assert.Contains(t, stmt.SQL.String(), "ORDER BY (RANDOM() / 18446744073709551616.0 + 0.5) LIMIT 2")
+		assert.NotContains(t, stmt.SQL.String(), "`name`")
+	})
+
+	t.Run("Percent", func(t *testing.T) {
+		var users []TestUser
+		err := builder.FindAll(&FilterRequest{Sample: &Sample{Percent: 100}}, &users)
+		assert.NoError(t, err)
+		assert.Len(t, users, 3)
+
+		count, err := builder.Count(&FilterRequest{Sample: &Sample{Percent: 0.0001}})
+		assert.NoError(t, err)
+		assert.Less(t, count, int64(3))
+	})
+
+	t.Run("Postgres TABLESAMPLE", func(t *testing.T) {
+		stmt := NewQueryBuilder[TestUser](setupDialectDB(t, "postgres")).Build(&FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+			Sample:  &Sample{Percent: 10},
+		}).Find(&[]TestUser{}).Statement
+		assert.Contains(t, stmt.SQL.String(), "FROM `test_users` TABLESAMPLE BERNOULLI (?) WHERE")
+		assert.Contains(t, stmt.Vars, 10.0)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, req := range []*FilterRequest{
+			{Sample: &Sample{}},
+			{Sample: &Sample{Percent: 120}},
+			{Sample: &Sample{Percent: 10, Rows: 5}},
+			{Sample: &Sample{Rows: 5}, Page: &Pagination{Page: 1, PageSize: 10}},
+			{Sample: &Sample{Rows: 5}, Sorts: []Sort{{Field: "Age"}}},
+		} {
+			var users []TestUser
+			assert.Error(t, builder.FindAll(req, &users))
+			assert.Error(t, builder.Validate(req))
+		}
+	})
+}
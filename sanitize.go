@@ -0,0 +1,128 @@
+package querybuild
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm/schema"
+)
+
+// timeLayouts 时间字段过滤取值支持的格式
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// filterValues 返回过滤条件需要按字段类型校验的取值，模糊匹配、正则与IP网段仅校验长度
+func filterValues(filter Filter) (values []string, typed bool) {
+	switch filter.Op {
+	case IS_NULL, NOT_NULL:
+		return nil, false
+	case IN, NOT_IN, BETWEEN, NOT_BETWEEN:
+		return strings.Split(filter.Value, ","), true
+	case LIKE, NOT_LIKE, STARTS_WITH, ENDS_WITH, CONTAINS, REGEXP, NOT_REGEXP, INET_IN_CIDR, CIDR_CONTAINS:
+		return []string{filter.Value}, false
+	}
+	return []string{filter.Value}, true
+}
+
+// checkFilterValue 校验过滤取值是否符合字段的类型、长度与枚举取值
+//
+// 仅在开启 WithValueValidation 时校验，enum('a','b') 形式的列类型总是校验；连接表字段、以函数包装的字段与注册了取值编码器的字段不校验。
+func (qb *QueryBuilder[T]) checkFilterValue(filter Filter) error {
+	if filter.Fn != "" {
+		return nil
+	}
+	field := qb.schema.LookUpField(filter.Field)
+	if field == nil || field.DBName == "" {
+		return nil
+	}
+	// 请求取值不是列的存储格式，由编码器在构建条件时校验
+	if _, ok := qb.encoders.Get(field.Name); ok {
+		return nil
+	}
+	// 枚举类型的列总是校验取值，避免数据库因无效的枚举取值报错
+	if !qb.opts.validateValues && (FieldInfo{DataType: field.DataType}).enumValues() == nil {
+		return nil
+	}
+
+	values, typed := filterValues(filter)
+	enum := qb.enumValues(field)
+	labels := qb.enumLabels(field)
+	uuid := qb.uuidStorage(filter) != 0
+	custom := qb.customValueField(filter) != nil
+	for _, value := range values {
+		value = enumCode(labels, value)
+		if uuid {
+			if _, ok := parseUUID(value); !ok {
+				return &InvalidValueError{Field: filter.Field, Value: value, Reason: "not a UUID"}
+			}
+			continue
+		}
+		if custom {
+			// 自定义类型字段的取值按字段类型解析
+			if _, err := columnValue(qb.db.Statement.Context, field, value); err != nil {
+				return &InvalidValueError{Field: filter.Field, Value: value, Reason: err.Error()}
+			}
+			continue
+		}
+		if field.DataType == schema.String && field.Size > 0 && utf8.RuneCountInString(value) > field.Size {
+			return &InvalidValueError{Field: filter.Field, Value: value, Reason: "exceeds column size " + strconv.Itoa(field.Size)}
+		}
+		if !typed {
+			continue
+		}
+		if reason := checkValueType(field.DataType, value); reason != "" {
+			return &InvalidValueError{Field: filter.Field, Value: value, Reason: reason}
+		}
+		if enum != nil && !enum[value] {
+			return &InvalidValueError{Field: filter.Field, Value: value, Reason: "not an allowed value", Allowed: qb.enumList(field)}
+		}
+	}
+	return nil
+}
+
+// checkValueType 校验取值能否转换为字段类型，返回不符合的原因
+func checkValueType(dataType schema.DataType, value string) string {
+	var err error
+	switch dataType {
+	case schema.Int:
+		_, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "not an integer"
+		}
+	case schema.Uint:
+		_, err = strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return "not an unsigned integer"
+		}
+	case schema.Float:
+		_, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "not a number"
+		}
+	case schema.Bool:
+		if _, ok := parseBool(value); !ok {
+			return "not a boolean"
+		}
+	case schema.Time:
+		if _, ok := parseTime(value); !ok {
+			return "not a time"
+		}
+	}
+	return ""
+}
+
+// parseTime 按支持的格式解析时间取值
+func parseTime(value string) (time.Time, bool) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,72 @@
+package querybuild
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sanitizeUser 带字段长度与枚举标签的测试模型
+type sanitizeUser struct {
+	ID        uint      `gorm:"primarykey"`
+	Name      string    `gorm:"size:5"`
+	Age       int       `gorm:"column:age"`
+	Score     float64   `gorm:"column:score"`
+	Active    bool      `gorm:"column:active"`
+	Status    string    `gorm:"column:status" enum:"active,inactive"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+func TestQueryBuilder_ValueValidation(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[sanitizeUser](db, WithValueValidation())
+
+	tests := []struct {
+		name   string
+		filter Filter
+		reason string
+	}{
+		{name: "Valid integer", filter: Filter{Field: "Age", Op: GT, Value: "18"}},
+		{name: "Invalid integer", filter: Filter{Field: "Age", Op: GT, Value: "abc"}, reason: "not an integer"},
+		{name: "Invalid IN entry", filter: Filter{Field: "Age", Op: IN, Value: "1,2,x"}, reason: "not an integer"},
+		{name: "Invalid float", filter: Filter{Field: "Score", Op: EQ, Value: "1.5.1"}, reason: "not a number"},
+		{name: "Invalid bool", filter: Filter{Field: "Active", Op: EQ, Value: "maybe"}, reason: "not a boolean"},
+		{name: "Valid date", filter: Filter{Field: "CreatedAt", Op: BETWEEN, Value: "2024-01-01,2024-01-31 23:59:59"}},
+		{name: "Invalid time", filter: Filter{Field: "CreatedAt", Op: GE, Value: "yesterday"}, reason: "not a time"},
+		{name: "Too long", filter: Filter{Field: "Name", Op: EQ, Value: "abcdef"}, reason: "exceeds column size 5"},
+		{name: "Like on integer", filter: Filter{Field: "Age", Op: LIKE, Value: "1"}},
+		{name: "Enum", filter: Filter{Field: "Status", Op: IN, Value: "active,inactive"}},
+		{name: "Unknown enum", filter: Filter{Field: "Status", Op: EQ, Value: "deleted"}, reason: "not an allowed value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := builder.Validate(&FilterRequest{Filters: []Filter{tt.filter}})
+			if tt.reason == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var valueErr *InvalidValueError
+			assert.ErrorAs(t, err, &valueErr)
+			assert.Equal(t, tt.filter.Field, valueErr.Field)
+			assert.Equal(t, tt.reason, valueErr.Reason)
+		})
+	}
+
+	t.Run("Build", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db, WithValueValidation(), WithEnum("Status", "active", "inactive"))
+
+		var users []TestUser
+		assert.NoError(t, builder.Build(&FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+		}).Find(&users).Error)
+		assert.Len(t, users, 2)
+
+		err := builder.Build(&FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "deleted"}},
+		}).Find(&users).Error
+		var valueErr *InvalidValueError
+		assert.ErrorAs(t, err, &valueErr)
+	})
+}
@@ -0,0 +1,169 @@
+package querybuild
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrSavedSearchNotFound 保存的查询不存在
+var ErrSavedSearchNotFound = errors.New("saved search not found")
+
+// SavedSearch 用户保存的查询
+type SavedSearch struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Owner     string    `gorm:"size:191;uniqueIndex:idx_saved_search" json:"owner"` // 所属用户
+	Model     string    `gorm:"size:191;uniqueIndex:idx_saved_search" json:"model"` // 查询的模型表名
+	Name      string    `gorm:"size:191;uniqueIndex:idx_saved_search" json:"name"`  // 查询名称
+	Request   string    `gorm:"type:text" json:"request"`                           // JSON 格式的过滤请求
+	Schema    string    `gorm:"size:64" json:"schema"`                              // 保存时模型结构的指纹
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 保存的查询使用的表名
+func (SavedSearch) TableName() string {
+	return "saved_searches"
+}
+
+// SchemaDriftError 模型结构变化后保存的查询不再有效
+type SchemaDriftError struct {
+	Name string // 查询名称
+	Err  error  // 按当前模型结构校验的错误
+}
+
+func (e *SchemaDriftError) Error() string {
+	return fmt.Sprintf("saved search %s is invalid for the current model: %v", e.Name, e.Err)
+}
+
+// Unwrap 返回校验错误
+func (e *SchemaDriftError) Unwrap() error {
+	return e.Err
+}
+
+// SavedSearches 按用户保存、列出、加载与执行过滤请求
+type SavedSearches[T any] struct {
+	db      *gorm.DB
+	builder *QueryBuilder[T]
+}
+
+// NewSavedSearches 创建保存查询的存储，查询保存在 db 的 saved_searches 表中，按 builder 的配置校验与执行
+func NewSavedSearches[T any](db *gorm.DB, builder *QueryBuilder[T]) *SavedSearches[T] {
+	return &SavedSearches[T]{db: db, builder: builder}
+}
+
+// WithContext 返回绑定上下文的存储副本
+func (s *SavedSearches[T]) WithContext(ctx context.Context) *SavedSearches[T] {
+	return &SavedSearches[T]{db: s.db.WithContext(ctx), builder: s.builder.WithContext(ctx)}
+}
+
+// AutoMigrate 创建或更新保存查询的表结构
+func (s *SavedSearches[T]) AutoMigrate() error {
+	return s.db.AutoMigrate(&SavedSearch{})
+}
+
+// Save 校验请求后以指定名称保存，同名查询已存在时覆盖
+func (s *SavedSearches[T]) Save(owner, name string, req *FilterRequest) error {
+	if name == "" {
+		return fmt.Errorf("saved search name is required")
+	}
+	if err := s.builder.Validate(req); err != nil {
+		return err
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	search := SavedSearch{
+		Owner:   owner,
+		Model:   s.builder.tableName(),
+		Name:    name,
+		Request: string(data),
+		Schema:  s.builder.schemaFingerprint(),
+	}
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "owner"}, {Name: "model"}, {Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"request", "schema", "updated_at"}),
+	}).Create(&search).Error
+}
+
+// List 列出用户在当前模型上保存的查询，按名称排序
+func (s *SavedSearches[T]) List(owner string) ([]SavedSearch, error) {
+	var searches []SavedSearch
+	err := s.db.Where("owner = ? AND model = ?", owner, s.builder.tableName()).
+		Order("name").
+		Find(&searches).Error
+	return searches, err
+}
+
+// Load 加载保存的查询
+//
+// 模型结构在保存后发生变化时按当前结构重新校验，不再有效时返回 *SchemaDriftError，
+// 仍然有效时更新保存的结构指纹。
+func (s *SavedSearches[T]) Load(owner, name string) (*FilterRequest, error) {
+	var search SavedSearch
+	err := s.db.Where("owner = ? AND model = ? AND name = ?", owner, s.builder.tableName(), name).
+		Take(&search).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSavedSearchNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var req FilterRequest
+	if err := json.Unmarshal([]byte(search.Request), &req); err != nil {
+		return nil, &SchemaDriftError{Name: name, Err: err}
+	}
+
+	if fingerprint := s.builder.schemaFingerprint(); search.Schema != fingerprint {
+		if err := s.builder.Validate(&req); err != nil {
+			return nil, &SchemaDriftError{Name: name, Err: err}
+		}
+		if err := s.db.Model(&search).Update("schema", fingerprint).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &req, nil
+}
+
+// Delete 删除保存的查询
+func (s *SavedSearches[T]) Delete(owner, name string) error {
+	result := s.db.Where("owner = ? AND model = ? AND name = ?", owner, s.builder.tableName(), name).
+		Delete(&SavedSearch{})
+	if result.Error == nil && result.RowsAffected == 0 {
+		return ErrSavedSearchNotFound
+	}
+	return result.Error
+}
+
+// Execute 加载并分页执行保存的查询，page 不为空时替换保存的分页参数
+func (s *SavedSearches[T]) Execute(owner, name string, page *Pagination) (*PageResult[T], error) {
+	req, err := s.Load(owner, name)
+	if err != nil {
+		return nil, err
+	}
+	if page != nil {
+		req.Page = page
+	}
+	return s.builder.FindPage(req)
+}
+
+// schemaFingerprint 计算模型可查询字段的名称、字段名与类型的指纹
+func (qb *QueryBuilder[T]) schemaFingerprint() string {
+	h := sha256.New()
+	for _, field := range qb.schema.Fields {
+		if field.DBName != "" {
+			fmt.Fprintf(h, "%s:%s:%s;", field.Name, field.DBName, field.DataType)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,57 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSavedSearches(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+	searches := NewSavedSearches(db, builder)
+	assert.NoError(t, searches.AutoMigrate())
+
+	req := &FilterRequest{
+		Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+		Sorts:   []Sort{{Field: "Age", Desc: true}},
+	}
+	assert.NoError(t, searches.Save("alice", "active", req))
+	assert.NoError(t, searches.Save("alice", "active", req), "saving again overwrites")
+	assert.NoError(t, searches.Save("bob", "everyone", &FilterRequest{}))
+	assert.Error(t, searches.Save("alice", "invalid", &FilterRequest{Sorts: []Sort{{Field: "Unknown"}}}))
+
+	list, err := searches.List("alice")
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, "active", list[0].Name)
+
+	result, err := searches.Execute("alice", "active", &Pagination{Page: 1, PageSize: 1})
+	assert.NoError(t, err)
+	assert.Len(t, result.Items, 1)
+	assert.Equal(t, "Bob Johnson", result.Items[0].Name)
+	assert.Equal(t, int64(2), result.Pagination.Total)
+
+	_, err = searches.Load("bob", "active")
+	assert.ErrorIs(t, err, ErrSavedSearchNotFound)
+
+	t.Run("Schema drift", func(t *testing.T) {
+		type slimUser struct {
+			ID   uint
+			Name string
+		}
+		slim := NewSavedSearches(db, NewQueryBuilder[slimUser](db, WithTable("test_users")))
+
+		_, err := slim.Load("alice", "active")
+		var drift *SchemaDriftError
+		assert.ErrorAs(t, err, &drift)
+		assert.Equal(t, "active", drift.Name)
+
+		loaded, err := slim.Load("bob", "everyone")
+		assert.NoError(t, err, "requests still valid after drift load")
+		assert.Empty(t, loaded.Filters)
+	})
+
+	assert.NoError(t, searches.Delete("alice", "active"))
+	assert.ErrorIs(t, searches.Delete("alice", "active"), ErrSavedSearchNotFound)
+}
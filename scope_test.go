@@ -0,0 +1,213 @@
+package querybuild
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestQueryBuilder_ParamScopes(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+	builder.RegisterParamScope(FilterScope, "min_age", func(db *gorm.DB, values ...interface{}) *gorm.DB {
+		return db.Where("age >= ?", values...)
+	})
+	builder.RegisterParamScope(GroupScope, "age_bucket", func(db *gorm.DB, values ...interface{}) *gorm.DB {
+		size, ok := values[0].(int)
+		if !ok || size <= 0 {
+			db.AddError(fmt.Errorf("invalid bucket size: %v", values[0]))
+			return db
+		}
+		return db.Group(fmt.Sprintf("age / %d", size))
+	})
+	builder.RegisterParamScope(SelectScope, "bucket_count", func(db *gorm.DB, values ...interface{}) *gorm.DB {
+		return db.Select("age / ? AS bucket, COUNT(*) AS count", values...)
+	})
+	builder.RegisterParamScope(JoinScope, "orders_over", func(db *gorm.DB, values ...interface{}) *gorm.DB {
+		return db.Joins("INNER JOIN test_orders AS o ON o.user_id = test_users.id AND o.amount > ?", values...)
+	})
+	builder.RegisterScope(FilterScope, "active", func(db *gorm.DB) *gorm.DB {
+		return db.Where("status = ?", "active")
+	})
+
+	names := func(t *testing.T, req *FilterRequest) []string {
+		req.Sorts = []Sort{{Field: "ID"}}
+		var users []TestUser
+		assert.NoError(t, builder.FindAll(req, &users))
+		var result []string
+		for _, user := range users {
+			result = append(result, user.Name)
+		}
+		return result
+	}
+
+	t.Run("Filter", func(t *testing.T) {
+		assert.Equal(t, []string{"Jane Smith", "Bob Johnson"}, names(t, &FilterRequest{
+			CustomFilter: &CustomFilter{ScopeName: "min_age", Values: []interface{}{30}},
+		}))
+	})
+
+	t.Run("Join", func(t *testing.T) {
+		assert.Equal(t, []string{"John Doe"}, names(t, &FilterRequest{
+			Joins: []Join{{ScopeName: "orders_over", Values: []interface{}{90}}},
+		}))
+	})
+
+	t.Run("Group and select", func(t *testing.T) {
+		type Bucket struct {
+			Bucket int
+			Count  int
+		}
+		var buckets []Bucket
+		err := builder.Build(&FilterRequest{
+			CustomFields: []CustomField{{Name: "bucket", ScopeName: "bucket_count", Values: []interface{}{10}}},
+			Groups:       []Group{{ScopeName: "age_bucket", Values: []interface{}{10}}},
+		}).Scan(&buckets).Error
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []Bucket{{Bucket: 2, Count: 1}, {Bucket: 3, Count: 2}}, buckets)
+	})
+
+	t.Run("Scope without parameters ignores values", func(t *testing.T) {
+		assert.Equal(t, []string{"John Doe", "Bob Johnson"}, names(t, &FilterRequest{
+			CustomFilter: &CustomFilter{ScopeName: "active", Values: []interface{}{"ignored"}},
+		}))
+	})
+
+	t.Run("Multiple custom filters", func(t *testing.T) {
+		assert.Equal(t, []string{"Bob Johnson"}, names(t, &FilterRequest{
+			CustomFilters: []CustomFilter{
+				{ScopeName: "active"},
+				{ScopeName: "min_age", Values: []interface{}{30}},
+			},
+		}))
+		assert.Equal(t, []string{"Bob Johnson"}, names(t, &FilterRequest{
+			CustomFilter:  &CustomFilter{ScopeName: "active"},
+			CustomFilters: []CustomFilter{{ScopeName: "min_age", Values: []interface{}{30}}},
+		}))
+
+		req := &FilterRequest{CustomFilters: []CustomFilter{{ScopeName: "active"}, {ScopeName: "missing"}}}
+		assert.Error(t, builder.Validate(req))
+		lenient, warnings := builder.lenientRequest(req)
+		assert.Len(t, warnings, 1)
+		assert.Equal(t, []CustomFilter{{ScopeName: "active"}}, lenient.CustomFilters)
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		assert.NoError(t, builder.Validate(&FilterRequest{
+			Groups: []Group{{ScopeName: "age_bucket", Values: []interface{}{10}}},
+			Joins:  []Join{{ScopeName: "orders_over", Values: []interface{}{90}}},
+		}))
+	})
+}
+
+func TestQueryBuilder_ScopeAllowed(t *testing.T) {
+	type adminKey struct{}
+	isAdmin := func(ctx context.Context) bool {
+		admin, _ := ctx.Value(adminKey{}).(bool)
+		return admin
+	}
+
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+	builder.RegisterScope(FilterScope, "inactive", func(db *gorm.DB) *gorm.DB {
+		return db.Where("status = ?", "inactive")
+	}, ScopeAllowed(isAdmin))
+	builder.RegisterParamScope(FilterScope, "min_age", func(db *gorm.DB, values ...interface{}) *gorm.DB {
+		return db.Where("age >= ?", values...)
+	}, ScopeAllowed(isAdmin), ScopeIgnoreDenied())
+
+	admin := builder.WithContext(context.WithValue(context.Background(), adminKey{}, true))
+	find := func(qb *QueryBuilder[TestUser], scope string, values ...interface{}) ([]TestUser, error) {
+		var users []TestUser
+		err := qb.FindAll(&FilterRequest{CustomFilter: &CustomFilter{ScopeName: scope, Values: values}}, &users)
+		return users, err
+	}
+
+	t.Run("Rejected", func(t *testing.T) {
+		_, err := find(builder, "inactive")
+		var notAllowed *ScopeNotAllowedError
+		assert.ErrorAs(t, err, &notAllowed)
+		assert.Equal(t, "inactive", notAllowed.Name)
+
+		users, err := find(admin, "inactive")
+		assert.NoError(t, err)
+		assert.Len(t, users, 1)
+	})
+
+	t.Run("Ignored", func(t *testing.T) {
+		users, err := find(builder, "min_age", 30)
+		assert.NoError(t, err)
+		assert.Len(t, users, 3)
+
+		users, err = find(admin, "min_age", 30)
+		assert.NoError(t, err)
+		assert.Len(t, users, 2)
+	})
+}
+
+func TestQueryBuilder_ScopeAllowedCache(t *testing.T) {
+	type adminKey struct{}
+	isAdmin := func(ctx context.Context) bool {
+		admin, _ := ctx.Value(adminKey{}).(bool)
+		return admin
+	}
+
+	builder := NewQueryBuilder[TestUser](setupTestDB(t),
+		WithCache(NewMemoryCache(), time.Minute),
+		WithCountCache(NewMemoryCache(), time.Minute),
+	)
+	builder.RegisterScope(FilterScope, "all", func(db *gorm.DB) *gorm.DB {
+		return db
+	}, ScopeAllowed(isAdmin))
+	admin := builder.WithContext(context.WithValue(context.Background(), adminKey{}, true))
+	req := func() *FilterRequest {
+		return &FilterRequest{CustomFilter: &CustomFilter{ScopeName: "all"}, Page: &Pagination{Page: 1, PageSize: 10}}
+	}
+
+	result, err := admin.FindPage(req())
+	assert.NoError(t, err)
+	assert.Len(t, result.Items, 3)
+
+	// 特权调用方的缓存结果不会返回给未获授权的调用方
+	_, err = builder.FindPage(req())
+	var notAllowed *ScopeNotAllowedError
+	assert.ErrorAs(t, err, &notAllowed)
+
+	_, err = builder.Count(&FilterRequest{CustomFilter: &CustomFilter{ScopeName: "all"}})
+	assert.ErrorAs(t, err, &notAllowed)
+}
+
+func TestQueryBuilder_ScopeRegistration(t *testing.T) {
+	builder := NewQueryBuilder[TestUser](setupTestDB(t))
+	active := func(db *gorm.DB) *gorm.DB { return db.Where("status = ?", "active") }
+	inactive := func(db *gorm.DB) *gorm.DB { return db.Where("status = ?", "inactive") }
+	count := func(qb *QueryBuilder[TestUser]) int64 {
+		n, err := qb.Count(&FilterRequest{CustomFilter: &CustomFilter{ScopeName: "status"}})
+		assert.NoError(t, err)
+		return n
+	}
+
+	assert.NoError(t, builder.RegisterScope(FilterScope, "status", active))
+	assert.ErrorIs(t, builder.RegisterScope(FilterScope, "status", inactive), ErrScopeExists)
+	assert.Equal(t, int64(2), count(builder))
+
+	assert.NoError(t, builder.ReplaceScope(FilterScope, "status", inactive))
+	assert.Equal(t, int64(1), count(builder))
+	assert.ErrorIs(t, builder.ReplaceScope(FilterScope, "missing", inactive), ErrScopeNotFound)
+
+	assert.NoError(t, builder.UnregisterScope(FilterScope, "status"))
+	assert.ErrorIs(t, builder.UnregisterScope(FilterScope, "status"), ErrScopeNotFound)
+	assert.Error(t, builder.Validate(&FilterRequest{CustomFilter: &CustomFilter{ScopeName: "status"}}))
+
+	assert.NoError(t, builder.RegisterScope(FilterScope, "status", active))
+	builder.Freeze()
+	assert.ErrorIs(t, builder.RegisterScope(FilterScope, "other", active), ErrRegistryFrozen)
+	assert.ErrorIs(t, builder.ReplaceScope(FilterScope, "status", inactive), ErrRegistryFrozen)
+	assert.ErrorIs(t, builder.UnregisterScope(FilterScope, "status"), ErrRegistryFrozen)
+	assert.ErrorIs(t, builder.Clone().RegisterScope(FilterScope, "other", active), ErrRegistryFrozen)
+	assert.Equal(t, int64(2), count(builder))
+}
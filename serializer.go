@@ -0,0 +1,160 @@
+package querybuild
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	valuerType          = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	scannerType         = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// customValueField 返回过滤条件中需按字段的序列化器或自定义类型转换取值的模型字段，不需要转换时返回 nil
+//
+// 包括使用 serializer 标签或实现序列化器接口的字段，以及实现 driver.Valuer、sql.Scanner 或
+// encoding.TextUnmarshaler 的自定义类型字段（如以整数存储、以名称过滤的枚举）；时间与布尔字段按各自的规则处理。
+func (qb *QueryBuilder[T]) customValueField(filter Filter) *schema.Field {
+	switch filter.Op {
+	case EQ, NE, GT, GE, LT, LE, IN, NOT_IN, BETWEEN, NOT_BETWEEN, GT_OR_NULL, GE_OR_NULL, LT_OR_NULL, LE_OR_NULL:
+	default:
+		return nil
+	}
+	if filter.NoCase || filter.Fn != "" {
+		return nil
+	}
+	field := qb.schema.LookUpField(filter.Field)
+	if field == nil || field.DBName == "" || field.DataType == schema.Time || field.DataType == schema.Bool {
+		return nil
+	}
+	if field.Serializer != nil {
+		return field
+	}
+	typ := field.IndirectFieldType
+	ptr := reflect.PointerTo(typ)
+	if typ.Implements(valuerType) || ptr.Implements(valuerType) || ptr.Implements(scannerType) || ptr.Implements(textUnmarshalerType) {
+		return field
+	}
+	return nil
+}
+
+// customCondition 构建自定义类型字段的过滤条件，取值按字段类型解析后转换为数据库取值绑定
+func (qb *QueryBuilder[T]) customCondition(query *gorm.DB, column string, field *schema.Field, filter Filter) (string, []interface{}, error) {
+	convert := func(value string) (interface{}, error) {
+		v, err := columnValue(query.Statement.Context, field, value)
+		if err != nil {
+			return nil, &InvalidValueError{Field: filter.Field, Value: value, Reason: err.Error()}
+		}
+		return v, nil
+	}
+
+	switch filter.Op {
+	case IN, NOT_IN:
+		items := strings.Split(filter.Value, ",")
+		values := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			v, err := convert(item)
+			if err != nil {
+				return "", nil, err
+			}
+			values = append(values, v)
+		}
+		return conditionSQL(column, filter), []interface{}{values}, nil
+	case BETWEEN, NOT_BETWEEN:
+		lower, upper, ok := strings.Cut(filter.Value, ",")
+		if !ok || strings.Contains(upper, ",") {
+			return "", nil, &InvalidValueError{Field: filter.Field, Value: filter.Value, Reason: "not a range"}
+		}
+		from, err := convert(lower)
+		if err != nil {
+			return "", nil, err
+		}
+		to, err := convert(upper)
+		if err != nil {
+			return "", nil, err
+		}
+		return conditionSQL(column, filter), []interface{}{from, to}, nil
+	}
+
+	v, err := convert(filter.Value)
+	if err != nil {
+		return "", nil, err
+	}
+	return conditionSQL(column, filter), []interface{}{v}, nil
+}
+
+// columnValue 将文本取值解析为字段的 Go 类型，再按字段的序列化器或 driver.Valuer 转换为写入数据库的取值
+func columnValue(ctx context.Context, field *schema.Field, value string) (interface{}, error) {
+	ptr := reflect.New(field.IndirectFieldType)
+	if err := parseInto(ptr, value); err != nil {
+		return nil, err
+	}
+
+	v := ptr.Elem().Interface()
+	if field.Serializer != nil {
+		serializer, ok := v.(schema.SerializerValuerInterface)
+		if !ok {
+			serializer = field.Serializer
+		}
+		return serializer.Value(ctx, field, reflect.Value{}, v)
+	}
+	if valuer, ok := ptr.Interface().(driver.Valuer); ok {
+		return valuer.Value()
+	}
+	return v, nil
+}
+
+// parseInto 将文本取值解析到 ptr 指向的值：依次尝试 encoding.TextUnmarshaler、sql.Scanner、基本类型的解析，
+// 其余类型（如序列化为 JSON 的结构体与切片）按 JSON 解析
+func parseInto(ptr reflect.Value, value string) error {
+	switch target := ptr.Interface().(type) {
+	case encoding.TextUnmarshaler:
+		return target.UnmarshalText([]byte(value))
+	case sql.Scanner:
+		return target.Scan(value)
+	}
+
+	elem := ptr.Elem()
+	switch elem.Kind() {
+	case reflect.String:
+		elem.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, elem.Type().Bits())
+		if err != nil {
+			return err
+		}
+		elem.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(strings.TrimSpace(value), 10, elem.Type().Bits())
+		if err != nil {
+			return err
+		}
+		elem.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), elem.Type().Bits())
+		if err != nil {
+			return err
+		}
+		elem.SetFloat(f)
+	case reflect.Bool:
+		b, ok := parseBool(value)
+		if !ok {
+			return errors.New("not a boolean")
+		}
+		elem.SetBool(b)
+	default:
+		return json.Unmarshal([]byte(value), ptr.Interface())
+	}
+	return nil
+}
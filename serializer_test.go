@@ -0,0 +1,92 @@
+package querybuild
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPriority int
+
+var testPriorityNames = map[string]testPriority{"low": 1, "high": 2}
+
+func (p *testPriority) UnmarshalText(text []byte) error {
+	if v, ok := testPriorityNames[string(text)]; ok {
+		*p = v
+		return nil
+	}
+	n, err := strconv.Atoi(string(text))
+	if err != nil {
+		return fmt.Errorf("unknown priority %q", text)
+	}
+	*p = testPriority(n)
+	return nil
+}
+
+func (p testPriority) Value() (driver.Value, error) {
+	return int64(p), nil
+}
+
+func (p *testPriority) Scan(src interface{}) error {
+	n, ok := src.(int64)
+	if !ok {
+		return errors.New("invalid priority")
+	}
+	*p = testPriority(n)
+	return nil
+}
+
+type TestTicket struct {
+	ID       uint         `gorm:"primarykey"`
+	Priority testPriority `gorm:"column:priority;type:integer"`
+	Labels   []string     `gorm:"column:labels;serializer:json"`
+	OpenedAt int64        `gorm:"column:opened_at;serializer:unixtime;type:datetime"`
+}
+
+func TestQueryBuilder_CustomTypeValues(t *testing.T) {
+	db := setupTestDB(t)
+	assert.NoError(t, db.AutoMigrate(&TestTicket{}))
+	assert.NoError(t, db.Create([]TestTicket{
+		{ID: 1, Priority: 1, Labels: []string{"bug"}, OpenedAt: 1700000000},
+		{ID: 2, Priority: 2, Labels: []string{"bug", "ui"}, OpenedAt: 1700003600},
+	}).Error)
+	builder := NewQueryBuilder[TestTicket](db, WithValueValidation())
+
+	find := func(filters ...Filter) []uint {
+		var tickets []TestTicket
+		assert.NoError(t, builder.FindAll(&FilterRequest{Filters: filters, Sorts: []Sort{{Field: "ID"}}}, &tickets))
+		var ids []uint
+		for _, ticket := range tickets {
+			ids = append(ids, ticket.ID)
+		}
+		return ids
+	}
+
+	t.Run("Valuer enum", func(t *testing.T) {
+		assert.Equal(t, []uint{2}, find(Filter{Field: "Priority", Op: EQ, Value: "high"}))
+		assert.Equal(t, []uint{1, 2}, find(Filter{Field: "Priority", Op: IN, Value: "low,high"}))
+		assert.Equal(t, []uint{1}, find(Filter{Field: "Priority", Op: LT, Value: "2"}))
+	})
+
+	t.Run("JSON serializer", func(t *testing.T) {
+		assert.Equal(t, []uint{2}, find(Filter{Field: "Labels", Op: EQ, Value: `["bug", "ui"]`}))
+		assert.Equal(t, []uint{1}, find(Filter{Field: "Labels", Op: NE, Value: `["bug","ui"]`}))
+	})
+
+	t.Run("Unix time serializer", func(t *testing.T) {
+		assert.Equal(t, []uint{2}, find(Filter{Field: "OpenedAt", Op: EQ, Value: "1700003600"}))
+	})
+
+	t.Run("Invalid value", func(t *testing.T) {
+		var tickets []TestTicket
+		err := builder.FindAll(&FilterRequest{Filters: []Filter{{Field: "Priority", Op: EQ, Value: "urgent"}}}, &tickets)
+		var invalid *InvalidValueError
+		if assert.ErrorAs(t, err, &invalid) {
+			assert.Equal(t, "Priority", invalid.Field)
+		}
+	})
+}
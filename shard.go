@@ -0,0 +1,312 @@
+package querybuild
+
+import (
+	"cmp"
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// FanOut 在多个数据库分片上并发执行相同的请求，并在内存中合并各分片的结果
+type FanOut[T any] struct {
+	qb     *QueryBuilder[T]
+	shards []*gorm.DB
+}
+
+// fanOutSort 合并结果使用的排序字段
+type fanOutSort struct {
+	field      *schema.Field
+	desc       bool
+	nullsLarge bool // 与分片方言一致将 NULL 视为大于任何非空取值
+}
+
+// FanOut 返回在指定分片上执行查询的执行器，各分片使用当前构建器的配置、注册表与钩子
+//
+// 分片查询不使用只读副本与结果缓存。合并时按请求排序加主键排序重新排序，
+// 因此排序仅支持模型字段的普通升降序排序，且请求不能包含分组、聚合与去重。
+func (qb *QueryBuilder[T]) FanOut(shards ...*gorm.DB) *FanOut[T] {
+	return &FanOut[T]{qb: qb, shards: shards}
+}
+
+// Count 并发统计各分片的记录数并求和
+func (f *FanOut[T]) Count(req *FilterRequest) (int64, error) {
+	countReq := *req
+	countReq.Page = nil
+	counts := make([]int64, len(f.shards))
+	err := f.each(func(i int, qb *QueryBuilder[T]) error {
+		var err error
+		counts[i], err = qb.Count(&countReq)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, count := range counts {
+		total += count
+	}
+	return total, nil
+}
+
+// FindAll 查询各分片的记录并合并排序，请求包含分页时按页码与每页数量截取合并后的结果
+func (f *FanOut[T]) FindAll(req *FilterRequest) ([]T, error) {
+	req, err := f.qb.beforeFind(req)
+	if err != nil {
+		return nil, err
+	}
+	offset, limit := 0, -1
+	if req.Page != nil {
+		page := *req.Page
+		f.qb.normalizePage(&page)
+		offset, limit = (page.Page-1)*page.PageSize, page.PageSize
+	}
+	items, err := f.find(req, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return f.qb.afterFind(items)
+}
+
+// FindPage 分页查询各分片的记录，总记录数为各分片记录数之和
+//
+// 每个分片查询合并后当前页所需的前 页码×每页数量 条记录，页码较大时各分片的查询量随之增加。
+func (f *FanOut[T]) FindPage(req *FilterRequest) (*PageResult[T], error) {
+	req, err := f.qb.beforeFind(req)
+	if err != nil {
+		return nil, err
+	}
+	page := &Pagination{}
+	if req.Page != nil {
+		page = req.Page
+	}
+	f.qb.normalizePage(page)
+
+	total, err := f.Count(req)
+	if err != nil {
+		return nil, err
+	}
+	items, err := f.find(req, (page.Page-1)*page.PageSize, page.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	page.Total = total
+	page.computeMeta()
+
+	if items, err = f.qb.afterFind(items); err != nil {
+		return nil, err
+	}
+	return &PageResult[T]{Items: items, Pagination: page}, nil
+}
+
+// find 查询各分片排序后的前 offset+limit 条记录，合并排序后截取，limit 小于 0 时不限制
+func (f *FanOut[T]) find(req *FilterRequest, offset, limit int) ([]T, error) {
+	if len(req.Groups) > 0 || len(req.Aggrs) > 0 || req.Distinct || req.Sample.byRows() {
+		return nil, fmt.Errorf("fan-out queries do not support groups, aggregations, distinct or row sampling")
+	}
+	sorts, err := f.sorts(req)
+	if err != nil {
+		return nil, err
+	}
+
+	shardReq := *req
+	shardReq.Page = nil
+	shardReq.Sorts = make([]Sort, len(sorts))
+	for i, s := range sorts {
+		shardReq.Sorts[i] = Sort{Field: s.field.Name, Desc: s.desc}
+	}
+
+	results := make([][]T, len(f.shards))
+	err = f.each(func(i int, qb *QueryBuilder[T]) error {
+		query := qb.build(&shardReq, false)
+		if limit >= 0 {
+			query = query.Limit(offset + limit)
+		}
+		return query.Find(&results[i]).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var items []T
+	for _, result := range results {
+		items = append(items, result...)
+	}
+	f.sortItems(items, sorts)
+
+	if offset >= len(items) {
+		return []T{}, nil
+	}
+	items = items[offset:]
+	if limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+// sorts 解析合并使用的排序，未指定时使用默认排序，并追加尚未参与排序的主键
+func (f *FanOut[T]) sorts(req *FilterRequest) ([]fanOutSort, error) {
+	reqSorts := req.Sorts
+	if len(reqSorts) == 0 {
+		reqSorts = f.qb.opts.defaultSorts
+	}
+
+	nullsLarge := nullsLargest(f.dialect())
+	sorts := make([]fanOutSort, 0, len(reqSorts)+len(f.qb.keys))
+	for _, s := range f.qb.primaryKeySorts(reqSorts) {
+		if s.ScopeName != "" || s.CountOf != "" || len(s.Values) > 0 || s.NoCase || s.Collation != "" {
+			return nil, fmt.Errorf("fan-out queries only support plain field sorts: %s", s.Field)
+		}
+		field := f.qb.schema.LookUpField(s.Field)
+		if field == nil || field.DBName == "" {
+			return nil, fmt.Errorf("invalid field name: %s", s.Field)
+		}
+		if err := f.qb.checkSortAllowed(s.Field); err != nil {
+			return nil, err
+		}
+		sorts = append(sorts, fanOutSort{field: field, desc: s.Desc, nullsLarge: nullsLarge})
+	}
+	return sorts, nil
+}
+
+// dialect 返回分片的方言名称，合并排序按分片数据库的规则处理 NULL
+func (f *FanOut[T]) dialect() string {
+	if len(f.shards) > 0 && f.shards[0].Dialector != nil {
+		return f.shards[0].Dialector.Name()
+	}
+	return f.qb.dialect()
+}
+
+// sortItems 按排序字段对合并后的记录稳定排序
+func (f *FanOut[T]) sortItems(items []T, sorts []fanOutSort) {
+	ctx := f.qb.db.Statement.Context
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := reflect.ValueOf(&items[i]).Elem(), reflect.ValueOf(&items[j]).Elem()
+		for _, s := range sorts {
+			x, _ := s.field.ValueOf(ctx, a)
+			y, _ := s.field.ValueOf(ctx, b)
+			if c := compareValues(x, y, s.nullsLarge); c != 0 {
+				return (c < 0) != s.desc
+			}
+		}
+		return false
+	})
+}
+
+// each 在各分片上并发执行查询，任一分片失败时取消其他分片的查询并返回第一个错误
+func (f *FanOut[T]) each(fn func(i int, qb *QueryBuilder[T]) error) error {
+	if len(f.shards) == 0 {
+		return fmt.Errorf("fan-out requires at least one shard")
+	}
+	ctx, cancel := context.WithCancel(f.qb.db.Statement.Context)
+	defer cancel()
+
+	var (
+		wg   sync.WaitGroup
+		once sync.Once
+		err  error
+	)
+	for i, shard := range f.shards {
+		qb := f.qb.onShard(shard.WithContext(ctx))
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if shardErr := fn(i, qb); shardErr != nil {
+				once.Do(func() {
+					err = fmt.Errorf("shard %d: %w", i, shardErr)
+					cancel()
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+	return err
+}
+
+// onShard 返回在分片上执行查询的构建器副本，不使用只读副本与缓存
+func (qb *QueryBuilder[T]) onShard(db *gorm.DB) *QueryBuilder[T] {
+	clone := *qb
+	clone.db = db
+	clone.opts.readDB = nil
+	clone.opts.cache = nil
+	clone.opts.countCache = nil
+	return &clone
+}
+
+// compareValues 比较两个字段取值，nullsLarge 为 false 时 NULL 小于任何非空取值，否则大于任何非空取值，
+// 无法比较的类型按文本比较
+func compareValues(a, b interface{}, nullsLarge bool) int {
+	a, b = derefValue(a), derefValue(b)
+	nullOrder := -1
+	if nullsLarge {
+		nullOrder = 1
+	}
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return nullOrder
+	case b == nil:
+		return -nullOrder
+	}
+
+	switch x := a.(type) {
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y)
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0
+			case !x:
+				return -1
+			}
+			return 1
+		}
+	}
+
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch {
+	case av.CanInt() && bv.CanInt():
+		return cmp.Compare(av.Int(), bv.Int())
+	case av.CanUint() && bv.CanUint():
+		return cmp.Compare(av.Uint(), bv.Uint())
+	case av.CanFloat() && bv.CanFloat():
+		return cmp.Compare(av.Float(), bv.Float())
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// derefValue 解引用指针，driver.Valuer（如 sql.NullString）取其驱动取值，空值返回 nil
+func derefValue(value interface{}) interface{} {
+	if valuer, ok := value.(driver.Valuer); ok {
+		if v := reflect.ValueOf(valuer); v.Kind() == reflect.Ptr && v.IsNil() {
+			return nil
+		}
+		value, _ = valuer.Value()
+	}
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
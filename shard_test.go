@@ -0,0 +1,145 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupShardDB 创建包含指定用户的分片数据库
+func setupShardDB(t *testing.T, users ...TestUser) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&TestUser{}))
+	assert.NoError(t, db.Create(&users).Error)
+	return db
+}
+
+// rankedUser 排序字段可为 NULL 的测试模型
+type rankedUser struct {
+	ID    uint   `gorm:"primarykey"`
+	Name  string `gorm:"column:name"`
+	Score *int   `gorm:"column:score"`
+}
+
+func TestQueryBuilder_FanOutNullSorts(t *testing.T) {
+	score := func(v int) *int { return &v }
+	shard := func(dialect string, users ...rankedUser) *gorm.DB {
+		db, err := gorm.Open(namedDialector{Dialector: sqlite.Open(":memory:"), name: dialect}, &gorm.Config{})
+		assert.NoError(t, err)
+		assert.NoError(t, db.AutoMigrate(&rankedUser{}))
+		assert.NoError(t, db.Create(&users).Error)
+		return db
+	}
+	names := func(users []rankedUser) []string {
+		result := make([]string, len(users))
+		for i, user := range users {
+			result[i] = user.Name
+		}
+		return result
+	}
+
+	tests := []struct {
+		dialect string
+		asc     []string
+		desc    []string
+	}{
+		{"sqlite", []string{"Bob", "Dave", "Carol", "Alice"}, []string{"Alice", "Carol", "Bob", "Dave"}},
+		{"postgres", []string{"Carol", "Alice", "Bob", "Dave"}, []string{"Bob", "Dave", "Alice", "Carol"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			east := shard(tt.dialect, rankedUser{Name: "Alice", Score: score(20)}, rankedUser{Name: "Bob"})
+			west := shard(tt.dialect, rankedUser{Name: "Carol", Score: score(10)}, rankedUser{Name: "Dave"})
+			fanOut := NewQueryBuilder[rankedUser](east).FanOut(east, west)
+
+			users, err := fanOut.FindAll(&FilterRequest{Sorts: []Sort{{Field: "Score"}}})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.asc, names(users))
+
+			users, err = fanOut.FindAll(&FilterRequest{Sorts: []Sort{{Field: "Score", Desc: true}}})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.desc, names(users))
+		})
+	}
+}
+
+func TestQueryBuilder_FanOut(t *testing.T) {
+	east := setupShardDB(t,
+		TestUser{Name: "Alice", Age: 22, Status: "active"},
+		TestUser{Name: "Carol", Age: 41, Status: "active"},
+	)
+	west := setupShardDB(t,
+		TestUser{Name: "Bob", Age: 35, Status: "active"},
+		TestUser{Name: "Dave", Age: 28, Status: "inactive"},
+		TestUser{Name: "Erin", Age: 30, Status: "active"},
+	)
+	builder := NewQueryBuilder[TestUser](east)
+	fanOut := builder.FanOut(east, west)
+
+	names := func(users []TestUser) []string {
+		result := make([]string, len(users))
+		for i, user := range users {
+			result[i] = user.Name
+		}
+		return result
+	}
+
+	t.Run("FindAll", func(t *testing.T) {
+		users, err := fanOut.FindAll(&FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+			Sorts:   []Sort{{Field: "Age", Desc: true}},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Carol", "Bob", "Erin", "Alice"}, names(users))
+
+		users, err = fanOut.FindAll(&FilterRequest{
+			Sorts: []Sort{{Field: "Age"}},
+			Page:  &Pagination{Page: 2, PageSize: 2},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Erin", "Bob"}, names(users))
+	})
+
+	t.Run("FindPage", func(t *testing.T) {
+		result, err := fanOut.FindPage(&FilterRequest{
+			Sorts: []Sort{{Field: "Name"}},
+			Page:  &Pagination{Page: 2, PageSize: 2},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Carol", "Dave"}, names(result.Items))
+		assert.Equal(t, int64(5), result.Pagination.Total)
+		assert.Equal(t, 3, result.Pagination.TotalPages)
+		assert.True(t, result.Pagination.HasNext)
+
+		result, err = fanOut.FindPage(&FilterRequest{Page: &Pagination{Page: 4, PageSize: 2}})
+		assert.NoError(t, err)
+		assert.Empty(t, result.Items)
+	})
+
+	t.Run("Count", func(t *testing.T) {
+		count, err := fanOut.Count(&FilterRequest{Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}}})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), count)
+	})
+
+	t.Run("Unsupported requests", func(t *testing.T) {
+		_, err := fanOut.FindAll(&FilterRequest{Aggrs: []Aggregation{{Field: "Age", Op: SUM}}})
+		assert.ErrorContains(t, err, "do not support")
+
+		_, err = fanOut.FindAll(&FilterRequest{Sorts: []Sort{{Field: "Name", NoCase: true}}})
+		assert.ErrorContains(t, err, "plain field sorts")
+
+		_, err = builder.FanOut().Count(&FilterRequest{})
+		assert.ErrorContains(t, err, "at least one shard")
+	})
+
+	t.Run("Shard error", func(t *testing.T) {
+		broken, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+		assert.NoError(t, err)
+		_, err = builder.FanOut(east, broken).FindAll(&FilterRequest{})
+		assert.ErrorContains(t, err, "shard 1:")
+	})
+}
@@ -0,0 +1,18 @@
+package querybuild
+
+import (
+	"context"
+	"time"
+)
+
+// SlowQuery 执行耗时超过阈值的查询
+type SlowQuery struct {
+	Model    string         // 模型表名
+	Request  *FilterRequest // 查询请求
+	SQL      string         // 不含取值的SQL语句
+	Vars     []interface{}  // SQL语句的绑定参数
+	Duration time.Duration  // 执行耗时
+}
+
+// SlowQueryFunc 慢查询回调
+type SlowQueryFunc func(ctx context.Context, q SlowQuery)
@@ -0,0 +1,41 @@
+package querybuild
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_WithSlowQueryThreshold(t *testing.T) {
+	db := setupTestDB(t)
+
+	var slow []SlowQuery
+	onSlow := func(ctx context.Context, q SlowQuery) {
+		slow = append(slow, q)
+	}
+
+	req := &FilterRequest{Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}}}
+
+	t.Run("Queries over threshold", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db, WithSlowQueryThreshold(0, onSlow))
+
+		var users []TestUser
+		assert.NoError(t, builder.Build(req).Find(&users).Error)
+		assert.Len(t, slow, 1)
+		assert.Equal(t, "test_users", slow[0].Model)
+		assert.Same(t, req, slow[0].Request)
+		assert.Contains(t, slow[0].SQL, "`test_users`.`status` = ?")
+		assert.Equal(t, []interface{}{"active"}, slow[0].Vars)
+	})
+
+	t.Run("Queries under threshold", func(t *testing.T) {
+		slow = nil
+		builder := NewQueryBuilder[TestUser](db, WithSlowQueryThreshold(time.Hour, onSlow))
+
+		var users []TestUser
+		assert.NoError(t, builder.Build(req).Find(&users).Error)
+		assert.Empty(t, slow)
+	})
+}
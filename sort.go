@@ -0,0 +1,174 @@
+package querybuild
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// orderItem 单个排序项，普通排序使用 column，带参数的排序使用 expr
+type orderItem struct {
+	column clause.OrderByColumn
+	expr   clause.Expression
+}
+
+// rawOrder 创建普通的排序项
+func rawOrder(sql string) orderItem {
+	return orderItem{column: clause.OrderByColumn{Column: clause.Column{Name: sql, Raw: true}}}
+}
+
+// takeOrders 取出查询中已有的排序项并从查询中移除
+func takeOrders(query *gorm.DB) []orderItem {
+	c, ok := query.Statement.Clauses["ORDER BY"]
+	if !ok {
+		return nil
+	}
+	delete(query.Statement.Clauses, "ORDER BY")
+
+	orderBy, ok := c.Expression.(clause.OrderBy)
+	if !ok {
+		return nil
+	}
+	if orderBy.Expression != nil {
+		return []orderItem{{expr: orderBy.Expression}}
+	}
+	items := make([]orderItem, 0, len(orderBy.Columns))
+	for _, column := range orderBy.Columns {
+		items = append(items, orderItem{column: column})
+	}
+	return items
+}
+
+// applyOrders 将排序项写入查询
+//
+// 仅包含普通排序时使用列排序，便于调用方继续追加 Order；包含带参数的排序时合并为单个排序表达式，
+// 因为 gorm 合并 ORDER BY 子句时会丢弃已有的排序表达式。
+func applyOrders(query *gorm.DB, items []orderItem) *gorm.DB {
+	if len(items) == 0 {
+		return query
+	}
+
+	hasExpr := false
+	for _, item := range items {
+		if item.expr != nil {
+			hasExpr = true
+			break
+		}
+	}
+
+	if !hasExpr {
+		columns := make([]clause.OrderByColumn, 0, len(items))
+		for _, item := range items {
+			columns = append(columns, item.column)
+		}
+		return query.Order(clause.OrderBy{Columns: columns})
+	}
+
+	exprs := make([]clause.Expression, 0, len(items))
+	for _, item := range items {
+		if item.expr != nil {
+			exprs = append(exprs, item.expr)
+			continue
+		}
+		sql := item.column.Column.Name
+		if !item.column.Column.Raw {
+			sql = query.Statement.Quote(item.column.Column)
+		}
+		if item.column.Desc {
+			sql += " DESC"
+		}
+		exprs = append(exprs, clause.Expr{SQL: sql})
+	}
+	return query.Order(clause.OrderBy{Expression: clause.CommaExpression{Exprs: exprs}})
+}
+
+// valueSortExpr 生成按给定取值顺序排序的表达式，Desc 时反转取值顺序，未列出的取值始终排在最后
+//
+// MySQL 使用 FIELD()，PostgreSQL 使用 array_position()，其他数据库使用 CASE 表达式。
+func (qb *QueryBuilder[T]) valueSortExpr(field string, sort Sort) clause.Expression {
+	values := make([]interface{}, 0, len(sort.Values))
+	for _, v := range sort.Values {
+		values = append(values, v)
+	}
+	if sort.Desc {
+		for i, j := 0, len(values)-1; i < j; i, j = i+1, j-1 {
+			values[i], values[j] = values[j], values[i]
+		}
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+
+	var expr clause.Expr
+	switch qb.dialect() {
+	case dialectMySQL:
+		position := fmt.Sprintf("FIELD(%s, %s)", field, placeholders)
+		expr = clause.Expr{
+			SQL:  fmt.Sprintf("%s = 0, %s", position, position),
+			Vars: append(append([]interface{}{}, values...), values...),
+		}
+	case dialectPostgres:
+		expr = clause.Expr{
+			SQL:  fmt.Sprintf("array_position(ARRAY[%s]::text[], %s::text) NULLS LAST", placeholders, field),
+			Vars: values,
+		}
+	default:
+		var sql strings.Builder
+		sql.WriteString("CASE")
+		for i := range values {
+			fmt.Fprintf(&sql, " WHEN %s = ? THEN %d", field, i)
+		}
+		fmt.Fprintf(&sql, " ELSE %d END", len(values))
+		expr = clause.Expr{SQL: sql.String(), Vars: values}
+	}
+
+	expr.WithoutParentheses = true
+	return expr
+}
+
+// checkSortAllowed 检查字段是否允许排序
+func (qb *QueryBuilder[T]) checkSortAllowed(field string) error {
+	if qb.opts.sortFields == nil || qb.opts.sortFields[field] {
+		return nil
+	}
+	for _, pk := range qb.keys {
+		if pk.Name == field {
+			return nil
+		}
+	}
+	return &SortNotAllowedError{Field: field}
+}
+
+// tieBreakSorts 为分页查询追加主键排序，避免排序键不唯一时分页出现重复或遗漏
+//
+// 分组、聚合与 DISTINCT 查询的结果行不对应单条记录，不追加主键排序。
+func (qb *QueryBuilder[T]) tieBreakSorts(req *FilterRequest, sorts []Sort) []Sort {
+	if qb.opts.noTieBreak || req.Page == nil || req.Distinct ||
+		len(req.Groups) > 0 || len(req.Aggrs) > 0 {
+		return sorts
+	}
+	return qb.primaryKeySorts(sorts)
+}
+
+// primaryKeySorts 在排序后追加尚未参与排序的主键字段，指定了 WithKeyFields 时追加标识字段
+func (qb *QueryBuilder[T]) primaryKeySorts(sorts []Sort) []Sort {
+	if len(qb.keys) == 0 {
+		return sorts
+	}
+
+	result := append(make([]Sort, 0, len(sorts)+len(qb.keys)), sorts...)
+	for _, pk := range qb.keys {
+		sorted := false
+		for _, sort := range sorts {
+			if sort.Field == pk.Name && sort.CountOf == "" && len(sort.Values) == 0 {
+				sorted = true
+				break
+			}
+		}
+		if !sorted {
+			result = append(result, Sort{Field: pk.Name})
+		}
+	}
+	return result
+}
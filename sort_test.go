@@ -0,0 +1,164 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestQueryBuilder_ValueSort(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+	assert.NoError(t, db.Create(&TestUser{Name: "Pending User", Age: 40, Status: "pending"}).Error)
+
+	names := func(users []TestUser) []string {
+		result := make([]string, 0, len(users))
+		for _, u := range users {
+			result = append(result, u.Name)
+		}
+		return result
+	}
+
+	t.Run("Explicit order with unlisted values last", func(t *testing.T) {
+		var users []TestUser
+		err := builder.FindAll(&FilterRequest{
+			Sorts: []Sort{
+				{Field: "Status", Values: []string{"pending", "inactive"}},
+				{Field: "Age"},
+			},
+		}, &users)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Pending User", "Jane Smith", "John Doe", "Bob Johnson"}, names(users))
+	})
+
+	t.Run("Desc reverses listed order", func(t *testing.T) {
+		var users []TestUser
+		err := builder.FindAll(&FilterRequest{
+			Sorts: []Sort{
+				{Field: "Status", Values: []string{"pending", "inactive", "active"}, Desc: true},
+				{Field: "Age", Desc: true},
+			},
+		}, &users)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Bob Johnson", "John Doe", "Jane Smith", "Pending User"}, names(users))
+	})
+
+	t.Run("Dialect rendering", func(t *testing.T) {
+		for dialect, expected := range map[string]string{
+			"mysql":    "ORDER BY FIELD(`test_users`.`status`, ?, ?) = 0, FIELD(`test_users`.`status`, ?, ?)",
+			"postgres": "ORDER BY array_position(ARRAY[?, ?]::text[], `test_users`.`status`::text) NULLS LAST",
+		} {
+			builder := NewQueryBuilder[TestUser](setupDialectDB(t, dialect))
+			var users []TestUser
+			query := builder.Build(&FilterRequest{
+				Sorts: []Sort{{Field: "Status", Values: []string{"pending", "active"}}},
+			}).Find(&users)
+			assert.NoError(t, query.Error)
+			assert.Contains(t, query.Statement.SQL.String(), expected)
+		}
+	})
+}
+
+func TestQueryBuilder_TieBreak(t *testing.T) {
+	db := setupTestDB(t)
+
+	// 分页时 Build 会先执行计数查询，之后以 DryRun 会话生成数据查询的SQL
+	toSQL := func(builder *QueryBuilder[TestUser], req *FilterRequest) string {
+		var users []TestUser
+		query := builder.Build(req).Session(&gorm.Session{DryRun: true}).Find(&users)
+		assert.NoError(t, query.Error)
+		return query.Statement.SQL.String()
+	}
+
+	t.Run("Paginated query appends primary key", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db)
+		sql := toSQL(builder, &FilterRequest{
+			Sorts: []Sort{{Field: "Status"}},
+			Page:  &Pagination{Page: 2, PageSize: 10},
+		})
+		assert.Contains(t, sql, "ORDER BY `test_users`.`status` ASC,`test_users`.`id` ASC")
+	})
+
+	t.Run("Primary key already sorted", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db)
+		sql := toSQL(builder, &FilterRequest{
+			Sorts: []Sort{{Field: "ID", Desc: true}},
+			Page:  &Pagination{Page: 1, PageSize: 10},
+		})
+		assert.Contains(t, sql, "ORDER BY `test_users`.`id` DESC LIMIT")
+	})
+
+	t.Run("Unpaginated query is unchanged", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db)
+		sql := toSQL(builder, &FilterRequest{Sorts: []Sort{{Field: "Status"}}})
+		assert.NotContains(t, sql, "`test_users`.`id` ASC")
+	})
+
+	t.Run("Disabled by option", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db, WithTieBreak(false))
+		sql := toSQL(builder, &FilterRequest{
+			Sorts: []Sort{{Field: "Status"}},
+			Page:  &Pagination{Page: 1, PageSize: 10},
+		})
+		assert.NotContains(t, sql, "`test_users`.`id` ASC")
+	})
+
+	t.Run("Stable pages over duplicate sort keys", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](setupTestDB(t))
+		seen := map[uint]bool{}
+		for page := 1; page <= 3; page++ {
+			var users []TestUser
+			err := builder.FindAll(&FilterRequest{
+				Sorts: []Sort{{Field: "Status"}},
+				Page:  &Pagination{Page: page, PageSize: 1},
+			}, &users)
+			assert.NoError(t, err)
+			assert.Len(t, users, 1)
+			seen[users[0].ID] = true
+		}
+		assert.Len(t, seen, 3)
+	})
+}
+
+func TestQueryBuilder_AllowSortFields(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db, AllowSortFields("CreatedAt", "Orders"))
+
+	t.Run("Allowed field", func(t *testing.T) {
+		var users []TestUser
+		err := builder.FindAll(&FilterRequest{
+			Sorts: []Sort{{Field: "CreatedAt", Desc: true}, {CountOf: "Orders"}, {Field: "ID"}},
+		}, &users)
+		assert.NoError(t, err)
+		assert.Len(t, users, 3)
+	})
+
+	t.Run("Disallowed field returns typed error", func(t *testing.T) {
+		var users []TestUser
+		err := builder.FindAll(&FilterRequest{
+			Sorts: []Sort{{Field: "Email"}},
+		}, &users)
+		var notAllowed *SortNotAllowedError
+		assert.ErrorAs(t, err, &notAllowed)
+		assert.Equal(t, "Email", notAllowed.Field)
+	})
+
+	t.Run("Filtering is not restricted", func(t *testing.T) {
+		count, err := builder.Count(&FilterRequest{
+			Filters: []Filter{{Field: "Email", Op: EQ, Value: "john@example.com"}},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("Tie-break on primary key is allowed", func(t *testing.T) {
+		var users []TestUser
+		err := builder.FindAll(&FilterRequest{
+			Sorts: []Sort{{Field: "CreatedAt"}},
+			Page:  &Pagination{Page: 1, PageSize: 2},
+		}, &users)
+		assert.NoError(t, err)
+		assert.Len(t, users, 2)
+	})
+}
@@ -0,0 +1,61 @@
+package querybuild
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_FindChanges(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db, WithSyncField("CreatedAt"))
+
+	// 与已有记录相同的时间，验证按主键继续
+	same := TestUser{Name: "Same Time", Status: "active"}
+	var bob TestUser
+	assert.NoError(t, db.Where("name = ?", "Bob Johnson").Take(&bob).Error)
+	same.CreatedAt = bob.CreatedAt
+	assert.NoError(t, db.Create(&same).Error)
+
+	req := &FilterRequest{Filters: []Filter{{Field: "Status", Op: NE, Value: "deleted"}}}
+
+	var names []string
+	watermark := ""
+	for i := 0; i < 5; i++ {
+		result, err := builder.FindChanges(req, watermark, 1)
+		assert.NoError(t, err)
+		for _, item := range result.Items {
+			names = append(names, item.Name)
+		}
+		watermark = result.Watermark
+		if !result.HasMore {
+			break
+		}
+	}
+	assert.Equal(t, []string{"Bob Johnson", "Same Time", "Jane Smith", "John Doe"}, names)
+
+	t.Run("No new changes", func(t *testing.T) {
+		result, err := builder.FindChanges(req, watermark, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, result.Items)
+		assert.Equal(t, watermark, result.Watermark)
+		assert.False(t, result.HasMore)
+	})
+
+	t.Run("New change after watermark", func(t *testing.T) {
+		assert.NoError(t, db.Create(&TestUser{Name: "Newcomer", Status: "active", CreatedAt: time.Now().Add(time.Hour)}).Error)
+		result, err := builder.FindChanges(req, watermark, 10)
+		assert.NoError(t, err)
+		assert.Len(t, result.Items, 1)
+		assert.Equal(t, "Newcomer", result.Items[0].Name)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := builder.FindChanges(req, "not-a-watermark", 10)
+		assert.ErrorIs(t, err, ErrInvalidWatermark)
+
+		_, err = NewQueryBuilder[TestUser](db).FindChanges(req, "", 10)
+		assert.Error(t, err, "model has no UpdatedAt field")
+	})
+}
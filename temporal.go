@@ -0,0 +1,86 @@
+package querybuild
+
+import (
+	"fmt"
+	"time"
+)
+
+// History 模型的历史表，每行记录模型的一个版本及其有效期
+type History struct {
+	Table     string            // 历史表名
+	ValidFrom string            // 版本生效时间列
+	ValidTo   string            // 版本失效时间列，当前版本为 NULL
+	Columns   map[string]string // 模型字段名到历史表列名的映射，未列出的字段使用与模型相同的列名
+}
+
+// temporalScope 历史查询的时间范围条件
+type temporalScope struct {
+	sql  string        // 有效期条件
+	args []interface{} // 条件参数
+	key  string        // 区分不同时间范围的缓存键后缀
+	err  error         // 构建历史查询时的错误
+}
+
+// AsOf 返回查询历史表在时间点 t 有效版本的构建器副本
+//
+// 需通过 WithHistory 配置历史表。过滤、排序与结果字段按 History.Columns 映射到历史表的列，
+// 查询结果仍扫描到模型中。
+func (qb *QueryBuilder[T]) AsOf(t time.Time) *QueryBuilder[T] {
+	return qb.temporalBuilder(func(from, to string) (string, []interface{}) {
+		return fmt.Sprintf("%s <= ? AND (%s > ? OR %s IS NULL)", from, to, to), []interface{}{t, t}
+	}, "asof:"+t.UTC().Format(time.RFC3339Nano))
+}
+
+// During 返回查询历史表在 [from, to) 期间内任一时刻有效版本的构建器副本，同一记录可能返回多个版本
+func (qb *QueryBuilder[T]) During(from, to time.Time) *QueryBuilder[T] {
+	return qb.temporalBuilder(func(validFrom, validTo string) (string, []interface{}) {
+		return fmt.Sprintf("%s < ? AND (%s > ? OR %s IS NULL)", validFrom, validTo, validTo), []interface{}{to, from}
+	}, "during:"+from.UTC().Format(time.RFC3339Nano)+"/"+to.UTC().Format(time.RFC3339Nano))
+}
+
+// temporalBuilder 创建指向历史表的构建器副本，字段映射按历史表的列重建
+func (qb *QueryBuilder[T]) temporalBuilder(cond func(from, to string) (string, []interface{}), key string) *QueryBuilder[T] {
+	clone := *qb
+	clone.opts = qb.opts.clone()
+
+	h := qb.opts.history
+	if h == nil {
+		clone.temporal = &temporalScope{err: fmt.Errorf("history table is not configured")}
+		return &clone
+	}
+	for _, column := range append([]string{h.Table, h.ValidFrom, h.ValidTo}, mapValues(h.Columns)...) {
+		if !isIdentifier(column) {
+			clone.temporal = &temporalScope{err: fmt.Errorf("invalid history column: %s", column)}
+			return &clone
+		}
+	}
+
+	clone.opts.table = h.Table
+	clone.fields = tableSchemaFields(qb.schema, h.Table)
+	for field, column := range h.Columns {
+		if info, ok := clone.fields[field]; ok {
+			info.Name = column
+			clone.fields[field] = info
+		}
+	}
+	clone.plans = nil
+	if clone.opts.planCacheSize > 0 {
+		clone.plans = newPlanCache(clone.opts.planCacheSize)
+	}
+
+	sql, args := cond(
+		quoteField(FieldInfo{TableName: h.Table, Name: h.ValidFrom}),
+		quoteField(FieldInfo{TableName: h.Table, Name: h.ValidTo}),
+	)
+	clone.temporal = &temporalScope{sql: sql, args: args, key: key}
+	return &clone
+}
+
+// mapValues 返回映射中的全部取值
+func mapValues(m map[string]string) []string {
+	values := make([]string, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	return values
+}
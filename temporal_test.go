@@ -0,0 +1,78 @@
+package querybuild
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_AsOf(t *testing.T) {
+	db := setupTestDB(t)
+	assert.NoError(t, db.Exec(`CREATE TABLE test_users_history (
+		id INTEGER, name TEXT, email TEXT, age INTEGER, user_status TEXT, tags TEXT,
+		created_at DATETIME, valid_from DATETIME, valid_to DATETIME)`).Error)
+
+	t1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	t3 := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	insert := `INSERT INTO test_users_history (id, name, age, user_status, valid_from, valid_to) VALUES (?, ?, ?, ?, ?, ?)`
+	assert.NoError(t, db.Exec(insert, 1, "Alice", 20, "pending", t1, t2).Error)
+	assert.NoError(t, db.Exec(insert, 1, "Alice", 21, "active", t2, nil).Error)
+	assert.NoError(t, db.Exec(insert, 2, "Bob", 30, "active", t1, t3).Error)
+
+	builder := NewQueryBuilder[TestUser](db, WithHistory(History{
+		Table:     "test_users_history",
+		ValidFrom: "valid_from",
+		ValidTo:   "valid_to",
+		Columns:   map[string]string{"Status": "user_status"},
+	}))
+	req := &FilterRequest{Sorts: []Sort{{Field: "ID"}}}
+
+	t.Run("As of", func(t *testing.T) {
+		var users []TestUser
+		err := builder.AsOf(t1.Add(time.Hour)).FindAll(req, &users)
+		assert.NoError(t, err)
+		assert.Len(t, users, 2)
+		assert.Equal(t, "pending", users[0].Status)
+		assert.Equal(t, 20, users[0].Age)
+
+		users = nil
+		err = builder.AsOf(t3.Add(time.Hour)).FindAll(req, &users)
+		assert.NoError(t, err)
+		assert.Len(t, users, 1)
+		assert.Equal(t, "active", users[0].Status)
+		assert.Equal(t, 21, users[0].Age)
+	})
+
+	t.Run("Mapped filter", func(t *testing.T) {
+		var users []TestUser
+		err := builder.AsOf(t2).FindAll(&FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+		}, &users)
+		assert.NoError(t, err)
+		assert.Len(t, users, 2)
+	})
+
+	t.Run("During", func(t *testing.T) {
+		var users []TestUser
+		err := builder.During(t1, t2.Add(time.Hour)).FindAll(&FilterRequest{
+			Filters: []Filter{{Field: "ID", Op: EQ, Value: "1"}},
+		}, &users)
+		assert.NoError(t, err)
+		assert.Len(t, users, 2)
+	})
+
+	t.Run("Current table unchanged", func(t *testing.T) {
+		var users []TestUser
+		err := builder.FindAll(&FilterRequest{}, &users)
+		assert.NoError(t, err)
+		assert.Len(t, users, 3)
+	})
+
+	t.Run("Not configured", func(t *testing.T) {
+		var users []TestUser
+		err := NewQueryBuilder[TestUser](db).AsOf(t1).FindAll(req, &users)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,72 @@
+package querybuild
+
+import (
+	"context"
+	"fmt"
+)
+
+// TransformFunc 对单条记录的结果转换，就地修改记录，如解密字段、计算派生值或本地化枚举
+type TransformFunc[T any] func(ctx context.Context, item *T) error
+
+// namedTransform 结果转换管道中的命名转换
+type namedTransform[T any] struct {
+	name string
+	fn   TransformFunc[T]
+}
+
+// RegisterTransform 在结果转换管道末尾注册命名的转换，名称已存在时返回错误
+//
+// 转换在 FindAll、FindOne、FindPage、FindCursor、增量同步与导出取得结果后逐条执行，先于脱敏规则与查询后钩子；
+// 任一转换返回错误时查询失败。通过 Clone 派生的构建器复制当前的管道，此后各自独立。
+func (qb *QueryBuilder[T]) RegisterTransform(name string, fn TransformFunc[T]) error {
+	qb.hooks.mu.Lock()
+	defer qb.hooks.mu.Unlock()
+	for _, t := range qb.hooks.transforms {
+		if t.name == name {
+			return fmt.Errorf("transform already registered: %s", name)
+		}
+	}
+	qb.hooks.transforms = append(qb.hooks.transforms, namedTransform[T]{name: name, fn: fn})
+	return nil
+}
+
+// RemoveTransform 从结果转换管道中移除转换，名称不存在时返回错误
+func (qb *QueryBuilder[T]) RemoveTransform(name string) error {
+	qb.hooks.mu.Lock()
+	defer qb.hooks.mu.Unlock()
+	for i, t := range qb.hooks.transforms {
+		if t.name == name {
+			qb.hooks.transforms = append(qb.hooks.transforms[:i:i], qb.hooks.transforms[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("transform not found: %s", name)
+}
+
+// Transforms 返回结果转换管道中转换的名称，按执行顺序排列
+func (qb *QueryBuilder[T]) Transforms() []string {
+	qb.hooks.mu.RLock()
+	defer qb.hooks.mu.RUnlock()
+	names := make([]string, len(qb.hooks.transforms))
+	for i, t := range qb.hooks.transforms {
+		names[i] = t.name
+	}
+	return names
+}
+
+// applyTransforms 依次对每条记录执行结果转换
+func (qb *QueryBuilder[T]) applyTransforms(items []T) error {
+	qb.hooks.mu.RLock()
+	transforms := qb.hooks.transforms
+	qb.hooks.mu.RUnlock()
+
+	ctx := qb.db.Statement.Context
+	for _, t := range transforms {
+		for i := range items {
+			if err := t.fn(ctx, &items[i]); err != nil {
+				return fmt.Errorf("transform %s: %w", t.name, err)
+			}
+		}
+	}
+	return nil
+}
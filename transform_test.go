@@ -0,0 +1,68 @@
+package querybuild
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_Transforms(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db, WithMaskFunc("Email", func(v interface{}) interface{} {
+		return strings.Repeat("*", len(v.(string)))
+	}))
+
+	assert.NoError(t, builder.RegisterTransform("upper", func(ctx context.Context, user *TestUser) error {
+		user.Name = strings.ToUpper(user.Name)
+		return nil
+	}))
+	assert.NoError(t, builder.RegisterTransform("domain", func(ctx context.Context, user *TestUser) error {
+		// 转换先于脱敏执行，可读取原始取值
+		_, domain, _ := strings.Cut(user.Email, "@")
+		user.Tags = domain
+		return nil
+	}))
+	assert.Error(t, builder.RegisterTransform("upper", func(context.Context, *TestUser) error { return nil }))
+	assert.Equal(t, []string{"upper", "domain"}, builder.Transforms())
+
+	t.Run("Applied after fetch", func(t *testing.T) {
+		var users []TestUser
+		assert.NoError(t, builder.FindAll(&FilterRequest{Sorts: []Sort{{Field: "Age"}}}, &users))
+		if assert.Len(t, users, 3) {
+			assert.Equal(t, "JOHN DOE", users[0].Name)
+			assert.Equal(t, "example.com", users[0].Tags)
+			assert.Equal(t, "****************", users[0].Email)
+		}
+
+		page, err := builder.FindPage(&FilterRequest{Page: &Pagination{Page: 1, PageSize: 1}, Sorts: []Sort{{Field: "Age"}}})
+		assert.NoError(t, err)
+		if assert.Len(t, page.Items, 1) {
+			assert.Equal(t, "JOHN DOE", page.Items[0].Name)
+		}
+	})
+
+	t.Run("Clone and remove", func(t *testing.T) {
+		clone := builder.Clone()
+		assert.NoError(t, clone.RemoveTransform("upper"))
+		assert.Error(t, clone.RemoveTransform("upper"))
+		assert.Equal(t, []string{"domain"}, clone.Transforms())
+		assert.Equal(t, []string{"upper", "domain"}, builder.Transforms())
+
+		user, err := clone.FindOneOrNil(&FilterRequest{Filters: []Filter{{Field: "Name", Op: EQ, Value: "John Doe"}}})
+		assert.NoError(t, err)
+		assert.Equal(t, "John Doe", user.Name)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		failing := builder.Clone()
+		assert.NoError(t, failing.RegisterTransform("fail", func(context.Context, *TestUser) error {
+			return errors.New("key unavailable")
+		}))
+		var users []TestUser
+		err := failing.FindAll(&FilterRequest{}, &users)
+		assert.ErrorContains(t, err, "transform fail: key unavailable")
+	})
+}
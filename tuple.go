@@ -0,0 +1,85 @@
+package querybuild
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// TupleFilter 多字段组合取值过滤条件，用于按复合键批量查找，如 (org_id, external_id)
+type TupleFilter struct {
+	Fields []string   `json:"fields"` // 组合的字段
+	Values [][]string `json:"values"` // 取值元组，每个元组的取值数量与字段数量相同
+	Not    bool       `json:"not"`    // 为 true 时生成 NOT IN
+}
+
+// applyTupleFilters 应用多字段组合取值过滤条件
+func (qb *QueryBuilder[T]) applyTupleFilters(query *gorm.DB, filters []TupleFilter) *gorm.DB {
+	for _, filter := range filters {
+		cond, args, err := qb.tupleCondition(query, filter)
+		if err != nil {
+			query.AddError(err)
+			continue
+		}
+		if cond != "" {
+			query = whereCondition(query, cond, args)
+		}
+	}
+	return query
+}
+
+// tupleCondition 构建 (a, b) IN ((?, ?), (?, ?)) 条件，SQLite 使用 (a, b) IN (VALUES (?, ?), (?, ?))
+//
+// 取值元组为空时 IN 条件不匹配任何记录，NOT IN 条件不生成条件。
+func (qb *QueryBuilder[T]) tupleCondition(query *gorm.DB, filter TupleFilter) (string, []interface{}, error) {
+	if len(filter.Fields) == 0 {
+		return "", nil, fmt.Errorf("tuple filter requires at least one field")
+	}
+	columns := make([]string, 0, len(filter.Fields))
+	for _, field := range filter.Fields {
+		column, err := qb.resolveField(query, field)
+		if err != nil {
+			return "", nil, err
+		}
+		columns = append(columns, column)
+	}
+
+	if len(filter.Values) == 0 {
+		if filter.Not {
+			return "", nil, nil
+		}
+		return "1 = 0", nil, nil
+	}
+
+	placeholder := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	tuples := make([]string, 0, len(filter.Values))
+	args := make([]interface{}, 0, len(filter.Values)*len(columns))
+	for _, tuple := range filter.Values {
+		if len(tuple) != len(columns) {
+			return "", nil, fmt.Errorf("tuple filter on %s expects %d values, got %d",
+				strings.Join(filter.Fields, ", "), len(columns), len(tuple))
+		}
+		tuples = append(tuples, placeholder)
+		for _, value := range tuple {
+			args = append(args, value)
+		}
+	}
+
+	list := strings.Join(tuples, ", ")
+	if qb.dialect() == dialectSQLite {
+		// SQLite 仅在 VALUES 子句中支持行值列表
+		list = "VALUES " + list
+	}
+	op := " IN "
+	if filter.Not {
+		op = " NOT IN "
+	}
+	return "(" + strings.Join(columns, ", ") + ")" + op + "(" + list + ")", args, nil
+}
+
+// checkTupleFilter 校验多字段组合取值过滤条件
+func (qb *QueryBuilder[T]) checkTupleFilter(query *gorm.DB, filter TupleFilter) error {
+	_, _, err := qb.tupleCondition(query, filter)
+	return err
+}
@@ -0,0 +1,57 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_TupleFilters(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+
+	find := func(filter TupleFilter) ([]TestUser, error) {
+		var users []TestUser
+		err := builder.FindAll(&FilterRequest{TupleFilters: []TupleFilter{filter}}, &users)
+		return users, err
+	}
+
+	t.Run("IN", func(t *testing.T) {
+		users, err := find(TupleFilter{
+			Fields: []string{"Status", "Age"},
+			Values: [][]string{{"active", "25"}, {"inactive", "30"}, {"active", "30"}},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, users, 2)
+	})
+
+	t.Run("NOT IN", func(t *testing.T) {
+		users, err := find(TupleFilter{Fields: []string{"Status", "Age"}, Values: [][]string{{"active", "25"}}, Not: true})
+		assert.NoError(t, err)
+		assert.Len(t, users, 2)
+	})
+
+	t.Run("Empty values", func(t *testing.T) {
+		users, err := find(TupleFilter{Fields: []string{"Status", "Age"}})
+		assert.NoError(t, err)
+		assert.Empty(t, users)
+	})
+
+	t.Run("Postgres", func(t *testing.T) {
+		var users []TestUser
+		query := NewQueryBuilder[TestUser](setupDialectDB(t, "postgres")).Build(&FilterRequest{
+			TupleFilters: []TupleFilter{{Fields: []string{"Status", "Age"}, Values: [][]string{{"active", "25"}, {"inactive", "30"}}}},
+		}).Find(&users)
+		assert.NoError(t, query.Error)
+		assert.Contains(t, query.Statement.SQL.String(), "(`test_users`.`status`, `test_users`.`age`) IN ((?, ?), (?, ?))")
+		assert.Equal(t, []interface{}{"active", "25", "inactive", "30"}, query.Statement.Vars)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := find(TupleFilter{Fields: []string{"Status", "Age"}, Values: [][]string{{"active"}}})
+		assert.Error(t, err)
+		_, err = find(TupleFilter{Fields: []string{"Missing"}, Values: [][]string{{"x"}}})
+		assert.Error(t, err)
+		assert.Error(t, builder.Validate(&FilterRequest{TupleFilters: []TupleFilter{{}}}))
+	})
+}
@@ -0,0 +1,57 @@
+package querybuild
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUnscopedDisabled 请求包含软删除的记录，但构建器未通过 WithUnscoped 开启
+	ErrUnscopedDisabled = errors.New("unscoped queries are disabled")
+	// ErrHardDeleteDisabled 请求永久删除记录，但构建器未通过 WithHardDelete 开启
+	ErrHardDeleteDisabled = errors.New("hard delete is disabled")
+)
+
+// WithUnscoped 允许请求通过 FilterRequest.Unscoped 包含软删除的记录，默认不允许
+func WithUnscoped(enabled bool) Option {
+	return func(o *options) {
+		o.allowUnscoped = enabled
+	}
+}
+
+// WithHardDelete 允许 DeleteAll 通过 HardDelete 永久删除记录，默认不允许
+func WithHardDelete(enabled bool) Option {
+	return func(o *options) {
+		o.allowHardDelete = enabled
+	}
+}
+
+// HardDelete 永久删除记录，包括已被软删除的匹配记录，需通过 WithHardDelete 开启
+//
+// 模型没有软删除字段时与普通删除相同，但同样需要开启。
+func HardDelete() MutationOption {
+	return func(o *mutationOptions) {
+		o.hardDelete = true
+	}
+}
+
+// checkUnscoped 校验请求是否允许包含软删除的记录
+func (qb *QueryBuilder[T]) checkUnscoped(req *FilterRequest) error {
+	if req.Unscoped && !qb.opts.allowUnscoped {
+		return ErrUnscopedDisabled
+	}
+	return nil
+}
+
+// applyUnscoped 请求包含软删除的记录时取消软删除条件
+func (qb *QueryBuilder[T]) applyUnscoped(query *gorm.DB, req *FilterRequest) *gorm.DB {
+	if !req.Unscoped {
+		return query
+	}
+	if err := qb.checkUnscoped(req); err != nil {
+		query.AddError(err)
+		return query
+	}
+	return query.Unscoped()
+}
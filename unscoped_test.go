@@ -0,0 +1,99 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// testNote 带软删除字段的测试模型
+type testNote struct {
+	ID        uint `gorm:"primarykey"`
+	Title     string
+	DeletedAt gorm.DeletedAt
+}
+
+func setupNoteDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&testNote{}))
+	assert.NoError(t, db.Create(&[]testNote{{Title: "a"}, {Title: "b"}, {Title: "c"}}).Error)
+	assert.NoError(t, db.Where("title = ?", "c").Delete(&testNote{}).Error)
+	return db
+}
+
+func TestQueryBuilder_Unscoped(t *testing.T) {
+	db := setupNoteDB(t)
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		builder := NewQueryBuilder[testNote](db)
+		var notes []testNote
+		err := builder.FindAll(&FilterRequest{Unscoped: true}, &notes)
+		assert.ErrorIs(t, err, ErrUnscopedDisabled)
+
+		err = builder.Validate(&FilterRequest{Unscoped: true})
+		assert.ErrorIs(t, err, ErrUnscopedDisabled)
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		builder := NewQueryBuilder[testNote](db, WithUnscoped(true))
+		var notes []testNote
+		assert.NoError(t, builder.FindAll(&FilterRequest{}, &notes))
+		assert.Len(t, notes, 2)
+		assert.NoError(t, builder.FindAll(&FilterRequest{Unscoped: true}, &notes))
+		assert.Len(t, notes, 3)
+	})
+
+	t.Run("Lenient mode drops unscoped", func(t *testing.T) {
+		var warnings []error
+		builder := NewQueryBuilder[testNote](db, WithLenient(func(err error) {
+			warnings = append(warnings, err)
+		}))
+		var notes []testNote
+		assert.NoError(t, builder.FindAll(&FilterRequest{Unscoped: true}, &notes))
+		assert.Len(t, notes, 2)
+		assert.Len(t, warnings, 1)
+	})
+}
+
+func TestQueryBuilder_HardDelete(t *testing.T) {
+	all := func() *FilterRequest {
+		return &FilterRequest{Filters: []Filter{{Field: "Title", Op: IN, Value: "a,c"}}}
+	}
+	count := func(db *gorm.DB) int64 {
+		var n int64
+		assert.NoError(t, db.Unscoped().Model(&testNote{}).Count(&n).Error)
+		return n
+	}
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		db := setupNoteDB(t)
+		_, err := NewQueryBuilder[testNote](db).DeleteAll(all(), HardDelete())
+		assert.ErrorIs(t, err, ErrHardDeleteDisabled)
+		assert.Equal(t, int64(3), count(db))
+	})
+
+	t.Run("Soft delete", func(t *testing.T) {
+		db := setupNoteDB(t)
+		rows, err := NewQueryBuilder[testNote](db).DeleteAll(all())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), rows)
+		assert.Equal(t, int64(3), count(db))
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		db := setupNoteDB(t)
+		builder := NewQueryBuilder[testNote](db, WithHardDelete(true))
+		var deleted []testNote
+		rows, err := builder.DeleteAll(all(), HardDelete(), Returning(&deleted))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), rows)
+		assert.Len(t, deleted, 2)
+		assert.Equal(t, int64(1), count(db))
+
+		_, err = builder.UpdateAll(all(), map[string]interface{}{"Title": "x"}, HardDelete())
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,188 @@
+package querybuild
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// lookupKey 作为查找与冲突键的等值过滤条件
+type lookupKey struct {
+	field *schema.Field
+	value interface{}
+}
+
+// FirstOrCreate 按请求中的 EQ 过滤条件查找记录，不存在时以 defaults 为基础、写入过滤条件取值后创建
+//
+// 请求只能包含模型字段的 EQ 过滤条件，每个字段只能出现一次；查找与创建均使用主库，不执行查询钩子。
+// 返回的 created 表示记录是否为本次创建。并发创建时由唯一索引保证幂等，冲突时返回数据库错误。
+func (qb *QueryBuilder[T]) FirstOrCreate(req *FilterRequest, defaults T) (item *T, created bool, err error) {
+	if err := qb.checkWritable(); err != nil {
+		return nil, false, err
+	}
+	keys, err := qb.lookupKeys(req)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var found T
+	query := qb.withExecInfo(qb.newWriteQuery(), req)
+	for _, key := range keys {
+		query = query.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: key.field.DBName}, Value: key.value})
+	}
+	err = query.Take(&found).Error
+	if err == nil {
+		return &found, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	if err := qb.setKeys(&defaults, keys); err != nil {
+		return nil, false, err
+	}
+	if err := qb.withExecInfo(qb.newWriteQuery(), req).Create(&defaults).Error; err != nil {
+		return nil, false, err
+	}
+	_ = qb.InvalidateCache()
+	return &defaults, true, nil
+}
+
+// Upsert 以请求中的 EQ 过滤条件作为冲突键写入记录，键取值写入 item 后插入，冲突时更新主键以外的字段
+//
+// 请求的要求与 FirstOrCreate 相同。冲突键需存在唯一索引，MySQL 忽略冲突列，按表上的唯一索引判断冲突。
+// 指定了 Returning 时将写入后的完整记录同时写入 item 与返回目标。
+func (qb *QueryBuilder[T]) Upsert(req *FilterRequest, item *T, opts ...MutationOption) error {
+	if err := qb.checkWritable(); err != nil {
+		return err
+	}
+	mo := newMutationOptions(opts)
+	keys, err := qb.lookupKeys(req)
+	if err != nil {
+		return err
+	}
+	if err := qb.setKeys(item, keys); err != nil {
+		return err
+	}
+	var dest *[]T
+	if mo.returning != nil {
+		if dest, err = qb.returningDest(mo); err != nil {
+			return err
+		}
+	}
+
+	columns := make([]clause.Column, len(keys))
+	for i, key := range keys {
+		columns[i] = clause.Column{Name: key.field.DBName}
+	}
+	onConflict := clause.OnConflict{Columns: columns, UpdateAll: true}
+
+	switch {
+	case dest == nil:
+		err = qb.withExecInfo(qb.newWriteQuery(), req).Clauses(onConflict).Create(item).Error
+	case qb.nativeReturning():
+		err = qb.withExecInfo(qb.newWriteQuery(), req).Clauses(onConflict, clause.Returning{}).Create(item).Error
+	default:
+		err = qb.db.Transaction(func(tx *gorm.DB) error {
+			if err := qb.withExecInfo(qb.modelQuery(tx), req).Clauses(onConflict).Create(item).Error; err != nil {
+				return err
+			}
+			query := qb.modelQuery(tx)
+			for _, key := range keys {
+				query = query.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: key.field.DBName}, Value: key.value})
+			}
+			return query.Take(item).Error
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if dest != nil {
+		*dest = append(*dest, *item)
+	}
+	_ = qb.InvalidateCache()
+	return nil
+}
+
+// lookupKeys 解析请求中作为查找与冲突键的等值过滤条件，取值按字段类型转换或经取值编码器编码
+func (qb *QueryBuilder[T]) lookupKeys(req *FilterRequest) ([]lookupKey, error) {
+	if req == nil || len(req.Filters) == 0 {
+		return nil, fmt.Errorf("lookup requires at least one EQ filter")
+	}
+	if len(req.CustomFields) > 0 || req.CustomFilter != nil || len(req.CustomFilters) > 0 || len(req.Sorts) > 0 || len(req.Aggrs) > 0 ||
+		len(req.Groups) > 0 || len(req.Joins) > 0 || req.SubQuery != nil || len(req.FilterGroups) > 0 || len(req.TupleFilters) > 0 ||
+		len(req.Exists) > 0 || len(req.CountFilters) > 0 || req.Page != nil || req.Distinct || req.DedupeByPK || req.Unscoped || req.Sample != nil {
+		return nil, fmt.Errorf("lookup request can only contain EQ filters")
+	}
+	loc, err := qb.requestLocation(req)
+	if err != nil {
+		return nil, err
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	keys := make([]lookupKey, 0, len(req.Filters))
+	seen := make(map[string]bool, len(req.Filters))
+	for _, filter := range req.Filters {
+		if filter.Op != EQ || filter.NoCase || filter.Fn != "" {
+			return nil, fmt.Errorf("lookup filter must be a plain EQ filter: %s", filter.Field)
+		}
+		field := qb.schema.LookUpField(filter.Field)
+		if field == nil || field.DBName == "" {
+			return nil, fmt.Errorf("invalid field name: %s", filter.Field)
+		}
+		if qb.encryptedField(field.Name) != nil {
+			return nil, fmt.Errorf("encrypted field cannot be used as a lookup key: %s", filter.Field)
+		}
+		if seen[field.Name] {
+			return nil, fmt.Errorf("duplicate lookup field: %s", filter.Field)
+		}
+		seen[field.Name] = true
+		if err := qb.checkFilterValue(filter); err != nil {
+			return nil, err
+		}
+
+		value := enumCode(qb.enumLabels(field), filter.Value)
+		key := lookupKey{field: field, value: value}
+		if encode, ok := qb.encoders.Get(field.Name); ok {
+			if key.value, err = encodeValue(encode, filter.Field, value); err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+			continue
+		}
+		switch field.DataType {
+		case schema.Bool:
+			b, ok := parseBool(value)
+			if !ok {
+				return nil, &InvalidValueError{Field: filter.Field, Value: value, Reason: "not a boolean"}
+			}
+			key.value = b
+		case schema.Time:
+			t, _, ok := parseTimeIn(value, loc)
+			if !ok {
+				return nil, &InvalidValueError{Field: filter.Field, Value: value, Reason: "not a time"}
+			}
+			key.value = t
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// setKeys 将查找键的取值写入记录
+func (qb *QueryBuilder[T]) setKeys(item *T, keys []lookupKey) error {
+	value := reflect.ValueOf(item).Elem()
+	for _, key := range keys {
+		if err := key.field.Set(qb.db.Statement.Context, value, key.value); err != nil {
+			return fmt.Errorf("invalid value for field %s: %w", key.field.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// testAccount 按邮箱唯一的测试账户模型
+type testAccount struct {
+	ID     uint   `gorm:"primarykey"`
+	Email  string `gorm:"uniqueIndex"`
+	Name   string
+	Score  int
+	Active bool
+}
+
+func TestQueryBuilder_FirstOrCreate(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+
+	t.Run("Existing", func(t *testing.T) {
+		user, created, err := builder.FirstOrCreate(&FilterRequest{
+			Filters: []Filter{{Field: "Email", Op: EQ, Value: "john@example.com"}},
+		}, TestUser{Name: "Someone Else"})
+		assert.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, "John Doe", user.Name)
+	})
+
+	t.Run("Create", func(t *testing.T) {
+		req := &FilterRequest{Filters: []Filter{
+			{Field: "Email", Op: EQ, Value: "alice@example.com"},
+			{Field: "Age", Op: EQ, Value: "28"},
+		}}
+		user, created, err := builder.FirstOrCreate(req, TestUser{Name: "Alice", Status: "active"})
+		assert.NoError(t, err)
+		assert.True(t, created)
+		assert.NotZero(t, user.ID)
+		assert.Equal(t, "alice@example.com", user.Email)
+		assert.Equal(t, 28, user.Age)
+
+		again, created, err := builder.FirstOrCreate(req, TestUser{Name: "Alice"})
+		assert.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, user.ID, again.ID)
+
+		var count int64
+		assert.NoError(t, db.Model(&TestUser{}).Where("email = ?", "alice@example.com").Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		_, _, err := builder.FirstOrCreate(&FilterRequest{}, TestUser{})
+		assert.Error(t, err)
+
+		_, _, err = builder.FirstOrCreate(&FilterRequest{
+			Filters: []Filter{{Field: "Age", Op: GT, Value: "20"}},
+		}, TestUser{})
+		assert.ErrorContains(t, err, "plain EQ filter")
+
+		_, _, err = builder.FirstOrCreate(&FilterRequest{
+			Filters: []Filter{{Field: "Email", Op: EQ, Value: "a@example.com"}},
+			Sorts:   []Sort{{Field: "Name"}},
+		}, TestUser{})
+		assert.ErrorContains(t, err, "can only contain EQ filters")
+
+		_, _, err = builder.FirstOrCreate(&FilterRequest{
+			Filters: []Filter{{Field: "Email", Op: EQ, Value: "a@example.com"}, {Field: "Email", Op: EQ, Value: "b@example.com"}},
+		}, TestUser{})
+		assert.ErrorContains(t, err, "duplicate lookup field")
+	})
+}
+
+func TestQueryBuilder_Upsert(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&testAccount{}))
+	builder := NewQueryBuilder[testAccount](db)
+	req := &FilterRequest{Filters: []Filter{{Field: "Email", Op: EQ, Value: "alice@example.com"}}}
+
+	assert.NoError(t, builder.Upsert(req, &testAccount{Name: "Alice", Score: 1, Active: true}))
+	assert.NoError(t, builder.Upsert(req, &testAccount{Name: "Alice Liddell", Score: 2}))
+
+	var accounts []testAccount
+	assert.NoError(t, db.Find(&accounts).Error)
+	if assert.Len(t, accounts, 1) {
+		assert.Equal(t, "alice@example.com", accounts[0].Email)
+		assert.Equal(t, "Alice Liddell", accounts[0].Name)
+		assert.Equal(t, 2, accounts[0].Score)
+		assert.False(t, accounts[0].Active)
+	}
+
+	err = builder.Upsert(&FilterRequest{Filters: []Filter{{Field: "Active", Op: EQ, Value: "maybe"}}}, &testAccount{})
+	assert.Error(t, err)
+}
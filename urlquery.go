@@ -0,0 +1,246 @@
+package querybuild
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ParseOperator 按名称解析过滤操作符，名称不区分大小写，如 eq、NOT_IN
+func ParseOperator(name string) (Operator, error) {
+	upper := strings.ToUpper(name)
+	for op := EQ; op <= lastOperator; op++ {
+		if op.String() == upper {
+			return op, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported operator: %s", name)
+}
+
+// ParseSorts 解析逗号分隔的排序简写，如 "-CreatedAt,+Name"
+//
+// 字段前的 - 表示降序，+ 或无前缀表示升序，也可使用 Field:desc 的写法。
+// 字段需为标识符或 alias.column 形式的连接表字段，是否为有效的模型字段在构建时校验。
+// URL 查询参数中未编码的 + 会被解码为空格，因此条目两端的空白被忽略。
+func ParseSorts(s string) ([]Sort, error) {
+	var sorts []Sort
+	for _, token := range strings.Split(s, ",") {
+		token = strings.TrimSpace(token)
+		sort := Sort{}
+		sign := token != "" && (token[0] == '-' || token[0] == '+')
+		if sign {
+			sort.Desc = token[0] == '-'
+			token = token[1:]
+		}
+
+		field, direction, ok := strings.Cut(token, ":")
+		if !isSortField(field) {
+			return nil, fmt.Errorf("invalid sort parameter: %s", s)
+		}
+		sort.Field = field
+		if ok {
+			switch strings.ToLower(direction) {
+			case "asc":
+			case "desc":
+				sort.Desc = true
+			default:
+				return nil, fmt.Errorf("invalid sort direction: %s", s)
+			}
+			if sign {
+				return nil, fmt.Errorf("invalid sort direction: %s", s)
+			}
+		}
+		sorts = append(sorts, sort)
+	}
+	return sorts, nil
+}
+
+// isSortField 判断排序简写中的字段是否为标识符或 alias.column 形式
+func isSortField(field string) bool {
+	if alias, column, ok := strings.Cut(field, "."); ok {
+		return isIdentifier(alias) && isIdentifier(column)
+	}
+	return isIdentifier(field)
+}
+
+// UnmarshalJSON 解析操作符，支持数值与名称两种写法，如 1 或 "NE"
+func (op *Operator) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var name string
+		if err := json.Unmarshal(data, &name); err != nil {
+			return err
+		}
+		parsed, err := ParseOperator(name)
+		if err != nil {
+			return err
+		}
+		*op = parsed
+		return nil
+	}
+	var n int32
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*op = Operator(n)
+	return nil
+}
+
+// ParseQuery 从URL查询参数解析过滤请求
+//
+// 支持的参数：
+//
+//	filter=Field:OP:value   过滤条件，可重复，OP 为操作符名称，如 filter=Status:eq:active
+//	sort=Field[:desc]       排序，可重复，如 sort=CreatedAt:desc，也可使用 ParseSorts 的简写，如 sort=-CreatedAt,Name
+//	page=2&page_size=20     分页
+//	distinct=true           去重
+//	time_zone=Asia/Shanghai 业务时区
+func ParseQuery(values url.Values) (*FilterRequest, error) {
+	req := &FilterRequest{}
+
+	for _, raw := range values["filter"] {
+		parts := strings.SplitN(raw, ":", 3)
+		if len(parts) < 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid filter parameter: %s", raw)
+		}
+		op, err := ParseOperator(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		filter := Filter{Field: parts[0], Op: op}
+		if len(parts) == 3 {
+			filter.Value = parts[2]
+		}
+		req.Filters = append(req.Filters, filter)
+	}
+
+	for _, raw := range values["sort"] {
+		sorts, err := ParseSorts(raw)
+		if err != nil {
+			return nil, err
+		}
+		req.Sorts = append(req.Sorts, sorts...)
+	}
+
+	if values.Has("page") || values.Has("page_size") {
+		req.Page = &Pagination{}
+		for key, dest := range map[string]*int{"page": &req.Page.Page, "page_size": &req.Page.PageSize} {
+			if !values.Has(key) {
+				continue
+			}
+			n, err := strconv.Atoi(values.Get(key))
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s parameter: %s", key, values.Get(key))
+			}
+			*dest = n
+		}
+	}
+
+	if values.Has("distinct") {
+		distinct, err := strconv.ParseBool(values.Get("distinct"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid distinct parameter: %s", values.Get("distinct"))
+		}
+		req.Distinct = distinct
+	}
+	req.TimeZone = values.Get("time_zone")
+	return req, nil
+}
+
+// EncodeQuery 将过滤请求编码为URL查询参数，是 ParseQuery 的逆操作
+//
+// 参数按 ParseQuery 的格式生成，请求包含查询参数无法表示的部分（如连接、聚合、忽略大小写）时返回错误。
+func EncodeQuery(req *FilterRequest) (url.Values, error) {
+	if len(req.CustomFields) > 0 || req.CustomFilter != nil || len(req.CustomFilters) > 0 || len(req.Aggrs) > 0 || len(req.Groups) > 0 ||
+		len(req.Joins) > 0 || req.SubQuery != nil || len(req.FilterGroups) > 0 || len(req.TupleFilters) > 0 || len(req.Exists) > 0 || len(req.CountFilters) > 0 || req.DedupeByPK || req.Unscoped || req.Sample != nil {
+		return nil, fmt.Errorf("filter request cannot be encoded as query parameters")
+	}
+
+	values := make(url.Values)
+	for _, filter := range req.Filters {
+		if filter.NoCase || filter.Fn != "" {
+			return nil, fmt.Errorf("filter cannot be encoded as query parameters: %s", filter.Field)
+		}
+		param := filter.Field + ":" + strings.ToLower(filter.Op.String())
+		if filter.Value != "" {
+			param += ":" + filter.Value
+		}
+		values.Add("filter", param)
+	}
+	for _, sort := range req.Sorts {
+		if sort.NoCase || sort.ScopeName != "" || sort.CountOf != "" || len(sort.Values) > 0 || sort.Collation != "" {
+			return nil, fmt.Errorf("sort cannot be encoded as query parameters: %s", sort.Field)
+		}
+		param := sort.Field
+		if sort.Desc {
+			param += ":desc"
+		}
+		values.Add("sort", param)
+	}
+	if req.Page != nil {
+		values.Set("page", strconv.Itoa(req.Page.Page))
+		values.Set("page_size", strconv.Itoa(req.Page.PageSize))
+	}
+	if req.Distinct {
+		values.Set("distinct", "true")
+	}
+	if req.TimeZone != "" {
+		values.Set("time_zone", req.TimeZone)
+	}
+	return values, nil
+}
+
+// Links 分页结果的导航链接，不存在的页面链接为空
+type Links struct {
+	Self  string `json:"self"`
+	First string `json:"first,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last,omitempty"`
+}
+
+// PageLinks 根据查询后的分页信息生成当前页、首页、上一页、下一页与末页链接
+//
+// 链接在 base 的基础上替换过滤请求相关的查询参数，base 中的其他参数保持不变，参数按名称排序。
+func PageLinks(base string, req *FilterRequest) (*Links, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+	values, err := EncodeQuery(req)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	for _, key := range []string{"filter", "sort", "page", "page_size", "distinct", "time_zone"} {
+		query.Del(key)
+	}
+	for key, vs := range values {
+		query[key] = vs
+	}
+
+	link := func(page int) string {
+		if req.Page != nil {
+			query.Set("page", strconv.Itoa(page))
+		}
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+
+	page := req.Page
+	if page == nil {
+		return &Links{Self: link(0)}, nil
+	}
+	links := &Links{Self: link(page.Page), First: link(1)}
+	if page.HasPrev {
+		links.Prev = link(page.Page - 1)
+	}
+	if page.HasNext {
+		links.Next = link(page.Page + 1)
+	}
+	if page.TotalPages > 0 {
+		links.Last = link(page.TotalPages)
+	}
+	return links, nil
+}
@@ -0,0 +1,106 @@
+package querybuild
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuery(t *testing.T) {
+	values, _ := url.ParseQuery("filter=Status:eq:active&filter=Email:ends_with:example.com:8080" +
+		"&filter=Tags:is_null&sort=CreatedAt:desc&sort=Name&page=2&page_size=10&distinct=true")
+
+	req, err := ParseQuery(values)
+	assert.NoError(t, err)
+	assert.Equal(t, []Filter{
+		{Field: "Status", Op: EQ, Value: "active"},
+		{Field: "Email", Op: ENDS_WITH, Value: "example.com:8080"},
+		{Field: "Tags", Op: IS_NULL},
+	}, req.Filters)
+	assert.Equal(t, []Sort{{Field: "CreatedAt", Desc: true}, {Field: "Name"}}, req.Sorts)
+	assert.Equal(t, &Pagination{Page: 2, PageSize: 10}, req.Page)
+	assert.True(t, req.Distinct)
+
+	for _, query := range []string{
+		"filter=Status",
+		"filter=Status:unknown:x",
+		"sort=Name:sideways",
+		"sort=-Name:asc",
+		"sort=Name,,Age",
+		"sort=Name.drop.table",
+		"page=two",
+	} {
+		values, _ := url.ParseQuery(query)
+		_, err := ParseQuery(values)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestParseSorts(t *testing.T) {
+	sorts, err := ParseSorts("-CreatedAt,+Name, Age:desc,u.name")
+	assert.NoError(t, err)
+	assert.Equal(t, []Sort{
+		{Field: "CreatedAt", Desc: true},
+		{Field: "Name"},
+		{Field: "Age", Desc: true},
+		{Field: "u.name"},
+	}, sorts)
+
+	// 未编码的 + 在URL查询参数中解码为空格
+	values, _ := url.ParseQuery("sort=-CreatedAt,+Name")
+	req, err := ParseQuery(values)
+	assert.NoError(t, err)
+	assert.Equal(t, []Sort{{Field: "CreatedAt", Desc: true}, {Field: "Name"}}, req.Sorts)
+
+	for _, s := range []string{"", "-", "Name,", "--Name", "Name:up", "a.b.c", "Na me"} {
+		_, err := ParseSorts(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestEncodeQuery(t *testing.T) {
+	req := &FilterRequest{
+		Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}, {Field: "Tags", Op: NOT_NULL}},
+		Sorts:   []Sort{{Field: "CreatedAt", Desc: true}},
+		Page:    &Pagination{Page: 2, PageSize: 10},
+	}
+	values, err := EncodeQuery(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "filter=Status%3Aeq%3Aactive&filter=Tags%3Anot_null&page=2&page_size=10&sort=CreatedAt%3Adesc", values.Encode())
+
+	parsed, err := ParseQuery(values)
+	assert.NoError(t, err)
+	assert.Equal(t, req, parsed)
+
+	_, err = EncodeQuery(&FilterRequest{Joins: []Join{{Table: "orders"}}})
+	assert.Error(t, err)
+}
+
+func TestPageLinks(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+
+	req := &FilterRequest{
+		Sorts: []Sort{{Field: "Name"}},
+		Page:  &Pagination{Page: 2, PageSize: 1},
+	}
+	_, err := builder.FindPage(req)
+	assert.NoError(t, err)
+
+	links, err := PageLinks("https://api.example.com/users?api_key=k&page=9", req)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.example.com/users?api_key=k&page=2&page_size=1&sort=Name", links.Self)
+	assert.Equal(t, "https://api.example.com/users?api_key=k&page=1&page_size=1&sort=Name", links.First)
+	assert.Equal(t, "https://api.example.com/users?api_key=k&page=1&page_size=1&sort=Name", links.Prev)
+	assert.Equal(t, "https://api.example.com/users?api_key=k&page=3&page_size=1&sort=Name", links.Next)
+	assert.Equal(t, "https://api.example.com/users?api_key=k&page=3&page_size=1&sort=Name", links.Last)
+
+	req.Page = &Pagination{Page: 3, PageSize: 1}
+	_, err = builder.FindPage(req)
+	assert.NoError(t, err)
+	links, err = PageLinks("/users", req)
+	assert.NoError(t, err)
+	assert.Empty(t, links.Next)
+	assert.Equal(t, "/users?page=2&page_size=1&sort=Name", links.Prev)
+}
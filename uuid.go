@@ -0,0 +1,121 @@
+package querybuild
+
+import (
+	"encoding/hex"
+	"reflect"
+	"strings"
+)
+
+// UUIDStorage UUID 字段在数据库中的存储方式
+type UUIDStorage int
+
+const (
+	UUIDText   UUIDStorage = iota + 1 // 以小写带连字符的 36 位文本存储，如 PostgreSQL uuid 与 CHAR(36) 列
+	UUIDBinary                        // 以 16 字节二进制存储，如 MySQL BINARY(16) 列
+)
+
+// WithUUIDFields 将字段标记为按 storage 存储的 UUID 字段
+//
+// UUID 字段的 EQ、NE、IN 与 NOT_IN 过滤取值接受大小写、带或不带连字符、花括号与 urn:uuid: 前缀的写法，
+// 解析后按存储方式绑定为规范文本或 16 字节二进制；无法解析的取值返回 InvalidValueError。
+// 未声明时，Go 类型为 [16]byte 或标签为 binary(16) 的字段视为二进制存储，标签为 uuid 的字段视为文本存储。
+func WithUUIDFields(storage UUIDStorage, fields ...string) Option {
+	return func(o *options) {
+		if o.uuids == nil {
+			o.uuids = make(map[string]UUIDStorage, len(fields))
+		}
+		for _, field := range fields {
+			o.uuids[field] = storage
+		}
+	}
+}
+
+// uuidStorage 返回过滤条件字段的 UUID 存储方式，非 UUID 字段或不转换取值的操作符返回 0
+func (qb *QueryBuilder[T]) uuidStorage(filter Filter) UUIDStorage {
+	switch filter.Op {
+	case EQ, NE, IN, NOT_IN:
+	default:
+		return 0
+	}
+	if filter.NoCase || filter.Fn != "" {
+		return 0
+	}
+	field := qb.schema.LookUpField(filter.Field)
+	if field == nil || field.DBName == "" {
+		return 0
+	}
+	if storage, ok := qb.opts.uuids[field.Name]; ok {
+		return storage
+	}
+
+	typ := field.IndirectFieldType
+	if typ.Kind() == reflect.Array && typ.Len() == 16 && typ.Elem().Kind() == reflect.Uint8 && field.Serializer == nil &&
+		!typ.Implements(valuerType) && !reflect.PointerTo(typ).Implements(valuerType) {
+		return UUIDBinary
+	}
+	switch strings.ToLower(strings.ReplaceAll(field.TagSettings["TYPE"], " ", "")) {
+	case "binary(16)":
+		return UUIDBinary
+	case "uuid":
+		return UUIDText
+	}
+	return 0
+}
+
+// uuidCondition 构建 UUID 字段的过滤条件，取值解析后按存储方式绑定
+func uuidCondition(field string, filter Filter, storage UUIDStorage) (string, []interface{}, error) {
+	items := []string{filter.Value}
+	if filter.Op == IN || filter.Op == NOT_IN {
+		items = strings.Split(filter.Value, ",")
+	}
+
+	values := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		id, ok := parseUUID(item)
+		if !ok {
+			return "", nil, &InvalidValueError{Field: filter.Field, Value: item, Reason: "not a UUID"}
+		}
+		if storage == UUIDBinary {
+			values = append(values, id[:])
+		} else {
+			values = append(values, formatUUID(id))
+		}
+	}
+
+	if filter.Op == IN || filter.Op == NOT_IN {
+		return conditionSQL(field, filter), []interface{}{values}, nil
+	}
+	return conditionSQL(field, filter), values, nil
+}
+
+// parseUUID 解析 UUID 的常见写法：带或不带连字符的 32 位十六进制、花括号与 urn:uuid: 前缀，不区分大小写
+func parseUUID(value string) ([16]byte, bool) {
+	var id [16]byte
+	s := strings.TrimSpace(value)
+	if len(s) >= 9 && strings.EqualFold(s[:9], "urn:uuid:") {
+		s = s[9:]
+	} else if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+		s = s[1 : len(s)-1]
+	}
+
+	switch len(s) {
+	case 36:
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return id, false
+		}
+		s = s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	case 32:
+	default:
+		return id, false
+	}
+	if _, err := hex.Decode(id[:], []byte(s)); err != nil {
+		return id, false
+	}
+	return id, true
+}
+
+// formatUUID 将 UUID 格式化为小写带连字符的规范文本
+func formatUUID(id [16]byte) string {
+	s := hex.EncodeToString(id[:])
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
@@ -0,0 +1,55 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestDevice struct {
+	ID  uint   `gorm:"primarykey"`
+	Key []byte `gorm:"column:key;type:binary(16)"`
+	Ref string `gorm:"column:ref;size:36"`
+}
+
+func TestQueryBuilder_UUIDFields(t *testing.T) {
+	db := setupTestDB(t)
+	assert.NoError(t, db.AutoMigrate(&TestDevice{}))
+	first, _ := parseUUID("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	second, _ := parseUUID("6ba7b811-9dad-11d1-80b4-00c04fd430c8")
+	assert.NoError(t, db.Create([]TestDevice{
+		{ID: 1, Key: first[:], Ref: formatUUID(first)},
+		{ID: 2, Key: second[:], Ref: formatUUID(second)},
+	}).Error)
+	builder := NewQueryBuilder[TestDevice](db, WithUUIDFields(UUIDText, "Ref"), WithValueValidation())
+
+	find := func(filter Filter) []uint {
+		var devices []TestDevice
+		assert.NoError(t, builder.FindAll(&FilterRequest{Filters: []Filter{filter}, Sorts: []Sort{{Field: "ID"}}}, &devices))
+		var ids []uint
+		for _, device := range devices {
+			ids = append(ids, device.ID)
+		}
+		return ids
+	}
+
+	t.Run("Binary storage", func(t *testing.T) {
+		assert.Equal(t, []uint{1}, find(Filter{Field: "Key", Op: EQ, Value: "6BA7B810-9DAD-11D1-80B4-00C04FD430C8"}))
+		assert.Equal(t, []uint{1, 2}, find(Filter{Field: "Key", Op: IN, Value: "6ba7b8109dad11d180b400c04fd430c8,{6ba7b811-9dad-11d1-80b4-00c04fd430c8}"}))
+		assert.Equal(t, []uint{2}, find(Filter{Field: "Key", Op: NE, Value: "urn:uuid:6ba7b810-9dad-11d1-80b4-00c04fd430c8"}))
+	})
+
+	t.Run("Text storage", func(t *testing.T) {
+		assert.Equal(t, []uint{2}, find(Filter{Field: "Ref", Op: EQ, Value: "{6BA7B811-9DAD-11D1-80B4-00C04FD430C8}"}))
+		assert.Equal(t, []uint{1}, find(Filter{Field: "Ref", Op: NOT_IN, Value: "6ba7b8119dad11d180b400c04fd430c8"}))
+	})
+
+	t.Run("Invalid UUID", func(t *testing.T) {
+		var devices []TestDevice
+		err := builder.FindAll(&FilterRequest{Filters: []Filter{{Field: "Ref", Op: EQ, Value: "6ba7b810-9dad"}}}, &devices)
+		var invalid *InvalidValueError
+		if assert.ErrorAs(t, err, &invalid) {
+			assert.Equal(t, "not a UUID", invalid.Reason)
+		}
+	})
+}
@@ -0,0 +1,407 @@
+package querybuild
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Mode 请求中存在无效条目时的处理方式
+type Mode int
+
+const (
+	ModeDefault Mode = iota // 无效的字段报告错误，不支持的条件被忽略
+	ModeStrict              // 请求存在任何问题时整个请求失败
+	ModeLenient             // 跳过无效的条目，并通过警告处理函数报告
+)
+
+// Validate 校验请求中的字段、操作符、作用域与分页参数，不执行查询
+//
+// 发现问题时返回 *ValidationError，其中包含请求中的全部问题，便于接口层一次性返回给调用方。
+// 重复与互相矛盾的过滤条件不视为错误，可通过 Analyze 检查。适用于当前调用方的缺省规则与构建时相同应用，
+// 时间范围不符合 RangeRequire 规则时包含 ErrRangeExceeded。
+func (qb *QueryBuilder[T]) Validate(req *FilterRequest) error {
+	var errs []error
+	add := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	req = qb.skipEmptyFilters(req)
+	errs = append(errs, qb.limitErrors(req)...)
+	if defaulted, err := qb.applyDefaults(req); err != nil {
+		add(err)
+	} else {
+		req = defaulted
+	}
+	if qb.opts.maxComplexity > 0 {
+		if score := qb.Complexity(req); score > qb.opts.maxComplexity {
+			add(&QueryTooComplexError{Score: score, Max: qb.opts.maxComplexity})
+		}
+	}
+
+	for _, field := range req.CustomFields {
+		add(qb.checkCustomField(field))
+	}
+	add(qb.checkCustomFilter(req.CustomFilter))
+	for i := range req.CustomFilters {
+		add(qb.checkCustomFilter(&req.CustomFilters[i]))
+	}
+
+	aliases := make(map[string]string)
+	for _, join := range uniqueJoins(req.Joins) {
+		add(qb.checkJoin(join, aliases))
+	}
+	query := qb.validationQuery(aliases)
+	loc, err := qb.requestLocation(req)
+	add(err)
+	query = withLocation(query, loc)
+
+	for _, filter := range req.Filters {
+		add(qb.checkFilter(query, filter))
+	}
+	for _, group := range req.FilterGroups {
+		add(qb.checkFilterGroup(query, group))
+	}
+	for _, tuple := range req.TupleFilters {
+		add(qb.checkTupleFilter(query, tuple))
+	}
+	for _, exists := range req.Exists {
+		add(qb.checkExists(exists))
+	}
+	for _, count := range req.CountFilters {
+		add(qb.checkCountFilter(count))
+	}
+	for _, sort := range req.Sorts {
+		add(qb.checkSort(query, sort))
+	}
+	for _, group := range req.Groups {
+		add(qb.checkGroup(query, group))
+	}
+	for _, aggr := range req.Aggrs {
+		add(qb.checkAggregation(query, aggr, req.Groups))
+	}
+	add(qb.checkUnscoped(req))
+	add(checkSample(req))
+	add(checkPage(req.Page))
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: errs}
+}
+
+// lenientRequest 返回跳过无效条目后的请求副本及被跳过条目的警告，分页参数与原请求共享
+func (qb *QueryBuilder[T]) lenientRequest(req *FilterRequest) (*FilterRequest, []error) {
+	var warnings []error
+	keep := func(err error) bool {
+		if err != nil {
+			warnings = append(warnings, err)
+			return false
+		}
+		return true
+	}
+
+	result := *req
+	result.CustomFields = nil
+	for _, field := range req.CustomFields {
+		if keep(qb.checkCustomField(field)) {
+			result.CustomFields = append(result.CustomFields, field)
+		}
+	}
+	if !keep(qb.checkCustomFilter(req.CustomFilter)) {
+		result.CustomFilter = nil
+	}
+	result.CustomFilters = nil
+	for _, filter := range req.CustomFilters {
+		if keep(qb.checkCustomFilter(&filter)) {
+			result.CustomFilters = append(result.CustomFilters, filter)
+		}
+	}
+
+	aliases := make(map[string]string)
+	result.Joins = nil
+	for _, join := range uniqueJoins(req.Joins) {
+		if keep(qb.checkJoin(join, aliases)) {
+			result.Joins = append(result.Joins, join)
+		}
+	}
+	query := qb.validationQuery(aliases)
+	if loc, err := qb.requestLocation(req); keep(err) {
+		query = withLocation(query, loc)
+	} else {
+		result.TimeZone = ""
+	}
+
+	result.Filters = nil
+	for _, filter := range req.Filters {
+		if keep(qb.checkFilter(query, filter)) {
+			result.Filters = append(result.Filters, filter)
+		}
+	}
+	result.FilterGroups = nil
+	for _, group := range req.FilterGroups {
+		if keep(qb.checkFilterGroup(query, group)) {
+			result.FilterGroups = append(result.FilterGroups, group)
+		}
+	}
+	result.TupleFilters = nil
+	for _, tuple := range req.TupleFilters {
+		if keep(qb.checkTupleFilter(query, tuple)) {
+			result.TupleFilters = append(result.TupleFilters, tuple)
+		}
+	}
+	result.Exists = nil
+	for _, exists := range req.Exists {
+		if keep(qb.checkExists(exists)) {
+			result.Exists = append(result.Exists, exists)
+		}
+	}
+	result.CountFilters = nil
+	for _, count := range req.CountFilters {
+		if keep(qb.checkCountFilter(count)) {
+			result.CountFilters = append(result.CountFilters, count)
+		}
+	}
+	result.Sorts = nil
+	for _, sort := range req.Sorts {
+		if keep(qb.checkSort(query, sort)) {
+			result.Sorts = append(result.Sorts, sort)
+		}
+	}
+	result.Groups = nil
+	for _, group := range req.Groups {
+		if keep(qb.checkGroup(query, group)) {
+			result.Groups = append(result.Groups, group)
+		}
+	}
+	result.Aggrs = nil
+	for _, aggr := range req.Aggrs {
+		if keep(qb.checkAggregation(query, aggr, result.Groups)) {
+			result.Aggrs = append(result.Aggrs, aggr)
+		}
+	}
+	if !keep(qb.checkUnscoped(req)) {
+		result.Unscoped = false
+	}
+	if !keep(checkSample(&result)) {
+		result.Sample = nil
+	}
+	// 无效的分页参数在构建时按默认值规范化
+	keep(checkPage(req.Page))
+
+	return &result, warnings
+}
+
+// applyMode 按构建器的处理方式预处理请求，严格模式下请求无效时返回错误
+func (qb *QueryBuilder[T]) applyMode(req *FilterRequest) (*FilterRequest, error) {
+	switch qb.opts.mode {
+	case ModeStrict:
+		if err := qb.Validate(req); err != nil {
+			return req, err
+		}
+	case ModeLenient:
+		lenient, warnings := qb.lenientRequest(req)
+		if qb.opts.onWarning != nil {
+			for _, warning := range warnings {
+				qb.opts.onWarning(warning)
+			}
+		}
+		return lenient, nil
+	}
+	return req, nil
+}
+
+// validationQuery 创建用于解析字段的 DryRun 查询，并记录连接表别名
+func (qb *QueryBuilder[T]) validationQuery(aliases map[string]string) *gorm.DB {
+	query := qb.newQuery().Session(&gorm.Session{DryRun: true})
+	if len(aliases) > 0 {
+		query = query.InstanceSet(joinAliasesKey, aliases)
+	}
+	return query
+}
+
+// checkCustomField 校验自定义字段的作用域
+func (qb *QueryBuilder[T]) checkCustomField(field CustomField) error {
+	if _, ok := qb.registry.Get(SelectScope, field.ScopeName); !ok {
+		return fmt.Errorf("unknown select scope: %s", field.ScopeName)
+	}
+	return nil
+}
+
+// checkCustomFilter 校验自定义过滤条件的作用域
+func (qb *QueryBuilder[T]) checkCustomFilter(filter *CustomFilter) error {
+	if filter == nil || filter.ScopeName == "" {
+		return nil
+	}
+	if _, ok := qb.registry.Get(FilterScope, filter.ScopeName); !ok {
+		return fmt.Errorf("unknown filter scope: %s", filter.ScopeName)
+	}
+	return nil
+}
+
+// checkJoin 校验连接，合法的别名记录到 aliases 中
+func (qb *QueryBuilder[T]) checkJoin(join Join, aliases map[string]string) error {
+	if join.Type == "" && join.ScopeName != "" {
+		if _, ok := qb.registry.Get(JoinScope, join.ScopeName); !ok {
+			return fmt.Errorf("unknown join scope: %s", join.ScopeName)
+		}
+		return nil
+	}
+
+	switch strings.ToUpper(join.Type) {
+	case "LEFT", "RIGHT", "INNER":
+	default:
+		return fmt.Errorf("unsupported join type: %s", join.Type)
+	}
+
+	if join.Alias != "" {
+		if !isIdentifier(join.Alias) {
+			return fmt.Errorf("invalid join alias: %s", join.Alias)
+		}
+		if _, ok := aliases[join.Alias]; ok {
+			return fmt.Errorf("duplicate join alias: %s", join.Alias)
+		}
+		aliases[join.Alias] = join.Table
+	}
+	return nil
+}
+
+// checkFilter 校验过滤条件的字段、操作符与取值
+func (qb *QueryBuilder[T]) checkFilter(query *gorm.DB, filter Filter) error {
+	field, err := qb.filterField(query, filter)
+	if err != nil {
+		return err
+	}
+	if err := qb.checkOperator(filter.Op); err != nil {
+		return err
+	}
+	if qb.typedFilter(query, filter) {
+		if _, _, err := qb.filterCondition(query, field, filter); err != nil {
+			return err
+		}
+		return qb.checkFilterValue(filter)
+	}
+	if conditionSQL(field, filter) == "" {
+		return fmt.Errorf("unsupported operator %s on field: %s", filter.Op, filter.Field)
+	}
+	if _, ok := conditionArgs(filter); !ok {
+		return fmt.Errorf("invalid value for operator %s on field %s: %s", filter.Op, filter.Field, filter.Value)
+	}
+	return qb.checkFilterValue(filter)
+}
+
+// checkExists 校验关联存在性过滤条件
+func (qb *QueryBuilder[T]) checkExists(filter ExistsFilter) error {
+	if filter.All {
+		_, _, err := qb.allOfCondition(filter)
+		return err
+	}
+	if filter.LeftJoin {
+		_, _, err := qb.antiJoin(filter, "anti")
+		return err
+	}
+	_, err := qb.existsSubQuery(qb.schema, qb.tableName(), filter)
+	return err
+}
+
+// checkCountFilter 校验关联数量过滤条件
+func (qb *QueryBuilder[T]) checkCountFilter(filter CountFilter) error {
+	if _, err := qb.relationSubQuery(qb.schema, qb.tableName(), filter.Relation, filter.Filter, "COUNT(*)"); err != nil {
+		return err
+	}
+	_, _, err := countCondition(filter)
+	return err
+}
+
+// checkSort 校验排序字段、排序作用域与关联数量排序
+func (qb *QueryBuilder[T]) checkSort(query *gorm.DB, sort Sort) error {
+	if sort.ScopeName != "" {
+		if _, ok := qb.registry.Get(SortScope, sort.ScopeName); ok {
+			return nil
+		}
+		if sort.Field == "" && sort.CountOf == "" {
+			return fmt.Errorf("unknown sort scope: %s", sort.ScopeName)
+		}
+	}
+
+	if sort.CountOf != "" {
+		if err := qb.checkSortAllowed(sort.CountOf); err != nil {
+			return err
+		}
+		_, err := qb.countSortExpr(sort)
+		return err
+	}
+
+	if err := qb.checkSortAllowed(sort.Field); err != nil {
+		return err
+	}
+	field, err := qb.resolveField(query, sort.Field)
+	if err != nil || len(sort.Values) > 0 {
+		return err
+	}
+	_, err = qb.sortField(field, sort)
+	return err
+}
+
+// checkGroup 校验分组字段与分组作用域
+func (qb *QueryBuilder[T]) checkGroup(query *gorm.DB, group Group) error {
+	if group.ScopeName != "" {
+		if _, ok := qb.registry.Get(GroupScope, group.ScopeName); ok {
+			return nil
+		}
+		if group.Field == "" {
+			return fmt.Errorf("unknown group scope: %s", group.ScopeName)
+		}
+	}
+
+	if _, err := qb.resolveField(query, group.Field); err != nil {
+		return err
+	}
+	if group.Having != "" {
+		return fmt.Errorf("having conditions must be implemented via ScopeFunc")
+	}
+	return nil
+}
+
+// checkAggregation 校验聚合字段、聚合操作、窗口计算与附加选择字段
+func (qb *QueryBuilder[T]) checkAggregation(query *gorm.DB, aggr Aggregation, groups []Group) error {
+	var errs []error
+	field, fieldArgs, err := qb.aggregateField(query, aggr)
+	if err != nil {
+		errs = append(errs, err)
+	} else {
+		expr, _, err := qb.conditionalAggregateExpr(query, field, fieldArgs, aggr)
+		switch {
+		case err != nil:
+			errs = append(errs, err)
+		case expr == "":
+			errs = append(errs, fmt.Errorf("unsupported aggregation: %s", aggr.Op))
+		case aggr.Window != nil:
+			if _, _, err := qb.windowExpr(query, expr, aggr.Window, groups); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	for _, name := range aggr.AddSelects {
+		if _, ok := qb.expressions.Get(name); ok {
+			continue
+		}
+		if _, err := qb.resolveField(query, name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkPage 校验分页参数
+func checkPage(page *Pagination) error {
+	if page != nil && (page.Page < 0 || page.PageSize < 0) {
+		return fmt.Errorf("invalid pagination: page %d, page size %d", page.Page, page.PageSize)
+	}
+	return nil
+}
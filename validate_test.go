@@ -0,0 +1,114 @@
+package querybuild
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_Validate(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db, AllowSortFields("Name"))
+
+	t.Run("Valid request", func(t *testing.T) {
+		err := builder.Validate(&FilterRequest{
+			Filters: []Filter{
+				{Field: "Status", Op: EQ, Value: "active"},
+				{Field: "o.amount", Op: GT, Value: "10"},
+			},
+			Joins:  []Join{{Type: "LEFT", Table: "test_orders", Alias: "o", Condition: "o.user_id = test_users.id"}},
+			Exists: []ExistsFilter{{Relation: "Orders"}},
+			Sorts:  []Sort{{Field: "Name"}},
+			Aggrs:  []Aggregation{{Field: "Age", Op: AVG}},
+			Groups: []Group{{Field: "Status"}},
+			Page:   &Pagination{Page: 1, PageSize: 10},
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("All problems reported", func(t *testing.T) {
+		err := builder.Validate(&FilterRequest{
+			Filters: []Filter{
+				{Field: "Unknown", Op: EQ, Value: "x"},
+				{Field: "Age", Op: BETWEEN, Value: "1"},
+				{Field: "Age", Op: Operator(99), Value: "1"},
+			},
+			CustomFilter: &CustomFilter{ScopeName: "missing"},
+			Exists:       []ExistsFilter{{Relation: "Missing"}},
+			Sorts:        []Sort{{Field: "Age"}},
+			Aggrs:        []Aggregation{{Field: "Age", Op: UNKNOWN_OP}},
+			Page:         &Pagination{Page: -1},
+		})
+
+		var validationErr *ValidationError
+		assert.True(t, errors.As(err, &validationErr))
+		assert.Len(t, validationErr.Errors, 8)
+
+		var sortErr *SortNotAllowedError
+		assert.ErrorAs(t, err, &sortErr)
+		assert.Equal(t, "Age", sortErr.Field)
+		assert.Contains(t, err.Error(), "invalid field name: Unknown")
+		assert.Contains(t, err.Error(), "unknown filter scope: missing")
+	})
+
+	t.Run("Default range rules", func(t *testing.T) {
+		bounded := builder.Clone(WithDefaults(DefaultRule{
+			Name:  "bounded",
+			Range: &DateRange{Field: "CreatedAt", Max: 72 * time.Hour, Enforce: RangeRequire},
+		}))
+		err := bounded.Validate(&FilterRequest{})
+		assert.ErrorIs(t, err, ErrRangeExceeded)
+		_, countErr := bounded.Count(&FilterRequest{})
+		assert.ErrorIs(t, countErr, ErrRangeExceeded)
+
+		since := time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339)
+		until := time.Now().UTC().Format(time.RFC3339)
+		assert.NoError(t, bounded.Validate(&FilterRequest{
+			Filters: []Filter{{Field: "CreatedAt", Op: BETWEEN, Value: since + "," + until}},
+		}))
+	})
+}
+
+func TestQueryBuilder_Modes(t *testing.T) {
+	db := setupTestDB(t)
+	req := func() *FilterRequest {
+		return &FilterRequest{
+			Filters: []Filter{
+				{Field: "Status", Op: EQ, Value: "active"},
+				{Field: "Age", Op: BETWEEN, Value: "30"},
+				{Field: "Unknown", Op: EQ, Value: "x"},
+			},
+		}
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		var users []TestUser
+		err := NewQueryBuilder[TestUser](db).Build(req()).Find(&users).Error
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid field name: Unknown")
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		var users []TestUser
+		err := NewQueryBuilder[TestUser](db, WithStrict()).Build(req()).Find(&users).Error
+		var validationErr *ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Len(t, validationErr.Errors, 2)
+	})
+
+	t.Run("Lenient", func(t *testing.T) {
+		var warnings []error
+		builder := NewQueryBuilder[TestUser](db, WithLenient(func(err error) {
+			warnings = append(warnings, err)
+		}))
+
+		original := req()
+		var users []TestUser
+		assert.NoError(t, builder.Build(original).Find(&users).Error)
+		assert.Len(t, users, 2)
+		assert.Len(t, warnings, 2)
+		assert.Len(t, original.Filters, 3, "request is not modified")
+	})
+}
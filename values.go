@@ -0,0 +1,230 @@
+package querybuild
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maskedValueAlias 以SQL脱敏表达式取字段值时表达式的别名
+const maskedValueAlias = "masked_value"
+
+// Pluck 查询符合条件记录的单个字段值
+func (qb *QueryBuilder[T]) Pluck(req *FilterRequest, field string) ([]interface{}, error) {
+	query := qb.Build(req)
+	column, err := qb.resolveField(query, field)
+	if err != nil {
+		return nil, err
+	}
+
+	query, column = qb.valueColumn(query, field, column)
+
+	var values []interface{}
+	if err := query.Pluck(column, &values).Error; err != nil {
+		return nil, err
+	}
+	if err := qb.decryptValues(query, field, values); err != nil {
+		return nil, err
+	}
+	qb.maskValues(query, field, values)
+	return values, nil
+}
+
+// DistinctValues 查询符合条件记录中字段的去重取值，按取值升序排列，limit 小于等于 0 时不限制数量
+func (qb *QueryBuilder[T]) DistinctValues(req *FilterRequest, field string, limit int) ([]interface{}, error) {
+	query := qb.filterQuery(req)
+	column, err := qb.resolveField(query, field)
+	if err != nil {
+		return nil, err
+	}
+
+	query, column = qb.valueColumn(query.Distinct(), field, column)
+	query = query.Order(fmt.Sprintf("%s ASC", column))
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var values []interface{}
+	if err := query.Pluck(column, &values).Error; err != nil {
+		return nil, err
+	}
+	if err := qb.decryptValues(query, field, values); err != nil {
+		return nil, err
+	}
+	qb.maskValues(query, field, values)
+	return values, nil
+}
+
+// valueColumn 返回取单个字段值的查询与取值使用的列
+//
+// 字段配置了SQL脱敏表达式时以别名选择表达式并返回别名，避免表达式被作为列名引用。
+func (qb *QueryBuilder[T]) valueColumn(query *gorm.DB, field, column string) (*gorm.DB, string) {
+	masked := qb.maskedColumn(query, field, column)
+	if masked == column {
+		return query, column
+	}
+	return query.Clauses(clause.Select{
+		Distinct:   query.Statement.Distinct,
+		Expression: clause.Expr{SQL: masked + " AS " + maskedValueAlias},
+	}), maskedValueAlias
+}
+
+// filterQuery 构建仅包含连接与过滤条件的查询，不含排序、分组、聚合与分页
+//
+// 请求与构建完整查询时相同整理并校验，缺省过滤条件与时间范围规则同样生效。
+func (qb *QueryBuilder[T]) filterQuery(req *FilterRequest) *gorm.DB {
+	query, req, ok := qb.prepareQuery(req)
+	if !ok {
+		return query
+	}
+	query = qb.applyJoins(query, req.Joins)
+	return qb.applyConditions(query, req)
+}
+
+// FacetBucket 分面统计中的单个取值及其数量
+type FacetBucket struct {
+	Value interface{} `json:"value"`
+	Count int64       `json:"count"`
+}
+
+// Facets 统计当前过滤条件下各字段的取值分布，统计某字段时忽略该字段自身的过滤条件
+//
+// 加密字段的取值解密后返回，配置了脱敏规则的字段按脱敏后的取值统计。
+func (qb *QueryBuilder[T]) Facets(req *FilterRequest, fields ...string) (map[string][]FacetBucket, error) {
+	facets := make(map[string][]FacetBucket, len(fields))
+	for _, field := range fields {
+		facetReq := *req
+		facetReq.Filters = make([]Filter, 0, len(req.Filters))
+		for _, filter := range req.Filters {
+			if filter.Field != field {
+				facetReq.Filters = append(facetReq.Filters, filter)
+			}
+		}
+
+		query := qb.filterQuery(&facetReq)
+		column, err := qb.resolveField(query, field)
+		if err != nil {
+			return nil, err
+		}
+
+		// 配置了SQL脱敏表达式的字段按别名分组，避免表达式被作为列名引用
+		group := column
+		if masked := qb.maskedColumn(query, field, column); masked != column {
+			column, group = masked, "facet_value"
+		}
+		var rows []map[string]interface{}
+		err = query.
+			Select(fmt.Sprintf("%s AS facet_value, COUNT(*) AS facet_count", column)).
+			Group(group).
+			Order("facet_count DESC, facet_value ASC").
+			Find(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+
+		values := make([]interface{}, len(rows))
+		for i, row := range rows {
+			values[i] = row["facet_value"]
+		}
+		if err := qb.decryptValues(query, field, values); err != nil {
+			return nil, err
+		}
+		qb.maskValues(query, field, values)
+
+		// 脱敏函数可能将不同取值替换为相同结果，相同取值的数量合并后重新按数量排序
+		buckets := make([]FacetBucket, 0, len(rows))
+		index := make(map[string]int, len(rows))
+		for i, row := range rows {
+			key := countKey(values[i])
+			if j, ok := index[key]; ok {
+				buckets[j].Count += toInt64(row["facet_count"])
+				continue
+			}
+			index[key] = len(buckets)
+			buckets = append(buckets, FacetBucket{Value: values[i], Count: toInt64(row["facet_count"])})
+		}
+		sort.SliceStable(buckets, func(i, j int) bool { return buckets[i].Count > buckets[j].Count })
+		facets[field] = buckets
+	}
+	return facets, nil
+}
+
+// CountBy 统计当前过滤条件下字段各取值的记录数，返回取值到数量的映射
+//
+// 取值按字符串作为键，NULL 对应空字符串，时间取值使用 RFC3339 格式；配置了脱敏规则的字段按脱敏后的取值统计。
+func (qb *QueryBuilder[T]) CountBy(req *FilterRequest, field string) (map[string]int64, error) {
+	query := qb.filterQuery(req)
+	column, err := qb.resolveField(query, field)
+	if err != nil {
+		return nil, err
+	}
+
+	// 配置了SQL脱敏表达式的字段按别名分组，避免表达式被作为列名引用
+	group := column
+	if masked := qb.maskedColumn(query, field, column); masked != column {
+		column, group = masked, "count_value"
+	}
+	var rows []map[string]interface{}
+	err = query.
+		Select(fmt.Sprintf("%s AS count_value, COUNT(*) AS count_total", column)).
+		Group(group).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(rows))
+	for i, row := range rows {
+		values[i] = row["count_value"]
+	}
+	if err := qb.decryptValues(query, field, values); err != nil {
+		return nil, err
+	}
+	qb.maskValues(query, field, values)
+
+	counts := make(map[string]int64, len(rows))
+	for i, row := range rows {
+		counts[countKey(values[i])] += toInt64(row["count_total"])
+	}
+	return counts, nil
+}
+
+// countKey 将分组取值转换为 CountBy 结果的键
+func countKey(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+// toInt64 将驱动返回的计数值转换为 int64
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case int32:
+		return int64(n)
+	case int:
+		return int64(n)
+	case uint64:
+		return int64(n)
+	case float64:
+		return int64(n)
+	case []byte:
+		i, _ := strconv.ParseInt(string(n), 10, 64)
+		return i
+	case string:
+		i, _ := strconv.ParseInt(n, 10, 64)
+		return i
+	}
+	return 0
+}
@@ -0,0 +1,176 @@
+package querybuild
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_Pluck(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+
+	t.Run("Pluck filtered values", func(t *testing.T) {
+		values, err := builder.Pluck(&FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+			Sorts:   []Sort{{Field: "Age"}},
+		}, "Name")
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{"John Doe", "Bob Johnson"}, values)
+	})
+
+	t.Run("Invalid field", func(t *testing.T) {
+		_, err := builder.Pluck(&FilterRequest{}, "Password")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid field name")
+	})
+}
+
+func TestQueryBuilder_DistinctValues(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+
+	t.Run("All distinct values", func(t *testing.T) {
+		values, err := builder.DistinctValues(&FilterRequest{}, "Status", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{"active", "inactive"}, values)
+	})
+
+	t.Run("Distinct values under filters with limit", func(t *testing.T) {
+		values, err := builder.DistinctValues(&FilterRequest{
+			Filters: []Filter{{Field: "Age", Op: GE, Value: "30"}},
+		}, "Status", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{"active"}, values)
+	})
+
+	t.Run("Invalid field", func(t *testing.T) {
+		_, err := builder.DistinctValues(&FilterRequest{}, "Password", 10)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid field name")
+	})
+}
+
+func TestQueryBuilder_Facets(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+
+	t.Run("Facets exclude own filter", func(t *testing.T) {
+		facets, err := builder.Facets(&FilterRequest{
+			Filters: []Filter{
+				{Field: "Status", Op: EQ, Value: "active"},
+				{Field: "Age", Op: LE, Value: "30"},
+			},
+		}, "Status", "Age")
+		assert.NoError(t, err)
+
+		// Status 分面只受 Age 条件约束
+		assert.Equal(t, []FacetBucket{
+			{Value: "active", Count: 1},
+			{Value: "inactive", Count: 1},
+		}, facets["Status"])
+
+		// Age 分面只受 Status 条件约束
+		assert.Len(t, facets["Age"], 2)
+		for _, bucket := range facets["Age"] {
+			assert.Equal(t, int64(1), bucket.Count)
+		}
+	})
+
+	t.Run("Invalid facet field", func(t *testing.T) {
+		_, err := builder.Facets(&FilterRequest{}, "Password")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid field name")
+	})
+}
+
+func TestQueryBuilder_CountBy(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestUser](db)
+
+	t.Run("Count by field", func(t *testing.T) {
+		counts, err := builder.CountBy(&FilterRequest{}, "Status")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int64{"active": 2, "inactive": 1}, counts)
+
+		counts, err = builder.CountBy(&FilterRequest{
+			Filters: []Filter{{Field: "Age", Op: GE, Value: "30"}},
+		}, "Age")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int64{"30": 1, "35": 1}, counts)
+	})
+
+	t.Run("Masked values are merged", func(t *testing.T) {
+		masked := builder.Clone(WithMaskFunc("Status", func(interface{}) interface{} { return "***" }))
+		counts, err := masked.CountBy(&FilterRequest{}, "Status")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int64{"***": 3}, counts)
+	})
+
+	t.Run("Invalid field", func(t *testing.T) {
+		_, err := builder.CountBy(&FilterRequest{}, "Password")
+		assert.Error(t, err)
+	})
+}
+
+func TestQueryBuilder_ValuesRequestRules(t *testing.T) {
+	db := setupTestDB(t)
+
+	t.Run("Default filters", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db, WithDefaults(DefaultRule{
+			Name:    "active",
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+		}))
+		counts, err := builder.CountBy(&FilterRequest{}, "Status")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int64{"active": 2}, counts)
+
+		values, err := builder.DistinctValues(&FilterRequest{}, "Status", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{"active"}, values)
+	})
+
+	t.Run("Range rules", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db, WithLocation(time.UTC), WithDefaults(DefaultRule{
+			Name:  "bounded",
+			Range: &DateRange{Field: "CreatedAt", Max: 72 * time.Hour, Enforce: RangeRequire},
+		}))
+		_, err := builder.CountBy(&FilterRequest{}, "Status")
+		assert.ErrorIs(t, err, ErrRangeExceeded)
+		_, err = builder.DistinctValues(&FilterRequest{}, "Status", 0)
+		assert.ErrorIs(t, err, ErrRangeExceeded)
+		_, err = builder.Facets(&FilterRequest{}, "Status")
+		assert.ErrorIs(t, err, ErrRangeExceeded)
+	})
+
+	t.Run("Limits", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db, WithLimits(Limits{MaxFilters: 1}))
+		req := &FilterRequest{Filters: []Filter{
+			{Field: "Status", Op: EQ, Value: "active"},
+			{Field: "Age", Op: GE, Value: "30"},
+		}}
+		var limitErr *LimitExceededError
+		_, err := builder.CountBy(req, "Status")
+		assert.ErrorAs(t, err, &limitErr)
+		_, err = builder.Facets(req, "Name")
+		assert.ErrorAs(t, err, &limitErr)
+	})
+
+	t.Run("Strict mode", func(t *testing.T) {
+		builder := NewQueryBuilder[TestUser](db, WithStrict())
+		_, err := builder.DistinctValues(&FilterRequest{Filters: []Filter{{Field: "status", Op: EQ, Value: "active"}}}, "Name", 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("Enum labels", func(t *testing.T) {
+		builder := NewQueryBuilder[labeledUser](db, WithValueValidation())
+		counts, err := builder.CountBy(&FilterRequest{Filters: []Filter{{Field: "Status", Op: EQ, Value: "已激活"}}}, "Status")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int64{"active": 2}, counts)
+
+		_, err = builder.CountBy(&FilterRequest{Filters: []Filter{{Field: "Status", Op: EQ, Value: "已删除"}}}, "Status")
+		var valueErr *InvalidValueError
+		assert.ErrorAs(t, err, &valueErr)
+	})
+}
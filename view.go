@@ -0,0 +1,65 @@
+package querybuild
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ErrReadOnly 对只读构建器（如视图）执行写入
+var ErrReadOnly = errors.New("query builder is read-only")
+
+// NewViewQueryBuilder 创建以数据库视图为查询目标的只读构建器
+//
+// 模型仅描述视图的列，无需对应可迁移的表；过滤、排序、聚合、分组与分页与普通构建器相同，
+// UpdateAll、UpdateExpr、DeleteAll、FirstOrCreate 与 Upsert 返回 ErrReadOnly。
+// 视图没有主键时可通过 WithKeyFields 指定唯一标识记录的列。
+func NewViewQueryBuilder[T any](db *gorm.DB, view string, opts ...Option) *QueryBuilder[T] {
+	return NewQueryBuilder[T](db, append([]Option{WithTable(view), WithReadOnly()}, opts...)...)
+}
+
+// WithReadOnly 将构建器设为只读，写入方法返回 ErrReadOnly
+func WithReadOnly() Option {
+	return func(o *options) {
+		o.readOnly = true
+	}
+}
+
+// WithKeyFields 指定唯一标识记录的字段，替代模型的主键
+//
+// 用于视图等没有主键的模型：分页的稳定排序、游标分页、按主键去重、延迟连接与增量同步均使用这些字段。
+// 字段不存在时构建的查询返回错误。
+func WithKeyFields(fields ...string) Option {
+	return func(o *options) {
+		o.keyFields = append([]string(nil), fields...)
+	}
+}
+
+// checkWritable 校验构建器是否允许写入
+func (qb *QueryBuilder[T]) checkWritable() error {
+	if qb.opts.readOnly {
+		return ErrReadOnly
+	}
+	return nil
+}
+
+// resolveKeys 解析唯一标识记录的字段，未指定时使用模型的主键
+func (qb *QueryBuilder[T]) resolveKeys() ([]*schema.Field, error) {
+	if len(qb.opts.keyFields) == 0 {
+		if qb.schema == nil {
+			return nil, nil
+		}
+		return qb.schema.PrimaryFields, nil
+	}
+	keys := make([]*schema.Field, 0, len(qb.opts.keyFields))
+	for _, name := range qb.opts.keyFields {
+		field := qb.schema.LookUpField(name)
+		if field == nil || field.DBName == "" {
+			return nil, fmt.Errorf("invalid key field: %s", name)
+		}
+		keys = append(keys, field)
+	}
+	return keys, nil
+}
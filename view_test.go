@@ -0,0 +1,88 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// testUserSummary 基于视图的只读模型，没有主键
+type testUserSummary struct {
+	UserID     uint
+	Name       string
+	Status     string
+	OrderTotal float64
+}
+
+// setupViewDB 创建汇总用户订单金额的视图
+func setupViewDB(t *testing.T) *gorm.DB {
+	db := setupTestDB(t)
+	assert.NoError(t, db.Exec(`CREATE VIEW user_summaries AS
+		SELECT u.id AS user_id, u.name, u.status, COALESCE(SUM(o.amount), 0) AS order_total
+		FROM test_users u LEFT JOIN test_orders o ON o.user_id = u.id
+		GROUP BY u.id, u.name, u.status`).Error)
+	return db
+}
+
+func TestQueryBuilder_View(t *testing.T) {
+	db := setupViewDB(t)
+	builder := NewViewQueryBuilder[testUserSummary](db, "user_summaries", WithKeyFields("UserID"), WithCursorSecret([]byte("secret")))
+
+	t.Run("Filter and sort", func(t *testing.T) {
+		var rows []testUserSummary
+		assert.NoError(t, builder.FindAll(&FilterRequest{
+			Filters: []Filter{{Field: "Status", Op: EQ, Value: "active"}},
+			Sorts:   []Sort{{Field: "OrderTotal", Desc: true}},
+		}, &rows))
+		if assert.Len(t, rows, 2) {
+			assert.Equal(t, "John Doe", rows[0].Name)
+			assert.Equal(t, 150.0, rows[0].OrderTotal)
+			assert.Equal(t, 0.0, rows[1].OrderTotal)
+		}
+	})
+
+	t.Run("Aggregate", func(t *testing.T) {
+		type result struct {
+			Status string
+			Total  float64
+		}
+		var results []result
+		assert.NoError(t, builder.FindAll(&FilterRequest{
+			Groups: []Group{{Field: "Status"}},
+			Aggrs:  []Aggregation{{Field: "OrderTotal", Op: SUM, Alias: "total"}},
+			Sorts:  []Sort{{Field: "Status"}},
+		}, &results))
+		assert.Equal(t, []result{{Status: "active", Total: 150}, {Status: "inactive", Total: 80}}, results)
+	})
+
+	t.Run("Key fields", func(t *testing.T) {
+		req := &FilterRequest{Sorts: []Sort{{Field: "Status"}}, Page: &Pagination{Page: 1, PageSize: 2}}
+		assert.Equal(t, []Sort{{Field: "Status"}, {Field: "UserID"}}, builder.tieBreakSorts(req, req.Sorts))
+
+		page, err := builder.FindCursor(&FilterRequest{}, "", 2)
+		assert.NoError(t, err)
+		if assert.Len(t, page.Items, 2) {
+			next, err := builder.FindCursor(&FilterRequest{}, page.NextCursor, 2)
+			assert.NoError(t, err)
+			if assert.Len(t, next.Items, 1) {
+				assert.Equal(t, "Bob Johnson", next.Items[0].Name)
+			}
+		}
+
+		invalid := NewViewQueryBuilder[testUserSummary](db, "user_summaries", WithKeyFields("Missing"))
+		_, err = invalid.Count(&FilterRequest{})
+		assert.ErrorContains(t, err, "invalid key field: Missing")
+	})
+
+	t.Run("Read only", func(t *testing.T) {
+		req := &FilterRequest{Filters: []Filter{{Field: "UserID", Op: EQ, Value: "1"}}}
+		_, err := builder.UpdateAll(req, map[string]interface{}{"Name": "x"})
+		assert.ErrorIs(t, err, ErrReadOnly)
+		_, err = builder.DeleteAll(req)
+		assert.ErrorIs(t, err, ErrReadOnly)
+		assert.ErrorIs(t, builder.Upsert(req, &testUserSummary{}), ErrReadOnly)
+		_, _, err = builder.FirstOrCreate(req, testUserSummary{})
+		assert.ErrorIs(t, err, ErrReadOnly)
+	})
+}
@@ -0,0 +1,100 @@
+package querybuild
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// WindowOp 聚合结果的窗口计算类型
+type WindowOp string
+
+const (
+	RUNNING_TOTAL       WindowOp = "running_total"       // 截至当前周期的累计值
+	PREVIOUS_PERIOD     WindowOp = "previous_period"     // 上一周期的值
+	PERIOD_CHANGE       WindowOp = "period_change"       // 与上一周期的差值
+	PERIOD_CHANGE_RATIO WindowOp = "period_change_ratio" // 相对上一周期的变化率，上一周期为 0 或不存在时为 NULL
+)
+
+// Window 按周期分组的聚合结果的窗口计算，如累计值与环比
+//
+// 周期字段按升序排列，其余分组字段作为窗口分区，如按 Status 与 Month 分组时分别计算各状态的累计值。
+type Window struct {
+	Op     WindowOp `json:"op"`
+	Period string   `json:"period"` // 周期字段，需为分组字段，为空时使用唯一的时间类型分组字段
+}
+
+// windowExpr 以窗口计算包装聚合表达式，返回包装后的表达式及聚合表达式的引用次数
+func (qb *QueryBuilder[T]) windowExpr(query *gorm.DB, expr string, window *Window, groups []Group) (string, int, error) {
+	over, err := qb.windowClause(query, window, groups)
+	if err != nil {
+		return "", 0, err
+	}
+	previous := "LAG(" + expr + ") " + over
+	switch window.Op {
+	case RUNNING_TOTAL:
+		return "SUM(" + expr + ") " + over, 1, nil
+	case PREVIOUS_PERIOD:
+		return previous, 1, nil
+	case PERIOD_CHANGE:
+		return "(" + expr + " - " + previous + ")", 2, nil
+	case PERIOD_CHANGE_RATIO:
+		return "((" + expr + " - " + previous + ") * 1.0 / NULLIF(" + previous + ", 0))", 3, nil
+	}
+	return "", 0, fmt.Errorf("invalid window operation: %s", window.Op)
+}
+
+// windowClause 生成按周期字段排序、按其余分组字段分区的 OVER 子句
+func (qb *QueryBuilder[T]) windowClause(query *gorm.DB, window *Window, groups []Group) (string, error) {
+	period, err := qb.windowPeriod(window, groups)
+	if err != nil {
+		return "", err
+	}
+	var partitions []string
+	var order string
+	for _, group := range groups {
+		column, err := qb.resolveField(query, group.Field)
+		if err != nil {
+			return "", err
+		}
+		if group.Field == period {
+			order = column
+		} else {
+			partitions = append(partitions, column)
+		}
+	}
+
+	over := "OVER ("
+	if len(partitions) > 0 {
+		over += "PARTITION BY " + strings.Join(partitions, ", ") + " "
+	}
+	return over + "ORDER BY " + order + ")", nil
+}
+
+// windowPeriod 确定窗口计算的周期字段，分组需均为字段分组
+func (qb *QueryBuilder[T]) windowPeriod(window *Window, groups []Group) (string, error) {
+	if len(groups) == 0 {
+		return "", fmt.Errorf("window %s requires grouped aggregations", window.Op)
+	}
+	var timeGroups []string
+	for _, group := range groups {
+		if group.ScopeName != "" || group.Field == "" {
+			return "", fmt.Errorf("window %s only supports field groups", window.Op)
+		}
+		if window.Period != "" && group.Field == window.Period {
+			return group.Field, nil
+		}
+		if field := qb.schema.LookUpField(group.Field); field != nil && field.DataType == schema.Time {
+			timeGroups = append(timeGroups, group.Field)
+		}
+	}
+	if window.Period != "" {
+		return "", fmt.Errorf("window period must be a group field: %s", window.Period)
+	}
+	if len(timeGroups) != 1 {
+		return "", fmt.Errorf("window %s requires a period field", window.Op)
+	}
+	return timeGroups[0], nil
+}
@@ -0,0 +1,81 @@
+package querybuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestQueryBuilder_WindowAggregations(t *testing.T) {
+	db := setupTestDB(t)
+	builder := NewQueryBuilder[TestOrder](db)
+
+	t.Run("Running total and period over period", func(t *testing.T) {
+		type Result struct {
+			UserID uint     `gorm:"column:user_id"`
+			Amount float64  `gorm:"column:amount"`
+			Total  float64  `gorm:"column:total"`
+			Prev   *float64 `gorm:"column:prev"`
+			Change *float64 `gorm:"column:change"`
+			Ratio  *float64 `gorm:"column:ratio"`
+		}
+		var results []Result
+		err := builder.FindAll(&FilterRequest{
+			Groups: []Group{{Field: "UserID"}},
+			Sorts:  []Sort{{Field: "UserID"}},
+			Aggrs: []Aggregation{
+				{Field: "Amount", Op: SUM},
+				{Field: "Amount", Op: SUM, Alias: "total", Window: &Window{Op: RUNNING_TOTAL, Period: "UserID"}},
+				{Field: "Amount", Op: SUM, Alias: "prev", Window: &Window{Op: PREVIOUS_PERIOD, Period: "UserID"}},
+				{Field: "Amount", Op: SUM, Alias: "change", Window: &Window{Op: PERIOD_CHANGE, Period: "UserID"}},
+				{Field: "Amount", Op: SUM, Alias: "ratio", Window: &Window{Op: PERIOD_CHANGE_RATIO, Period: "UserID"}},
+			},
+		}, &results)
+		assert.NoError(t, err)
+		if assert.Len(t, results, 2) {
+			assert.Equal(t, float64(150), results[0].Total)
+			assert.Nil(t, results[0].Prev)
+			assert.Nil(t, results[0].Change)
+			assert.Nil(t, results[0].Ratio)
+
+			assert.Equal(t, float64(230), results[1].Total)
+			assert.Equal(t, float64(150), *results[1].Prev)
+			assert.Equal(t, float64(-70), *results[1].Change)
+			assert.InDelta(t, -70.0/150, *results[1].Ratio, 1e-9)
+		}
+	})
+
+	t.Run("Partition by other groups", func(t *testing.T) {
+		users := NewQueryBuilder[TestUser](db.Session(&gorm.Session{DryRun: true}))
+		query := users.Build(&FilterRequest{
+			Groups: []Group{{Field: "Status"}, {Field: "CreatedAt"}},
+			Aggrs: []Aggregation{
+				{Field: "ID", Op: COUNT, Alias: "signups", Window: &Window{Op: RUNNING_TOTAL}},
+			},
+		}).Find(&[]map[string]interface{}{})
+		assert.NoError(t, query.Error)
+		assert.Contains(t, query.Statement.SQL.String(),
+			"SUM(COUNT(`test_users`.`id`)) OVER (PARTITION BY `test_users`.`status` ORDER BY `test_users`.`created_at`) as signups")
+	})
+
+	t.Run("Invalid period", func(t *testing.T) {
+		var results []map[string]interface{}
+		err := builder.FindAll(&FilterRequest{
+			Groups: []Group{{Field: "Status"}},
+			Aggrs:  []Aggregation{{Field: "Amount", Op: SUM, Window: &Window{Op: RUNNING_TOTAL}}},
+		}, &results)
+		assert.ErrorContains(t, err, "requires a period field")
+
+		err = builder.FindAll(&FilterRequest{
+			Groups: []Group{{Field: "Status"}},
+			Aggrs:  []Aggregation{{Field: "Amount", Op: SUM, Window: &Window{Op: RUNNING_TOTAL, Period: "UserID"}}},
+		}, &results)
+		assert.ErrorContains(t, err, "window period must be a group field")
+
+		err = builder.Validate(&FilterRequest{
+			Aggrs: []Aggregation{{Field: "Amount", Op: SUM, Window: &Window{Op: PERIOD_CHANGE}}},
+		})
+		assert.ErrorContains(t, err, "requires grouped aggregations")
+	})
+}
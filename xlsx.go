@@ -0,0 +1,118 @@
+package querybuild
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// xlsxStaticFiles 工作簿中与数据无关的固定文件
+var xlsxStaticFiles = []struct{ name, content string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// xlsxWriter 以单个工作表流式写出 XLSX 工作簿，数值写为数字单元格，其他取值写为内联字符串
+type xlsxWriter struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	err   error
+}
+
+// newXLSXWriter 创建 XLSX 输出
+func newXLSXWriter(w io.Writer) *xlsxWriter {
+	x := &xlsxWriter{zw: zip.NewWriter(w)}
+	for _, file := range xlsxStaticFiles {
+		f, err := x.zw.Create(file.name)
+		if err == nil {
+			_, err = io.WriteString(f, file.content)
+		}
+		if err != nil {
+			x.err = err
+			return x
+		}
+	}
+
+	f, err := x.zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		x.err = err
+		return x
+	}
+	x.sheet = bufio.NewWriter(f)
+	x.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return x
+}
+
+func (x *xlsxWriter) WriteHeader(headers []string) error {
+	values := make([]interface{}, len(headers))
+	for i, header := range headers {
+		values[i] = header
+	}
+	return x.WriteRow(values)
+}
+
+func (x *xlsxWriter) WriteRow(values []interface{}) error {
+	if x.err != nil {
+		return x.err
+	}
+	x.sheet.WriteString("<row>")
+	for _, value := range values {
+		if number, ok := xlsxNumber(value); ok {
+			x.sheet.WriteString("<c><v>" + number + "</v></c>")
+			continue
+		}
+		x.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(x.sheet, []byte(exportString(value))); err != nil {
+			x.err = err
+			return err
+		}
+		x.sheet.WriteString("</t></is></c>")
+	}
+	_, x.err = x.sheet.WriteString("</row>")
+	return x.err
+}
+
+func (x *xlsxWriter) Close() error {
+	if x.err != nil {
+		return x.err
+	}
+	x.sheet.WriteString("</sheetData></worksheet>")
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zw.Close()
+}
+
+// xlsxNumber 将数值类型的取值转换为数字单元格的文本
+func xlsxNumber(value interface{}) (string, bool) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64), true
+	}
+	return "", false
+}